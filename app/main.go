@@ -2,29 +2,374 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
 	ginadapter "github.com/awslabs/aws-lambda-go-api-proxy/gin"
 	"github.com/gin-gonic/gin"
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth/authorizer"
+	auth_handler "github.com/williamkoller/cloud-architecture-golang/internal/auth/handler"
+	auth_repository "github.com/williamkoller/cloud-architecture-golang/internal/auth/repository"
+	auth_router "github.com/williamkoller/cloud-architecture-golang/internal/auth/router"
+	group_handler "github.com/williamkoller/cloud-architecture-golang/internal/groups/handler"
+	group_repository "github.com/williamkoller/cloud-architecture-golang/internal/groups/repository"
+	group_router "github.com/williamkoller/cloud-architecture-golang/internal/groups/router"
 	"github.com/williamkoller/cloud-architecture-golang/internal/metrics"
 	metrics_handler "github.com/williamkoller/cloud-architecture-golang/internal/metrics/handler"
 	metrics_router "github.com/williamkoller/cloud-architecture-golang/internal/metrics/router"
+	"github.com/williamkoller/cloud-architecture-golang/internal/notify"
+	org_domain "github.com/williamkoller/cloud-architecture-golang/internal/org/domain"
+	org_handler "github.com/williamkoller/cloud-architecture-golang/internal/org/handler"
+	org_repository "github.com/williamkoller/cloud-architecture-golang/internal/org/repository"
+	org_router "github.com/williamkoller/cloud-architecture-golang/internal/org/router"
+	"github.com/williamkoller/cloud-architecture-golang/internal/passwordreset"
+	rbac_handler "github.com/williamkoller/cloud-architecture-golang/internal/rbac/handler"
+	rbac_repository "github.com/williamkoller/cloud-architecture-golang/internal/rbac/repository"
+	rbac_router "github.com/williamkoller/cloud-architecture-golang/internal/rbac/router"
+	"github.com/williamkoller/cloud-architecture-golang/internal/secrets"
+	"github.com/williamkoller/cloud-architecture-golang/internal/tenant"
+	usr_domain "github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/events"
 	"github.com/williamkoller/cloud-architecture-golang/internal/usr/handler"
 	"github.com/williamkoller/cloud-architecture-golang/internal/usr/repository"
 	usr_router "github.com/williamkoller/cloud-architecture-golang/internal/usr/router"
+	"github.com/williamkoller/cloud-architecture-golang/internal/verification"
 )
 
 var (
 	router      *gin.Engine
 	ginLambdaV2 *ginadapter.GinLambdaV2
+	userPurger  *repository.Purger
+	tokenSweep  *auth_repository.Sweeper
+	keyRotator  *auth.KeyRotator
+	userRelay   *repository.OutboxRelay
+	// lambdaAuthorizer atende quando o binário roda como autorizador (lambdaHandler)
+	lambdaAuthorizer *authorizer.Authorizer
 )
 
+// envDuration lê uma duração (ex.: "720h") do ambiente, com valor padrão
+func envDuration(key string, def time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+		log.Printf("invalid %s=%q, using %s", key, v, def)
+	}
+	return def
+}
+
+// envInt lê um inteiro do ambiente, com valor padrão
+func envInt(key string, def int) int {
+	if v := os.Getenv(key); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+		log.Printf("invalid %s=%q, using %d", key, v, def)
+	}
+	return def
+}
+
+// passwordPolicyFromEnv monta a política de senha a partir de PASSWORD_*
+func passwordPolicyFromEnv() vo.PasswordPolicy {
+	policy := vo.DefaultPasswordPolicy()
+	policy.MinLength = envInt("PASSWORD_MIN_LENGTH", policy.MinLength)
+	policy.MaxLength = envInt("PASSWORD_MAX_LENGTH", policy.MaxLength)
+	policy.RequireUpper = os.Getenv("PASSWORD_REQUIRE_UPPER") == "true"
+	policy.RequireLower = os.Getenv("PASSWORD_REQUIRE_LOWER") == "true"
+	policy.RequireDigit = os.Getenv("PASSWORD_REQUIRE_DIGIT") == "true"
+	policy.RequireSymbol = os.Getenv("PASSWORD_REQUIRE_SYMBOL") == "true"
+	policy.RejectPersonalInfo = os.Getenv("PASSWORD_ALLOW_PERSONAL_INFO") != "true"
+
+	if path := os.Getenv("PASSWORD_DENYLIST_FILE"); path != "" {
+		list, err := vo.LoadDenyList(path, os.Getenv("PASSWORD_DENYLIST_BLOOM") == "true")
+		if err != nil {
+			log.Printf("could not load password deny-list %s: %v", path, err)
+		} else {
+			policy.DenyList = list
+		}
+	}
+	return policy
+}
+
+// hasherFromEnv escolhe o algoritmo de hash de senha (PASSWORD_HASHER) e seu custo.
+// Hashes antigos continuam válidos e são migrados no próximo login.
+func hasherFromEnv() vo.Hasher {
+	name := os.Getenv("PASSWORD_HASHER")
+	if name == "" {
+		name = vo.AlgorithmBcrypt
+	}
+
+	switch strings.ToLower(name) {
+	case vo.AlgorithmArgon2id:
+		h := vo.DefaultArgon2idHasher()
+		h.Memory = uint32(envInt("PASSWORD_ARGON2_MEMORY_KB", int(h.Memory)))
+		h.Time = uint32(envInt("PASSWORD_ARGON2_TIME", int(h.Time)))
+		h.Threads = uint8(envInt("PASSWORD_ARGON2_THREADS", int(h.Threads)))
+		return h
+	case vo.AlgorithmScrypt:
+		h := vo.DefaultScryptHasher()
+		h.LogN = uint8(envInt("PASSWORD_SCRYPT_LN", int(h.LogN)))
+		return h
+	case vo.AlgorithmBcrypt:
+		h := vo.DefaultBcryptHasher()
+		h.Cost = envInt("PASSWORD_BCRYPT_COST", h.Cost)
+		return h
+	}
+
+	log.Printf("invalid PASSWORD_HASHER=%q, using %s", name, vo.AlgorithmBcrypt)
+	return vo.DefaultBcryptHasher()
+}
+
+// outboxFlushMiddleware drena o outbox ao fim de cada escrita. No Lambda o
+// processo congela entre invocações e o relay em segundo plano não chega a rodar.
+func outboxFlushMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			return
+		}
+		if _, err := userRelay.RelayOnce(context.WithoutCancel(c.Request.Context())); err != nil {
+			log.Printf("outbox flush failed: %v", err)
+		}
+	}
+}
+
+// passwordResetFromEnv monta a recuperação de senha, ou devolve nil quando não
+// há por onde entregar o link. O token dá acesso à conta: sai pelo webhook de
+// notificações e só vai para o log em modo local.
+func passwordResetFromEnv(webhook *notify.Webhook) *passwordreset.Service {
+	var notifier passwordreset.Notifier
+	switch {
+	case webhook != nil:
+		notifier = passwordreset.NotifierFunc(func(ctx context.Context, m passwordreset.Message) error {
+			return webhook.Send(ctx, "password_reset", m)
+		})
+	case os.Getenv("LOCAL") == "true":
+		notifier = passwordreset.LogNotifier{}
+	default:
+		return nil
+	}
+
+	return passwordreset.NewService(notifier,
+		passwordreset.WithTTL(envDuration("PASSWORD_RESET_TOKEN_TTL", 30*time.Minute)),
+		passwordreset.WithLinkBase(os.Getenv("PASSWORD_RESET_LINK_BASE")),
+		passwordreset.WithRequestLimit(
+			envDuration("PASSWORD_RESET_REQUEST_INTERVAL", time.Minute),
+			envInt("PASSWORD_RESET_MAX_PER_HOUR", 5),
+		),
+	)
+}
+
+// secretFromEnv lê o segredo da variável de ambiente de mesmo nome ou, sem
+// ela, do provider de JWT_KEY_PROVIDER
+func secretFromEnv(name string) ([]byte, error) {
+	if v := os.Getenv(name); v != "" {
+		return []byte(v), nil
+	}
+	return secretsProviderFromEnv().Secret(context.Background(), name)
+}
+
+// notifyWebhookFromEnv devolve o serviço de envio das notificações
+// (NOTIFY_WEBHOOK_URL), com o token do segredo NOTIFY_WEBHOOK_TOKEN, ou nil
+// sem ele
+func notifyWebhookFromEnv() *notify.Webhook {
+	url := os.Getenv("NOTIFY_WEBHOOK_URL")
+	if url == "" {
+		return nil
+	}
+	token, err := secretFromEnv("NOTIFY_WEBHOOK_TOKEN")
+	if err != nil && !errors.Is(err, secrets.ErrNotFound) {
+		log.Fatalf("could not read NOTIFY_WEBHOOK_TOKEN: %v", err)
+	}
+	return notify.NewWebhook(url, strings.TrimSpace(string(token)))
+}
+
+// verificationFromEnv monta a verificação de email, ou devolve nil quando não
+// há por onde entregar o link. O link é uma credencial: sai pelo webhook de
+// notificações e só em modo local vai para o log ou, com VERIFICATION_MAIL_FILE,
+// para um arquivo JSONL. A chave (segredo VERIFICATION_SIGNING_KEY) precisa ser
+// a mesma em todas as instâncias; só em modo local ela é gerada no início.
+func verificationFromEnv(webhook *notify.Webhook) *verification.Service {
+	local := os.Getenv("LOCAL") == "true"
+
+	var notifier verification.Notifier
+	switch path := os.Getenv("VERIFICATION_MAIL_FILE"); {
+	case webhook != nil:
+		notifier = verification.NotifierFunc(func(ctx context.Context, m verification.Message) error {
+			return webhook.Send(ctx, "email_verification", m)
+		})
+	case local && path != "":
+		notifier = verification.NewFileNotifier(path)
+	case local:
+		notifier = verification.LogNotifier{}
+	default:
+		return nil
+	}
+
+	key, err := secretFromEnv("VERIFICATION_SIGNING_KEY")
+	switch {
+	case err == nil:
+	case errors.Is(err, secrets.ErrNotFound) && local:
+		if key, err = verification.RandomKey(); err != nil {
+			log.Fatalf("could not generate verification key: %v", err)
+		}
+		log.Println("VERIFICATION_SIGNING_KEY not set; using an ephemeral key")
+	case errors.Is(err, secrets.ErrNotFound):
+		log.Fatalf("email verification needs VERIFICATION_SIGNING_KEY: links signed with a generated key fail on other instances")
+	default:
+		log.Fatalf("could not read VERIFICATION_SIGNING_KEY: %v", err)
+	}
+	signer, err := verification.NewSigner(key)
+	if err != nil {
+		log.Fatalf("invalid VERIFICATION_SIGNING_KEY: %v", err)
+	}
+
+	return verification.NewService(signer, notifier,
+		verification.WithTTL(envDuration("VERIFICATION_TOKEN_TTL", 24*time.Hour)),
+		verification.WithLinkBase(os.Getenv("VERIFICATION_LINK_BASE")),
+		verification.WithResendLimit(
+			envDuration("VERIFICATION_RESEND_INTERVAL", time.Minute),
+			envInt("VERIFICATION_RESEND_MAX_PER_HOUR", 5),
+		),
+	)
+}
+
+// secretsProviderFromEnv escolhe de onde vêm os segredos (JWT_KEY_PROVIDER):
+// env (padrão), file (arquivos em JWT_KEY_DIR) ou secretsmanager (extensão
+// AWS Parameters and Secrets do Lambda). Local e Lambda usam os mesmos nomes.
+func secretsProviderFromEnv() secrets.Provider {
+	switch p := os.Getenv("JWT_KEY_PROVIDER"); p {
+	case "", "env":
+		if os.Getenv("JWT_PRIVATE_KEY_FILE") != "" {
+			return secrets.Files{}
+		}
+		return secrets.Env{}
+	case "file":
+		return secrets.Files{Dir: os.Getenv("JWT_KEY_DIR")}
+	case "secretsmanager":
+		return secrets.NewLambdaExtension()
+	default:
+		log.Fatalf("unknown JWT_KEY_PROVIDER %q (use env, file or secretsmanager)", p)
+		return nil
+	}
+}
+
+// signingKeysFromEnv monta as chaves de assinatura. JWT_ALGORITHM escolhe HS256
+// (padrão) ou RS256/EdDSA. A chave ativa vem do segredo JWT_SIGNING_KEY (por
+// padrão JWT_SECRET em HS256, JWT_PRIVATE_KEY ou JWT_PRIVATE_KEY_FILE nos
+// demais) e a próxima, opcional, de JWT_NEXT_SIGNING_KEY; o provider é relido
+// a cada JWT_KEY_ROTATION_INTERVAL (padrão 5m). Com JWT_KEY_ROTATION=generate,
+// ou sem chave configurada em modo local, as chaves são geradas no processo e
+// trocadas a cada intervalo (padrão 24h); servem a uma única instância, não
+// sobrevivem a um reinício e por isso não são aceitas no Lambda. Fora do modo
+// local a falta da chave impede a inicialização. O kid de cada chave é derivado dela.
+func signingKeysFromEnv(accessTTL time.Duration) (*auth.KeyManager, *auth.KeyRotator) {
+	alg := os.Getenv("JWT_ALGORITHM")
+	if alg == "" {
+		alg = auth.AlgHS256
+	}
+	if alg != auth.AlgHS256 && alg != auth.AlgRS256 && alg != auth.AlgEdDSA {
+		log.Fatalf("unknown JWT_ALGORITHM %q (use HS256, RS256 or EdDSA)", alg)
+	}
+
+	provider := secretsProviderFromEnv()
+	activeName := os.Getenv("JWT_SIGNING_KEY")
+	switch {
+	case activeName != "":
+	case os.Getenv("JWT_PRIVATE_KEY_FILE") != "":
+		activeName = os.Getenv("JWT_PRIVATE_KEY_FILE")
+	case alg == auth.AlgHS256:
+		activeName = "JWT_SECRET"
+	default:
+		activeName = "JWT_PRIVATE_KEY"
+	}
+
+	// A chave substituída continua aceita enquanto houver tokens assinados por ela
+	overlap := envDuration("JWT_KEY_OVERLAP", 2*accessTTL)
+	onRotate := func(kid string) {
+		metrics.SigningKeyRotationsInc()
+		log.Printf("signing key rotated; new kid %s", kid)
+	}
+
+	ctx := context.Background()
+	source := auth.ProviderKeys(provider, alg, activeName, os.Getenv("JWT_NEXT_SIGNING_KEY"))
+	interval := envDuration("JWT_KEY_ROTATION_INTERVAL", 5*time.Minute)
+	active, _, err := source(ctx)
+	if os.Getenv("JWT_KEY_ROTATION") == "generate" || errors.Is(err, secrets.ErrNotFound) {
+		// Chaves geradas no processo servem a uma única instância: não chegam ao
+		// autorizador nem às outras instâncias do Lambda, que recusariam os tokens
+		switch {
+		case authorizerMode():
+			log.Fatalf("the authorizer needs the signing keys shared with the API: set %s in the secrets provider", activeName)
+		case os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != "":
+			log.Fatalf("signing keys generated in process do not work on Lambda: set %s in the secrets provider", activeName)
+		case err != nil && os.Getenv("LOCAL") != "true":
+			log.Fatalf("%s not found: set it in the secrets provider, or JWT_KEY_ROTATION=generate for a single instance", activeName)
+		}
+		if err != nil {
+			log.Printf("%s not found; generating signing keys in process", activeName)
+		}
+		source = auth.GeneratedKeys(alg)
+		interval = envDuration("JWT_KEY_ROTATION_INTERVAL", 24*time.Hour)
+		active, err = auth.GenerateSigningKey(alg)
+	}
+	if err != nil {
+		log.Fatalf("invalid JWT signing key: %v", err)
+	}
+
+	keys := auth.NewKeyManager(active, auth.WithRotationOverlap(overlap))
+	rotator := auth.NewKeyRotator(keys, source, interval, onRotate)
+	// Publica a próxima chave, quando houver, já no início
+	if _, err := rotator.RotateOnce(ctx); err != nil {
+		log.Fatalf("invalid JWT signing key: %v", err)
+	}
+	return keys, rotator
+}
+
+// tokenIssuerFromEnv monta o emissor dos tokens de acesso sobre as chaves em uso
+func tokenIssuerFromEnv(keys *auth.KeyManager, accessTTL time.Duration) *auth.TokenIssuer {
+	return auth.NewTokenIssuerWithKeys(keys,
+		auth.WithTokenTTL(accessTTL),
+		auth.WithIssuer(os.Getenv("JWT_ISSUER")),
+		auth.WithAudience(os.Getenv("JWT_AUDIENCE")),
+	)
+}
+
+// bootstrapAdminFromEnv cria o primeiro Admin da organização padrão a partir de
+// BOOTSTRAP_ADMIN_EMAIL e BOOTSTRAP_ADMIN_PASSWORD, se ele ainda não existir:
+// com as rotas protegidas, sem ele ninguém consegue listar usuários nem
+// conceder papéis
+func bootstrapAdminFromEnv(repo repository.UserRepository) {
+	email, password := os.Getenv("BOOTSTRAP_ADMIN_EMAIL"), os.Getenv("BOOTSTRAP_ADMIN_PASSWORD")
+	if email == "" || password == "" {
+		return
+	}
+
+	admin, err := usr_domain.NewUser("Administrator", email, password, true, usr_domain.UserTypeAdmin)
+	if err != nil {
+		log.Fatalf("invalid bootstrap admin: %v", err)
+	}
+	ctx := context.Background()
+	if _, found, err := repo.GetByEmail(ctx, admin.Email); err != nil || found {
+		return
+	}
+	if _, err := repo.Create(ctx, admin); err != nil && err != repository.ErrAlreadyExists {
+		log.Fatalf("could not create bootstrap admin: %v", err)
+	}
+	log.Printf("bootstrap admin %s created", admin.Email)
+}
+
 func healthMiddleware() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		if c.Request.URL.Path == "/health" {
@@ -39,6 +384,11 @@ func healthMiddleware() gin.HandlerFunc {
 func init() {
 	metrics.Init("cloud-arch-golang", "1.0.0")
 
+	// EMAIL_FOLD_LOCAL_PART=false preserva maiúsculas na parte local do email
+	vo.ConfigureEmail(vo.EmailOptions{FoldLocalPart: os.Getenv("EMAIL_FOLD_LOCAL_PART") != "false"})
+	vo.ConfigurePasswordPolicy(passwordPolicyFromEnv())
+	vo.ConfigureHasher(hasherFromEnv())
+
 	gin.SetMode(gin.ReleaseMode)
 	router = gin.New()
 
@@ -51,6 +401,9 @@ func init() {
 	router.Use(metrics.Middleware())
 
 	api := router.Group("/api")
+	if os.Getenv("LOCAL") != "true" {
+		api.Use(outboxFlushMiddleware())
+	}
 
 	router.GET("/health", func(c *gin.Context) {
 		c.String(http.StatusOK, "OK")
@@ -67,14 +420,160 @@ func init() {
 	mh := metrics_handler.NewMetricsHandler()
 	metrics_router.RegisterMetricsRoute(router, mh)
 
-	userRepo := repository.NewInMemoryUserRepository()
-	userHandler := handler.NewUserHandler(userRepo)
-	usr_router.RegisterUserRoutes(api, userHandler)
+	// Eventos de domínio dos usuários; novos assinantes (webhooks, notificações) entram aqui.
+	// O repositório grava os eventos no outbox junto com cada escrita e o relay os
+	// entrega ao dispatcher, então nada se perde se o processo parar entre os dois.
+	userEvents := events.NewDispatcher()
+	userEvents.SubscribeAll(events.AuditLog)
+	userOutbox := repository.NewOutbox()
+	userRelay = repository.NewOutboxRelay(
+		userOutbox,
+		events.OutboxPublisher(userEvents),
+		envDuration("OUTBOX_RELAY_INTERVAL", time.Second),
+		envInt("OUTBOX_RELAY_BATCH", 100),
+		func(s repository.OutboxStats) {
+			metrics.ObserveOutbox(s.Published, s.Failed, s.Parked, s.Pending, s.DeadLetters, s.OldestAge)
+		},
+		repository.WithMaxAttempts(envInt("OUTBOX_MAX_ATTEMPTS", repository.DefaultMaxAttempts)),
+	)
+
+	// Autenticação: um token Bearer válido vira o principal da requisição e os
+	// guardas de permissão de cada rota decidem o resto. Tokens de usuários
+	// excluídos, inativos ou com as sessões encerradas são recusados. Serviços
+	// se autenticam com "Authorization: ApiKey" e valem pelos escopos da chave.
+	userRepos := repository.NewTenantRepositories(repository.WithOutbox(userOutbox))
+	accessTTL := envDuration("JWT_ACCESS_TTL", 15*time.Minute)
+	var signingKeys *auth.KeyManager
+	signingKeys, keyRotator = signingKeysFromEnv(accessTTL)
+	tokenIssuer := tokenIssuerFromEnv(signingKeys, accessTTL)
+	apiKeys := auth_repository.NewInMemoryAPIKeyRepository()
+	credentials := []auth.AuthenticateOption{
+		auth.WithSessionCheck(auth_handler.ActiveSessions(userRepos)),
+		auth.WithAPIKeys(auth_handler.APIKeys(apiKeys)),
+	}
+	// Atrás do autorizador Lambda, o principal vem no evento e o token não é
+	// conferido de novo; a sessão sim, porque os usuários estão só aqui
+	api.Use(auth.Authenticate(tokenIssuer, append(credentials, auth.WithTrustedPrincipal(authorizer.FromGateway))...))
+	// O autorizador roda em outra função, sem os usuários nem as API keys desta
+	// (em memória): confere só a assinatura e as claims do token, com as mesmas
+	// chaves de assinatura da API (JWT_KEY_PROVIDER), e deixa as API keys passarem
+	// para a API conferir
+	lambdaAuthorizer = authorizer.New(auth.NewAuthenticator(tokenIssuer), authorizer.DeferAPIKeys())
+	bootstrapAdminFromEnv(userRepos.For(org_domain.DefaultOrgID))
+
+	// Catálogo de papéis e permissões; é também o resolver dos guardas. É um só
+	// para todas as organizações, então só a plataforma (organização padrão) o altera.
+	roleRepo := rbac_repository.NewInMemoryRoleRepository()
+	rbac_router.RegisterRoleRoutes(api, rbac_handler.NewRoleHandler(roleRepo), rbac_router.WithPermissionGuards(roleRepo))
+
+	// Organizações (tenants): cada uma tem seu próprio repositório de usuários.
+	// Nas rotas /users a organização vem do header X-Org-ID, do subdomínio de
+	// TENANT_BASE_DOMAIN ou da claim org da credencial; sem nenhum deles, a padrão.
+	// Criar e (des)ativar organizações é da plataforma; um tenant só vê e renomeia a sua.
+	// O cadastro anônimo escolhe a organização só pelo subdomínio; pelo header ou
+	// pela rota, apenas nas que ligam selfSignup.
+	orgRepo := org_repository.NewInMemoryOrgRepository()
+	org_router.RegisterOrgRoutes(api, org_handler.NewOrgHandler(orgRepo), org_router.WithPermissionGuards(roleRepo))
+	resolveOrg := tenant.Middleware(tenant.Resolver{
+		Header:     tenant.DefaultHeader,
+		BaseDomain: os.Getenv("TENANT_BASE_DOMAIN"),
+		Default:    org_domain.DefaultOrgID,
+	}, orgRepo)
+
+	userOpts := []handler.Option{
+		handler.WithTenants(userRepos),
+		handler.WithRoleCatalog(roleRepo),
+		handler.WithPermissionResolver(roleRepo),
+	}
+
+	// Verificação de email: usuários nascem em pending_verification e o link sai
+	// pelo outbox. Fica desligada sem um notifier (webhook ou modo local) e com
+	// EMAIL_VERIFICATION_REQUIRED=false; desligada, as rotas /users/verify dão 404.
+	webhook := notifyWebhookFromEnv()
+	if os.Getenv("EMAIL_VERIFICATION_REQUIRED") != "false" {
+		if verifier := verificationFromEnv(webhook); verifier != nil {
+			userEvents.Subscribe(usr_domain.EventUserCreated, verification.SendOnCreated(verifier))
+			userOpts = append(userOpts, handler.WithEmailVerification(verifier))
+		} else {
+			log.Println("email verification disabled: set NOTIFY_WEBHOOK_URL to deliver the links")
+		}
+	}
+
+	// Recuperação de senha: tokens de uso único, guardados só como hash e
+	// enviados pelo outbox. Como a verificação, fica desligada sem um notifier
+	// (webhook ou modo local); desligada, as rotas /password dão 404.
+	if resets := passwordResetFromEnv(webhook); resets != nil {
+		userEvents.Subscribe(usr_domain.EventUserPasswordResetRequested, passwordreset.SendOnRequested(resets))
+		userOpts = append(userOpts, handler.WithPasswordReset(resets))
+	} else {
+		log.Println("password reset disabled: set NOTIFY_WEBHOOK_URL to deliver the links")
+	}
+	userHandler := handler.NewUserHandler(userRepos.For(org_domain.DefaultOrgID), userOpts...)
+	userGuards := usr_router.WithPermissionGuards(roleRepo)
+	usr_router.RegisterUserRoutes(api.Group("", resolveOrg), userHandler, userGuards)
+	usr_router.RegisterOrgUserRoutes(api, userHandler, resolveOrg, userGuards)
+
+	// Login com email e senha; o token traz o usuário, os papéis e a organização.
+	// Os refresh tokens (padrão 30 dias) são trocados a cada uso e caem junto
+	// com as sessões do usuário: redefinição de senha, exclusão ou bloqueio.
+	refreshTokens := auth_repository.NewInMemoryRefreshTokenRepository()
+	revokeSessions := auth_repository.RevokeUserSessions(refreshTokens)
+	userEvents.Subscribe(usr_domain.EventUserSessionsRevoked, revokeSessions)
+	userEvents.Subscribe(usr_domain.EventUserDeleted, revokeSessions)
+	userEvents.Subscribe(usr_domain.EventUserStatusChanged, revokeSessions)
+	tokenSweep = auth_repository.NewSweeper(
+		refreshTokens,
+		envDuration("REFRESH_TOKEN_SWEEP_INTERVAL", time.Hour),
+		metrics.RefreshTokensSweptAdd,
+	)
+	mfaIssuer := os.Getenv("MFA_ISSUER")
+	if mfaIssuer == "" {
+		mfaIssuer = "cloud-arch-golang"
+	}
+	authHandler := auth_handler.NewAuthHandler(userRepos, tokenIssuer,
+		auth_handler.WithRefreshTokens(refreshTokens, envDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour)),
+		auth_handler.WithMFA(auth_repository.NewInMemoryMFARepository(), auth_repository.NewInMemoryMFAChallengeRepository(), mfaIssuer),
+		auth_handler.WithPermissionResolver(roleRepo),
+	)
+	authGuards := auth_router.WithPermissionGuards(roleRepo)
+	auth_router.RegisterAuthRoutes(api.Group("", resolveOrg), authHandler, authGuards)
+	auth_router.RegisterAuthRoutes(api.Group("/orgs/:org", resolveOrg), authHandler, authGuards)
+	// Chaves públicas para quem confere os tokens offline; o cache dos clientes
+	// deve ser menor que a antecedência com que a próxima chave é publicada
+	auth_router.RegisterJWKSRoute(router, auth_handler.NewJWKSHandler(signingKeys, envDuration("JWKS_MAX_AGE", 5*time.Minute)))
+
+	apiKeyHandler := auth_handler.NewAPIKeyHandler(apiKeys, roleRepo)
+	apiKeyGuards := auth_router.WithPermissionGuards(roleRepo)
+	auth_router.RegisterAPIKeyRoutes(api.Group("", resolveOrg), apiKeyHandler, apiKeyGuards)
+	auth_router.RegisterAPIKeyRoutes(api.Group("/orgs/:org", resolveOrg), apiKeyHandler, apiKeyGuards)
+
+	// Grupos de usuários; a exclusão de um usuário o retira dos grupos via outbox
+	groupRepo := group_repository.NewInMemoryGroupRepository()
+	userEvents.Subscribe(usr_domain.EventUserDeleted, group_repository.RemoveDeletedMembers(groupRepo))
+	groupHandler := group_handler.NewGroupHandler(groupRepo, userRepos)
+	groupGuards := group_router.WithPermissionGuards(roleRepo)
+	group_router.RegisterGroupRoutes(api.Group("", resolveOrg), groupHandler, groupGuards)
+	group_router.RegisterGroupRoutes(api.Group("/orgs/:org", resolveOrg), groupHandler, groupGuards)
+
+	// Usuários excluídos logicamente são removidos após a retenção (padrão 30 dias)
+	userPurger = repository.NewPurger(
+		userRepos,
+		envDuration("USER_PURGE_RETENTION", 30*24*time.Hour),
+		envDuration("USER_PURGE_INTERVAL", time.Hour),
+		metrics.UsersPurgedAdd,
+	)
 
 	ginLambdaV2 = ginadapter.NewV2(router)
 }
 
 func main() {
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go userPurger.Run(workersCtx)
+	go tokenSweep.Run(workersCtx)
+	go keyRotator.Run(workersCtx)
+	go userRelay.Run(workersCtx)
+
 	if os.Getenv("LOCAL") == "true" {
 		log.Println("Starting server locally on :8080")
 
@@ -107,6 +606,7 @@ func main() {
 
 		<-sigChan
 		log.Println("Shutting down server gracefully...")
+		stopWorkers()
 
 		// Graceful shutdown com timeout generoso
 		ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
@@ -116,10 +616,48 @@ func main() {
 			log.Fatalf("Server forced to shutdown: %v", err)
 		}
 
+		// Última rodada para não deixar eventos no outbox
+		if _, err := userRelay.RelayOnce(ctx); err != nil {
+			log.Printf("final outbox flush failed: %v", err)
+		}
+
 		log.Println("Server stopped successfully")
 		return
 	}
 
 	// Para execução em Lambda
-	lambda.Start(ginLambdaV2.ProxyWithContext)
+	lambda.Start(lambdaHandler())
+}
+
+// lambdaHandler escolhe o papel do binário no Lambda pela variável
+// LAMBDA_MODE ou, sem ela, pelo nome do handler configurado na função
+// (_HANDLER): "proxy" (padrão) atende a API; "authorizer" e "authorizer-iam"
+// são o autorizador da HTTP API, com resposta simples ou política IAM.
+func lambdaHandler() interface{} {
+	switch lambdaMode() {
+	case "authorizer", "authorizer-simple":
+		log.Println("Starting as API Gateway authorizer (simple responses)")
+		return lambdaAuthorizer.Simple
+	case "authorizer-iam":
+		log.Println("Starting as API Gateway authorizer (IAM policies)")
+		return lambdaAuthorizer.IAMPolicy
+	default:
+		return ginLambdaV2.ProxyWithContext
+	}
+}
+
+func lambdaMode() string {
+	if mode := os.Getenv("LAMBDA_MODE"); mode != "" {
+		return mode
+	}
+	return os.Getenv("_HANDLER")
+}
+
+// authorizerMode indica se o binário roda como autorizador da HTTP API
+func authorizerMode() bool {
+	switch lambdaMode() {
+	case "authorizer", "authorizer-simple", "authorizer-iam":
+		return true
+	}
+	return false
 }
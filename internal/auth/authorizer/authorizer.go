@@ -0,0 +1,209 @@
+package authorizer
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/awslabs/aws-lambda-go-api-proxy/core"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+)
+
+// Chaves do contexto da autorização. O API Gateway só repassa valores
+// simples, então listas vão separadas por vírgula.
+const (
+	ContextUserID = "userId"
+	ContextRoles  = "roles"
+	ContextOrgID  = "orgId"
+	ContextKeyID  = "keyId"
+	ContextScopes = "scopes"
+	// ContextIssuedAt é a emissão do token, para a API conferir a sessão
+	ContextIssuedAt = "iat"
+)
+
+// Authorizer é o autorizador Lambda (tipo REQUEST) de uma HTTP API do API
+// Gateway. Confere o token ou a API key com as regras do Authenticate e
+// entrega o principal no contexto da autorização; no modo proxy, o
+// Authenticate confia nesse contexto (FromGateway) em vez de conferir o token
+// de novo. Autorizador e API precisam das mesmas chaves de assinatura.
+type Authorizer struct {
+	authenticator *auth.Authenticator
+	deferAPIKeys  bool
+}
+
+// Option configura o Authorizer
+type Option func(*Authorizer)
+
+// DeferAPIKeys deixa passar, sem principal no contexto, as requisições com
+// "Authorization: ApiKey": quem confere a chave é a API, que tem o cadastro.
+func DeferAPIKeys() Option {
+	return func(a *Authorizer) {
+		a.deferAPIKeys = true
+	}
+}
+
+func New(a *auth.Authenticator, opts ...Option) *Authorizer {
+	z := &Authorizer{authenticator: a}
+	for _, opt := range opts {
+		opt(z)
+	}
+	return z
+}
+
+// Simple responde no formato simples (enableSimpleResponses). Credencial
+// recusada vira isAuthorized=false, e o API Gateway responde 403; só falhas
+// de quem confere voltam como erro.
+func (a *Authorizer) Simple(ctx context.Context, req events.APIGatewayV2CustomAuthorizerV2Request) (events.APIGatewayV2CustomAuthorizerSimpleResponse, error) {
+	if a.deferred(req) || anonymous(req) {
+		return events.APIGatewayV2CustomAuthorizerSimpleResponse{IsAuthorized: true}, nil
+	}
+	p, err := a.authenticator.Resolve(ctx, authorization(req))
+	if err != nil {
+		if auth.Rejected(err) {
+			return events.APIGatewayV2CustomAuthorizerSimpleResponse{IsAuthorized: false}, nil
+		}
+		return events.APIGatewayV2CustomAuthorizerSimpleResponse{}, err
+	}
+	return events.APIGatewayV2CustomAuthorizerSimpleResponse{IsAuthorized: true, Context: Context(p)}, nil
+}
+
+// IAMPolicy responde com uma política IAM. A política vale para a API
+// inteira, e não só para a rota pedida, porque o API Gateway guarda a
+// resposta em cache por identidade e a reaproveita nas outras rotas.
+func (a *Authorizer) IAMPolicy(ctx context.Context, req events.APIGatewayV2CustomAuthorizerV2Request) (events.APIGatewayV2CustomAuthorizerIAMPolicyResponse, error) {
+	if a.deferred(req) {
+		return policy("apikey", "Allow", req.RouteArn, nil), nil
+	}
+	if anonymous(req) {
+		return policy("anonymous", "Allow", req.RouteArn, nil), nil
+	}
+	p, err := a.authenticator.Resolve(ctx, authorization(req))
+	if err != nil {
+		if auth.Rejected(err) {
+			return policy("anonymous", "Deny", req.RouteArn, nil), nil
+		}
+		return events.APIGatewayV2CustomAuthorizerIAMPolicyResponse{}, err
+	}
+	return policy(principalID(p), "Allow", req.RouteArn, Context(p)), nil
+}
+
+// anonymous indica uma requisição sem credencial. Passa sem principal no
+// contexto: as rotas públicas (login, cadastro, redefinição de senha) ficam
+// abertas e as demais respondem 401 na API. Só credencial inválida é negada.
+func anonymous(req events.APIGatewayV2CustomAuthorizerV2Request) bool {
+	return strings.TrimSpace(authorization(req)) == ""
+}
+
+// deferred indica uma API key que fica para a API conferir
+func (a *Authorizer) deferred(req events.APIGatewayV2CustomAuthorizerV2Request) bool {
+	scheme, _, _ := strings.Cut(authorization(req), " ")
+	return a.deferAPIKeys && strings.EqualFold(scheme, "ApiKey")
+}
+
+func policy(principalID, effect, routeArn string, context map[string]interface{}) events.APIGatewayV2CustomAuthorizerIAMPolicyResponse {
+	return events.APIGatewayV2CustomAuthorizerIAMPolicyResponse{
+		PrincipalID: principalID,
+		PolicyDocument: events.APIGatewayCustomAuthorizerPolicy{
+			Version: "2012-10-17",
+			Statement: []events.IAMPolicyStatement{{
+				Action:   []string{"execute-api:Invoke"},
+				Effect:   effect,
+				Resource: []string{apiWildcard(routeArn)},
+			}},
+		},
+		Context: context,
+	}
+}
+
+// apiWildcard troca o método e o caminho do ARN da rota por *:
+// arn:aws:execute-api:região:conta:api/estágio/GET/users vira .../api/estágio/*
+func apiWildcard(routeArn string) string {
+	parts := strings.SplitN(routeArn, "/", 3)
+	if len(parts) < 2 {
+		return routeArn
+	}
+	return parts[0] + "/" + parts[1] + "/*"
+}
+
+func principalID(p auth.Principal) string {
+	if p.KeyID != "" {
+		return "apikey:" + p.KeyID
+	}
+	return string(p.UserID)
+}
+
+// authorization lê o header Authorization; no formato 2.0 os nomes chegam em minúsculas
+func authorization(req events.APIGatewayV2CustomAuthorizerV2Request) string {
+	if v, ok := req.Headers["authorization"]; ok {
+		return v
+	}
+	for name, v := range req.Headers {
+		if strings.EqualFold(name, "Authorization") {
+			return v
+		}
+	}
+	return ""
+}
+
+// Context é o contexto da autorização com o principal
+func Context(p auth.Principal) map[string]interface{} {
+	ctx := map[string]interface{}{
+		ContextUserID: string(p.UserID),
+		ContextRoles:  strings.Join(p.Roles, ","),
+		ContextOrgID:  p.OrgID,
+	}
+	if p.IssuedAt != 0 {
+		ctx[ContextIssuedAt] = strconv.FormatInt(p.IssuedAt, 10)
+	}
+	if p.KeyID != "" {
+		scopes := make([]string, len(p.Scopes))
+		for i, s := range p.Scopes {
+			scopes[i] = string(s)
+		}
+		ctx[ContextKeyID] = p.KeyID
+		ctx[ContextScopes] = strings.Join(scopes, ",")
+	}
+	return ctx
+}
+
+// Principal reconstrói o principal do contexto da autorização
+func Principal(ctx map[string]interface{}) (auth.Principal, bool) {
+	userID, _ := ctx[ContextUserID].(string)
+	keyID, _ := ctx[ContextKeyID].(string)
+	if userID == "" && keyID == "" {
+		return auth.Principal{}, false
+	}
+	roles, _ := ctx[ContextRoles].(string)
+	orgID, _ := ctx[ContextOrgID].(string)
+	scopes, _ := ctx[ContextScopes].(string)
+	iat, _ := ctx[ContextIssuedAt].(string)
+	issuedAt, _ := strconv.ParseInt(iat, 10, 64)
+
+	p := auth.Principal{UserID: vo.UserID(userID), Roles: split(roles), OrgID: orgID, KeyID: keyID, IssuedAt: issuedAt}
+	for _, s := range split(scopes) {
+		p.Scopes = append(p.Scopes, rbac.Permission(s))
+	}
+	return p, true
+}
+
+// FromGateway devolve o principal deixado pelo autorizador no evento da
+// HTTP API. Lê o contexto do evento, e não os headers que o adaptador
+// acrescenta: esses o cliente pode forjar.
+func FromGateway(ctx context.Context) (auth.Principal, bool) {
+	rc, ok := core.GetAPIGatewayV2ContextFromContext(ctx)
+	if !ok || rc.Authorizer == nil || rc.Authorizer.Lambda == nil {
+		return auth.Principal{}, false
+	}
+	return Principal(rc.Authorizer.Lambda)
+}
+
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
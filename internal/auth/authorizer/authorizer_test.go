@@ -0,0 +1,256 @@
+package authorizer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/awslabs/aws-lambda-go-api-proxy/core"
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+)
+
+const routeArn = "arn:aws:execute-api:us-east-1:123456789012:abc123/prod/GET/api/v1/users"
+
+func newAuthorizer(t *testing.T) (*Authorizer, *auth.TokenIssuer) {
+	t.Helper()
+	key, err := auth.NewHS256Key("test", []byte(strings.Repeat("s", 32)))
+	if err != nil {
+		t.Fatalf("NewHS256Key: %v", err)
+	}
+	issuer := auth.NewTokenIssuer(key)
+	keys := func(ctx context.Context, key string) (auth.Principal, error) {
+		switch key {
+		case "ak_1.secret":
+			return auth.Principal{KeyID: "ak_1", OrgID: "acme", Scopes: []rbac.Permission{rbac.PermUsersRead}}, nil
+		case "ak_down.secret":
+			return auth.Principal{}, errors.New("store unavailable")
+		}
+		return auth.Principal{}, auth.ErrInvalidAPIKey
+	}
+	return New(auth.NewAuthenticator(issuer, auth.WithAPIKeys(keys))), issuer
+}
+
+func event(authorization string) events.APIGatewayV2CustomAuthorizerV2Request {
+	req := events.APIGatewayV2CustomAuthorizerV2Request{Version: "2.0", Type: "REQUEST", RouteArn: routeArn}
+	if authorization != "" {
+		req.Headers = map[string]string{"authorization": authorization}
+	}
+	return req
+}
+
+func TestSimple_AuthorizesValidCredentials(t *testing.T) {
+	a, issuer := newAuthorizer(t)
+	ctx := context.Background()
+	token, _ := issuer.Issue(auth.Principal{UserID: "user-1", Roles: []string{"user", "auditor"}, OrgID: "acme"})
+
+	resp, err := a.Simple(ctx, event("Bearer "+token.Token))
+	if err != nil || !resp.IsAuthorized {
+		t.Fatalf("valid token: %+v %v", resp, err)
+	}
+	p, ok := Principal(resp.Context)
+	if !ok || p.UserID != "user-1" || p.OrgID != "acme" || len(p.Roles) != 2 || p.KeyID != "" || p.IssuedAt == 0 {
+		t.Fatalf("principal from context: %+v", p)
+	}
+
+	resp, err = a.Simple(ctx, event("ApiKey ak_1.secret"))
+	if p, _ := Principal(resp.Context); err != nil || !resp.IsAuthorized || p.KeyID != "ak_1" || len(p.Scopes) != 1 || p.Scopes[0] != rbac.PermUsersRead {
+		t.Fatalf("api key: %+v %v", resp, err)
+	}
+
+	for _, header := range []string{"Bearer nope", "ApiKey ak_2.wrong", "Basic abc"} {
+		resp, err := a.Simple(ctx, event(header))
+		if err != nil || resp.IsAuthorized || resp.Context != nil {
+			t.Fatalf("%q should be denied: %+v %v", header, resp, err)
+		}
+	}
+	if _, err := a.Simple(ctx, event("ApiKey ak_down.secret")); err == nil {
+		t.Fatalf("failures of the key store should be errors, not denials")
+	}
+}
+
+func TestIAMPolicy_CoversTheWholeAPI(t *testing.T) {
+	a, issuer := newAuthorizer(t)
+	token, _ := issuer.Issue(auth.Principal{UserID: "user-1", OrgID: "acme"})
+
+	resp, err := a.IAMPolicy(context.Background(), event("Bearer "+token.Token))
+	if err != nil {
+		t.Fatalf("IAMPolicy: %v", err)
+	}
+	stmt := resp.PolicyDocument.Statement[0]
+	if resp.PrincipalID != "user-1" || stmt.Effect != "Allow" || stmt.Resource[0] != "arn:aws:execute-api:us-east-1:123456789012:abc123/prod/*" {
+		t.Fatalf("policy: %+v", resp)
+	}
+
+	resp, _ = a.IAMPolicy(context.Background(), event("Bearer nope"))
+	if resp.PolicyDocument.Statement[0].Effect != "Deny" || resp.Context != nil {
+		t.Fatalf("invalid token should be denied: %+v", resp)
+	}
+}
+
+func TestAnonymous_ReachesPublicRoutes(t *testing.T) {
+	a, issuer := newAuthorizer(t)
+	ctx := context.Background()
+
+	// Sem credencial passa sem principal; quem decide é a rota
+	resp, err := a.Simple(ctx, event(""))
+	if err != nil || !resp.IsAuthorized || resp.Context != nil {
+		t.Fatalf("anonymous request should pass: %+v %v", resp, err)
+	}
+	policy, err := a.IAMPolicy(ctx, event(""))
+	if err != nil || policy.PolicyDocument.Statement[0].Effect != "Allow" || policy.Context != nil {
+		t.Fatalf("anonymous request should pass: %+v %v", policy, err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(auth.Authenticate(issuer, auth.WithTrustedPrincipal(FromGateway)))
+	r.POST("/auth/login", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/me", func(c *gin.Context) {
+		if _, ok := auth.PrincipalFrom(c.Request.Context()); !ok {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	accessor := core.RequestAccessorV2{}
+	for path, want := range map[string]int{"/auth/login": http.StatusOK, "/me": http.StatusUnauthorized} {
+		method := http.MethodGet
+		if path == "/auth/login" {
+			method = http.MethodPost
+		}
+		req, err := accessor.EventToRequestWithContext(ctx, events.APIGatewayV2HTTPRequest{
+			RawPath: path,
+			RequestContext: events.APIGatewayV2HTTPRequestContext{
+				HTTP:       events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: method, Path: path},
+				Authorizer: &events.APIGatewayV2HTTPRequestContextAuthorizerDescription{Lambda: resp.Context},
+			},
+		})
+		if err != nil {
+			t.Fatalf("EventToRequest: %v", err)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Fatalf("anonymous %s %s: got %d, want %d", method, path, w.Code, want)
+		}
+	}
+}
+
+func TestDeferAPIKeys_LeavesKeysToTheAPI(t *testing.T) {
+	_, issuer := newAuthorizer(t)
+	a := New(auth.NewAuthenticator(issuer), DeferAPIKeys())
+	ctx := context.Background()
+
+	resp, err := a.Simple(ctx, event("ApiKey ak_2.wrong"))
+	if err != nil || !resp.IsAuthorized || resp.Context != nil {
+		t.Fatalf("api key should pass without principal: %+v %v", resp, err)
+	}
+	policy, err := a.IAMPolicy(ctx, event("ApiKey ak_2.wrong"))
+	if err != nil || policy.PolicyDocument.Statement[0].Effect != "Allow" || policy.Context != nil {
+		t.Fatalf("api key should pass without principal: %+v %v", policy, err)
+	}
+	if resp, _ := a.Simple(ctx, event("Bearer nope")); resp.IsAuthorized {
+		t.Fatalf("tokens are still verified by the authorizer")
+	}
+}
+
+func TestFromGateway_TrustsOnlyTheEventContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(auth.Authenticate(auth.NewTokenIssuer(mustKey(t)), auth.WithTrustedPrincipal(FromGateway)))
+	r.GET("/me", func(c *gin.Context) {
+		p, _ := auth.PrincipalFrom(c.Request.Context())
+		c.String(http.StatusOK, string(p.UserID))
+	})
+
+	accessor := core.RequestAccessorV2{}
+	event := events.APIGatewayV2HTTPRequest{
+		RawPath: "/me",
+		Headers: map[string]string{"authorization": "Bearer not-checked-again"},
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: http.MethodGet, Path: "/me"},
+			Authorizer: &events.APIGatewayV2HTTPRequestContextAuthorizerDescription{
+				Lambda: Context(auth.Principal{UserID: "user-1", OrgID: "acme"}),
+			},
+		},
+	}
+	req, err := accessor.EventToRequestWithContext(context.Background(), event)
+	if err != nil {
+		t.Fatalf("EventToRequest: %v", err)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "user-1" {
+		t.Fatalf("authorizer context should be trusted: %d %s", w.Code, w.Body.String())
+	}
+
+	// Fora do Lambda, o mesmo token é conferido e recusado
+	plain := httptest.NewRequest(http.MethodGet, "/me", nil)
+	plain.Header.Set("Authorization", "Bearer not-checked-again")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, plain)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("without the event the token must be verified: got %d", w.Code)
+	}
+}
+
+func TestFromGateway_StillChecksTheSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// Sessões encerradas depois da emissão do token
+	check := func(ctx context.Context, c auth.Claims) error {
+		if c.Subject == "user-1" && c.IssuedAt < 2000 {
+			return auth.ErrSessionRevoked
+		}
+		return nil
+	}
+	r := gin.New()
+	r.Use(auth.Authenticate(auth.NewTokenIssuer(mustKey(t)), auth.WithSessionCheck(check), auth.WithTrustedPrincipal(FromGateway)))
+	r.GET("/me", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	accessor := core.RequestAccessorV2{}
+	for _, tc := range []struct {
+		issuedAt int64
+		want     int
+	}{
+		{1000, http.StatusUnauthorized},
+		{3000, http.StatusOK},
+	} {
+		event := events.APIGatewayV2HTTPRequest{
+			RawPath: "/me",
+			RequestContext: events.APIGatewayV2HTTPRequestContext{
+				HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: http.MethodGet, Path: "/me"},
+				Authorizer: &events.APIGatewayV2HTTPRequestContextAuthorizerDescription{
+					Lambda: Context(auth.Principal{UserID: "user-1", OrgID: "acme", IssuedAt: tc.issuedAt}),
+				},
+			},
+		}
+		req, err := accessor.EventToRequestWithContext(context.Background(), event)
+		if err != nil {
+			t.Fatalf("EventToRequest: %v", err)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Fatalf("iat %d: got %d, want %d", tc.issuedAt, w.Code, tc.want)
+		}
+	}
+}
+
+func mustKey(t *testing.T) auth.SigningKey {
+	t.Helper()
+	key, err := auth.NewHS256Key("test", []byte(strings.Repeat("s", 32)))
+	if err != nil {
+		t.Fatalf("NewHS256Key: %v", err)
+	}
+	return key
+}
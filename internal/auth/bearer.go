@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+)
+
+var (
+	// ErrSessionRevoked indica um token válido de uma sessão que não vale mais
+	// (usuário excluído ou inativo, ou sessões encerradas depois da emissão)
+	ErrSessionRevoked = errors.New("session is no longer valid")
+	// ErrInvalidAPIKey cobre chave desconhecida, revogada ou vencida, sem distinguir
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	// ErrMissingCredentials indica que a requisição não trouxe o header Authorization
+	ErrMissingCredentials = errors.New("missing credentials")
+)
+
+// TokenVerifier confere um token de acesso e devolve as claims
+type TokenVerifier interface {
+	Verify(token string) (Claims, error)
+}
+
+// SessionCheck confere, a cada requisição, se a sessão do token ainda vale.
+// Devolve ErrSessionRevoked para recusar o token; outros erros viram 500.
+type SessionCheck func(ctx context.Context, c Claims) error
+
+// APIKeyCheck confere o valor de uma API key e devolve o principal da chave.
+// Devolve ErrInvalidAPIKey para recusar a chave; outros erros viram 500.
+type APIKeyCheck func(ctx context.Context, key string) (Principal, error)
+
+// TrustedPrincipal devolve o principal já autenticado por quem está à frente
+// da aplicação (por exemplo, o autorizador do API Gateway)
+type TrustedPrincipal func(ctx context.Context) (Principal, bool)
+
+// AuthenticateOption configura o Authenticate e o Authenticator
+type AuthenticateOption func(*authenticateConfig)
+
+type authenticateConfig struct {
+	check   SessionCheck
+	apiKeys APIKeyCheck
+	trusted TrustedPrincipal
+}
+
+// WithSessionCheck confere a sessão depois da assinatura e da validade
+func WithSessionCheck(check SessionCheck) AuthenticateOption {
+	return func(cfg *authenticateConfig) {
+		cfg.check = check
+	}
+}
+
+// WithAPIKeys aceita também "Authorization: ApiKey <chave>", conferida por check
+func WithAPIKeys(check APIKeyCheck) AuthenticateOption {
+	return func(cfg *authenticateConfig) {
+		cfg.apiKeys = check
+	}
+}
+
+// WithTrustedPrincipal usa o principal de from, quando houver, sem conferir a
+// assinatura de novo; a sessão de um usuário ainda passa pelo WithSessionCheck.
+// Só serve para fontes que o cliente não controla.
+func WithTrustedPrincipal(from TrustedPrincipal) AuthenticateOption {
+	return func(cfg *authenticateConfig) {
+		cfg.trusted = from
+	}
+}
+
+// Authenticator confere o valor do header Authorization fora de um handler
+// gin, com as mesmas regras do Authenticate
+type Authenticator struct {
+	verifier TokenVerifier
+	cfg      authenticateConfig
+}
+
+func NewAuthenticator(v TokenVerifier, opts ...AuthenticateOption) *Authenticator {
+	a := &Authenticator{verifier: v}
+	for _, opt := range opts {
+		opt(&a.cfg)
+	}
+	return a
+}
+
+// Resolve devolve o principal da credencial. Credencial recusada devolve
+// ErrMissingCredentials, ErrInvalidToken, ErrTokenExpired, ErrSessionRevoked
+// ou ErrInvalidAPIKey; outros erros são falhas de quem confere.
+func (a *Authenticator) Resolve(ctx context.Context, header string) (Principal, error) {
+	if header == "" {
+		return Principal{}, ErrMissingCredentials
+	}
+	scheme, credential, _ := strings.Cut(header, " ")
+	credential = strings.TrimSpace(credential)
+	switch {
+	case credential == "":
+		return Principal{}, ErrInvalidToken
+	case strings.EqualFold(scheme, "Bearer"):
+		return a.bearer(ctx, credential)
+	case strings.EqualFold(scheme, "ApiKey") && a.cfg.apiKeys != nil:
+		return a.cfg.apiKeys(ctx, credential)
+	default:
+		return Principal{}, ErrInvalidToken
+	}
+}
+
+// bearer confere o token de acesso e a sessão
+func (a *Authenticator) bearer(ctx context.Context, token string) (Principal, error) {
+	claims, err := a.verifier.Verify(token)
+	if err != nil {
+		// O motivo exato fica de fora da resposta, exceto o vencimento
+		if !errors.Is(err, ErrTokenExpired) {
+			err = ErrInvalidToken
+		}
+		return Principal{}, err
+	}
+	if a.cfg.check != nil {
+		if err := a.cfg.check(ctx, claims); err != nil {
+			return Principal{}, err
+		}
+	}
+	return Principal{UserID: vo.UserID(claims.Subject), Roles: claims.Roles, OrgID: claims.OrgID, IssuedAt: claims.IssuedAt}, nil
+}
+
+// trusted confere a sessão do principal recebido de quem está à frente, que
+// só conferiu a assinatura e as claims do token. Uma API key chega conferida.
+func (a *Authenticator) trusted(ctx context.Context) (Principal, bool, error) {
+	if a.cfg.trusted == nil {
+		return Principal{}, false, nil
+	}
+	p, ok := a.cfg.trusted(ctx)
+	if !ok {
+		return Principal{}, false, nil
+	}
+	if a.cfg.check != nil && p.KeyID == "" {
+		claims := Claims{Subject: string(p.UserID), Roles: p.Roles, OrgID: p.OrgID, IssuedAt: p.IssuedAt}
+		if err := a.cfg.check(ctx, claims); err != nil {
+			return Principal{}, true, err
+		}
+	}
+	return p, true, nil
+}
+
+// Rejected indica se err é uma credencial recusada, e não uma falha de quem confere
+func Rejected(err error) bool {
+	for _, target := range []error{ErrMissingCredentials, ErrInvalidToken, ErrTokenExpired, ErrSessionRevoked, ErrInvalidAPIKey} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticate lê o token do header "Authorization: Bearer", confere e anexa
+// o principal. Sem o header a requisição segue sem principal: as rotas
+// públicas continuam acessíveis e os guardas respondem 401. Um token
+// presente mas inválido é recusado aqui mesmo, com 401. Com WithAPIKeys, o
+// esquema ApiKey é aceito nas mesmas condições; com WithTrustedPrincipal, o
+// principal já autenticado dispensa a conferência do token, mas não a da sessão.
+func Authenticate(v TokenVerifier, opts ...AuthenticateOption) gin.HandlerFunc {
+	a := NewAuthenticator(v, opts...)
+
+	return func(c *gin.Context) {
+		p, ok, err := a.trusted(c.Request.Context())
+		if !ok {
+			header := c.GetHeader("Authorization")
+			if header == "" {
+				c.Next()
+				return
+			}
+			p, err = a.Resolve(c.Request.Context(), header)
+		}
+		switch {
+		case err == nil:
+			SetPrincipal(c, p)
+			c.Next()
+		case errors.Is(err, ErrInvalidAPIKey):
+			c.Header("WWW-Authenticate", `ApiKey error="invalid_key", error_description="`+ErrInvalidAPIKey.Error()+`"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": ErrInvalidAPIKey.Error()})
+		case Rejected(err):
+			rejectToken(c, err)
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+	}
+}
+
+// rejectToken responde 401 com o desafio Bearer da RFC 6750
+func rejectToken(c *gin.Context, err error) {
+	if !errors.Is(err, ErrTokenExpired) && !errors.Is(err, ErrSessionRevoked) {
+		err = ErrInvalidToken
+	}
+	c.Header("WWW-Authenticate", `Bearer error="invalid_token", error_description="`+err.Error()+`"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+}
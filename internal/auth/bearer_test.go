@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+)
+
+func TestAuthenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	issuer := NewTokenIssuer(mustHS256(t, "h1"), WithIssuerClock(func() time.Time { return now }))
+	valid, err := issuer.Issue(Principal{UserID: "u1", Roles: []string{"admin"}, OrgID: "acme"})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	revoked, _ := issuer.Issue(Principal{UserID: "gone"})
+	broken, _ := issuer.Issue(Principal{UserID: "broken"})
+	expired, _ := NewTokenIssuer(mustHS256(t, "h1"), WithTokenTTL(time.Minute),
+		WithIssuerClock(func() time.Time { return now.Add(-time.Hour) })).Issue(Principal{UserID: "u1"})
+
+	check := func(ctx context.Context, c Claims) error {
+		switch c.Subject {
+		case "gone":
+			return ErrSessionRevoked
+		case "broken":
+			return errors.New("directory unavailable")
+		}
+		return nil
+	}
+
+	cases := []struct {
+		name      string
+		header    string
+		want      int
+		principal bool
+	}{
+		{"no header passes without principal", "", http.StatusOK, false},
+		{"valid token", "Bearer " + valid.Token, http.StatusOK, true},
+		{"scheme is case insensitive", "bearer " + valid.Token, http.StatusOK, true},
+		{"other scheme", "Basic dXNlcjpwYXNz", http.StatusUnauthorized, false},
+		{"garbage", "Bearer not-a-token", http.StatusUnauthorized, false},
+		{"expired", "Bearer " + expired.Token, http.StatusUnauthorized, false},
+		{"revoked session", "Bearer " + revoked.Token, http.StatusUnauthorized, false},
+		{"session check failure", "Bearer " + broken.Token, http.StatusInternalServerError, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(Authenticate(issuer, WithSessionCheck(check)))
+			var got *Principal
+			r.GET("/me", func(c *gin.Context) {
+				if p, ok := PrincipalFrom(c.Request.Context()); ok {
+					got = &p
+				}
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.want {
+				t.Fatalf("status: got %d, want %d body=%s", w.Code, tc.want, w.Body.String())
+			}
+			if w.Code == http.StatusUnauthorized && !strings.HasPrefix(w.Header().Get("WWW-Authenticate"), "Bearer") {
+				t.Fatalf("missing Bearer challenge: %q", w.Header().Get("WWW-Authenticate"))
+			}
+			if (got != nil) != tc.principal {
+				t.Fatalf("principal attached=%v, want %v", got != nil, tc.principal)
+			}
+			if got != nil && (got.UserID != "u1" || got.OrgID != "acme" || len(got.Roles) != 1) {
+				t.Fatalf("principal: %+v", got)
+			}
+		})
+	}
+}
+
+func TestAuthenticate_APIKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	issuer := NewTokenIssuer(mustHS256(t, "h1"))
+	check := func(ctx context.Context, key string) (Principal, error) {
+		switch key {
+		case "ak_1.secret":
+			return Principal{KeyID: "ak_1", OrgID: "acme", Scopes: []rbac.Permission{rbac.PermUsersRead}}, nil
+		case "ak_2.secret":
+			return Principal{}, errors.New("key store unavailable")
+		}
+		return Principal{}, ErrInvalidAPIKey
+	}
+
+	cases := []struct {
+		name      string
+		opts      []AuthenticateOption
+		header    string
+		want      int
+		challenge string
+	}{
+		{"valid key", []AuthenticateOption{WithAPIKeys(check)}, "ApiKey ak_1.secret", http.StatusOK, ""},
+		{"scheme is case insensitive", []AuthenticateOption{WithAPIKeys(check)}, "apikey ak_1.secret", http.StatusOK, ""},
+		{"unknown key", []AuthenticateOption{WithAPIKeys(check)}, "ApiKey ak_9.secret", http.StatusUnauthorized, "ApiKey"},
+		{"key store failure", []AuthenticateOption{WithAPIKeys(check)}, "ApiKey ak_2.secret", http.StatusInternalServerError, ""},
+		{"api keys disabled", nil, "ApiKey ak_1.secret", http.StatusUnauthorized, "Bearer"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(Authenticate(issuer, tc.opts...))
+			var got Principal
+			r.GET("/me", func(c *gin.Context) {
+				got, _ = PrincipalFrom(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			req.Header.Set("Authorization", tc.header)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.want {
+				t.Fatalf("status: got %d, want %d body=%s", w.Code, tc.want, w.Body.String())
+			}
+			if tc.challenge != "" && !strings.HasPrefix(w.Header().Get("WWW-Authenticate"), tc.challenge) {
+				t.Fatalf("challenge: got %q, want %s", w.Header().Get("WWW-Authenticate"), tc.challenge)
+			}
+			if w.Code == http.StatusOK && (got.KeyID != "ak_1" || got.OrgID != "acme" || got.UserID != "") {
+				t.Fatalf("principal: %+v", got)
+			}
+		})
+	}
+}
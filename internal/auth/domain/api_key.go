@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+)
+
+// apiKeyPrefix marca as chaves desta API, para que scanners de segredos as reconheçam
+const apiKeyPrefix = "ak_"
+
+var (
+	ErrInvalidAPIKeyName = errors.New("api key name must have 1-100 characters")
+	ErrNoScopes          = errors.New("api key needs at least one scope")
+	ErrInvalidExpiry     = errors.New("api key expiry must be in the future")
+)
+
+// APIKey é uma credencial de serviço da organização. O valor entregue ao
+// cliente é "<prefixo>.<segredo>": o prefixo é público e identifica a chave
+// nas listagens e nos logs, e do valor inteiro só o hash é guardado.
+type APIKey struct {
+	Prefix string
+	Hash   string
+	Name   string
+	OrgID  string
+	// Scopes são as permissões exatas da chave; papéis não se aplicam
+	Scopes    []rbac.Permission
+	CreatedBy vo.UserID
+
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// NewAPIKey valida nome, escopos e validade (nil para não vencer) e devolve a
+// chave com o valor a ser mostrado uma única vez ao cliente
+func NewAPIKey(name, orgID string, scopes []rbac.Permission, createdBy vo.UserID, now time.Time, expiresAt *time.Time) (APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > 100 {
+		return APIKey{}, "", ErrInvalidAPIKeyName
+	}
+	if expiresAt != nil && !expiresAt.After(now) {
+		return APIKey{}, "", ErrInvalidExpiry
+	}
+	normalized, err := normalizeScopes(scopes)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return APIKey{}, "", err
+	}
+	secret, err := randomString(32)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+	prefix := apiKeyPrefix + hex.EncodeToString(id)
+	raw := prefix + "." + secret
+
+	return APIKey{
+		Prefix:    prefix,
+		Hash:      HashToken(raw),
+		Name:      name,
+		OrgID:     orgID,
+		Scopes:    normalized,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+	}, raw, nil
+}
+
+// normalizeScopes recusa escopos desconhecidos e devolve a lista ordenada e sem repetição
+func normalizeScopes(scopes []rbac.Permission) ([]rbac.Permission, error) {
+	seen := make(map[rbac.Permission]bool, len(scopes))
+	out := make([]rbac.Permission, 0, len(scopes))
+	var unknown []string
+	for _, s := range scopes {
+		s = rbac.Permission(strings.TrimSpace(string(s)))
+		if !s.Valid() {
+			unknown = append(unknown, string(s))
+			continue
+		}
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown scopes: %s", strings.Join(unknown, ", "))
+	}
+	if len(out) == 0 {
+		return nil, ErrNoScopes
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out, nil
+}
+
+// APIKeyPrefix extrai o prefixo do valor apresentado pelo cliente
+func APIKeyPrefix(raw string) (string, bool) {
+	prefix, secret, ok := strings.Cut(raw, ".")
+	if !ok || !strings.HasPrefix(prefix, apiKeyPrefix) || secret == "" {
+		return "", false
+	}
+	return prefix, true
+}
+
+// Matches confere o valor apresentado com o hash guardado em tempo constante
+func (k APIKey) Matches(raw string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashToken(raw)), []byte(k.Hash)) == 1
+}
+
+// Expired indica se a chave passou da validade
+func (k APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// Usable indica se a chave ainda autentica
+func (k APIKey) Usable(now time.Time) bool {
+	return k.RevokedAt == nil && !k.Expired(now)
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+)
+
+func TestAPIKey_NewStoresOnlyTheHash(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	k, raw, err := NewAPIKey(" billing job ", "acme",
+		[]rbac.Permission{rbac.PermUsersWrite, rbac.PermUsersRead, rbac.PermUsersRead}, "admin-1", now, nil)
+	if err != nil {
+		t.Fatalf("NewAPIKey: %v", err)
+	}
+	if k.Name != "billing job" || k.OrgID != "acme" || k.CreatedBy != "admin-1" || !k.CreatedAt.Equal(now) {
+		t.Fatalf("key: %+v", k)
+	}
+	if len(k.Scopes) != 2 || k.Scopes[0] != rbac.PermUsersRead || k.Scopes[1] != rbac.PermUsersWrite {
+		t.Fatalf("scopes should be sorted without duplicates: %v", k.Scopes)
+	}
+	if !strings.HasPrefix(raw, k.Prefix+".") || k.Hash == raw || strings.Contains(k.Hash, raw) {
+		t.Fatalf("raw %q should start with the prefix and never be stored: %+v", raw, k)
+	}
+
+	prefix, ok := APIKeyPrefix(raw)
+	if !ok || prefix != k.Prefix {
+		t.Fatalf("APIKeyPrefix: got %q, %v", prefix, ok)
+	}
+	if !k.Matches(raw) || k.Matches(raw+"x") || k.Matches(k.Prefix+".other") {
+		t.Fatalf("Matches should accept only the issued value")
+	}
+	for _, bad := range []string{"", "ak_123", "xx_123.secret", "ak_123."} {
+		if _, ok := APIKeyPrefix(bad); ok {
+			t.Fatalf("APIKeyPrefix(%q) should fail", bad)
+		}
+	}
+}
+
+func TestAPIKey_Validation(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Minute)
+	scopes := []rbac.Permission{rbac.PermUsersRead}
+
+	if _, _, err := NewAPIKey("  ", "acme", scopes, "", now, nil); !errors.Is(err, ErrInvalidAPIKeyName) {
+		t.Fatalf("blank name: got %v", err)
+	}
+	if _, _, err := NewAPIKey("job", "acme", nil, "", now, nil); !errors.Is(err, ErrNoScopes) {
+		t.Fatalf("no scopes: got %v", err)
+	}
+	if _, _, err := NewAPIKey("job", "acme", []rbac.Permission{"users:fly"}, "", now, nil); err == nil || !strings.Contains(err.Error(), "users:fly") {
+		t.Fatalf("unknown scope: got %v", err)
+	}
+	if _, _, err := NewAPIKey("job", "acme", scopes, "", now, &past); !errors.Is(err, ErrInvalidExpiry) {
+		t.Fatalf("past expiry: got %v", err)
+	}
+}
+
+func TestAPIKey_Usable(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	exp := now.Add(time.Hour)
+	k, _, _ := NewAPIKey("job", "acme", []rbac.Permission{rbac.PermUsersRead}, "", now, &exp)
+
+	if !k.Usable(now) {
+		t.Fatalf("fresh key should be usable")
+	}
+	if k.Usable(exp) {
+		t.Fatalf("key should expire at ExpiresAt")
+	}
+	k.RevokedAt = &now
+	if k.Usable(now) {
+		t.Fatalf("revoked key should not be usable")
+	}
+}
@@ -0,0 +1,240 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+)
+
+// Parâmetros do TOTP (RFC 6238) aceitos por todos os aplicativos autenticadores
+const (
+	totpPeriod = 30 * time.Second
+	totpDigits = 6
+	// totpSkew é quantos períodos antes e depois do atual são aceitos, para
+	// relógios dessincronizados
+	totpSkew = 1
+
+	recoveryCodeCount = 10
+
+	// MFAMaxFailures é quantos códigos errados seguidos, somando todos os
+	// desafios do usuário, travam o fator por MFALockout. Sem isso, quem tem a
+	// senha abre desafios novos e chuta códigos sem fim.
+	MFAMaxFailures = 10
+	MFALockout     = 15 * time.Minute
+)
+
+var (
+	ErrInvalidMFACode    = errors.New("invalid authentication code")
+	ErrMFANotEnabled     = errors.New("mfa is not enabled")
+	ErrMFAAlreadyEnabled = errors.New("mfa is already enabled")
+	ErrMFALocked         = errors.New("too many invalid authentication codes; retry later")
+)
+
+var base32NoPad = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// MFA é o segundo fator TOTP de um usuário. O segredo precisa ser guardado
+// em claro para gerar os códigos; os códigos de recuperação, só o hash.
+type MFA struct {
+	UserID vo.UserID
+	OrgID  string
+	Secret []byte
+	// ConfirmedAt é quando o usuário provou ter o segredo; antes disso o
+	// fator não é exigido no login
+	ConfirmedAt *time.Time
+	// LastUsedStep é o período do último código aceito: um código não vale duas vezes
+	LastUsedStep  int64
+	RecoveryCodes []string
+	// FailedAttempts conta os códigos errados desde o último aceito; ao chegar
+	// a MFAMaxFailures o fator fica travado até LockedUntil
+	FailedAttempts int
+	LockedUntil    *time.Time
+	CreatedAt      time.Time
+	Version        int
+}
+
+// NewMFA inicia a inscrição com um segredo novo de 160 bits
+func NewMFA(userID vo.UserID, orgID string, now time.Time) (MFA, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return MFA{}, err
+	}
+	return MFA{UserID: userID, OrgID: orgID, Secret: secret, CreatedAt: now}, nil
+}
+
+// Enabled indica se o fator foi confirmado e é exigido no login
+func (m MFA) Enabled() bool {
+	return m.ConfirmedAt != nil
+}
+
+// SecretBase32 é o segredo no formato digitado nos aplicativos autenticadores
+func (m MFA) SecretBase32() string {
+	return base32NoPad.EncodeToString(m.Secret)
+}
+
+// OTPAuthURI é o URI otpauth:// que os aplicativos leem por QR code
+func (m MFA) OTPAuthURI(issuer, account string) string {
+	q := url.Values{}
+	q.Set("secret", m.SecretBase32())
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(totpDigits))
+	q.Set("period", fmt.Sprint(int(totpPeriod.Seconds())))
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// Confirm confere o primeiro código e ativa o fator, devolvendo os códigos de
+// recuperação, que só são mostrados agora
+func (m *MFA) Confirm(code string, now time.Time) ([]string, error) {
+	if m.Enabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if err := m.VerifyCode(code, now); err != nil {
+		return nil, err
+	}
+	codes, err := m.ResetRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	m.ConfirmedAt = &now
+	return codes, nil
+}
+
+// VerifyCode aceita o código do período atual ou de um vizinho (totpSkew),
+// desde que posterior ao último aceito
+func (m *MFA) VerifyCode(code string, now time.Time) error {
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return ErrInvalidMFACode
+	}
+	current := now.Unix() / int64(totpPeriod.Seconds())
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= m.LastUsedStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(totpCode(m.Secret, step)), []byte(code)) == 1 {
+			m.LastUsedStep = step
+			m.clearFailures()
+			return nil
+		}
+	}
+	return ErrInvalidMFACode
+}
+
+// UseRecoveryCode aceita um código de recuperação e o descarta
+func (m *MFA) UseRecoveryCode(code string) error {
+	hash := HashToken(normalizeRecoveryCode(code))
+	for i, h := range m.RecoveryCodes {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1 {
+			m.RecoveryCodes = append(m.RecoveryCodes[:i:i], m.RecoveryCodes[i+1:]...)
+			m.clearFailures()
+			return nil
+		}
+	}
+	return ErrInvalidMFACode
+}
+
+// Locked indica se o fator está travado por erros seguidos e por quanto tempo
+func (m MFA) Locked(now time.Time) (time.Duration, bool) {
+	if m.LockedUntil == nil || !now.Before(*m.LockedUntil) {
+		return 0, false
+	}
+	return m.LockedUntil.Sub(now), true
+}
+
+// RecordFailure conta um código errado; o que completa MFAMaxFailures trava o
+// fator e zera a contagem para depois da trava
+func (m *MFA) RecordFailure(now time.Time) {
+	m.FailedAttempts++
+	if m.FailedAttempts >= MFAMaxFailures {
+		until := now.Add(MFALockout)
+		m.LockedUntil = &until
+		m.FailedAttempts = 0
+	}
+}
+
+// clearFailures zera a contagem quando um código é aceito
+func (m *MFA) clearFailures() {
+	m.FailedAttempts = 0
+	m.LockedUntil = nil
+}
+
+// ResetRecoveryCodes troca os códigos de recuperação e devolve os novos em claro
+func (m *MFA) ResetRecoveryCodes() ([]string, error) {
+	raw := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range raw {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(base32NoPad.EncodeToString(b))
+		raw[i] = code[:4] + "-" + code[4:]
+		hashes[i] = HashToken(code)
+	}
+	m.RecoveryCodes = hashes
+	return raw, nil
+}
+
+// normalizeRecoveryCode ignora hífens, espaços e maiúsculas digitados
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// TOTPCode é o código do período que contém t
+func TOTPCode(secret []byte, t time.Time) string {
+	return totpCode(secret, t.Unix()/int64(totpPeriod.Seconds()))
+}
+
+func totpCode(secret []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1_000_000)
+}
+
+// MFAChallenge é a etapa pendente de um login que exige o segundo fator.
+// Como o refresh token, é opaca e só o hash é guardado.
+type MFAChallenge struct {
+	Hash      string
+	UserID    vo.UserID
+	OrgID     string
+	ExpiresAt time.Time
+	// Attempts conta os códigos errados; ao atingir o limite o desafio cai
+	Attempts int
+}
+
+// NewMFAChallenge cria o desafio e devolve o valor entregue ao cliente
+func NewMFAChallenge(userID vo.UserID, orgID string, now time.Time, ttl time.Duration) (MFAChallenge, string, error) {
+	raw, err := randomString(32)
+	if err != nil {
+		return MFAChallenge{}, "", err
+	}
+	return MFAChallenge{
+		Hash:      HashToken(raw),
+		UserID:    userID,
+		OrgID:     orgID,
+		ExpiresAt: now.Add(ttl),
+	}, raw, nil
+}
+
+// Expired indica se o desafio passou da validade
+func (c MFAChallenge) Expired(now time.Time) bool {
+	return !now.Before(c.ExpiresAt)
+}
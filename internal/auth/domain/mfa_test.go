@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	// Vetores SHA-1 do apêndice B da RFC 6238, truncados para 6 dígitos
+	secret := []byte("12345678901234567890")
+	cases := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, want := range cases {
+		if got := TOTPCode(secret, time.Unix(unix, 0)); got != want {
+			t.Fatalf("T=%d: got %s, want %s", unix, got, want)
+		}
+	}
+}
+
+func TestMFA_VerifyCodeToleratesSkewAndRejectsReplay(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	m, err := NewMFA("user-1", "acme", now)
+	if err != nil {
+		t.Fatalf("NewMFA: %v", err)
+	}
+
+	if err := m.VerifyCode(TOTPCode(m.Secret, now.Add(-30*time.Second)), now); err != nil {
+		t.Fatalf("previous period should be accepted: %v", err)
+	}
+	// O mesmo código não vale de novo, nem um de período anterior
+	if err := m.VerifyCode(TOTPCode(m.Secret, now.Add(-30*time.Second)), now); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("replay: got %v", err)
+	}
+	if err := m.VerifyCode(TOTPCode(m.Secret, now), now); err != nil {
+		t.Fatalf("current period: %v", err)
+	}
+	if err := m.VerifyCode(TOTPCode(m.Secret, now.Add(-time.Minute)), now.Add(time.Minute)); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("code outside the skew: got %v", err)
+	}
+	for _, bad := range []string{"", "12345", "abcdef", "1234567"} {
+		if err := m.VerifyCode(bad, now); !errors.Is(err, ErrInvalidMFACode) {
+			t.Fatalf("%q: got %v", bad, err)
+		}
+	}
+}
+
+func TestMFA_ConfirmIssuesSingleUseRecoveryCodes(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	m, _ := NewMFA("user-1", "acme", now)
+	if m.Enabled() {
+		t.Fatalf("mfa should start pending")
+	}
+	if _, err := m.Confirm(TOTPCode(m.Secret, now.Add(-time.Hour)), now); err == nil || m.Enabled() {
+		t.Fatalf("wrong code should not confirm")
+	}
+
+	codes, err := m.Confirm(TOTPCode(m.Secret, now), now)
+	if err != nil {
+		t.Fatalf("Confirm: %v", err)
+	}
+	if !m.Enabled() || len(codes) != recoveryCodeCount || len(m.RecoveryCodes) != recoveryCodeCount {
+		t.Fatalf("confirm: enabled=%v codes=%v", m.Enabled(), codes)
+	}
+	for _, h := range m.RecoveryCodes {
+		for _, c := range codes {
+			if strings.Contains(h, strings.ReplaceAll(c, "-", "")) {
+				t.Fatalf("recovery codes must be stored hashed")
+			}
+		}
+	}
+	if _, err := m.Confirm(TOTPCode(m.Secret, now.Add(time.Minute)), now.Add(time.Minute)); !errors.Is(err, ErrMFAAlreadyEnabled) {
+		t.Fatalf("second confirm: got %v", err)
+	}
+
+	typed := " " + strings.ToUpper(strings.ReplaceAll(codes[3], "-", "")) + " "
+	if err := m.UseRecoveryCode(typed); err != nil {
+		t.Fatalf("UseRecoveryCode: %v", err)
+	}
+	if err := m.UseRecoveryCode(codes[3]); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("recovery code reuse: got %v", err)
+	}
+	if len(m.RecoveryCodes) != recoveryCodeCount-1 {
+		t.Fatalf("used code should be discarded: %d left", len(m.RecoveryCodes))
+	}
+}
+
+func TestMFA_OTPAuthURI(t *testing.T) {
+	m, _ := NewMFA("user-1", "acme", time.Now())
+	uri, err := url.Parse(m.OTPAuthURI("Users API", "ana@example.com"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	q := uri.Query()
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || q.Get("secret") != m.SecretBase32() || q.Get("issuer") != "Users API" {
+		t.Fatalf("uri: %s", uri)
+	}
+	if label, _ := url.PathUnescape(uri.EscapedPath()); label != "/Users API:ana@example.com" {
+		t.Fatalf("label: %s", label)
+	}
+}
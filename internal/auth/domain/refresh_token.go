@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+)
+
+// RefreshToken é um refresh token opaco; só o hash do valor é guardado. Cada
+// uso o troca por um novo da mesma família (o login que a originou), e a
+// reutilização de um token já trocado revoga a família inteira.
+type RefreshToken struct {
+	Hash     string
+	FamilyID string
+	UserID   vo.UserID
+	OrgID    string
+
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	// RotatedAt é quando o token foi trocado por outro; depois disso ele não vale mais
+	RotatedAt *time.Time
+	RevokedAt *time.Time
+}
+
+// NewRefreshToken gera um token para uma nova família (login) e devolve o
+// valor a ser entregue ao cliente, que não fica guardado
+func NewRefreshToken(userID vo.UserID, orgID string, now time.Time, ttl time.Duration) (RefreshToken, string, error) {
+	family, err := randomString(16)
+	if err != nil {
+		return RefreshToken{}, "", err
+	}
+	return newToken(family, userID, orgID, now, ttl)
+}
+
+// Next gera o token que substitui t na rotação, na mesma família
+func (t RefreshToken) Next(now time.Time, ttl time.Duration) (RefreshToken, string, error) {
+	return newToken(t.FamilyID, t.UserID, t.OrgID, now, ttl)
+}
+
+func newToken(family string, userID vo.UserID, orgID string, now time.Time, ttl time.Duration) (RefreshToken, string, error) {
+	raw, err := randomString(32)
+	if err != nil {
+		return RefreshToken{}, "", err
+	}
+	return RefreshToken{
+		Hash:      HashToken(raw),
+		FamilyID:  family,
+		UserID:    userID,
+		OrgID:     orgID,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(ttl),
+	}, raw, nil
+}
+
+// HashToken é a chave de busca do token no repositório
+func HashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// Expired indica se o token passou da validade
+func (t RefreshToken) Expired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// Usable indica se o token ainda pode ser trocado
+func (t RefreshToken) Usable(now time.Time) bool {
+	return t.RotatedAt == nil && t.RevokedAt == nil && !t.Expired(now)
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRefreshToken_NewAndNext(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tok, raw, err := NewRefreshToken("user-1", "acme", now, time.Hour)
+	if err != nil {
+		t.Fatalf("NewRefreshToken: %v", err)
+	}
+	if raw == "" || tok.Hash != HashToken(raw) || tok.Hash == raw {
+		t.Fatalf("only the hash should be stored: %+v", tok)
+	}
+	if tok.FamilyID == "" || tok.UserID != "user-1" || tok.OrgID != "acme" || !tok.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("token: %+v", tok)
+	}
+
+	next, nextRaw, err := tok.Next(now.Add(time.Minute), time.Hour)
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if next.FamilyID != tok.FamilyID || nextRaw == raw || next.Hash == tok.Hash {
+		t.Fatalf("next should stay in the family with a new value: %+v", next)
+	}
+
+	other, _, _ := NewRefreshToken("user-1", "acme", now, time.Hour)
+	if other.FamilyID == tok.FamilyID {
+		t.Fatalf("each login should start a new family")
+	}
+}
+
+func TestRefreshToken_Usable(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tok, _, _ := NewRefreshToken("user-1", "acme", now, time.Hour)
+
+	if !tok.Usable(now) {
+		t.Fatalf("fresh token should be usable")
+	}
+	if tok.Usable(now.Add(time.Hour)) || !tok.Expired(now.Add(time.Hour)) {
+		t.Fatalf("token should expire at ExpiresAt")
+	}
+
+	rotated := tok
+	rotated.RotatedAt = &now
+	revoked := tok
+	revoked.RevokedAt = &now
+	if rotated.Usable(now) || revoked.Usable(now) {
+		t.Fatalf("rotated or revoked tokens should not be usable")
+	}
+}
@@ -0,0 +1,10 @@
+package dtos
+
+import "time"
+
+// CreateAPIKeyRequest cria uma API key; sem expiresAt a chave não vence
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1,dive,required"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,11 @@
+package dtos
+
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,max=200"`
+}
+
+// RefreshRequest serve para POST /auth/refresh e POST /auth/logout
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required,max=200"`
+}
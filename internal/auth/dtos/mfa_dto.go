@@ -0,0 +1,13 @@
+package dtos
+
+// MFACodeRequest confirma a inscrição com o primeiro código do aplicativo
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+// MFAVerifyRequest conclui o login; exige o código TOTP ou um de recuperação
+type MFAVerifyRequest struct {
+	MFAToken     string `json:"mfaToken" binding:"required,max=200"`
+	Code         string `json:"code" binding:"omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recoveryCode" binding:"omitempty,max=20"`
+}
@@ -0,0 +1,160 @@
+package auth_handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	auth_domain "github.com/williamkoller/cloud-architecture-golang/internal/auth/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth/dtos"
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth/mappers"
+	auth_repository "github.com/williamkoller/cloud-architecture-golang/internal/auth/repository"
+	rbac_mappers "github.com/williamkoller/cloud-architecture-golang/internal/rbac/mappers"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/validation"
+)
+
+// lastUsedResolution é a precisão do último uso: dentro dela, usos seguidos
+// da mesma chave não geram uma escrita cada
+const lastUsedResolution = time.Minute
+
+// APIKeys é o APIKeyCheck do auth.Authenticate: confere a chave pelo prefixo
+// e pelo hash, recusa as revogadas e vencidas e registra o último uso
+func APIKeys(keys auth_repository.APIKeyRepository) auth.APIKeyCheck {
+	return func(ctx context.Context, raw string) (auth.Principal, error) {
+		prefix, ok := auth_domain.APIKeyPrefix(raw)
+		if !ok {
+			return auth.Principal{}, auth.ErrInvalidAPIKey
+		}
+		k, found, err := keys.Get(ctx, prefix)
+		if err != nil {
+			return auth.Principal{}, err
+		}
+		now := time.Now().UTC()
+		if !found || !k.Matches(raw) || !k.Usable(now) {
+			return auth.Principal{}, auth.ErrInvalidAPIKey
+		}
+
+		if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) >= lastUsedResolution {
+			// Falhar ao registrar o uso não deve derrubar a chamada
+			if err := keys.MarkUsed(ctx, k.Prefix, now); err != nil {
+				log.Printf("auth: could not record use of api key %s: %v", k.Prefix, err)
+			}
+		}
+		return auth.Principal{KeyID: k.Prefix, OrgID: k.OrgID, Scopes: k.Scopes}, nil
+	}
+}
+
+// APIKeyHandler expõe a administração das API keys da organização
+type APIKeyHandler struct {
+	keys           auth_repository.APIKeyRepository
+	permissions    auth.PermissionResolver
+	requestTimeout time.Duration
+	now            func() time.Time
+}
+
+// NewAPIKeyHandler recebe o resolver de permissões de quem cria as chaves:
+// ninguém concede a uma chave um escopo que não tem. Sem resolver, os
+// escopos não são conferidos.
+func NewAPIKeyHandler(keys auth_repository.APIKeyRepository, permissions auth.PermissionResolver) *APIKeyHandler {
+	return &APIKeyHandler{
+		keys:           keys,
+		permissions:    permissions,
+		requestTimeout: 5 * time.Second,
+		now:            time.Now,
+	}
+}
+
+func (h *APIKeyHandler) ctx(c *gin.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(c.Request.Context(), h.requestTimeout)
+}
+
+// CreateAPIKey cria a chave e devolve o valor, que não é mostrado de novo (POST /api-keys)
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req dtos.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.RespondValidationError(c, err)
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	scopes := rbac_mappers.ToPermissions(req.Scopes)
+	p, authenticated := auth.PrincipalFrom(ctx)
+	if h.permissions != nil && authenticated {
+		granted, err := p.Permissions(ctx, h.permissions)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		for _, s := range scopes {
+			if s.Valid() && !granted.Has(s) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "cannot grant scope " + string(s)})
+				return
+			}
+		}
+	}
+
+	k, raw, err := auth_domain.NewAPIKey(req.Name, orgOf(ctx), scopes, p.UserID, h.now().UTC(), req.ExpiresAt)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.keys.Create(ctx, k); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, mappers.ToCreatedAPIKeyResponse(k, raw))
+}
+
+// ListAPIKeys lista as chaves da organização, inclusive revogadas e vencidas (GET /api-keys)
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	keys, err := h.keys.List(ctx, orgOf(ctx))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, mappers.ToAPIKeyListResponse(keys))
+}
+
+// GetAPIKey devolve a chave da organização pelo prefixo (GET /api-keys/:prefix)
+func (h *APIKeyHandler) GetAPIKey(c *gin.Context) {
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	k, ok, err := h.keys.Get(ctx, c.Param("prefix"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !ok || k.OrgID != orgOf(ctx) {
+		c.JSON(http.StatusNotFound, gin.H{"error": auth_repository.ErrAPIKeyNotFound.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, mappers.ToAPIKeyResponse(k))
+}
+
+// RevokeAPIKey revoga a chave; a próxima requisição com ela já é recusada (DELETE /api-keys/:prefix)
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	if _, err := h.keys.Revoke(ctx, orgOf(ctx), c.Param("prefix"), h.now().UTC()); err != nil {
+		if err == auth_repository.ErrAPIKeyNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,154 @@
+package auth_handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	auth_domain "github.com/williamkoller/cloud-architecture-golang/internal/auth/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth/mappers"
+	auth_repository "github.com/williamkoller/cloud-architecture-golang/internal/auth/repository"
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+	rbac_repository "github.com/williamkoller/cloud-architecture-golang/internal/rbac/repository"
+	"github.com/williamkoller/cloud-architecture-golang/internal/tenant"
+)
+
+func TestAPIKeys_CheckAcceptsOnlyUsableKeys(t *testing.T) {
+	keys := auth_repository.NewInMemoryAPIKeyRepository()
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	k, raw, err := auth_domain.NewAPIKey("job", "acme", []rbac.Permission{rbac.PermUsersRead}, "admin-1", now, nil)
+	if err != nil {
+		t.Fatalf("NewAPIKey: %v", err)
+	}
+	_ = keys.Create(ctx, k)
+	check := APIKeys(keys)
+
+	p, err := check(ctx, raw)
+	if err != nil {
+		t.Fatalf("valid key: %v", err)
+	}
+	if p.KeyID != k.Prefix || p.OrgID != "acme" || p.UserID != "" || len(p.Scopes) != 1 {
+		t.Fatalf("principal: %+v", p)
+	}
+	if stored, _, _ := keys.Get(ctx, k.Prefix); stored.LastUsedAt == nil {
+		t.Fatalf("last use should be recorded")
+	}
+
+	for _, bad := range []string{"", "garbage", k.Prefix + ".wrong-secret", "ak_0000000000000000.secret"} {
+		if _, err := check(ctx, bad); !errors.Is(err, auth.ErrInvalidAPIKey) {
+			t.Fatalf("%q: got %v", bad, err)
+		}
+	}
+
+	_, _ = keys.Revoke(ctx, "acme", k.Prefix, now)
+	if _, err := check(ctx, raw); !errors.Is(err, auth.ErrInvalidAPIKey) {
+		t.Fatalf("revoked key: got %v", err)
+	}
+}
+
+func routerWithAPIKeyRoutes(h *APIKeyHandler, p auth.Principal) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if org := c.GetHeader("X-Test-Org"); org != "" {
+			c.Request = c.Request.WithContext(tenant.WithOrg(c.Request.Context(), org))
+		}
+		auth.SetPrincipal(c, p)
+		c.Next()
+	})
+	r.POST("/api-keys", h.CreateAPIKey)
+	r.GET("/api-keys", h.ListAPIKeys)
+	r.GET("/api-keys/:prefix", h.GetAPIKey)
+	r.DELETE("/api-keys/:prefix", h.RevokeAPIKey)
+	return r
+}
+
+func request(r http.Handler, method, path, org string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("X-Test-Org", org)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPIKeyHandler_CreateListRevoke(t *testing.T) {
+	keys := auth_repository.NewInMemoryAPIKeyRepository()
+	h := NewAPIKeyHandler(keys, rbac_repository.NewInMemoryRoleRepository())
+	r := routerWithAPIKeyRoutes(h, auth.Principal{UserID: "admin-1", Roles: []string{rbac.RoleAdmin}, OrgID: "acme"})
+
+	w := post(t, r, "/api-keys", map[string]any{"name": "billing", "scopes": []string{"users:read", "users:write"}}, "acme")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: got %d body=%s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("Cache-Control: got %q", w.Header().Get("Cache-Control"))
+	}
+	var created mappers.CreatedAPIKeyResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	if created.Key == "" || created.OrgID != "acme" || created.CreatedBy != "admin-1" || len(created.Scopes) != 2 {
+		t.Fatalf("created: %+v", created)
+	}
+
+	// O valor não aparece de novo nas consultas
+	w = request(r, http.MethodGet, "/api-keys", "acme")
+	var listed []map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &listed)
+	if w.Code != http.StatusOK || len(listed) != 1 || listed[0]["key"] != nil || listed[0]["prefix"] != created.Prefix {
+		t.Fatalf("list: got %d %s", w.Code, w.Body.String())
+	}
+	if w := request(r, http.MethodGet, "/api-keys", "globex"); w.Body.String() != "[]" {
+		t.Fatalf("other org should not see the key: %s", w.Body.String())
+	}
+	if w := request(r, http.MethodGet, "/api-keys/"+created.Prefix, "globex"); w.Code != http.StatusNotFound {
+		t.Fatalf("get from other org: got %d", w.Code)
+	}
+	if w := request(r, http.MethodDelete, "/api-keys/"+created.Prefix, "globex"); w.Code != http.StatusNotFound {
+		t.Fatalf("revoke from other org: got %d", w.Code)
+	}
+
+	if w := request(r, http.MethodDelete, "/api-keys/"+created.Prefix, "acme"); w.Code != http.StatusNoContent {
+		t.Fatalf("revoke: got %d", w.Code)
+	}
+	w = request(r, http.MethodGet, "/api-keys/"+created.Prefix, "acme")
+	var got mappers.APIKeyResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if got.RevokedAt == nil {
+		t.Fatalf("revoked key: %s", w.Body.String())
+	}
+	if _, err := APIKeys(keys)(context.Background(), created.Key); !errors.Is(err, auth.ErrInvalidAPIKey) {
+		t.Fatalf("revoked key should not authenticate: %v", err)
+	}
+}
+
+func TestAPIKeyHandler_CannotGrantMoreThanTheCaller(t *testing.T) {
+	h := NewAPIKeyHandler(auth_repository.NewInMemoryAPIKeyRepository(), rbac_repository.NewInMemoryRoleRepository())
+	// Uma chave que só administra chaves não cria outra que escreve usuários
+	r := routerWithAPIKeyRoutes(h, auth.Principal{KeyID: "ak_1", OrgID: "acme",
+		Scopes: []rbac.Permission{rbac.PermAPIKeysWrite, rbac.PermUsersRead}})
+
+	w := post(t, r, "/api-keys", map[string]any{"name": "escalate", "scopes": []string{"users:write"}}, "acme")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("escalation: got %d body=%s", w.Code, w.Body.String())
+	}
+	w = post(t, r, "/api-keys", map[string]any{"name": "reader", "scopes": []string{"users:read"}}, "acme")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("subset: got %d body=%s", w.Code, w.Body.String())
+	}
+	w = post(t, r, "/api-keys", map[string]any{"name": "typo", "scopes": []string{"users:fly"}}, "acme")
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("unknown scope: got %d body=%s", w.Code, w.Body.String())
+	}
+	w = post(t, r, "/api-keys", map[string]any{"name": "empty", "scopes": []string{}}, "acme")
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("no scopes: got %d body=%s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,258 @@
+package auth_handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	auth_domain "github.com/williamkoller/cloud-architecture-golang/internal/auth/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth/dtos"
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth/mappers"
+	auth_repository "github.com/williamkoller/cloud-architecture-golang/internal/auth/repository"
+	"github.com/williamkoller/cloud-architecture-golang/internal/metrics"
+	org "github.com/williamkoller/cloud-architecture-golang/internal/org/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/tenant"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/repository"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/validation"
+)
+
+// errInvalidCredentials é a resposta única para email desconhecido e senha errada
+var errInvalidCredentials = errors.New("invalid email or password")
+
+// UserDirectory devolve o repositório de usuários de cada organização
+type UserDirectory interface {
+	For(orgID string) repository.UserRepository
+}
+
+type AuthHandler struct {
+	users          UserDirectory
+	issuer         *auth.TokenIssuer
+	dummy          vo.Password
+	requestTimeout time.Duration
+
+	refresh    auth_repository.RefreshTokenRepository
+	refreshTTL time.Duration
+	now        func() time.Time
+
+	mfa          auth_repository.MFARepository
+	challenges   auth_repository.MFAChallengeRepository
+	mfaIssuer    string
+	challengeTTL time.Duration
+
+	// permissions, quando presente, impede o reset do MFA de quem tem
+	// permissões que o principal não tem
+	permissions auth.PermissionResolver
+}
+
+// Option configura o AuthHandler
+type Option func(*AuthHandler)
+
+// WithRefreshTokens faz o login emitir também um refresh token com a validade
+// informada e habilita /auth/refresh e /auth/logout
+func WithRefreshTokens(repo auth_repository.RefreshTokenRepository, ttl time.Duration) Option {
+	return func(h *AuthHandler) {
+		h.refresh = repo
+		h.refreshTTL = ttl
+	}
+}
+
+// WithMFA exige o segundo fator no login de quem o ativou e habilita as rotas
+// /auth/mfa. issuer é o nome mostrado nos aplicativos autenticadores.
+func WithMFA(factors auth_repository.MFARepository, challenges auth_repository.MFAChallengeRepository, issuer string) Option {
+	return func(h *AuthHandler) {
+		h.mfa = factors
+		h.challenges = challenges
+		h.mfaIssuer = issuer
+	}
+}
+
+// WithPermissionResolver exige, no reset do MFA, todas as permissões do usuário
+// alvo (ver auth.AuthorizeOver)
+func WithPermissionResolver(r auth.PermissionResolver) Option {
+	return func(h *AuthHandler) {
+		h.permissions = r
+	}
+}
+
+// NewAuthHandler deve ser criado depois de configurar o hasher de senhas: o
+// hash fictício usado para emails desconhecidos precisa ter o mesmo custo
+// dos hashes reais, senão o tempo de resposta revela quais emails existem.
+func NewAuthHandler(users UserDirectory, issuer *auth.TokenIssuer, opts ...Option) *AuthHandler {
+	h := &AuthHandler{
+		users:          users,
+		issuer:         issuer,
+		dummy:          dummyPassword(),
+		requestTimeout: 5 * time.Second,
+		now:            time.Now,
+		challengeTTL:   5 * time.Minute,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+func dummyPassword() vo.Password {
+	raw := make([]byte, 16)
+	_, _ = rand.Read(raw)
+	hash, err := vo.CurrentHasher().Hash(hex.EncodeToString(raw))
+	if err != nil {
+		log.Printf("auth: could not build dummy password hash: %v", err)
+	}
+	return vo.Password(hash)
+}
+
+func (h *AuthHandler) ctx(c *gin.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(c.Request.Context(), h.requestTimeout)
+}
+
+// orgOf devolve a organização resolvida pelo tenant.Middleware ou a padrão
+func orgOf(ctx context.Context) string {
+	if id, ok := tenant.OrgFrom(ctx); ok {
+		return id
+	}
+	return org.DefaultOrgID
+}
+
+// Login troca email e senha por um token de acesso (POST /auth/login).
+// Email desconhecido e senha errada têm a mesma resposta e o mesmo custo;
+// só quem acerta a senha descobre que a conta não está ativa. Com o segundo
+// fator ativo, a resposta é um desafio a ser concluído em /auth/mfa/verify.
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req dtos.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.RespondValidationError(c, err)
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	orgID := orgOf(ctx)
+	repo := h.users.For(orgID)
+
+	u, ok, err := h.findUser(ctx, repo, req.Email)
+	if err != nil {
+		metrics.LoginInc(orgID, "failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !ok {
+		h.dummy.Compare(req.Password)
+		h.rejectCredentials(c, orgID)
+		return
+	}
+	matched, upgraded := u.VerifyPassword(req.Password)
+	if !matched {
+		h.rejectCredentials(c, orgID)
+		return
+	}
+	if err := u.CanAuthenticate(); err != nil {
+		metrics.LoginInc(orgID, "inactive")
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if upgraded {
+		h.saveRehash(ctx, repo, u)
+	}
+
+	if h.mfa != nil {
+		m, found, err := h.mfa.Get(ctx, orgID, u.ID)
+		if err != nil {
+			metrics.LoginInc(orgID, "failed")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if found && m.Enabled() {
+			h.challenge(c, ctx, u)
+			return
+		}
+	}
+	h.completeLogin(c, ctx, repo, u)
+}
+
+// saveRehash grava o hash migrado pelo VerifyPassword, antes do desafio do MFA
+// (o VerifyMFA relê o usuário). É um compare-and-swap, para não desfazer uma
+// troca de senha concorrente; se falhar, a migração fica para o próximo login
+// e o login segue.
+func (h *AuthHandler) saveRehash(ctx context.Context, repo repository.UserRepository, u domain.User) {
+	if _, err := repo.Update(ctx, u); err != nil {
+		log.Printf("password rehash for user %s not saved: %v", u.ID, err)
+	}
+}
+
+// completeLogin registra o login e emite os tokens, depois de todos os fatores
+func (h *AuthHandler) completeLogin(c *gin.Context, ctx context.Context, repo repository.UserRepository, u domain.User) {
+	orgID := u.OrgID
+
+	// Só a data do login: logins concorrentes não conflitam entre si
+	u, err := repo.RecordLogin(ctx, u.ID)
+	if err != nil {
+		metrics.LoginInc(orgID, "failed")
+		switch err {
+		case repository.ErrNotFound:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": errInvalidCredentials.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	response, err := h.issueAccess(u)
+	if err == nil && h.refresh != nil {
+		response, err = h.startSession(ctx, u, response)
+	}
+	if err != nil {
+		metrics.LoginInc(orgID, "failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	metrics.LoginInc(orgID, "success")
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, response)
+}
+
+// issueAccess emite o token de acesso com o usuário, os papéis e a organização
+func (h *AuthHandler) issueAccess(u domain.User) (mappers.TokenResponse, error) {
+	token, err := h.issuer.Issue(auth.Principal{UserID: u.ID, Roles: u.EffectiveRoles(), OrgID: u.OrgID})
+	if err != nil {
+		return mappers.TokenResponse{}, err
+	}
+	return mappers.ToTokenResponse(token), nil
+}
+
+// startSession abre uma família de refresh tokens para o login
+func (h *AuthHandler) startSession(ctx context.Context, u domain.User, response mappers.TokenResponse) (mappers.TokenResponse, error) {
+	t, raw, err := auth_domain.NewRefreshToken(u.ID, u.OrgID, h.now().UTC(), h.refreshTTL)
+	if err != nil {
+		return response, err
+	}
+	if err := h.refresh.Create(ctx, t); err != nil {
+		return response, err
+	}
+	return response.WithRefreshToken(raw, t), nil
+}
+
+// findUser busca o usuário pelo email; email que não normaliza conta como desconhecido
+func (h *AuthHandler) findUser(ctx context.Context, repo repository.UserRepository, raw string) (domain.User, bool, error) {
+	email, err := vo.NewEmail(raw)
+	if err != nil {
+		return domain.User{}, false, nil
+	}
+	return repo.GetByEmail(ctx, email)
+}
+
+func (h *AuthHandler) rejectCredentials(c *gin.Context, orgID string) {
+	metrics.LoginInc(orgID, "invalid_credentials")
+	c.JSON(http.StatusUnauthorized, gin.H{"error": errInvalidCredentials.Error()})
+}
@@ -0,0 +1,428 @@
+package auth_handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth/mappers"
+	auth_repository "github.com/williamkoller/cloud-architecture-golang/internal/auth/repository"
+	"github.com/williamkoller/cloud-architecture-golang/internal/metrics"
+	"github.com/williamkoller/cloud-architecture-golang/internal/tenant"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/repository"
+)
+
+const testPassword = "Correct-Horse-42"
+
+// init metrics once for all tests
+func init() {
+	metrics.Init("test-service", "test-version")
+}
+
+func newTestIssuer(t *testing.T) *auth.TokenIssuer {
+	t.Helper()
+	key, err := auth.NewHS256Key("test", []byte(strings.Repeat("s", 32)))
+	if err != nil {
+		t.Fatalf("NewHS256Key: %v", err)
+	}
+	return auth.NewTokenIssuer(key)
+}
+
+func routerWithAuthRoutes(h *AuthHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if org := c.GetHeader("X-Test-Org"); org != "" {
+			c.Request = c.Request.WithContext(tenant.WithOrg(c.Request.Context(), org))
+		}
+		c.Next()
+	})
+	r.POST("/auth/login", h.Login)
+	r.POST("/auth/refresh", h.Refresh)
+	r.POST("/auth/logout", h.Logout)
+	return r
+}
+
+func login(t *testing.T, r http.Handler, email, password, org string) *httptest.ResponseRecorder {
+	t.Helper()
+	return post(t, r, "/auth/login", map[string]string{"email": email, "password": password}, org)
+}
+
+func post(t *testing.T, r http.Handler, path string, payload any, org string) *httptest.ResponseRecorder {
+	t.Helper()
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if org != "" {
+		req.Header.Set("X-Test-Org", org)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func seedUser(t *testing.T, repos *repository.TenantRepositories, org, email string, ut domain.UserType) domain.User {
+	t.Helper()
+	u, err := domain.NewUser("Ana", email, testPassword, true, ut)
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+	u.OrgID = org
+	created, err := repos.For(org).Create(context.Background(), u)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return created
+}
+
+func TestLogin_IssuesTokenWithSubjectRolesAndOrg(t *testing.T) {
+	vo.ConfigureHasher(vo.BcryptHasher{Cost: 4})
+	t.Cleanup(func() { vo.ConfigureHasher(vo.DefaultBcryptHasher()) })
+
+	repos := repository.NewTenantRepositories()
+	u := seedUser(t, repos, "acme", "ana@example.com", domain.UserTypeAdmin)
+	issuer := newTestIssuer(t)
+	r := routerWithAuthRoutes(NewAuthHandler(repos, issuer))
+
+	w := login(t, r, "Ana@Example.com", testPassword, "acme")
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: got %d body=%s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("Cache-Control: got %q", w.Header().Get("Cache-Control"))
+	}
+	var resp mappers.TokenResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.TokenType != "Bearer" || resp.ExpiresIn != 15*60 {
+		t.Fatalf("response: %+v", resp)
+	}
+
+	claims, err := issuer.Verify(resp.AccessToken)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.Subject != string(u.ID) || claims.OrgID != "acme" || len(claims.Roles) == 0 {
+		t.Fatalf("claims: %+v", claims)
+	}
+
+	stored, _, _ := repos.For("acme").GetByID(context.Background(), u.ID)
+	if stored.LastLoginAt == nil {
+		t.Fatalf("LastLoginAt was not recorded")
+	}
+
+	// O usuário existe só na sua organização
+	if w := login(t, r, "ana@example.com", testPassword, "other"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("login in other org: got %d", w.Code)
+	}
+}
+
+func TestLogin_UnknownEmailAndWrongPasswordLookTheSame(t *testing.T) {
+	vo.ConfigureHasher(vo.BcryptHasher{Cost: 4})
+	t.Cleanup(func() { vo.ConfigureHasher(vo.DefaultBcryptHasher()) })
+
+	repos := repository.NewTenantRepositories()
+	seedUser(t, repos, "acme", "ana@example.com", domain.UserTypeUser)
+	h := NewAuthHandler(repos, newTestIssuer(t))
+	r := routerWithAuthRoutes(h)
+
+	if !strings.HasPrefix(string(h.dummy), "$2") {
+		t.Fatalf("dummy hash should use the configured hasher, got %q", h.dummy)
+	}
+
+	wrong := login(t, r, "ana@example.com", "Wrong-Password-1", "acme")
+	unknown := login(t, r, "bob@example.com", testPassword, "acme")
+	if wrong.Code != http.StatusUnauthorized || unknown.Code != http.StatusUnauthorized {
+		t.Fatalf("codes: wrong=%d unknown=%d", wrong.Code, unknown.Code)
+	}
+	if wrong.Body.String() != unknown.Body.String() {
+		t.Fatalf("bodies differ: %s vs %s", wrong.Body.String(), unknown.Body.String())
+	}
+
+	if w := login(t, r, "not-an-email", testPassword, "acme"); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid payload: got %d", w.Code)
+	}
+}
+
+func TestLogin_RefusesInactiveAccounts(t *testing.T) {
+	vo.ConfigureHasher(vo.BcryptHasher{Cost: 4})
+	t.Cleanup(func() { vo.ConfigureHasher(vo.DefaultBcryptHasher()) })
+
+	repos := repository.NewTenantRepositories()
+	u := seedUser(t, repos, "acme", "ana@example.com", domain.UserTypeUser)
+	r := routerWithAuthRoutes(NewAuthHandler(repos, newTestIssuer(t)))
+
+	if err := u.ChangeStatus(domain.StatusSuspended, "chargeback", "admin"); err != nil {
+		t.Fatalf("suspend: %v", err)
+	}
+	if _, err := repos.For("acme").Update(context.Background(), u); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	// Senha errada continua 401: o status só aparece para quem sabe a senha
+	if w := login(t, r, "ana@example.com", "Wrong-Password-1", "acme"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong password on suspended account: got %d", w.Code)
+	}
+	w := login(t, r, "ana@example.com", testPassword, "acme")
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "suspended") {
+		t.Fatalf("suspended login: got %d body=%s", w.Code, w.Body.String())
+	}
+
+	if _, err := repos.For("acme").Delete(context.Background(), u.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if w := login(t, r, "ana@example.com", testPassword, "acme"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("deleted user login: got %d", w.Code)
+	}
+}
+
+func TestLogin_MigratesOutdatedHash(t *testing.T) {
+	vo.ConfigureHasher(vo.BcryptHasher{Cost: 4})
+	t.Cleanup(func() { vo.ConfigureHasher(vo.DefaultBcryptHasher()) })
+
+	repos := repository.NewTenantRepositories()
+	u := seedUser(t, repos, "acme", "ana@example.com", domain.UserTypeUser)
+
+	vo.ConfigureHasher(vo.BcryptHasher{Cost: 5})
+	r := routerWithAuthRoutes(NewAuthHandler(repos, newTestIssuer(t)))
+	if w := login(t, r, "ana@example.com", testPassword, "acme"); w.Code != http.StatusOK {
+		t.Fatalf("login: got %d body=%s", w.Code, w.Body.String())
+	}
+
+	stored, _, _ := repos.For("acme").GetByID(context.Background(), u.ID)
+	if stored.Password == u.Password || stored.Password.NeedsRehash() || !stored.Password.Compare(testPassword) {
+		t.Fatalf("password hash was not migrated")
+	}
+}
+
+func TestLogin_ConcurrentLoginsDoNotConflict(t *testing.T) {
+	vo.ConfigureHasher(vo.BcryptHasher{Cost: 4})
+	t.Cleanup(func() { vo.ConfigureHasher(vo.DefaultBcryptHasher()) })
+
+	repos := repository.NewTenantRepositories()
+	u := seedUser(t, repos, "acme", "ana@example.com", domain.UserTypeUser)
+
+	// Todos migram o hash ao mesmo tempo: só uma gravação vence, e todos entram
+	vo.ConfigureHasher(vo.BcryptHasher{Cost: 5})
+	r := routerWithAuthRoutes(NewAuthHandler(repos, newTestIssuer(t)))
+	codes := make([]int, 8)
+	var wg sync.WaitGroup
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = login(t, r, "ana@example.com", testPassword, "acme").Code
+		}()
+	}
+	wg.Wait()
+	for i, code := range codes {
+		if code != http.StatusOK {
+			t.Fatalf("login %d: got %d", i, code)
+		}
+	}
+
+	// Só a migração do hash muda a versão; o login em si não invalida ETags
+	stored, _, _ := repos.For("acme").GetByID(context.Background(), u.ID)
+	if stored.Version != u.Version+1 || stored.LastLoginAt == nil {
+		t.Fatalf("version: got %d, want %d (lastLogin=%v)", stored.Version, u.Version+1, stored.LastLoginAt)
+	}
+	if w := login(t, r, "ana@example.com", testPassword, "acme"); w.Code != http.StatusOK {
+		t.Fatalf("login: got %d", w.Code)
+	}
+	if again, _, _ := repos.For("acme").GetByID(context.Background(), u.ID); again.Version != stored.Version {
+		t.Fatalf("login bumped the version: %d -> %d", stored.Version, again.Version)
+	}
+}
+
+func decodeTokens(t *testing.T, w *httptest.ResponseRecorder) mappers.TokenResponse {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", w.Code, w.Body.String())
+	}
+	var resp mappers.TokenResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return resp
+}
+
+func refresh(t *testing.T, r http.Handler, token, org string) *httptest.ResponseRecorder {
+	t.Helper()
+	return post(t, r, "/auth/refresh", map[string]string{"refreshToken": token}, org)
+}
+
+func TestRefresh_RotatesAndDetectsReuse(t *testing.T) {
+	vo.ConfigureHasher(vo.BcryptHasher{Cost: 4})
+	t.Cleanup(func() { vo.ConfigureHasher(vo.DefaultBcryptHasher()) })
+
+	repos := repository.NewTenantRepositories()
+	seedUser(t, repos, "acme", "ana@example.com", domain.UserTypeUser)
+	issuer := newTestIssuer(t)
+	h := NewAuthHandler(repos, issuer, WithRefreshTokens(auth_repository.NewInMemoryRefreshTokenRepository(), time.Hour))
+	r := routerWithAuthRoutes(h)
+
+	first := decodeTokens(t, login(t, r, "ana@example.com", testPassword, "acme"))
+	if first.RefreshToken == "" || first.RefreshExpiresAt == "" {
+		t.Fatalf("login should return a refresh token: %+v", first)
+	}
+
+	second := decodeTokens(t, refresh(t, r, first.RefreshToken, "acme"))
+	if second.RefreshToken == "" || second.RefreshToken == first.RefreshToken {
+		t.Fatalf("refresh should rotate the token: %+v", second)
+	}
+	if _, err := issuer.Verify(second.AccessToken); err != nil {
+		t.Fatalf("new access token: %v", err)
+	}
+
+	// O token só vale na organização em que foi emitido
+	if w := refresh(t, r, second.RefreshToken, "other"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh in other org: got %d", w.Code)
+	}
+
+	// Reapresentar o token trocado revoga a sessão, inclusive o token atual
+	w := refresh(t, r, first.RefreshToken, "acme")
+	if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "reused") {
+		t.Fatalf("replay: got %d body=%s", w.Code, w.Body.String())
+	}
+	if w := refresh(t, r, second.RefreshToken, "acme"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("current token after replay: got %d", w.Code)
+	}
+
+	if w := refresh(t, r, "garbage", "acme"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("unknown token: got %d", w.Code)
+	}
+}
+
+func TestLogout_RevokesSession(t *testing.T) {
+	vo.ConfigureHasher(vo.BcryptHasher{Cost: 4})
+	t.Cleanup(func() { vo.ConfigureHasher(vo.DefaultBcryptHasher()) })
+
+	repos := repository.NewTenantRepositories()
+	seedUser(t, repos, "acme", "ana@example.com", domain.UserTypeUser)
+	h := NewAuthHandler(repos, newTestIssuer(t), WithRefreshTokens(auth_repository.NewInMemoryRefreshTokenRepository(), time.Hour))
+	r := routerWithAuthRoutes(h)
+
+	session := decodeTokens(t, login(t, r, "ana@example.com", testPassword, "acme"))
+	other := decodeTokens(t, login(t, r, "ana@example.com", testPassword, "acme"))
+
+	if w := post(t, r, "/auth/logout", map[string]string{"refreshToken": session.RefreshToken}, "acme"); w.Code != http.StatusNoContent {
+		t.Fatalf("logout: got %d", w.Code)
+	}
+	if w := refresh(t, r, session.RefreshToken, "acme"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh after logout: got %d", w.Code)
+	}
+	// Só a sessão do token informado é encerrada
+	decodeTokens(t, refresh(t, r, other.RefreshToken, "acme"))
+
+	if w := post(t, r, "/auth/logout", map[string]string{"refreshToken": "unknown"}, "acme"); w.Code != http.StatusNoContent {
+		t.Fatalf("logout with unknown token: got %d", w.Code)
+	}
+}
+
+func TestRefresh_RejectsRevokedSessionsAndInactiveUsers(t *testing.T) {
+	vo.ConfigureHasher(vo.BcryptHasher{Cost: 4})
+	t.Cleanup(func() { vo.ConfigureHasher(vo.DefaultBcryptHasher()) })
+
+	repos := repository.NewTenantRepositories()
+	u := seedUser(t, repos, "acme", "ana@example.com", domain.UserTypeUser)
+	h := NewAuthHandler(repos, newTestIssuer(t), WithRefreshTokens(auth_repository.NewInMemoryRefreshTokenRepository(), time.Hour))
+	r := routerWithAuthRoutes(h)
+
+	session := decodeTokens(t, login(t, r, "ana@example.com", testPassword, "acme"))
+
+	// Redefinição de senha encerra as sessões antes mesmo de o evento ser entregue
+	current, _, _ := repos.For("acme").GetByID(context.Background(), u.ID)
+	if err := current.ResetPassword("Another-Horse-43", time.Now().UTC()); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	if _, err := repos.For("acme").Update(context.Background(), current); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if w := refresh(t, r, session.RefreshToken, "acme"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh after password reset: got %d", w.Code)
+	}
+
+	// Sessão aberta depois da redefinição funciona até a conta ser suspensa
+	h.now = func() time.Time { return time.Now().Add(time.Second) }
+	session = decodeTokens(t, login(t, r, "ana@example.com", "Another-Horse-43", "acme"))
+	current, _, _ = repos.For("acme").GetByID(context.Background(), u.ID)
+	if err := current.ChangeStatus(domain.StatusSuspended, "", ""); err != nil {
+		t.Fatalf("suspend: %v", err)
+	}
+	if _, err := repos.For("acme").Update(context.Background(), current); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if w := refresh(t, r, session.RefreshToken, "acme"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh of suspended user: got %d", w.Code)
+	}
+}
+
+func TestRefresh_DisabledRoutesReturn404(t *testing.T) {
+	r := routerWithAuthRoutes(NewAuthHandler(repository.NewTenantRepositories(), newTestIssuer(t)))
+
+	if w := refresh(t, r, "token", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("refresh: got %d", w.Code)
+	}
+	if w := post(t, r, "/auth/logout", map[string]string{"refreshToken": "token"}, ""); w.Code != http.StatusNotFound {
+		t.Fatalf("logout: got %d", w.Code)
+	}
+}
+
+func TestActiveSessions(t *testing.T) {
+	vo.ConfigureHasher(vo.BcryptHasher{Cost: 4})
+	t.Cleanup(func() { vo.ConfigureHasher(vo.DefaultBcryptHasher()) })
+
+	repos := repository.NewTenantRepositories()
+	u := seedUser(t, repos, "acme", "ana@example.com", domain.UserTypeUser)
+	check := ActiveSessions(repos)
+	ctx := context.Background()
+	issued := time.Now().Add(-time.Minute).Unix()
+
+	claims := auth.Claims{Subject: string(u.ID), OrgID: "acme", IssuedAt: issued}
+	if err := check(ctx, claims); err != nil {
+		t.Fatalf("active user: %v", err)
+	}
+	if err := check(ctx, auth.Claims{Subject: string(u.ID), OrgID: "other", IssuedAt: issued}); err != auth.ErrSessionRevoked {
+		t.Fatalf("user from another org: got %v", err)
+	}
+	if err := check(ctx, auth.Claims{Subject: "not-an-id", IssuedAt: issued}); err != auth.ErrSessionRevoked {
+		t.Fatalf("invalid subject: got %v", err)
+	}
+
+	// Encerrar as sessões invalida os tokens emitidos antes
+	current, _, _ := repos.For("acme").GetByID(ctx, u.ID)
+	current.RevokeSessions(time.Now().UTC())
+	current, err := repos.For("acme").Update(ctx, current)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := check(ctx, claims); err != auth.ErrSessionRevoked {
+		t.Fatalf("token issued before revocation: got %v", err)
+	}
+	claims.IssuedAt = time.Now().Add(time.Second).Unix()
+	if err := check(ctx, claims); err != nil {
+		t.Fatalf("token issued after revocation: %v", err)
+	}
+
+	if err := current.ChangeStatus(domain.StatusLocked, "", ""); err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	if _, err := repos.For("acme").Update(ctx, current); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := check(ctx, claims); err != auth.ErrSessionRevoked {
+		t.Fatalf("locked user: got %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package auth_handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+)
+
+// JWKSHandler publica as chaves públicas que conferem os tokens de acesso
+type JWKSHandler struct {
+	keys   *auth.KeyManager
+	maxAge time.Duration
+}
+
+// NewJWKSHandler publica as chaves do KeyManager. maxAge é o cache permitido
+// aos clientes e deve ser menor que a antecedência com que a próxima chave é
+// publicada, senão quem verifica pode não conhecê-la quando ela assumir.
+func NewJWKSHandler(keys *auth.KeyManager, maxAge time.Duration) *JWKSHandler {
+	return &JWKSHandler{keys: keys, maxAge: maxAge}
+}
+
+// JWKS serve o conjunto de chaves (GET /.well-known/jwks.json). Com HS256 a
+// lista é vazia: o segredo não pode ser publicado.
+func (h *JWKSHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(h.maxAge.Seconds())))
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
@@ -0,0 +1,37 @@
+package auth_handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+)
+
+func TestJWKS_PublishesActiveAndStagedKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	active, _ := auth.GenerateSigningKey(auth.AlgEdDSA)
+	next, _ := auth.GenerateSigningKey(auth.AlgEdDSA)
+	keys := auth.NewKeyManager(active)
+	_ = keys.Stage(next)
+
+	r := gin.New()
+	r.GET("/.well-known/jwks.json", NewJWKSHandler(keys, 5*time.Minute).JWKS)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+
+	if w.Code != http.StatusOK || w.Header().Get("Cache-Control") != "public, max-age=300" {
+		t.Fatalf("got %d cache=%q", w.Code, w.Header().Get("Cache-Control"))
+	}
+	var set auth.JWKSet
+	if err := json.Unmarshal(w.Body.Bytes(), &set); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(set.Keys) != 2 || set.Keys[0].KeyID != active.KeyID() || set.Keys[1].KeyID != next.KeyID() {
+		t.Fatalf("keys: %+v", set.Keys)
+	}
+}
@@ -0,0 +1,361 @@
+package auth_handler
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	auth_domain "github.com/williamkoller/cloud-architecture-golang/internal/auth/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth/dtos"
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth/mappers"
+	auth_repository "github.com/williamkoller/cloud-architecture-golang/internal/auth/repository"
+	"github.com/williamkoller/cloud-architecture-golang/internal/metrics"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/validation"
+)
+
+// maxMFAAttempts é quantos códigos errados um desafio aceita antes de cair;
+// com o desafio descartado, é preciso repetir a senha. Entre desafios, os erros
+// se somam no fator até auth_domain.MFAMaxFailures, que o trava.
+const maxMFAAttempts = 5
+
+const errInvalidChallenge = "invalid or expired mfa challenge"
+
+// challenge responde ao login que ainda precisa do segundo fator
+func (h *AuthHandler) challenge(c *gin.Context, ctx context.Context, u domain.User) {
+	now := h.now().UTC()
+	ch, raw, err := auth_domain.NewMFAChallenge(u.ID, u.OrgID, now, h.challengeTTL)
+	if err == nil {
+		err = h.challenges.Create(ctx, ch)
+	}
+	if err != nil {
+		metrics.LoginInc(u.OrgID, "failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	metrics.LoginInc(u.OrgID, "mfa_required")
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, mappers.ToMFAChallengeResponse(raw, ch, now))
+}
+
+// VerifyMFA conclui o login com o código TOTP ou um código de recuperação
+// (POST /auth/mfa/verify). O desafio vale uma vez e cai após maxMFAAttempts
+// erros; o fator travado por erros seguidos responde 429 com Retry-After.
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	if h.mfa == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "mfa is not enabled"})
+		return
+	}
+
+	var req dtos.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.RespondValidationError(c, err)
+		return
+	}
+	if (req.Code == "") == (strings.TrimSpace(req.RecoveryCode) == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "provide either code or recoveryCode"})
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	orgID := orgOf(ctx)
+	hash := auth_domain.HashToken(req.MFAToken)
+	ch, ok, err := h.challenges.Get(ctx, hash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// Desafio de outra organização é tratado como desconhecido
+	if !ok || ch.OrgID != orgID || ch.Expired(h.now().UTC()) {
+		metrics.LoginInc(orgID, "invalid_mfa")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errInvalidChallenge})
+		return
+	}
+
+	m, found, err := h.mfa.Get(ctx, orgID, ch.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// Fator removido depois da senha: o login recomeça sem ele
+	if !found || !m.Enabled() {
+		_, _ = h.challenges.Consume(ctx, hash)
+		metrics.LoginInc(orgID, "invalid_mfa")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errInvalidChallenge})
+		return
+	}
+
+	now := h.now().UTC()
+	if wait, locked := m.Locked(now); locked {
+		metrics.LoginInc(orgID, "mfa_locked")
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": auth_domain.ErrMFALocked.Error()})
+		return
+	}
+
+	if req.Code != "" {
+		err = m.VerifyCode(req.Code, now)
+	} else {
+		err = m.UseRecoveryCode(req.RecoveryCode)
+	}
+	if err == nil {
+		// O compare-and-swap garante que o código (ou o de recuperação) vale uma vez
+		_, err = h.mfa.Save(ctx, m)
+	} else if err == auth_domain.ErrInvalidMFACode {
+		if ferr := h.recordMFAFailure(ctx, orgID, ch.UserID, now); ferr != nil {
+			err = ferr
+		}
+	}
+	switch err {
+	case nil:
+	case auth_domain.ErrInvalidMFACode, auth_repository.ErrMFAConflict:
+		if err := h.challenges.Fail(ctx, hash, maxMFAAttempts); err != nil && err != auth_repository.ErrChallengeNotFound {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		metrics.LoginInc(orgID, "invalid_mfa")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": auth_domain.ErrInvalidMFACode.Error()})
+		return
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	consumed, err := h.challenges.Consume(ctx, hash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !consumed {
+		metrics.LoginInc(orgID, "invalid_mfa")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errInvalidChallenge})
+		return
+	}
+
+	repo := h.users.For(orgID)
+	u, ok, err := repo.GetByID(ctx, ch.UserID)
+	if err != nil {
+		metrics.LoginInc(orgID, "failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !ok {
+		h.rejectCredentials(c, orgID)
+		return
+	}
+	// A conta pode ter sido suspensa entre a senha e o código
+	if err := u.CanAuthenticate(); err != nil {
+		metrics.LoginInc(orgID, "inactive")
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	h.completeLogin(c, ctx, repo, u)
+}
+
+// recordMFAFailure conta o código errado no fator do usuário, que vale para
+// todos os desafios. Em conflito relê e tenta de novo: chutes em paralelo não
+// podem se perder da contagem.
+func (h *AuthHandler) recordMFAFailure(ctx context.Context, orgID string, userID vo.UserID, now time.Time) error {
+	for {
+		m, found, err := h.mfa.Get(ctx, orgID, userID)
+		if err != nil || !found {
+			return err
+		}
+		m.RecordFailure(now)
+		switch _, err := h.mfa.Save(ctx, m); err {
+		case auth_repository.ErrMFAConflict:
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			continue
+		default:
+			return err
+		}
+	}
+}
+
+// EnrollMFA gera o segredo do usuário autenticado (POST /auth/mfa/enroll). O
+// fator só passa a ser exigido depois de confirmado; repetir a inscrição
+// antes disso troca o segredo.
+func (h *AuthHandler) EnrollMFA(c *gin.Context) {
+	if h.mfa == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "mfa is not enabled"})
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	u, ok := h.currentUser(c, ctx)
+	if !ok {
+		return
+	}
+
+	existing, found, err := h.mfa.Get(ctx, u.OrgID, u.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if found && existing.Enabled() {
+		c.JSON(http.StatusConflict, gin.H{"error": auth_domain.ErrMFAAlreadyEnabled.Error()})
+		return
+	}
+
+	m, err := auth_domain.NewMFA(u.ID, u.OrgID, h.now().UTC())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	m.Version = existing.Version
+	switch _, err := h.mfa.Save(ctx, m); err {
+	case nil:
+	case auth_repository.ErrMFAConflict:
+		c.JSON(http.StatusConflict, gin.H{"error": "mfa was modified concurrently; retry"})
+		return
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, mappers.ToMFAEnrollmentResponse(m, h.mfaIssuer, u.Email.String()))
+}
+
+// ConfirmMFA ativa o fator com o primeiro código do aplicativo e devolve os
+// códigos de recuperação, que não são mostrados de novo (POST /auth/mfa/confirm)
+func (h *AuthHandler) ConfirmMFA(c *gin.Context) {
+	if h.mfa == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "mfa is not enabled"})
+		return
+	}
+
+	var req dtos.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.RespondValidationError(c, err)
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	u, ok := h.currentUser(c, ctx)
+	if !ok {
+		return
+	}
+
+	m, found, err := h.mfa.Get(ctx, u.OrgID, u.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": auth_repository.ErrMFANotFound.Error()})
+		return
+	}
+
+	codes, err := m.Confirm(req.Code, h.now().UTC())
+	if err == nil {
+		_, err = h.mfa.Save(ctx, m)
+	}
+	switch err {
+	case nil:
+	case auth_domain.ErrMFAAlreadyEnabled, auth_repository.ErrMFAConflict:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case auth_domain.ErrInvalidMFACode:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, mappers.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// ResetMFA remove o segundo fator de um usuário que perdeu o aplicativo e os
+// códigos de recuperação (DELETE /users/:id/mfa). O próximo login pede só a senha.
+func (h *AuthHandler) ResetMFA(c *gin.Context) {
+	if h.mfa == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "mfa is not enabled"})
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	orgID := orgOf(ctx)
+	u, ok, err := h.userRef(ctx, orgID, strings.TrimSpace(c.Param("id")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user identifier"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	// Sem o segundo fator, a senha basta: tirá-lo de um Admin ajuda a tomar a conta
+	if h.permissions != nil && !auth.AuthorizeOver(c, h.permissions, u.EffectiveRoles()) {
+		return
+	}
+
+	removed, err := h.mfa.Delete(ctx, orgID, u.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": auth_repository.ErrMFANotFound.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// userRef resolve o parâmetro :id, que aceita o ID ou o email, como nas rotas de usuário
+func (h *AuthHandler) userRef(ctx context.Context, orgID, ref string) (domain.User, bool, error) {
+	repo := h.users.For(orgID)
+	if strings.Contains(ref, "@") {
+		email, err := vo.NewEmail(ref)
+		if err != nil {
+			return domain.User{}, false, err
+		}
+		return repo.GetByEmail(ctx, email)
+	}
+	id, err := vo.ParseUserID(ref)
+	if err != nil {
+		return domain.User{}, false, err
+	}
+	return repo.GetByID(ctx, id)
+}
+
+// currentUser carrega o usuário do token; API keys não têm segundo fator
+func (h *AuthHandler) currentUser(c *gin.Context, ctx context.Context) (domain.User, bool) {
+	p, ok := auth.PrincipalFrom(ctx)
+	if !ok || p.UserID == "" || p.KeyID != "" {
+		c.Header("WWW-Authenticate", "Bearer")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return domain.User{}, false
+	}
+
+	u, ok, err := h.users.For(orgOf(ctx)).GetByID(ctx, p.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return domain.User{}, false
+	}
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return domain.User{}, false
+	}
+	return u, true
+}
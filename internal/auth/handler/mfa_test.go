@@ -0,0 +1,323 @@
+package auth_handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	auth_domain "github.com/williamkoller/cloud-architecture-golang/internal/auth/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth/mappers"
+	auth_repository "github.com/williamkoller/cloud-architecture-golang/internal/auth/repository"
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+	rbac_repository "github.com/williamkoller/cloud-architecture-golang/internal/rbac/repository"
+	"github.com/williamkoller/cloud-architecture-golang/internal/tenant"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/repository"
+)
+
+// mfaFixture monta o handler com MFA e um relógio controlado pelo teste
+type mfaFixture struct {
+	repos   *repository.TenantRepositories
+	factors auth_repository.MFARepository
+	handler *AuthHandler
+	router  *gin.Engine
+	now     time.Time
+}
+
+func newMFAFixture(t *testing.T) *mfaFixture {
+	t.Helper()
+	vo.ConfigureHasher(vo.BcryptHasher{Cost: 4})
+	t.Cleanup(func() { vo.ConfigureHasher(vo.DefaultBcryptHasher()) })
+
+	f := &mfaFixture{
+		repos:   repository.NewTenantRepositories(),
+		factors: auth_repository.NewInMemoryMFARepository(),
+		now:     time.Now().UTC(),
+	}
+	f.handler = NewAuthHandler(f.repos, newTestIssuer(t),
+		WithMFA(f.factors, auth_repository.NewInMemoryMFAChallengeRepository(), "Users API"))
+	f.handler.now = func() time.Time { return f.now }
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if org := c.GetHeader("X-Test-Org"); org != "" {
+			c.Request = c.Request.WithContext(tenant.WithOrg(c.Request.Context(), org))
+		}
+		if user := c.GetHeader("X-Test-User"); user != "" {
+			p := auth.Principal{UserID: vo.UserID(user), OrgID: c.GetHeader("X-Test-Org")}
+			if roles := c.GetHeader("X-Test-Roles"); roles != "" {
+				p.Roles = strings.Split(roles, ",")
+			}
+			auth.SetPrincipal(c, p)
+		}
+		c.Next()
+	})
+	r.POST("/auth/login", f.handler.Login)
+	r.POST("/auth/mfa/verify", f.handler.VerifyMFA)
+	r.POST("/auth/mfa/enroll", f.handler.EnrollMFA)
+	r.POST("/auth/mfa/confirm", f.handler.ConfirmMFA)
+	r.DELETE("/users/:id/mfa", f.handler.ResetMFA)
+	f.router = r
+	return f
+}
+
+func (f *mfaFixture) as(t *testing.T, u domain.User, method, path string, payload any) *httptest.ResponseRecorder {
+	t.Helper()
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Org", u.OrgID)
+	req.Header.Set("X-Test-User", string(u.ID))
+	req.Header.Set("X-Test-Roles", strings.Join(u.EffectiveRoles(), ","))
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, req)
+	return w
+}
+
+// enroll inscreve e confirma o MFA do usuário, devolvendo o segredo e os códigos de recuperação
+func (f *mfaFixture) enroll(t *testing.T, u domain.User) ([]byte, []string) {
+	t.Helper()
+	w := f.as(t, u, http.MethodPost, "/auth/mfa/enroll", nil)
+	if w.Code != http.StatusOK || w.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("enroll: got %d body=%s", w.Code, w.Body.String())
+	}
+	var enrollment mappers.MFAEnrollmentResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &enrollment)
+	m, _, _ := f.factors.Get(context.Background(), u.OrgID, u.ID)
+	if enrollment.Secret != m.SecretBase32() {
+		t.Fatalf("enrollment secret does not match the stored one")
+	}
+
+	w = f.as(t, u, http.MethodPost, "/auth/mfa/confirm", map[string]string{"code": auth_domain.TOTPCode(m.Secret, f.now)})
+	if w.Code != http.StatusOK {
+		t.Fatalf("confirm: got %d body=%s", w.Code, w.Body.String())
+	}
+	var codes mappers.RecoveryCodesResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &codes)
+	// O código da confirmação já foi usado; o login seguinte espera o próximo período
+	f.now = f.now.Add(30 * time.Second)
+	return m.Secret, codes.RecoveryCodes
+}
+
+func (f *mfaFixture) challenge(t *testing.T, u domain.User) string {
+	t.Helper()
+	w := login(t, f.router, u.Email.String(), testPassword, u.OrgID)
+	var resp mappers.MFAChallengeResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || !resp.MFARequired || resp.MFAToken == "" {
+		t.Fatalf("login should return a challenge: %d %s", w.Code, w.Body.String())
+	}
+	return resp.MFAToken
+}
+
+func TestMFA_LoginRequiresSecondFactorOnceConfirmed(t *testing.T) {
+	f := newMFAFixture(t)
+	u := seedUser(t, f.repos, "acme", "ana@example.com", domain.UserTypeUser)
+
+	// Inscrição pendente não muda o login
+	f.as(t, u, http.MethodPost, "/auth/mfa/enroll", nil)
+	if w := login(t, f.router, "ana@example.com", testPassword, "acme"); decodeTokens(t, w).AccessToken == "" {
+		t.Fatalf("pending enrollment should not require mfa: %s", w.Body.String())
+	}
+
+	secret, _ := f.enroll(t, u)
+	if w := f.as(t, u, http.MethodPost, "/auth/mfa/enroll", nil); w.Code != http.StatusConflict {
+		t.Fatalf("enroll twice: got %d", w.Code)
+	}
+
+	before, _, _ := f.repos.For("acme").GetByID(context.Background(), u.ID)
+	token := f.challenge(t, u)
+	stored, _, _ := f.repos.For("acme").GetByID(context.Background(), u.ID)
+	if !stored.LastLoginAt.Equal(*before.LastLoginAt) {
+		t.Fatalf("login should only be recorded after the second factor")
+	}
+
+	code := auth_domain.TOTPCode(secret, f.now)
+	w := post(t, f.router, "/auth/mfa/verify", map[string]string{"mfaToken": token, "code": code}, "acme")
+	if decodeTokens(t, w).AccessToken == "" {
+		t.Fatalf("verify: %s", w.Body.String())
+	}
+
+	// O desafio vale uma vez, e o código também
+	if w := post(t, f.router, "/auth/mfa/verify", map[string]string{"mfaToken": token, "code": code}, "acme"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("challenge reuse: got %d", w.Code)
+	}
+	next := f.challenge(t, u)
+	if w := post(t, f.router, "/auth/mfa/verify", map[string]string{"mfaToken": next, "code": code}, "acme"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("code replay: got %d", w.Code)
+	}
+
+	// Desafio de outra organização é desconhecido
+	if w := post(t, f.router, "/auth/mfa/verify", map[string]string{"mfaToken": next, "code": code}, "globex"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("challenge in other org: got %d", w.Code)
+	}
+	if w := post(t, f.router, "/auth/mfa/verify", map[string]string{"mfaToken": next}, "acme"); w.Code != http.StatusBadRequest {
+		t.Fatalf("missing code: got %d", w.Code)
+	}
+}
+
+func TestMFA_RecoveryCodesAreSingleUse(t *testing.T) {
+	f := newMFAFixture(t)
+	u := seedUser(t, f.repos, "acme", "ana@example.com", domain.UserTypeUser)
+	_, codes := f.enroll(t, u)
+	if len(codes) == 0 {
+		t.Fatalf("confirm should return recovery codes")
+	}
+
+	w := post(t, f.router, "/auth/mfa/verify", map[string]string{"mfaToken": f.challenge(t, u), "recoveryCode": codes[0]}, "acme")
+	if decodeTokens(t, w).AccessToken == "" {
+		t.Fatalf("recovery code: %s", w.Body.String())
+	}
+	w = post(t, f.router, "/auth/mfa/verify", map[string]string{"mfaToken": f.challenge(t, u), "recoveryCode": codes[0]}, "acme")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("recovery code reuse: got %d", w.Code)
+	}
+}
+
+func TestMFA_ChallengeDropsAfterTooManyWrongCodes(t *testing.T) {
+	f := newMFAFixture(t)
+	u := seedUser(t, f.repos, "acme", "ana@example.com", domain.UserTypeUser)
+	secret, _ := f.enroll(t, u)
+
+	token := f.challenge(t, u)
+	wrong := auth_domain.TOTPCode(secret, f.now.Add(-time.Hour))
+	for i := 0; i < maxMFAAttempts; i++ {
+		if w := post(t, f.router, "/auth/mfa/verify", map[string]string{"mfaToken": token, "code": wrong}, "acme"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("wrong code %d: got %d", i, w.Code)
+		}
+	}
+	right := auth_domain.TOTPCode(secret, f.now)
+	if w := post(t, f.router, "/auth/mfa/verify", map[string]string{"mfaToken": token, "code": right}, "acme"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("challenge should be gone after the attempt limit: got %d", w.Code)
+	}
+
+	// Expirado também não vale
+	token = f.challenge(t, u)
+	f.now = f.now.Add(10 * time.Minute)
+	right = auth_domain.TOTPCode(secret, f.now)
+	if w := post(t, f.router, "/auth/mfa/verify", map[string]string{"mfaToken": token, "code": right}, "acme"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expired challenge: got %d", w.Code)
+	}
+}
+
+func TestMFA_FailuresAddUpAcrossChallenges(t *testing.T) {
+	f := newMFAFixture(t)
+	u := seedUser(t, f.repos, "acme", "ana@example.com", domain.UserTypeUser)
+	secret, _ := f.enroll(t, u)
+	wrong := auth_domain.TOTPCode(secret, f.now.Add(-time.Hour))
+
+	// Um desafio novo a cada maxMFAAttempts erros não zera a contagem
+	for sent := 0; sent < auth_domain.MFAMaxFailures; {
+		token := f.challenge(t, u)
+		for i := 0; i < maxMFAAttempts && sent < auth_domain.MFAMaxFailures; i, sent = i+1, sent+1 {
+			if w := post(t, f.router, "/auth/mfa/verify", map[string]string{"mfaToken": token, "code": wrong}, "acme"); w.Code != http.StatusUnauthorized {
+				t.Fatalf("wrong code %d: got %d", sent, w.Code)
+			}
+		}
+	}
+
+	// Travado, nem o código certo passa
+	right := auth_domain.TOTPCode(secret, f.now)
+	w := post(t, f.router, "/auth/mfa/verify", map[string]string{"mfaToken": f.challenge(t, u), "code": right}, "acme")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "900" {
+		t.Fatalf("locked factor: got %d retry-after=%q", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	f.now = f.now.Add(auth_domain.MFALockout)
+	right = auth_domain.TOTPCode(secret, f.now)
+	if w := post(t, f.router, "/auth/mfa/verify", map[string]string{"mfaToken": f.challenge(t, u), "code": right}, "acme"); decodeTokens(t, w).AccessToken == "" {
+		t.Fatalf("after the lockout: %s", w.Body.String())
+	}
+	if m, _, _ := f.factors.Get(context.Background(), "acme", u.ID); m.FailedAttempts != 0 || m.LockedUntil != nil {
+		t.Fatalf("an accepted code should clear the failures: %+v", m)
+	}
+}
+
+func TestMFA_LoginKeepsTheMigratedHash(t *testing.T) {
+	f := newMFAFixture(t)
+	u := seedUser(t, f.repos, "acme", "ana@example.com", domain.UserTypeUser)
+	secret, _ := f.enroll(t, u)
+
+	vo.ConfigureHasher(vo.BcryptHasher{Cost: 5})
+	token := f.challenge(t, u)
+	w := post(t, f.router, "/auth/mfa/verify", map[string]string{"mfaToken": token, "code": auth_domain.TOTPCode(secret, f.now)}, "acme")
+	if decodeTokens(t, w).AccessToken == "" {
+		t.Fatalf("verify: %s", w.Body.String())
+	}
+
+	stored, _, _ := f.repos.For("acme").GetByID(context.Background(), u.ID)
+	if stored.Password == u.Password || stored.Password.NeedsRehash() || !stored.Password.Compare(testPassword) {
+		t.Fatalf("password hash was not migrated")
+	}
+}
+
+func TestMFA_ResetRemovesTheFactor(t *testing.T) {
+	f := newMFAFixture(t)
+	u := seedUser(t, f.repos, "acme", "ana@example.com", domain.UserTypeUser)
+	admin := seedUser(t, f.repos, "acme", "admin@example.com", domain.UserTypeAdmin)
+	f.enroll(t, u)
+
+	if w := f.as(t, admin, http.MethodDelete, "/users/"+string(u.ID)+"/mfa", nil); w.Code != http.StatusNoContent {
+		t.Fatalf("reset: got %d body=%s", w.Code, w.Body.String())
+	}
+	if w := login(t, f.router, "ana@example.com", testPassword, "acme"); decodeTokens(t, w).AccessToken == "" {
+		t.Fatalf("login after reset should not require mfa: %s", w.Body.String())
+	}
+	if w := f.as(t, admin, http.MethodDelete, "/users/ana@example.com/mfa", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("reset without mfa: got %d", w.Code)
+	}
+	if w := f.as(t, admin, http.MethodDelete, "/users/not-an-id/mfa", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid id: got %d", w.Code)
+	}
+}
+
+func TestMFA_ResetRequiresEveryPermissionOfTheTarget(t *testing.T) {
+	f := newMFAFixture(t)
+	// support só tem users:write; o admin tem todas as permissões
+	roles := rbac_repository.NewInMemoryRoleRepository()
+	role, err := rbac.NewRole("support", "", []rbac.Permission{rbac.PermUsersWrite})
+	if err != nil {
+		t.Fatalf("NewRole: %v", err)
+	}
+	if _, err := roles.Create(context.Background(), role); err != nil {
+		t.Fatalf("Create role: %v", err)
+	}
+	f.handler.permissions = roles
+	admin := seedUser(t, f.repos, "acme", "admin@example.com", domain.UserTypeAdmin)
+	u := seedUser(t, f.repos, "acme", "ana@example.com", domain.UserTypeUser)
+	support := u
+	support.Roles = []string{"support"}
+	f.enroll(t, admin)
+	f.enroll(t, u)
+
+	if w := f.as(t, support, http.MethodDelete, "/users/"+string(admin.ID)+"/mfa", nil); w.Code != http.StatusForbidden {
+		t.Fatalf("support resets admin mfa: got %d body=%s", w.Code, w.Body.String())
+	}
+	if m, found, _ := f.factors.Get(context.Background(), "acme", admin.ID); !found || !m.Enabled() {
+		t.Fatalf("admin mfa was removed")
+	}
+	if w := f.as(t, support, http.MethodDelete, "/users/ana@example.com/mfa", nil); w.Code != http.StatusNoContent {
+		t.Fatalf("support resets user mfa: got %d body=%s", w.Code, w.Body.String())
+	}
+	if w := f.as(t, admin, http.MethodDelete, "/users/"+string(admin.ID)+"/mfa", nil); w.Code != http.StatusNoContent {
+		t.Fatalf("admin resets admin mfa: got %d body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestMFA_EnrollRequiresAUser(t *testing.T) {
+	f := newMFAFixture(t)
+	w := post(t, f.router, "/auth/mfa/enroll", nil, "acme")
+	if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") == "" {
+		t.Fatalf("anonymous enroll: got %d", w.Code)
+	}
+}
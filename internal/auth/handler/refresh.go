@@ -0,0 +1,132 @@
+package auth_handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	auth_domain "github.com/williamkoller/cloud-architecture-golang/internal/auth/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth/dtos"
+	auth_repository "github.com/williamkoller/cloud-architecture-golang/internal/auth/repository"
+	"github.com/williamkoller/cloud-architecture-golang/internal/metrics"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/validation"
+)
+
+const errInvalidRefreshToken = "invalid refresh token"
+
+// Refresh troca um refresh token por um novo par de tokens (POST /auth/refresh).
+// O token usado deixa de valer; reapresentá-lo revoga a sessão inteira.
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	if h.refresh == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "refresh tokens are not enabled"})
+		return
+	}
+
+	var req dtos.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.RespondValidationError(c, err)
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	orgID := orgOf(ctx)
+	hash := auth_domain.HashToken(req.RefreshToken)
+	current, ok, err := h.refresh.Get(ctx, hash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// Token de outra organização é tratado como desconhecido
+	if !ok || current.OrgID != orgID {
+		h.rejectRefresh(c, orgID, "invalid", errInvalidRefreshToken)
+		return
+	}
+
+	next, raw, err := current.Next(h.now().UTC(), h.refreshTTL)
+	if err == nil {
+		err = h.refresh.Rotate(ctx, hash, next)
+	}
+	switch err {
+	case nil:
+	case auth_repository.ErrTokenReused:
+		h.rejectRefresh(c, orgID, "reused", err.Error())
+		return
+	case auth_repository.ErrNotFound, auth_repository.ErrTokenExpired, auth_repository.ErrTokenRevoked:
+		h.rejectRefresh(c, orgID, "invalid", errInvalidRefreshToken)
+		return
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	u, ok, err := h.users.For(orgID).GetByID(ctx, current.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !ok || u.CanAuthenticate() != nil || revokedSince(u, current) {
+		// A conta mudou desde o login: a sessão acaba aqui, mesmo que o
+		// assinante dos eventos de usuário ainda não tenha rodado
+		if _, err := h.refresh.RevokeFamily(ctx, current.FamilyID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		h.rejectRefresh(c, orgID, "invalid", errInvalidRefreshToken)
+		return
+	}
+
+	response, err := h.issueAccess(u)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	metrics.TokenRefreshInc(orgID, "success")
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, response.WithRefreshToken(raw, next))
+}
+
+// Logout encerra a sessão do refresh token (POST /auth/logout). Tokens
+// desconhecidos ou já revogados também recebem 204.
+func (h *AuthHandler) Logout(c *gin.Context) {
+	if h.refresh == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "refresh tokens are not enabled"})
+		return
+	}
+
+	var req dtos.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.RespondValidationError(c, err)
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	current, ok, err := h.refresh.Get(ctx, auth_domain.HashToken(req.RefreshToken))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if ok && current.OrgID == orgOf(ctx) {
+		if _, err := h.refresh.RevokeFamily(ctx, current.FamilyID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// revokedSince indica se as sessões do usuário foram encerradas depois que o
+// token foi emitido (por exemplo, numa redefinição de senha)
+func revokedSince(u domain.User, t auth_domain.RefreshToken) bool {
+	return u.SessionsRevokedAt != nil && t.IssuedAt.Before(*u.SessionsRevokedAt)
+}
+
+func (h *AuthHandler) rejectRefresh(c *gin.Context, orgID, result, msg string) {
+	metrics.TokenRefreshInc(orgID, result)
+	c.JSON(http.StatusUnauthorized, gin.H{"error": msg})
+}
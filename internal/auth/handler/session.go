@@ -0,0 +1,38 @@
+package auth_handler
+
+import (
+	"context"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	org "github.com/williamkoller/cloud-architecture-golang/internal/org/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+)
+
+// ActiveSessions é o SessionCheck do auth.Authenticate: recusa tokens de
+// usuários excluídos ou inativos e os emitidos antes de as sessões serem
+// encerradas. iat tem precisão de segundos, então um token do mesmo segundo
+// do encerramento ainda é aceito.
+func ActiveSessions(users UserDirectory) auth.SessionCheck {
+	return func(ctx context.Context, c auth.Claims) error {
+		id, err := vo.ParseUserID(c.Subject)
+		if err != nil {
+			return auth.ErrSessionRevoked
+		}
+		orgID := c.OrgID
+		if orgID == "" {
+			orgID = org.DefaultOrgID
+		}
+
+		u, ok, err := users.For(orgID).GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !ok || u.CanAuthenticate() != nil {
+			return auth.ErrSessionRevoked
+		}
+		if u.SessionsRevokedAt != nil && c.IssuedAt < u.SessionsRevokedAt.Unix() {
+			return auth.ErrSessionRevoked
+		}
+		return nil
+	}
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// AccessToken é um token emitido e as claims que ele carrega
+type AccessToken struct {
+	Token     string
+	Claims    Claims
+	ExpiresAt time.Time
+}
+
+// TokenIssuer emite e confere os tokens de acesso da API
+type TokenIssuer struct {
+	keys     *KeyManager
+	ttl      time.Duration
+	issuer   string
+	audience string
+	now      func() time.Time
+}
+
+// IssuerOption configura o TokenIssuer
+type IssuerOption func(*TokenIssuer)
+
+// WithTokenTTL define a validade dos tokens de acesso (padrão 15 minutos)
+func WithTokenTTL(d time.Duration) IssuerOption {
+	return func(i *TokenIssuer) {
+		i.ttl = d
+	}
+}
+
+// WithIssuer define a claim iss, conferida na verificação
+func WithIssuer(iss string) IssuerOption {
+	return func(i *TokenIssuer) {
+		i.issuer = iss
+	}
+}
+
+// WithAudience define a claim aud, conferida na verificação
+func WithAudience(aud string) IssuerOption {
+	return func(i *TokenIssuer) {
+		i.audience = aud
+	}
+}
+
+// WithIssuerClock substitui o relógio, para testes
+func WithIssuerClock(now func() time.Time) IssuerOption {
+	return func(i *TokenIssuer) {
+		i.now = now
+	}
+}
+
+// NewTokenIssuer cria um emissor com uma chave fixa
+func NewTokenIssuer(key SigningKey, opts ...IssuerOption) *TokenIssuer {
+	return NewTokenIssuerWithKeys(NewKeyManager(key), opts...)
+}
+
+// NewTokenIssuerWithKeys cria um emissor que assina com a chave ativa do
+// KeyManager e aceita todas as chaves em uso, acompanhando as rotações
+func NewTokenIssuerWithKeys(keys *KeyManager, opts ...IssuerOption) *TokenIssuer {
+	i := &TokenIssuer{
+		keys: keys,
+		ttl:  15 * time.Minute,
+		now:  time.Now,
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i
+}
+
+// Issue emite um token de acesso para o principal
+func (i *TokenIssuer) Issue(p Principal) (AccessToken, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return AccessToken{}, err
+	}
+
+	now := i.now().UTC().Truncate(time.Second)
+	exp := now.Add(i.ttl)
+	c := Claims{
+		Issuer:    i.issuer,
+		Subject:   string(p.UserID),
+		Audience:  i.audience,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: exp.Unix(),
+		ID:        hex.EncodeToString(jti),
+		Roles:     p.Roles,
+		OrgID:     p.OrgID,
+	}
+	token, err := SignJWT(i.keys.Signer(), c)
+	if err != nil {
+		return AccessToken{}, err
+	}
+	return AccessToken{Token: token, Claims: c, ExpiresAt: exp}, nil
+}
+
+// Verify confere assinatura, validade, emissor e audiência do token
+func (i *TokenIssuer) Verify(token string) (Claims, error) {
+	c, err := ParseJWT(token, i.keys.Key)
+	if err != nil {
+		return Claims{}, err
+	}
+	if c.Issuer != i.issuer || c.Audience != i.audience {
+		return Claims{}, ErrInvalidToken
+	}
+	if i.now().Unix() >= c.ExpiresAt {
+		return Claims{}, ErrTokenExpired
+	}
+	return c, nil
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+)
+
+// JWK é a chave pública no formato da RFC 7517
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 (OKP)
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKSet é o documento servido em /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWK devolve a parte pública da chave. Chaves HS256 não têm parte
+// pública e nunca são publicadas.
+func PublicJWK(key SigningKey) (JWK, bool) {
+	switch k := key.(type) {
+	case rsaKey:
+		pub := k.priv.PublicKey
+		return JWK{
+			KeyType:   "RSA",
+			KeyID:     k.kid,
+			Use:       "sig",
+			Algorithm: AlgRS256,
+			N:         b64(pub.N.Bytes()),
+			E:         b64(big.NewInt(int64(pub.E)).Bytes()),
+		}, true
+	case edKey:
+		return JWK{
+			KeyType:   "OKP",
+			KeyID:     k.kid,
+			Use:       "sig",
+			Algorithm: AlgEdDSA,
+			Curve:     "Ed25519",
+			X:         b64(k.priv.Public().(ed25519.PublicKey)),
+		}, true
+	}
+	return JWK{}, false
+}
+
+// KeyIDFor deriva o kid do material da chave, para que cada chave nova
+// ganhe um kid novo sem configuração: o thumbprint da RFC 7638 nas chaves
+// assimétricas e um prefixo do SHA-256 do segredo nas HS256
+func KeyIDFor(key SigningKey) string {
+	var members any
+	switch k := key.(type) {
+	case rsaKey:
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{b64(big.NewInt(int64(k.priv.E)).Bytes()), "RSA", b64(k.priv.N.Bytes())}
+	case edKey:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{"Ed25519", "OKP", b64(k.priv.Public().(ed25519.PublicKey))}
+	case hmacKey:
+		sum := sha256.Sum256(k.secret)
+		return "hs256-" + hex.EncodeToString(sum[:8])
+	default:
+		return key.KeyID()
+	}
+	// Os membros já estão na ordem lexicográfica exigida pela RFC 7638
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return b64(sum[:])
+}
+
+// withKeyID devolve a mesma chave com outro kid
+func withKeyID(key SigningKey, kid string) SigningKey {
+	switch k := key.(type) {
+	case hmacKey:
+		k.kid = kid
+		return k
+	case rsaKey:
+		k.kid = kid
+		return k
+	case edKey:
+		k.kid = kid
+		return k
+	}
+	return key
+}
@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Algoritmos de assinatura suportados nos tokens de acesso
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid access token")
+	ErrTokenExpired = errors.New("access token expired")
+	ErrWeakKey      = errors.New("signing key too weak: HS256 needs 32 bytes, RS256 2048 bits")
+)
+
+// SigningKey assina e confere tokens com um algoritmo; KeyID vai no header kid
+type SigningKey interface {
+	Algorithm() string
+	KeyID() string
+	Sign(input []byte) ([]byte, error)
+	Verify(input, sig []byte) bool
+}
+
+type hmacKey struct {
+	kid    string
+	secret []byte
+}
+
+// NewHS256Key cria uma chave simétrica; quem verifica precisa do mesmo segredo
+func NewHS256Key(kid string, secret []byte) (SigningKey, error) {
+	if len(secret) < 32 {
+		return nil, ErrWeakKey
+	}
+	return hmacKey{kid: kid, secret: append([]byte(nil), secret...)}, nil
+}
+
+func (k hmacKey) Algorithm() string { return AlgHS256 }
+func (k hmacKey) KeyID() string     { return k.kid }
+
+func (k hmacKey) Sign(input []byte) ([]byte, error) {
+	m := hmac.New(sha256.New, k.secret)
+	m.Write(input)
+	return m.Sum(nil), nil
+}
+
+func (k hmacKey) Verify(input, sig []byte) bool {
+	want, _ := k.Sign(input)
+	return hmac.Equal(want, sig)
+}
+
+type rsaKey struct {
+	kid  string
+	priv *rsa.PrivateKey
+}
+
+// NewRS256Key cria uma chave RSA (mínimo de 2048 bits)
+func NewRS256Key(kid string, priv *rsa.PrivateKey) (SigningKey, error) {
+	if priv.N.BitLen() < 2048 {
+		return nil, ErrWeakKey
+	}
+	return rsaKey{kid: kid, priv: priv}, nil
+}
+
+func (k rsaKey) Algorithm() string { return AlgRS256 }
+func (k rsaKey) KeyID() string     { return k.kid }
+
+func (k rsaKey) Sign(input []byte) ([]byte, error) {
+	sum := sha256.Sum256(input)
+	return rsa.SignPKCS1v15(rand.Reader, k.priv, crypto.SHA256, sum[:])
+}
+
+func (k rsaKey) Verify(input, sig []byte) bool {
+	sum := sha256.Sum256(input)
+	return rsa.VerifyPKCS1v15(&k.priv.PublicKey, crypto.SHA256, sum[:], sig) == nil
+}
+
+type edKey struct {
+	kid  string
+	priv ed25519.PrivateKey
+}
+
+// NewEdDSAKey cria uma chave Ed25519
+func NewEdDSAKey(kid string, priv ed25519.PrivateKey) (SigningKey, error) {
+	if len(priv) != ed25519.PrivateKeySize {
+		return nil, ErrWeakKey
+	}
+	return edKey{kid: kid, priv: priv}, nil
+}
+
+func (k edKey) Algorithm() string { return AlgEdDSA }
+func (k edKey) KeyID() string     { return k.kid }
+
+func (k edKey) Sign(input []byte) ([]byte, error) {
+	return ed25519.Sign(k.priv, input), nil
+}
+
+func (k edKey) Verify(input, sig []byte) bool {
+	return ed25519.Verify(k.priv.Public().(ed25519.PublicKey), input, sig)
+}
+
+// ParsePrivateKeyPEM lê uma chave RSA (PKCS#1 ou PKCS#8) ou Ed25519 (PKCS#8)
+// e devolve a chave de assinatura do algoritmo correspondente
+func ParsePrivateKeyPEM(kid string, data []byte) (SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if priv, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return NewRS256Key(kid, priv)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch priv := parsed.(type) {
+	case *rsa.PrivateKey:
+		return NewRS256Key(kid, priv)
+	case ed25519.PrivateKey:
+		return NewEdDSAKey(kid, priv)
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+}
+
+// Claims são as claims dos tokens de acesso; datas em segundos Unix
+type Claims struct {
+	Issuer    string   `json:"iss,omitempty"`
+	Subject   string   `json:"sub"`
+	Audience  string   `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp"`
+	IssuedAt  int64    `json:"iat"`
+	ID        string   `json:"jti"`
+	Roles     []string `json:"roles,omitempty"`
+	OrgID     string   `json:"org,omitempty"`
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// SignJWT serializa as claims em um JWT compacto assinado com a chave
+func SignJWT(key SigningKey, c Claims) (string, error) {
+	header, err := json.Marshal(jwtHeader{Alg: key.Algorithm(), Typ: "JWT", Kid: key.KeyID()})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	input := b64(header) + "." + b64(payload)
+	sig, err := key.Sign([]byte(input))
+	if err != nil {
+		return "", err
+	}
+	return input + "." + b64(sig), nil
+}
+
+// ParseJWT confere a assinatura com a chave devolvida por keyFor para o kid
+// do header. O algoritmo do header precisa ser o da chave, o que descarta
+// "none" e a troca de RS256 por HS256. Validade e emissor ficam com quem chama.
+func ParseJWT(token string, keyFor func(kid string) (SigningKey, bool)) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, ErrInvalidToken
+	}
+
+	var h jwtHeader
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	key, ok := keyFor(h.Kid)
+	if !ok || key.Algorithm() != h.Alg {
+		return Claims{}, ErrInvalidToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !key.Verify([]byte(parts[0]+"."+parts[1]), sig) {
+		return Claims{}, ErrInvalidToken
+	}
+
+	var c Claims
+	if err := decodeSegment(parts[1], &c); err != nil || c.Subject == "" {
+		return Claims{}, ErrInvalidToken
+	}
+	return c, nil
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeSegment(seg string, v any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustHS256(t *testing.T, kid string) SigningKey {
+	t.Helper()
+	k, err := NewHS256Key(kid, []byte(strings.Repeat("k", 32)))
+	if err != nil {
+		t.Fatalf("NewHS256Key: %v", err)
+	}
+	return k
+}
+
+func TestSigningKeys_RoundTrip(t *testing.T) {
+	rsaPriv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa: %v", err)
+	}
+	_, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519: %v", err)
+	}
+	rsaKey, _ := NewRS256Key("r1", rsaPriv)
+	edKey, _ := NewEdDSAKey("e1", edPriv)
+
+	for _, key := range []SigningKey{mustHS256(t, "h1"), rsaKey, edKey} {
+		t.Run(key.Algorithm(), func(t *testing.T) {
+			token, err := SignJWT(key, Claims{Subject: "user-1", Roles: []string{"admin"}, ExpiresAt: 10})
+			if err != nil {
+				t.Fatalf("SignJWT: %v", err)
+			}
+			got, err := ParseJWT(token, func(kid string) (SigningKey, bool) { return key, kid == key.KeyID() })
+			if err != nil {
+				t.Fatalf("ParseJWT: %v", err)
+			}
+			if got.Subject != "user-1" || len(got.Roles) != 1 || got.Roles[0] != "admin" {
+				t.Fatalf("claims: %+v", got)
+			}
+
+			// Payload adulterado invalida a assinatura
+			parts := strings.Split(token, ".")
+			forged, _ := json.Marshal(Claims{Subject: "user-2", ExpiresAt: 10})
+			tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString(forged) + "." + parts[2]
+			if _, err := ParseJWT(tampered, func(string) (SigningKey, bool) { return key, true }); !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("tampered token: got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseJWT_RejectsAlgorithmMismatchAndNone(t *testing.T) {
+	key := mustHS256(t, "h1")
+	keyFor := func(string) (SigningKey, bool) { return key, true }
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"user-1","exp":10}`))
+	if _, err := ParseJWT(header+"."+payload+".", keyFor); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("alg none: got %v", err)
+	}
+
+	_, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+	edKey, _ := NewEdDSAKey("h1", edPriv)
+	token, _ := SignJWT(edKey, Claims{Subject: "user-1"})
+	if _, err := ParseJWT(token, keyFor); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("alg mismatch: got %v", err)
+	}
+
+	for _, bad := range []string{"", "a.b", "a.b.c.d", "!!.!!.!!"} {
+		if _, err := ParseJWT(bad, keyFor); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("malformed %q: got %v", bad, err)
+		}
+	}
+}
+
+func TestNewKeys_RejectWeakKeys(t *testing.T) {
+	if _, err := NewHS256Key("", []byte("short")); !errors.Is(err, ErrWeakKey) {
+		t.Fatalf("short HS256 secret: got %v", err)
+	}
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("rsa: %v", err)
+	}
+	if _, err := NewRS256Key("", small); !errors.Is(err, ErrWeakKey) {
+		t.Fatalf("1024-bit RSA: got %v", err)
+	}
+}
+
+func TestParsePrivateKeyPEM(t *testing.T) {
+	rsaPriv, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+	pkcs8RSA, _ := x509.MarshalPKCS8PrivateKey(rsaPriv)
+	pkcs8Ed, _ := x509.MarshalPKCS8PrivateKey(edPriv)
+
+	cases := []struct {
+		name  string
+		block *pem.Block
+		alg   string
+	}{
+		{"pkcs1 rsa", &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaPriv)}, AlgRS256},
+		{"pkcs8 rsa", &pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8RSA}, AlgRS256},
+		{"pkcs8 ed25519", &pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8Ed}, AlgEdDSA},
+	}
+	for _, tc := range cases {
+		key, err := ParsePrivateKeyPEM("k1", pem.EncodeToMemory(tc.block))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if key.Algorithm() != tc.alg || key.KeyID() != "k1" {
+			t.Fatalf("%s: got alg=%s kid=%s", tc.name, key.Algorithm(), key.KeyID())
+		}
+	}
+
+	if _, err := ParsePrivateKeyPEM("k1", []byte("not pem")); err == nil {
+		t.Fatalf("expected error for invalid PEM")
+	}
+}
+
+func TestTokenIssuer_IssueAndVerify(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	issuer := NewTokenIssuer(mustHS256(t, "h1"),
+		WithTokenTTL(10*time.Minute), WithIssuer("users-api"), WithAudience("api"), WithIssuerClock(clock))
+
+	tok, err := issuer.Issue(Principal{UserID: "user-1", Roles: []string{"viewer"}, OrgID: "acme"})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if !tok.ExpiresAt.Equal(now.Add(10 * time.Minute)) {
+		t.Fatalf("ExpiresAt: got %v", tok.ExpiresAt)
+	}
+
+	c, err := issuer.Verify(tok.Token)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if c.Subject != "user-1" || c.OrgID != "acme" || c.Issuer != "users-api" || c.IssuedAt != now.Unix() || c.ID == "" {
+		t.Fatalf("claims: %+v", c)
+	}
+
+	// Outro emissor com a mesma chave não é aceito
+	other := NewTokenIssuer(mustHS256(t, "h1"), WithIssuer("other"), WithAudience("api"), WithIssuerClock(clock))
+	if _, err := other.Verify(tok.Token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("wrong issuer: got %v", err)
+	}
+	// Chave com outro kid não confere o token
+	rotated := NewTokenIssuer(mustHS256(t, "h2"), WithIssuer("users-api"), WithAudience("api"), WithIssuerClock(clock))
+	if _, err := rotated.Verify(tok.Token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("unknown kid: got %v", err)
+	}
+
+	now = now.Add(10 * time.Minute)
+	if _, err := issuer.Verify(tok.Token); !errors.Is(err, ErrTokenExpired) {
+		t.Fatalf("expired token: got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/secrets"
+)
+
+// LoadSigningKey lê a chave do segredo name: o próprio segredo para HS256 e a
+// chave privada em PEM para RS256 e EdDSA. O kid é derivado da chave (KeyIDFor).
+func LoadSigningKey(ctx context.Context, p secrets.Provider, name, alg string) (SigningKey, error) {
+	data, err := p.Secret(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var key SigningKey
+	switch alg {
+	case AlgHS256:
+		// Arquivos e consoles de segredos costumam acrescentar a quebra de linha final
+		key, err = NewHS256Key("", bytes.TrimRight(data, "\r\n"))
+	case AlgRS256, AlgEdDSA:
+		if key, err = ParsePrivateKeyPEM("", data); err == nil && key.Algorithm() != alg {
+			err = fmt.Errorf("%s is a %s key, expected %s", name, key.Algorithm(), alg)
+		}
+	default:
+		err = fmt.Errorf("unknown signing algorithm %q", alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return withKeyID(key, KeyIDFor(key)), nil
+}
+
+// ProviderKeys é a fonte de chaves de um secrets.Provider: activeName guarda a
+// chave que assina e nextName (opcional) a próxima. Para rotacionar, grava-se
+// a chave nova em nextName e, depois de ao menos um intervalo, em activeName;
+// todas as instâncias convergem para as mesmas chaves.
+func ProviderKeys(p secrets.Provider, alg, activeName, nextName string) KeySource {
+	return func(ctx context.Context) (SigningKey, SigningKey, error) {
+		active, err := LoadSigningKey(ctx, p, activeName, alg)
+		if err != nil {
+			return nil, nil, err
+		}
+		if nextName == "" {
+			return active, nil, nil
+		}
+		next, err := LoadSigningKey(ctx, p, nextName, alg)
+		if errors.Is(err, secrets.ErrNotFound) {
+			return active, nil, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if next.KeyID() == active.KeyID() {
+			// Rotação concluída e a próxima ainda não trocada
+			return active, nil, nil
+		}
+		return active, next, nil
+	}
+}
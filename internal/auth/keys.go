@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrDuplicateKeyID = errors.New("signing key id already in use")
+
+// retiringKey é uma chave que já assinou e continua aceita até retireAt
+type retiringKey struct {
+	key      SigningKey
+	retireAt time.Time
+}
+
+// KeyManager guarda as chaves de assinatura pelo kid. Só a ativa assina; a
+// próxima (Stage) já é publicada no JWKS antes de assumir, para que quem
+// verifica offline a conheça a tempo, e as anteriores continuam aceitas
+// durante a janela de sobreposição, que deve cobrir a validade dos tokens.
+type KeyManager struct {
+	mu       sync.RWMutex
+	active   SigningKey
+	next     SigningKey
+	retiring []retiringKey
+	overlap  time.Duration
+	now      func() time.Time
+}
+
+// KeyManagerOption configura o KeyManager
+type KeyManagerOption func(*KeyManager)
+
+// WithRotationOverlap define por quanto tempo uma chave substituída ainda é
+// aceita na verificação (padrão 1 hora)
+func WithRotationOverlap(d time.Duration) KeyManagerOption {
+	return func(m *KeyManager) {
+		m.overlap = d
+	}
+}
+
+// WithKeyManagerClock substitui o relógio, para testes
+func WithKeyManagerClock(now func() time.Time) KeyManagerOption {
+	return func(m *KeyManager) {
+		m.now = now
+	}
+}
+
+func NewKeyManager(active SigningKey, opts ...KeyManagerOption) *KeyManager {
+	m := &KeyManager{
+		active:  active,
+		overlap: time.Hour,
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Signer devolve a chave que assina os tokens novos
+func (m *KeyManager) Signer() SigningKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.active
+}
+
+// Key devolve a chave aceita na verificação com o kid informado
+func (m *KeyManager) Key(kid string) (SigningKey, bool) {
+	for _, k := range m.Keys() {
+		if k.KeyID() == kid {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+// Keys devolve as chaves em uso: a ativa, a próxima e as ainda na sobreposição
+func (m *KeyManager) Keys() []SigningKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := m.now()
+	keys := []SigningKey{m.active}
+	if m.next != nil {
+		keys = append(keys, m.next)
+	}
+	for _, r := range m.retiring {
+		if now.Before(r.retireAt) {
+			keys = append(keys, r.key)
+		}
+	}
+	return keys
+}
+
+// Stage publica a próxima chave sem que ela assine; substitui a anterior ainda
+// não ativada, e nil deixa de publicá-la
+func (m *KeyManager) Stage(next SigningKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if next == nil || (m.next != nil && m.next.KeyID() == next.KeyID()) {
+		m.next = next
+		return nil
+	}
+	if m.inUse(next.KeyID()) {
+		return ErrDuplicateKeyID
+	}
+	m.next = next
+	return nil
+}
+
+// Rotate faz de key a chave ativa; a anterior fica aceita durante a sobreposição
+func (m *KeyManager) Rotate(key SigningKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if key.KeyID() == m.active.KeyID() {
+		return nil
+	}
+	staged := m.next != nil && m.next.KeyID() == key.KeyID()
+	if !staged && m.inUse(key.KeyID()) {
+		return ErrDuplicateKeyID
+	}
+
+	now := m.now()
+	m.retiring = append(m.retiring, retiringKey{key: m.active, retireAt: now.Add(m.overlap)})
+	m.active = key
+	if staged {
+		m.next = nil
+	}
+	m.prune(now)
+	return nil
+}
+
+// inUse indica se o kid é de uma chave ainda aceita. Deve ser chamado com o lock.
+func (m *KeyManager) inUse(kid string) bool {
+	if m.active.KeyID() == kid || (m.next != nil && m.next.KeyID() == kid) {
+		return true
+	}
+	now := m.now()
+	for _, r := range m.retiring {
+		if r.key.KeyID() == kid && now.Before(r.retireAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// prune descarta as chaves que saíram da sobreposição. Deve ser chamado com o lock.
+func (m *KeyManager) prune(now time.Time) {
+	kept := m.retiring[:0]
+	for _, r := range m.retiring {
+		if now.Before(r.retireAt) {
+			kept = append(kept, r)
+		}
+	}
+	m.retiring = kept
+}
+
+// JWKS devolve as chaves públicas em uso, com a ativa primeiro
+func (m *KeyManager) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, k := range m.Keys() {
+		if jwk, ok := PublicJWK(k); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/secrets"
+)
+
+func TestKeyIDFor_RSAThumbprint(t *testing.T) {
+	// Exemplo da seção 3.1 da RFC 7638
+	n, _ := base64.RawURLEncoding.DecodeString("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")
+	key := rsaKey{priv: &rsa.PrivateKey{PublicKey: rsa.PublicKey{N: new(big.Int).SetBytes(n), E: 65537}}}
+
+	if got := KeyIDFor(key); got != "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs" {
+		t.Fatalf("thumbprint: got %s", got)
+	}
+}
+
+func TestKeyManager_RotationKeepsPreviousKeyDuringOverlap(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	first, _ := GenerateSigningKey(AlgEdDSA)
+	second, _ := GenerateSigningKey(AlgEdDSA)
+
+	keys := NewKeyManager(first, WithRotationOverlap(time.Hour), WithKeyManagerClock(clock))
+	issuer := NewTokenIssuerWithKeys(keys, WithTokenTTL(2*time.Hour), WithIssuerClock(clock))
+	old, _ := issuer.Issue(Principal{UserID: "u1"})
+
+	// A próxima chave é publicada antes de assinar
+	if err := keys.Stage(second); err != nil {
+		t.Fatalf("Stage: %v", err)
+	}
+	if keys.Signer().KeyID() != first.KeyID() || len(keys.JWKS().Keys) != 2 {
+		t.Fatalf("staged key should be published but not sign: %+v", keys.JWKS())
+	}
+
+	if err := keys.Rotate(second); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	fresh, _ := issuer.Issue(Principal{UserID: "u1"})
+	if !strings.Contains(headerOf(t, fresh.Token), second.KeyID()) {
+		t.Fatalf("new tokens should use the new key")
+	}
+	if jwks := keys.JWKS(); len(jwks.Keys) != 2 || jwks.Keys[0].KeyID != second.KeyID() {
+		t.Fatalf("JWKS should list the active key first and keep the old one: %+v", jwks)
+	}
+	if _, err := issuer.Verify(old.Token); err != nil {
+		t.Fatalf("old token during overlap: %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	if _, ok := keys.Key(first.KeyID()); ok {
+		t.Fatalf("old key should retire after the overlap")
+	}
+	if _, err := issuer.Verify(fresh.Token); err != nil {
+		t.Fatalf("new token: %v", err)
+	}
+	if len(keys.JWKS().Keys) != 1 {
+		t.Fatalf("retired key should leave the JWKS: %+v", keys.JWKS())
+	}
+}
+
+func TestKeyManager_RejectsDuplicateKeyIDs(t *testing.T) {
+	first, _ := GenerateSigningKey(AlgHS256)
+	second, _ := GenerateSigningKey(AlgHS256)
+	keys := NewKeyManager(first)
+	_ = keys.Rotate(second)
+
+	// A chave substituída ainda está na sobreposição e não volta como outra
+	if err := keys.Stage(first); !errors.Is(err, ErrDuplicateKeyID) {
+		t.Fatalf("stage retiring key: got %v", err)
+	}
+	if len(keys.JWKS().Keys) != 0 {
+		t.Fatalf("HS256 keys must never be published")
+	}
+}
+
+func TestPublicJWK_VerifiesTokens(t *testing.T) {
+	rsaPriv, _ := rsa.GenerateKey(rand.Reader, 2048)
+	key, _ := NewRS256Key("r1", rsaPriv)
+	jwk, ok := PublicJWK(key)
+	if !ok || jwk.KeyType != "RSA" || jwk.Algorithm != AlgRS256 || jwk.Use != "sig" {
+		t.Fatalf("jwk: %+v", jwk)
+	}
+	n, _ := base64.RawURLEncoding.DecodeString(jwk.N)
+	e, _ := base64.RawURLEncoding.DecodeString(jwk.E)
+	pub := rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	if !pub.Equal(&rsaPriv.PublicKey) {
+		t.Fatalf("published RSA key does not match")
+	}
+
+	_, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+	edKey, _ := NewEdDSAKey("e1", edPriv)
+	jwk, _ = PublicJWK(edKey)
+	x, _ := base64.RawURLEncoding.DecodeString(jwk.X)
+	if jwk.KeyType != "OKP" || jwk.Curve != "Ed25519" || !ed25519.PublicKey(x).Equal(edPriv.Public()) {
+		t.Fatalf("jwk: %+v", jwk)
+	}
+}
+
+func TestKeyRotator_GeneratedKeysPublishBeforeSigning(t *testing.T) {
+	initial, _ := GenerateSigningKey(AlgEdDSA)
+	keys := NewKeyManager(initial)
+	var rotatedTo []string
+	rotator := NewKeyRotator(keys, GeneratedKeys(AlgEdDSA), time.Hour, func(kid string) { rotatedTo = append(rotatedTo, kid) })
+	ctx := context.Background()
+
+	// A primeira rodada só publica a próxima
+	if rotated, err := rotator.RotateOnce(ctx); err != nil || rotated {
+		t.Fatalf("first round: rotated=%v err=%v", rotated, err)
+	}
+	published := keys.JWKS().Keys[1].KeyID
+
+	if rotated, err := rotator.RotateOnce(ctx); err != nil || !rotated {
+		t.Fatalf("second round: rotated=%v err=%v", rotated, err)
+	}
+	if keys.Signer().KeyID() != published || len(rotatedTo) != 1 || rotatedTo[0] != published {
+		t.Fatalf("the published key should be the one promoted: signer=%s rotated=%v", keys.Signer().KeyID(), rotatedTo)
+	}
+	if _, ok := keys.Key(initial.KeyID()); !ok {
+		t.Fatalf("initial key should stay during the overlap")
+	}
+}
+
+type mapSecrets map[string][]byte
+
+func (m mapSecrets) Secret(ctx context.Context, name string) ([]byte, error) {
+	if v, ok := m[name]; ok {
+		return v, nil
+	}
+	return nil, fmt.Errorf("%w: %s", secrets.ErrNotFound, name)
+}
+
+func ed25519PEM(t *testing.T) []byte {
+	t.Helper()
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func TestProviderKeys_FollowTheSecrets(t *testing.T) {
+	ctx := context.Background()
+	store := mapSecrets{"jwt/active": ed25519PEM(t)}
+	source := ProviderKeys(store, AlgEdDSA, "jwt/active", "jwt/next")
+
+	first, _, err := source(ctx)
+	if err != nil {
+		t.Fatalf("source: %v", err)
+	}
+	if again, _ := LoadSigningKey(ctx, store, "jwt/active", AlgEdDSA); again.KeyID() != first.KeyID() {
+		t.Fatalf("kid should be derived from the key")
+	}
+	keys := NewKeyManager(first)
+	rotator := NewKeyRotator(keys, source, time.Minute, nil)
+
+	// Operação publica a próxima, e depois a promove
+	store["jwt/next"] = ed25519PEM(t)
+	if _, err := rotator.RotateOnce(ctx); err != nil || len(keys.JWKS().Keys) != 2 {
+		t.Fatalf("stage from provider: %v %+v", err, keys.JWKS())
+	}
+	store["jwt/active"] = store["jwt/next"]
+	if rotated, err := rotator.RotateOnce(ctx); err != nil || !rotated {
+		t.Fatalf("promote from provider: rotated=%v err=%v", rotated, err)
+	}
+	if keys.Signer().KeyID() == first.KeyID() {
+		t.Fatalf("signer should follow the active secret")
+	}
+
+	if _, err := LoadSigningKey(ctx, store, "jwt/active", AlgRS256); err == nil {
+		t.Fatalf("algorithm mismatch should fail")
+	}
+	if _, err := LoadSigningKey(ctx, mapSecrets{"hs": []byte(strings.Repeat("s", 32) + "\n")}, "hs", AlgHS256); err != nil {
+		t.Fatalf("HS256 secret with trailing newline: %v", err)
+	}
+}
+
+func headerOf(t *testing.T, token string) string {
+	t.Helper()
+	h, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[0])
+	if err != nil {
+		t.Fatalf("header: %v", err)
+	}
+	return string(h)
+}
@@ -0,0 +1,66 @@
+package mappers
+
+import (
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth/domain"
+)
+
+// APIKeyResponse descreve a chave sem o segredo
+type APIKeyResponse struct {
+	Prefix     string   `json:"prefix"`
+	Name       string   `json:"name"`
+	OrgID      string   `json:"orgId"`
+	Scopes     []string `json:"scopes"`
+	CreatedBy  string   `json:"createdBy,omitempty"`
+	CreatedAt  string   `json:"createdAt"`
+	ExpiresAt  *string  `json:"expiresAt,omitempty"`
+	LastUsedAt *string  `json:"lastUsedAt,omitempty"`
+	RevokedAt  *string  `json:"revokedAt,omitempty"`
+}
+
+// CreatedAPIKeyResponse é a única resposta que traz o valor da chave
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+func ToAPIKeyResponse(k domain.APIKey) APIKeyResponse {
+	scopes := make([]string, len(k.Scopes))
+	for i, s := range k.Scopes {
+		scopes[i] = string(s)
+	}
+
+	return APIKeyResponse{
+		Prefix:     k.Prefix,
+		Name:       k.Name,
+		OrgID:      k.OrgID,
+		Scopes:     scopes,
+		CreatedBy:  string(k.CreatedBy),
+		CreatedAt:  k.CreatedAt.UTC().Format(time.RFC3339),
+		ExpiresAt:  formatTimePtr(k.ExpiresAt),
+		LastUsedAt: formatTimePtr(k.LastUsedAt),
+		RevokedAt:  formatTimePtr(k.RevokedAt),
+	}
+}
+
+func ToAPIKeyListResponse(keys []domain.APIKey) []APIKeyResponse {
+	out := make([]APIKeyResponse, len(keys))
+	for i, k := range keys {
+		out[i] = ToAPIKeyResponse(k)
+	}
+	return out
+}
+
+func ToCreatedAPIKeyResponse(k domain.APIKey, raw string) CreatedAPIKeyResponse {
+	return CreatedAPIKeyResponse{APIKeyResponse: ToAPIKeyResponse(k), Key: raw}
+}
+
+// formatTimePtr formata datas opcionais em RFC 3339 (UTC)
+func formatTimePtr(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.UTC().Format(time.RFC3339)
+	return &s
+}
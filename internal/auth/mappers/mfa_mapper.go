@@ -0,0 +1,42 @@
+package mappers
+
+import (
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth/domain"
+)
+
+// MFAEnrollmentResponse leva o segredo para o aplicativo autenticador
+type MFAEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauthUri"`
+}
+
+// RecoveryCodesResponse só existe na confirmação: os códigos não são mostrados de novo
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// MFAChallengeResponse é a resposta do login quando falta o segundo fator
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfaRequired"`
+	MFAToken    string `json:"mfaToken"`
+	ExpiresIn   int64  `json:"expiresIn"`
+	ExpiresAt   string `json:"expiresAt"`
+}
+
+func ToMFAEnrollmentResponse(m domain.MFA, issuer, account string) MFAEnrollmentResponse {
+	return MFAEnrollmentResponse{
+		Secret:     m.SecretBase32(),
+		OTPAuthURI: m.OTPAuthURI(issuer, account),
+	}
+}
+
+func ToMFAChallengeResponse(raw string, c domain.MFAChallenge, now time.Time) MFAChallengeResponse {
+	return MFAChallengeResponse{
+		MFARequired: true,
+		MFAToken:    raw,
+		ExpiresIn:   int64(c.ExpiresAt.Sub(now).Seconds()),
+		ExpiresAt:   c.ExpiresAt.UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,35 @@
+package mappers
+
+import (
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth/domain"
+)
+
+// TokenResponse segue o formato de resposta de token do OAuth 2.0 (RFC 6749)
+type TokenResponse struct {
+	AccessToken string `json:"accessToken"`
+	TokenType   string `json:"tokenType"`
+	ExpiresIn   int64  `json:"expiresIn"`
+	ExpiresAt   string `json:"expiresAt"`
+	// RefreshToken só vem quando os refresh tokens estão habilitados
+	RefreshToken     string `json:"refreshToken,omitempty"`
+	RefreshExpiresAt string `json:"refreshExpiresAt,omitempty"`
+}
+
+func ToTokenResponse(t auth.AccessToken) TokenResponse {
+	return TokenResponse{
+		AccessToken: t.Token,
+		TokenType:   "Bearer",
+		ExpiresIn:   t.Claims.ExpiresAt - t.Claims.IssuedAt,
+		ExpiresAt:   t.ExpiresAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// WithRefreshToken acrescenta o refresh token à resposta
+func (r TokenResponse) WithRefreshToken(raw string, t domain.RefreshToken) TokenResponse {
+	r.RefreshToken = raw
+	r.RefreshExpiresAt = t.ExpiresAt.UTC().Format(time.RFC3339)
+	return r
+}
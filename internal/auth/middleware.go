@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+)
+
+// PermissionResolver traduz papéis em permissões efetivas
+type PermissionResolver interface {
+	Permissions(ctx context.Context, roles []string) (rbac.PermissionSet, error)
+}
+
+// Authorize confere se o principal da requisição tem a permissão. Quando não
+// tem, responde 401 (sem principal) ou 403 (sem a permissão), aborta e devolve false.
+func Authorize(c *gin.Context, resolver PermissionResolver, perm rbac.Permission) bool {
+	p, ok := PrincipalFrom(c.Request.Context())
+	if !ok {
+		c.Header("WWW-Authenticate", "Bearer")
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return false
+	}
+
+	perms, err := p.Permissions(c.Request.Context(), resolver)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	if !perms.Has(perm) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "missing permission " + string(perm)})
+		return false
+	}
+	return true
+}
+
+// AuthorizeOver confere, antes de mexer nas credenciais de outro usuário
+// (senha, email, segundo fator), se o principal tem todas as permissões dos
+// papéis dele: quem toma a conta herda o que ela pode. Quando não tem,
+// responde 401 (sem principal) ou 403, aborta e devolve false.
+func AuthorizeOver(c *gin.Context, resolver PermissionResolver, roles []string) bool {
+	p, ok := PrincipalFrom(c.Request.Context())
+	if !ok {
+		c.Header("WWW-Authenticate", "Bearer")
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return false
+	}
+
+	ctx := c.Request.Context()
+	granted, err := p.Permissions(ctx, resolver)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	target, err := resolver.Permissions(ctx, roles)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	for perm := range target {
+		if target.Has(perm) && !granted.Has(perm) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "user has permissions you lack (" + string(perm) + ")"})
+			return false
+		}
+	}
+	return true
+}
+
+// RequirePermission é o middleware equivalente a Authorize, para guardar rotas inteiras
+func RequirePermission(resolver PermissionResolver, perm rbac.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if Authorize(c, resolver, perm) {
+			c.Next()
+		}
+	}
+}
+
+// RequirePermissionOrSelf libera a rota para o próprio usuário, quando o
+// parâmetro param é o ID do principal, e exige perm dos demais
+func RequirePermissionOrSelf(resolver PermissionResolver, perm rbac.Permission, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if p, ok := PrincipalFrom(c.Request.Context()); ok && p.UserID != "" && c.Param(param) == string(p.UserID) {
+			c.Next()
+			return
+		}
+		if Authorize(c, resolver, perm) {
+			c.Next()
+		}
+	}
+}
+
+// RequirePlatform guarda as rotas que afetam todas as organizações (criar
+// organizações, editar o catálogo de papéis compartilhado): credenciais de um
+// tenant recebem 403. Vem depois de RequirePermission, que já exige o principal.
+func RequirePlatform() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, ok := PrincipalFrom(c.Request.Context())
+		if !ok {
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+			return
+		}
+		if !p.Platform() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "only platform administrators can change this resource"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+)
+
+type resolverFunc func(ctx context.Context, roles []string) (rbac.PermissionSet, error)
+
+func (f resolverFunc) Permissions(ctx context.Context, roles []string) (rbac.PermissionSet, error) {
+	return f(ctx, roles)
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resolver := resolverFunc(func(ctx context.Context, roles []string) (rbac.PermissionSet, error) {
+		set := rbac.PermissionSet{}
+		for _, r := range roles {
+			switch r {
+			case "reader":
+				set[rbac.PermUsersRead] = true
+			case "broken":
+				return nil, errors.New("catalog unavailable")
+			}
+		}
+		return set, nil
+	})
+
+	cases := []struct {
+		name      string
+		principal *Principal
+		want      int
+	}{
+		{"no principal", nil, http.StatusUnauthorized},
+		{"missing permission", &Principal{UserID: "u1", Roles: []string{"user"}}, http.StatusForbidden},
+		{"granted", &Principal{UserID: "u1", Roles: []string{"user", "reader"}}, http.StatusOK},
+		{"resolver error", &Principal{UserID: "u1", Roles: []string{"broken"}}, http.StatusInternalServerError},
+		{"api key with scope", &Principal{KeyID: "ak_1", Scopes: []rbac.Permission{rbac.PermUsersRead}}, http.StatusOK},
+		{"api key ignores roles", &Principal{KeyID: "ak_1", Roles: []string{"reader"}, Scopes: []rbac.Permission{rbac.PermUsersWrite}}, http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				if tc.principal != nil {
+					SetPrincipal(c, *tc.principal)
+				}
+				c.Next()
+			})
+			reached := false
+			r.GET("/users", RequirePermission(resolver, rbac.PermUsersRead), func(c *gin.Context) {
+				reached = true
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+			if w.Code != tc.want {
+				t.Fatalf("status: got %d, want %d", w.Code, tc.want)
+			}
+			if reached != (tc.want == http.StatusOK) {
+				t.Fatalf("handler reached=%v with status %d", reached, w.Code)
+			}
+		})
+	}
+}
+
+func TestRequirePermissionOrSelf(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resolver := resolverFunc(func(ctx context.Context, roles []string) (rbac.PermissionSet, error) {
+		set := rbac.PermissionSet{}
+		for _, r := range roles {
+			if r == "admin" {
+				set[rbac.PermUsersRead] = true
+			}
+		}
+		return set, nil
+	})
+
+	cases := []struct {
+		name      string
+		principal *Principal
+		path      string
+		want      int
+	}{
+		{"self", &Principal{UserID: "u1", Roles: []string{"user"}}, "/users/u1", http.StatusOK},
+		{"someone else", &Principal{UserID: "u1", Roles: []string{"user"}}, "/users/u2", http.StatusForbidden},
+		{"admin on someone else", &Principal{UserID: "u1", Roles: []string{"admin"}}, "/users/u2", http.StatusOK},
+		{"no principal", nil, "/users/u1", http.StatusUnauthorized},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				if tc.principal != nil {
+					SetPrincipal(c, *tc.principal)
+				}
+				c.Next()
+			})
+			r.GET("/users/:id", RequirePermissionOrSelf(resolver, rbac.PermUsersRead, "id"), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			if w.Code != tc.want {
+				t.Fatalf("status: got %d, want %d", w.Code, tc.want)
+			}
+		})
+	}
+}
+
+func TestRequirePlatform(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		name      string
+		principal *Principal
+		want      int
+	}{
+		{"default org", &Principal{UserID: "u1", Roles: []string{"admin"}, OrgID: "default"}, http.StatusOK},
+		{"credential without org", &Principal{UserID: "u1", Roles: []string{"admin"}}, http.StatusOK},
+		{"tenant admin", &Principal{UserID: "u1", Roles: []string{"admin"}, OrgID: "acme"}, http.StatusForbidden},
+		{"tenant api key", &Principal{KeyID: "ak_1", OrgID: "acme", Scopes: []rbac.Permission{rbac.PermOrgsWrite}}, http.StatusForbidden},
+		{"no principal", nil, http.StatusUnauthorized},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				if tc.principal != nil {
+					SetPrincipal(c, *tc.principal)
+				}
+				c.Next()
+			})
+			r.POST("/orgs", RequirePlatform(), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orgs", nil))
+			if w.Code != tc.want {
+				t.Fatalf("status: got %d, want %d", w.Code, tc.want)
+			}
+		})
+	}
+}
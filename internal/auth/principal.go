@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+
+	org "github.com/williamkoller/cloud-architecture-golang/internal/org/domain"
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+)
+
+// Principal identifica quem faz a requisição e com quais papéis
+type Principal struct {
+	UserID vo.UserID
+	Roles  []string
+	// OrgID é a organização a que a credencial pertence (claim org do token);
+	// vazio quando a credencial não é restrita a uma organização
+	OrgID string
+	// KeyID é o prefixo da API key quando a credencial é uma; nesse caso não
+	// há usuário e Scopes são as permissões exatas da chave
+	KeyID  string
+	Scopes []rbac.Permission
+	// IssuedAt é a emissão do token (segundos Unix), para conferir a sessão
+	// de um principal que chega já autenticado; zero para API keys
+	IssuedAt int64
+}
+
+// Permissions devolve as permissões efetivas do principal: os escopos de uma
+// API key ou as permissões dos papéis, segundo o resolver
+func (p Principal) Permissions(ctx context.Context, resolver PermissionResolver) (rbac.PermissionSet, error) {
+	if p.KeyID != "" {
+		set := make(rbac.PermissionSet, len(p.Scopes))
+		for _, s := range p.Scopes {
+			set[s] = true
+		}
+		return set, nil
+	}
+	return resolver.Permissions(ctx, p.Roles)
+}
+
+// Platform indica se a credencial administra a plataforma: pertence à
+// organização padrão ou não é restrita a nenhuma. Administradores de um
+// tenant não são da plataforma, mesmo com o papel admin.
+func (p Principal) Platform() bool {
+	return p.OrgID == "" || p.OrgID == org.DefaultOrgID
+}
+
+type principalKey struct{}
+
+// WithPrincipal anexa o principal ao contexto
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom recupera o principal anexado por um autenticador
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// SetPrincipal anexa o principal ao contexto da requisição, para uso pelos
+// autenticadores que rodam antes dos guardas de permissão
+func SetPrincipal(c *gin.Context, p Principal) {
+	c.Request = c.Request.WithContext(WithPrincipal(c.Request.Context(), p))
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth/domain"
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// APIKeyRepository guarda as API keys pelo prefixo público. A busca por
+// prefixo é global, porque a chave é conferida antes de a organização da
+// requisição ser resolvida; as operações de administração são por organização.
+type APIKeyRepository interface {
+	Create(ctx context.Context, k domain.APIKey) error
+	Get(ctx context.Context, prefix string) (domain.APIKey, bool, error)
+	// List devolve as chaves da organização, das mais antigas às mais novas
+	List(ctx context.Context, orgID string) ([]domain.APIKey, error)
+	// Revoke revoga a chave da organização; revogar de novo mantém a data original
+	Revoke(ctx context.Context, orgID, prefix string, at time.Time) (domain.APIKey, error)
+	// MarkUsed registra o último uso, sem voltar no tempo
+	MarkUsed(ctx context.Context, prefix string, at time.Time) error
+}
+
+type inMemoryAPIKeyRepo struct {
+	mu   sync.RWMutex
+	keys map[string]domain.APIKey
+}
+
+func NewInMemoryAPIKeyRepository() APIKeyRepository {
+	return &inMemoryAPIKeyRepo{keys: make(map[string]domain.APIKey)}
+}
+
+func (r *inMemoryAPIKeyRepo) Create(ctx context.Context, k domain.APIKey) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[k.Prefix] = k
+	return nil
+}
+
+func (r *inMemoryAPIKeyRepo) Get(ctx context.Context, prefix string) (domain.APIKey, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.APIKey{}, false, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	k, ok := r.keys[prefix]
+	return k, ok, nil
+}
+
+func (r *inMemoryAPIKeyRepo) List(ctx context.Context, orgID string) ([]domain.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]domain.APIKey, 0)
+	for _, k := range r.keys {
+		if k.OrgID == orgID {
+			out = append(out, k)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].Prefix < out[j].Prefix
+	})
+	return out, nil
+}
+
+func (r *inMemoryAPIKeyRepo) Revoke(ctx context.Context, orgID, prefix string, at time.Time) (domain.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.APIKey{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k, ok := r.keys[prefix]
+	if !ok || k.OrgID != orgID {
+		return domain.APIKey{}, ErrAPIKeyNotFound
+	}
+	if k.RevokedAt == nil {
+		k.RevokedAt = &at
+		r.keys[prefix] = k
+	}
+	return k, nil
+}
+
+func (r *inMemoryAPIKeyRepo) MarkUsed(ctx context.Context, prefix string, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k, ok := r.keys[prefix]
+	if !ok {
+		return ErrAPIKeyNotFound
+	}
+	if k.LastUsedAt == nil || at.After(*k.LastUsedAt) {
+		k.LastUsedAt = &at
+		r.keys[prefix] = k
+	}
+	return nil
+}
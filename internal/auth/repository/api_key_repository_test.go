@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth/domain"
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+)
+
+func mustAPIKey(t *testing.T, repo APIKeyRepository, name, org string, createdAt time.Time) domain.APIKey {
+	t.Helper()
+	k, _, err := domain.NewAPIKey(name, org, []rbac.Permission{rbac.PermUsersRead}, "admin-1", createdAt, nil)
+	if err != nil {
+		t.Fatalf("NewAPIKey: %v", err)
+	}
+	if err := repo.Create(context.Background(), k); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return k
+}
+
+func TestAPIKeys_ListAndRevokeAreScopedToTheOrg(t *testing.T) {
+	repo := NewInMemoryAPIKeyRepository()
+	ctx := context.Background()
+
+	second := mustAPIKey(t, repo, "second", "acme", t0.Add(time.Minute))
+	first := mustAPIKey(t, repo, "first", "acme", t0)
+	other := mustAPIKey(t, repo, "other", "globex", t0)
+
+	keys, err := repo.List(ctx, "acme")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(keys) != 2 || keys[0].Prefix != first.Prefix || keys[1].Prefix != second.Prefix {
+		t.Fatalf("List: got %+v", keys)
+	}
+
+	if _, err := repo.Revoke(ctx, "acme", other.Prefix, t0); err != ErrAPIKeyNotFound {
+		t.Fatalf("revoke from another org: got %v", err)
+	}
+	revoked, err := repo.Revoke(ctx, "acme", first.Prefix, t0)
+	if err != nil || revoked.RevokedAt == nil || !revoked.RevokedAt.Equal(t0) {
+		t.Fatalf("Revoke: got %+v, %v", revoked, err)
+	}
+	again, _ := repo.Revoke(ctx, "acme", first.Prefix, t0.Add(time.Hour))
+	if !again.RevokedAt.Equal(t0) {
+		t.Fatalf("second revoke should keep the original date: %v", again.RevokedAt)
+	}
+
+	// A chave segue encontrável pelo prefixo, mas não é mais utilizável
+	got, ok, _ := repo.Get(ctx, first.Prefix)
+	if !ok || got.Usable(t0) {
+		t.Fatalf("revoked key: got %+v, %v", got, ok)
+	}
+}
+
+func TestAPIKeys_MarkUsedNeverGoesBack(t *testing.T) {
+	repo := NewInMemoryAPIKeyRepository()
+	ctx := context.Background()
+	k := mustAPIKey(t, repo, "job", "acme", t0)
+
+	if err := repo.MarkUsed(ctx, k.Prefix, t0.Add(time.Hour)); err != nil {
+		t.Fatalf("MarkUsed: %v", err)
+	}
+	if err := repo.MarkUsed(ctx, k.Prefix, t0.Add(time.Minute)); err != nil {
+		t.Fatalf("MarkUsed: %v", err)
+	}
+	got, _, _ := repo.Get(ctx, k.Prefix)
+	if got.LastUsedAt == nil || !got.LastUsedAt.Equal(t0.Add(time.Hour)) {
+		t.Fatalf("LastUsedAt: got %v", got.LastUsedAt)
+	}
+	if err := repo.MarkUsed(ctx, "ak_missing", t0); err != ErrAPIKeyNotFound {
+		t.Fatalf("missing key: got %v", err)
+	}
+}
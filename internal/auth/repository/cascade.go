@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	org "github.com/williamkoller/cloud-architecture-golang/internal/org/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/events"
+)
+
+// RevokeUserSessions é o assinante que revoga os refresh tokens do usuário
+// quando as sessões são encerradas (redefinição de senha), quando ele é
+// excluído ou quando a conta deixa de estar ativa. É idempotente.
+func RevokeUserSessions(repo RefreshTokenRepository) events.Subscriber {
+	return func(ctx context.Context, e domain.Event) error {
+		switch e.EventName() {
+		case domain.EventUserSessionsRevoked, domain.EventUserDeleted:
+		case domain.EventUserStatusChanged:
+			if changed, ok := e.(domain.UserStatusChanged); !ok || changed.Status == domain.StatusActive {
+				return nil
+			}
+		default:
+			return nil
+		}
+		orgID, ok := events.OrgID(ctx)
+		if !ok {
+			orgID = org.DefaultOrgID
+		}
+		_, err := repo.RevokeUser(ctx, orgID, e.AggregateID())
+		return err
+	}
+}
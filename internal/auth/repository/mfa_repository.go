@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+)
+
+var (
+	ErrMFANotFound = errors.New("mfa not found")
+	// ErrMFAConflict indica que o fator mudou desde a leitura; num código
+	// TOTP, é outro uso do mesmo código vencendo a corrida
+	ErrMFAConflict       = errors.New("mfa was modified concurrently")
+	ErrChallengeNotFound = errors.New("mfa challenge not found")
+)
+
+// MFARepository guarda o segundo fator de cada usuário. Save é um
+// compare-and-swap em Version, como no repositório de usuários: é o que
+// impede que o mesmo código TOTP seja aceito por duas requisições.
+type MFARepository interface {
+	Get(ctx context.Context, orgID string, userID vo.UserID) (domain.MFA, bool, error)
+	// Save grava m se Version ainda for a guardada (0 para criar) e devolve
+	// o fator com a nova versão
+	Save(ctx context.Context, m domain.MFA) (domain.MFA, error)
+	Delete(ctx context.Context, orgID string, userID vo.UserID) (bool, error)
+}
+
+type mfaKey struct {
+	orgID  string
+	userID vo.UserID
+}
+
+type inMemoryMFARepo struct {
+	mu      sync.Mutex
+	factors map[mfaKey]domain.MFA
+}
+
+func NewInMemoryMFARepository() MFARepository {
+	return &inMemoryMFARepo{factors: make(map[mfaKey]domain.MFA)}
+}
+
+func (r *inMemoryMFARepo) Get(ctx context.Context, orgID string, userID vo.UserID) (domain.MFA, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.MFA{}, false, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.factors[mfaKey{orgID, userID}]
+	return cloneMFA(m), ok, nil
+}
+
+func (r *inMemoryMFARepo) Save(ctx context.Context, m domain.MFA) (domain.MFA, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.MFA{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := mfaKey{m.OrgID, m.UserID}
+	current, exists := r.factors[key]
+	if (exists && current.Version != m.Version) || (!exists && m.Version != 0) {
+		return domain.MFA{}, ErrMFAConflict
+	}
+	m.Version++
+	r.factors[key] = cloneMFA(m)
+	return m, nil
+}
+
+func (r *inMemoryMFARepo) Delete(ctx context.Context, orgID string, userID vo.UserID) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := mfaKey{orgID, userID}
+	_, ok := r.factors[key]
+	delete(r.factors, key)
+	return ok, nil
+}
+
+// cloneMFA copia as fatias para que quem lê não altere o que está guardado
+func cloneMFA(m domain.MFA) domain.MFA {
+	m.Secret = append([]byte(nil), m.Secret...)
+	m.RecoveryCodes = append([]string(nil), m.RecoveryCodes...)
+	return m
+}
+
+// MFAChallengeRepository guarda os logins à espera do segundo fator
+type MFAChallengeRepository interface {
+	Create(ctx context.Context, c domain.MFAChallenge) error
+	// Get devolve o desafio ainda válido
+	Get(ctx context.Context, hash string) (domain.MFAChallenge, bool, error)
+	// Fail conta um código errado e descarta o desafio ao atingir maxAttempts
+	Fail(ctx context.Context, hash string, maxAttempts int) error
+	// Consume descarta o desafio; só a primeira chamada devolve true
+	Consume(ctx context.Context, hash string) (bool, error)
+}
+
+type inMemoryChallengeRepo struct {
+	mu         sync.Mutex
+	challenges map[string]domain.MFAChallenge
+	now        func() time.Time
+}
+
+func NewInMemoryMFAChallengeRepository() MFAChallengeRepository {
+	return &inMemoryChallengeRepo{
+		challenges: make(map[string]domain.MFAChallenge),
+		now:        time.Now,
+	}
+}
+
+func (r *inMemoryChallengeRepo) Create(ctx context.Context, c domain.MFAChallenge) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Os desafios duram minutos; os vencidos saem aqui mesmo, sem rotina de limpeza
+	now := r.now()
+	for hash, old := range r.challenges {
+		if old.Expired(now) {
+			delete(r.challenges, hash)
+		}
+	}
+	r.challenges[c.Hash] = c
+	return nil
+}
+
+func (r *inMemoryChallengeRepo) Get(ctx context.Context, hash string) (domain.MFAChallenge, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.MFAChallenge{}, false, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.challenges[hash]
+	if !ok || c.Expired(r.now()) {
+		return domain.MFAChallenge{}, false, nil
+	}
+	return c, true, nil
+}
+
+func (r *inMemoryChallengeRepo) Fail(ctx context.Context, hash string, maxAttempts int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.challenges[hash]
+	if !ok {
+		return ErrChallengeNotFound
+	}
+	c.Attempts++
+	if c.Attempts >= maxAttempts {
+		delete(r.challenges, hash)
+		return nil
+	}
+	r.challenges[hash] = c
+	return nil
+}
+
+func (r *inMemoryChallengeRepo) Consume(ctx context.Context, hash string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.challenges[hash]
+	delete(r.challenges, hash)
+	return ok && !c.Expired(r.now()), nil
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth/domain"
+)
+
+func TestMFA_SaveIsCompareAndSwap(t *testing.T) {
+	repo := NewInMemoryMFARepository()
+	ctx := context.Background()
+
+	m, _ := domain.NewMFA("user-1", "acme", t0)
+	saved, err := repo.Save(ctx, m)
+	if err != nil || saved.Version != 1 {
+		t.Fatalf("create: %+v, %v", saved, err)
+	}
+	if _, err := repo.Save(ctx, m); err != ErrMFAConflict {
+		t.Fatalf("second create: got %v", err)
+	}
+
+	// Duas leituras do mesmo fator: só a primeira gravação vence
+	a, _, _ := repo.Get(ctx, "acme", "user-1")
+	b, _, _ := repo.Get(ctx, "acme", "user-1")
+	a.LastUsedStep, b.LastUsedStep = 10, 10
+	if _, err := repo.Save(ctx, a); err != nil {
+		t.Fatalf("first writer: %v", err)
+	}
+	if _, err := repo.Save(ctx, b); err != ErrMFAConflict {
+		t.Fatalf("second writer: got %v", err)
+	}
+
+	// Alterar o que foi lido não altera o guardado
+	a.Secret[0] ^= 0xff
+	if got, _, _ := repo.Get(ctx, "acme", "user-1"); got.Secret[0] == a.Secret[0] {
+		t.Fatalf("stored secret should not alias the caller's copy")
+	}
+
+	if _, ok, _ := repo.Get(ctx, "globex", "user-1"); ok {
+		t.Fatalf("factors are per organization")
+	}
+	if ok, _ := repo.Delete(ctx, "acme", "user-1"); !ok {
+		t.Fatalf("Delete should report the removed factor")
+	}
+	if _, ok, _ := repo.Get(ctx, "acme", "user-1"); ok {
+		t.Fatalf("factor should be gone")
+	}
+}
+
+func TestMFAChallenges_AttemptsAndSingleUse(t *testing.T) {
+	repo := NewInMemoryMFAChallengeRepository()
+	ctx := context.Background()
+	now := time.Now()
+
+	c, _, _ := domain.NewMFAChallenge("user-1", "acme", now, time.Minute)
+	_ = repo.Create(ctx, c)
+
+	for i := 0; i < 2; i++ {
+		if err := repo.Fail(ctx, c.Hash, 3); err != nil {
+			t.Fatalf("Fail: %v", err)
+		}
+	}
+	if got, ok, _ := repo.Get(ctx, c.Hash); !ok || got.Attempts != 2 {
+		t.Fatalf("after two failures: %+v %v", got, ok)
+	}
+	_ = repo.Fail(ctx, c.Hash, 3)
+	if _, ok, _ := repo.Get(ctx, c.Hash); ok {
+		t.Fatalf("challenge should be dropped at the attempt limit")
+	}
+
+	d, _, _ := domain.NewMFAChallenge("user-1", "acme", now, time.Minute)
+	_ = repo.Create(ctx, d)
+	if ok, _ := repo.Consume(ctx, d.Hash); !ok {
+		t.Fatalf("first consume should win")
+	}
+	if ok, _ := repo.Consume(ctx, d.Hash); ok {
+		t.Fatalf("challenge is single use")
+	}
+
+	expired, _, _ := domain.NewMFAChallenge("user-1", "acme", now.Add(-time.Hour), time.Minute)
+	_ = repo.Create(ctx, expired)
+	if _, ok, _ := repo.Get(ctx, expired.Hash); ok {
+		t.Fatalf("expired challenge should not be returned")
+	}
+}
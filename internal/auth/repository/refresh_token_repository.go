@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+)
+
+var (
+	ErrNotFound     = errors.New("refresh token not found")
+	ErrTokenExpired = errors.New("refresh token expired")
+	ErrTokenRevoked = errors.New("refresh token revoked")
+	// ErrTokenReused indica um token já trocado usado de novo: a família foi revogada
+	ErrTokenReused = errors.New("refresh token reused; session revoked")
+)
+
+// RefreshTokenRepository guarda os refresh tokens pelo hash. Rotate é atômico:
+// de duas trocas concorrentes do mesmo token, só uma vence, e a outra conta
+// como reutilização.
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, t domain.RefreshToken) error
+	Get(ctx context.Context, hash string) (domain.RefreshToken, bool, error)
+	// Rotate marca o token hash como trocado e grava next, da mesma família
+	Rotate(ctx context.Context, hash string, next domain.RefreshToken) error
+	// RevokeFamily revoga os tokens ainda válidos da família e devolve quantos eram
+	RevokeFamily(ctx context.Context, familyID string) (int, error)
+	// RevokeUser revoga todos os tokens do usuário na organização
+	RevokeUser(ctx context.Context, orgID string, userID vo.UserID) (int, error)
+	// DeleteExpired remove os tokens vencidos até before, trocados ou não
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
+}
+
+type inMemoryRefreshTokenRepo struct {
+	mu     sync.Mutex
+	tokens map[string]domain.RefreshToken
+	now    func() time.Time
+}
+
+// Option configura o repositório em memória
+type Option func(*inMemoryRefreshTokenRepo)
+
+// WithClock substitui o relógio usado na validade e nas datas de revogação
+func WithClock(now func() time.Time) Option {
+	return func(r *inMemoryRefreshTokenRepo) {
+		r.now = now
+	}
+}
+
+func NewInMemoryRefreshTokenRepository(opts ...Option) RefreshTokenRepository {
+	r := &inMemoryRefreshTokenRepo{
+		tokens: make(map[string]domain.RefreshToken),
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *inMemoryRefreshTokenRepo) Create(ctx context.Context, t domain.RefreshToken) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens[t.Hash] = t
+	return nil
+}
+
+func (r *inMemoryRefreshTokenRepo) Get(ctx context.Context, hash string) (domain.RefreshToken, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.RefreshToken{}, false, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tokens[hash]
+	return t, ok, nil
+}
+
+func (r *inMemoryRefreshTokenRepo) Rotate(ctx context.Context, hash string, next domain.RefreshToken) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.tokens[hash]
+	if !ok || current.FamilyID != next.FamilyID {
+		return ErrNotFound
+	}
+	now := r.now()
+	switch {
+	case current.RevokedAt != nil:
+		return ErrTokenRevoked
+	case current.RotatedAt != nil:
+		// Alguém guardou um token já trocado: ladrão ou cliente legítimo,
+		// não há como saber, então a sessão inteira cai
+		r.revokeFamily(current.FamilyID, now)
+		return ErrTokenReused
+	case current.Expired(now):
+		return ErrTokenExpired
+	}
+
+	current.RotatedAt = &now
+	r.tokens[hash] = current
+	r.tokens[next.Hash] = next
+	return nil
+}
+
+func (r *inMemoryRefreshTokenRepo) RevokeFamily(ctx context.Context, familyID string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.revokeFamily(familyID, r.now()), nil
+}
+
+// revokeFamily deve ser chamado com o lock
+func (r *inMemoryRefreshTokenRepo) revokeFamily(familyID string, now time.Time) int {
+	return r.revokeWhere(now, func(t domain.RefreshToken) bool {
+		return t.FamilyID == familyID
+	})
+}
+
+func (r *inMemoryRefreshTokenRepo) RevokeUser(ctx context.Context, orgID string, userID vo.UserID) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.revokeWhere(r.now(), func(t domain.RefreshToken) bool {
+		return t.OrgID == orgID && t.UserID == userID
+	}), nil
+}
+
+// revokeWhere revoga os tokens ainda não revogados que satisfazem match.
+// Deve ser chamado com o lock.
+func (r *inMemoryRefreshTokenRepo) revokeWhere(now time.Time, match func(domain.RefreshToken) bool) int {
+	n := 0
+	for hash, t := range r.tokens {
+		if t.RevokedAt != nil || !match(t) {
+			continue
+		}
+		t.RevokedAt = &now
+		r.tokens[hash] = t
+		if t.RotatedAt == nil && !t.Expired(now) {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *inMemoryRefreshTokenRepo) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for hash, t := range r.tokens {
+		if t.Expired(before) {
+			delete(r.tokens, hash)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth/domain"
+	usr "github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/events"
+	usr_repository "github.com/williamkoller/cloud-architecture-golang/internal/usr/repository"
+)
+
+var t0 = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func mustToken(t *testing.T, repo RefreshTokenRepository, user, org string) domain.RefreshToken {
+	t.Helper()
+	tok, _, err := domain.NewRefreshToken(vo.UserID(user), org, t0, time.Hour)
+	if err != nil {
+		t.Fatalf("NewRefreshToken: %v", err)
+	}
+	if err := repo.Create(context.Background(), tok); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return tok
+}
+
+func mustNext(t *testing.T, tok domain.RefreshToken) domain.RefreshToken {
+	t.Helper()
+	next, _, err := tok.Next(t0, time.Hour)
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	return next
+}
+
+func TestRotate_ReplayRevokesFamily(t *testing.T) {
+	repo := NewInMemoryRefreshTokenRepository(WithClock(func() time.Time { return t0 }))
+	ctx := context.Background()
+
+	first := mustToken(t, repo, "user-1", "acme")
+	second := mustNext(t, first)
+	if err := repo.Rotate(ctx, first.Hash, second); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	third := mustNext(t, second)
+	if err := repo.Rotate(ctx, second.Hash, third); err != nil {
+		t.Fatalf("Rotate second: %v", err)
+	}
+
+	// Outro login do mesmo usuário não é afetado
+	other := mustToken(t, repo, "user-1", "acme")
+
+	// Reapresentar o primeiro token derruba a família, inclusive o mais recente
+	if err := repo.Rotate(ctx, first.Hash, mustNext(t, first)); err != ErrTokenReused {
+		t.Fatalf("replay: got %v, want %v", err, ErrTokenReused)
+	}
+	if err := repo.Rotate(ctx, third.Hash, mustNext(t, third)); err != ErrTokenRevoked {
+		t.Fatalf("latest token after replay: got %v, want %v", err, ErrTokenRevoked)
+	}
+	if err := repo.Rotate(ctx, other.Hash, mustNext(t, other)); err != nil {
+		t.Fatalf("other family: %v", err)
+	}
+
+	if err := repo.Rotate(ctx, "unknown", mustNext(t, first)); err != ErrNotFound {
+		t.Fatalf("unknown hash: got %v, want %v", err, ErrNotFound)
+	}
+	// O substituto precisa ser da mesma família
+	fresh := mustToken(t, repo, "user-1", "acme")
+	if err := repo.Rotate(ctx, fresh.Hash, third); err != ErrNotFound {
+		t.Fatalf("foreign family: got %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestRotate_ConcurrentUseOnlyOneWins(t *testing.T) {
+	repo := NewInMemoryRefreshTokenRepository(WithClock(func() time.Time { return t0 }))
+	tok := mustToken(t, repo, "user-1", "acme")
+
+	var ok, reused atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			next, _, _ := tok.Next(t0, time.Hour)
+			switch repo.Rotate(context.Background(), tok.Hash, next) {
+			case nil:
+				ok.Add(1)
+			case ErrTokenReused, ErrTokenRevoked:
+				reused.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if ok.Load() != 1 || reused.Load() != 7 {
+		t.Fatalf("ok=%d reused=%d, want 1 and 7", ok.Load(), reused.Load())
+	}
+}
+
+func TestRotate_Expired(t *testing.T) {
+	now := t0
+	repo := NewInMemoryRefreshTokenRepository(WithClock(func() time.Time { return now }))
+	tok := mustToken(t, repo, "user-1", "acme")
+
+	now = tok.ExpiresAt
+	if err := repo.Rotate(context.Background(), tok.Hash, mustNext(t, tok)); err != ErrTokenExpired {
+		t.Fatalf("expired: got %v, want %v", err, ErrTokenExpired)
+	}
+}
+
+func TestRevokeUser_OnlyThatUserInThatOrg(t *testing.T) {
+	repo := NewInMemoryRefreshTokenRepository(WithClock(func() time.Time { return t0 }))
+	ctx := context.Background()
+
+	a := mustToken(t, repo, "user-1", "acme")
+	b := mustToken(t, repo, "user-1", "acme")
+	sameIDOtherOrg := mustToken(t, repo, "user-1", "globex")
+	otherUser := mustToken(t, repo, "user-2", "acme")
+
+	if n, err := repo.RevokeUser(ctx, "acme", "user-1"); err != nil || n != 2 {
+		t.Fatalf("RevokeUser: n=%d err=%v, want 2", n, err)
+	}
+	for _, tok := range []domain.RefreshToken{a, b} {
+		got, _, _ := repo.Get(ctx, tok.Hash)
+		if got.RevokedAt == nil {
+			t.Fatalf("token should be revoked: %+v", got)
+		}
+	}
+	for _, tok := range []domain.RefreshToken{sameIDOtherOrg, otherUser} {
+		got, _, _ := repo.Get(ctx, tok.Hash)
+		if got.RevokedAt != nil {
+			t.Fatalf("token should stay valid: %+v", got)
+		}
+	}
+	if n, _ := repo.RevokeUser(ctx, "acme", "user-1"); n != 0 {
+		t.Fatalf("second RevokeUser should be a no-op, got %d", n)
+	}
+}
+
+func TestSweeper_RemovesOnlyExpired(t *testing.T) {
+	repo := NewInMemoryRefreshTokenRepository(WithClock(func() time.Time { return t0 }))
+	ctx := context.Background()
+
+	old := mustToken(t, repo, "user-1", "acme")
+	fresh, _, _ := domain.NewRefreshToken("user-2", "acme", t0.Add(time.Hour), time.Hour)
+	if err := repo.Create(ctx, fresh); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	swept := 0
+	s := NewSweeper(repo, time.Hour, func(n int) { swept += n })
+	s.now = func() time.Time { return t0.Add(time.Hour) }
+	if n, err := s.SweepOnce(ctx); err != nil || n != 1 || swept != 1 {
+		t.Fatalf("SweepOnce: n=%d swept=%d err=%v", n, swept, err)
+	}
+	if _, ok, _ := repo.Get(ctx, old.Hash); ok {
+		t.Fatalf("expired token should be gone")
+	}
+	if _, ok, _ := repo.Get(ctx, fresh.Hash); !ok {
+		t.Fatalf("valid token should stay")
+	}
+}
+
+func TestRevokeUserSessions_Subscriber(t *testing.T) {
+	repo := NewInMemoryRefreshTokenRepository(WithClock(func() time.Time { return t0 }))
+	ctx := context.Background()
+
+	d := events.NewDispatcher()
+	sub := RevokeUserSessions(repo)
+	d.Subscribe(usr.EventUserSessionsRevoked, sub)
+	d.Subscribe(usr.EventUserStatusChanged, sub)
+	publish := func(e usr.Event) {
+		t.Helper()
+		msg := usr_repository.OutboxMessage{ID: e.EventName(), OrgID: "acme", Event: e}
+		if err := events.OutboxPublisher(d).Publish(ctx, msg); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+
+	tok := mustToken(t, repo, "user-1", "acme")
+	meta := usr.EventMeta{UserID: "user-1"}
+
+	// Voltar para active não encerra sessões
+	publish(usr.UserStatusChanged{EventMeta: meta, Previous: usr.StatusSuspended, Status: usr.StatusActive})
+	if got, _, _ := repo.Get(ctx, tok.Hash); got.RevokedAt != nil {
+		t.Fatalf("reactivation should not revoke sessions")
+	}
+
+	publish(usr.UserStatusChanged{EventMeta: meta, Previous: usr.StatusActive, Status: usr.StatusLocked})
+	if got, _, _ := repo.Get(ctx, tok.Hash); got.RevokedAt == nil {
+		t.Fatalf("locking the account should revoke sessions")
+	}
+
+	again := mustToken(t, repo, "user-1", "acme")
+	publish(usr.UserSessionsRevoked{EventMeta: meta})
+	if got, _, _ := repo.Get(ctx, again.Hash); got.RevokedAt == nil {
+		t.Fatalf("sessions revoked event should revoke refresh tokens")
+	}
+}
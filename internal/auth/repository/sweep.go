@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Sweeper remove periodicamente os refresh tokens vencidos. Tokens trocados
+// ficam guardados até vencer, para que a reutilização continue detectável.
+type Sweeper struct {
+	repo     RefreshTokenRepository
+	interval time.Duration
+	now      func() time.Time
+	onSweep  func(n int)
+}
+
+// NewSweeper cria a rotina de limpeza; onSweep (opcional) recebe quantos
+// tokens saíram em cada rodada
+func NewSweeper(repo RefreshTokenRepository, interval time.Duration, onSweep func(n int)) *Sweeper {
+	return &Sweeper{
+		repo:     repo,
+		interval: interval,
+		now:      time.Now,
+		onSweep:  onSweep,
+	}
+}
+
+// SweepOnce executa uma rodada de limpeza e devolve quantos tokens saíram
+func (s *Sweeper) SweepOnce(ctx context.Context) (int, error) {
+	n, err := s.repo.DeleteExpired(ctx, s.now())
+	if n > 0 && s.onSweep != nil {
+		s.onSweep(n)
+	}
+	return n, err
+}
+
+// Run executa a limpeza a cada intervalo até o contexto ser cancelado
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.SweepOnce(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("refresh token sweep failed: %v", err)
+			}
+		}
+	}
+}
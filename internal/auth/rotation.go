@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"log"
+	"time"
+)
+
+// KeySource informa, a cada rodada de rotação, a chave que deve assinar e a
+// próxima, a ser publicada antes de assumir. active nil mantém a chave atual;
+// next nil deixa de publicar a próxima.
+type KeySource func(ctx context.Context) (active, next SigningKey, err error)
+
+// KeyRotator aplica periodicamente ao KeyManager as chaves da fonte
+type KeyRotator struct {
+	keys     *KeyManager
+	source   KeySource
+	interval time.Duration
+	onRotate func(kid string)
+}
+
+// NewKeyRotator cria a rotina de rotação; onRotate (opcional) recebe o kid de
+// cada chave que passa a assinar
+func NewKeyRotator(keys *KeyManager, source KeySource, interval time.Duration, onRotate func(kid string)) *KeyRotator {
+	return &KeyRotator{
+		keys:     keys,
+		source:   source,
+		interval: interval,
+		onRotate: onRotate,
+	}
+}
+
+// RotateOnce consulta a fonte e aplica as chaves. Devolve se a chave ativa mudou.
+func (r *KeyRotator) RotateOnce(ctx context.Context) (bool, error) {
+	active, next, err := r.source(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	rotated := false
+	if active != nil && active.KeyID() != r.keys.Signer().KeyID() {
+		if err := r.keys.Rotate(active); err != nil {
+			return false, err
+		}
+		rotated = true
+		if r.onRotate != nil {
+			r.onRotate(active.KeyID())
+		}
+	}
+	return rotated, r.keys.Stage(next)
+}
+
+// Run executa a rotação a cada intervalo até o contexto ser cancelado
+func (r *KeyRotator) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.RotateOnce(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("signing key rotation failed: %v", err)
+			}
+		}
+	}
+}
+
+// GenerateSigningKey gera uma chave nova do algoritmo, com o kid derivado dela
+func GenerateSigningKey(alg string) (SigningKey, error) {
+	var (
+		key SigningKey
+		err error
+	)
+	switch alg {
+	case AlgHS256:
+		secret := make([]byte, 32)
+		if _, err = rand.Read(secret); err == nil {
+			key, err = NewHS256Key("", secret)
+		}
+	case AlgRS256:
+		var priv *rsa.PrivateKey
+		if priv, err = rsa.GenerateKey(rand.Reader, 2048); err == nil {
+			key, err = NewRS256Key("", priv)
+		}
+	case AlgEdDSA:
+		var priv ed25519.PrivateKey
+		if _, priv, err = ed25519.GenerateKey(rand.Reader); err == nil {
+			key, err = NewEdDSAKey("", priv)
+		}
+	default:
+		return nil, fmt.Errorf("unknown signing algorithm %q", alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return withKeyID(key, KeyIDFor(key)), nil
+}
+
+// GeneratedKeys é a fonte de chaves geradas no próprio processo: cada rodada
+// promove a chave publicada na rodada anterior e publica uma nova. A primeira
+// rodada só publica. Serve a uma instância única; com várias, cada uma teria
+// as suas chaves, e as chaves devem vir de um SecretsProvider.
+func GeneratedKeys(alg string) KeySource {
+	var pending SigningKey
+	return func(ctx context.Context) (SigningKey, SigningKey, error) {
+		fresh, err := GenerateSigningKey(alg)
+		if err != nil {
+			return nil, nil, err
+		}
+		promote := pending
+		pending = fresh
+		return promote, fresh, nil
+	}
+}
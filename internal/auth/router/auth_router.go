@@ -0,0 +1,75 @@
+package auth_router
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	auth_handler "github.com/williamkoller/cloud-architecture-golang/internal/auth/handler"
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+)
+
+// RouteOption configura o registro das rotas de autenticação e de API keys
+type RouteOption func(*routeConfig)
+
+type routeConfig struct {
+	resolver auth.PermissionResolver
+}
+
+// WithPermissionGuards exige apikeys:read para consultas e apikeys:write para
+// criar e revogar chaves, e users:write para o reset do MFA
+func WithPermissionGuards(resolver auth.PermissionResolver) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.resolver = resolver
+	}
+}
+
+func (cfg routeConfig) guard(perm rbac.Permission, h gin.HandlerFunc) []gin.HandlerFunc {
+	if cfg.resolver == nil {
+		return []gin.HandlerFunc{h}
+	}
+	return []gin.HandlerFunc{auth.RequirePermission(cfg.resolver, perm), h}
+}
+
+// RegisterAuthRoutes registra login, refresh, logout e o segundo fator. Login,
+// refresh, logout e a verificação do MFA são públicas: emitem a credencial
+// exigida pelas demais, e o refresh token ou o desafio é a prova de posse.
+// A inscrição no MFA exige o próprio usuário; o reset, users:write.
+func RegisterAuthRoutes(group *gin.RouterGroup, h *auth_handler.AuthHandler, opts ...RouteOption) {
+	var cfg routeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	authGroup := group.Group("/auth")
+	{
+		authGroup.POST("/login", h.Login)
+		authGroup.POST("/refresh", h.Refresh)
+		authGroup.POST("/logout", h.Logout)
+		authGroup.POST("/mfa/verify", h.VerifyMFA)
+		authGroup.POST("/mfa/enroll", h.EnrollMFA)
+		authGroup.POST("/mfa/confirm", h.ConfirmMFA)
+	}
+	group.DELETE("/users/:id/mfa", cfg.guard(rbac.PermUsersWrite, h.ResetMFA)...)
+}
+
+// RegisterJWKSRoute publica as chaves de verificação no caminho padrão, fora
+// de /api: quem verifica tokens offline não se autentica para buscá-las
+func RegisterJWKSRoute(router *gin.Engine, h *auth_handler.JWKSHandler) {
+	router.GET("/.well-known/jwks.json", h.JWKS)
+}
+
+// RegisterAPIKeyRoutes registra a administração das API keys da organização
+func RegisterAPIKeyRoutes(group *gin.RouterGroup, h *auth_handler.APIKeyHandler, opts ...RouteOption) {
+	var cfg routeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	keys := group.Group("/api-keys")
+	{
+		keys.POST("", cfg.guard(rbac.PermAPIKeysWrite, h.CreateAPIKey)...)
+		keys.GET("", cfg.guard(rbac.PermAPIKeysRead, h.ListAPIKeys)...)
+		keys.GET("/:prefix", cfg.guard(rbac.PermAPIKeysRead, h.GetAPIKey)...)
+		keys.DELETE("/:prefix", cfg.guard(rbac.PermAPIKeysWrite, h.RevokeAPIKey)...)
+	}
+}
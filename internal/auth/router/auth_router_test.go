@@ -0,0 +1,84 @@
+package auth_router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	auth_handler "github.com/williamkoller/cloud-architecture-golang/internal/auth/handler"
+	auth_repository "github.com/williamkoller/cloud-architecture-golang/internal/auth/repository"
+	rbac_repository "github.com/williamkoller/cloud-architecture-golang/internal/rbac/repository"
+)
+
+func TestRegisterAuthRoutes_RegistersAllExpectedRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	RegisterAuthRoutes(r.Group("/api/v1"), &auth_handler.AuthHandler{})
+
+	expected := map[string]string{
+		"POST /api/v1/auth/login":       ".Login",
+		"POST /api/v1/auth/refresh":     ".Refresh",
+		"POST /api/v1/auth/logout":      ".Logout",
+		"POST /api/v1/auth/mfa/verify":  ".VerifyMFA",
+		"POST /api/v1/auth/mfa/enroll":  ".EnrollMFA",
+		"POST /api/v1/auth/mfa/confirm": ".ConfirmMFA",
+		"DELETE /api/v1/users/:id/mfa":  ".ResetMFA",
+	}
+	found := 0
+	for _, ri := range r.Routes() {
+		want, ok := expected[ri.Method+" "+ri.Path]
+		if !ok {
+			continue
+		}
+		found++
+		if !strings.Contains(ri.Handler, want) {
+			t.Fatalf("handler mismatch for %s %s: got %q, want %q", ri.Method, ri.Path, ri.Handler, want)
+		}
+	}
+	if found != len(expected) {
+		t.Fatalf("found %d of %d routes", found, len(expected))
+	}
+}
+
+func TestRegisterAPIKeyRoutes_GuardsEveryRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resolver := rbac_repository.NewInMemoryRoleRepository()
+	r := gin.New()
+	RegisterAPIKeyRoutes(r.Group("/api/v1"), auth_handler.NewAPIKeyHandler(auth_repository.NewInMemoryAPIKeyRepository(), resolver),
+		WithPermissionGuards(resolver))
+
+	for _, route := range []string{
+		"POST /api/v1/api-keys",
+		"GET /api/v1/api-keys",
+		"GET /api/v1/api-keys/ak_1",
+		"DELETE /api/v1/api-keys/ak_1",
+	} {
+		method, path, _ := strings.Cut(route, " ")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("%s without credentials: got %d", route, w.Code)
+		}
+	}
+}
+
+func TestRegisterJWKSRoute_IsPublic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	key, _ := auth.GenerateSigningKey(auth.AlgEdDSA)
+	r := gin.New()
+	RegisterJWKSRoute(r, auth_handler.NewJWKSHandler(auth.NewKeyManager(key), time.Minute))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), key.KeyID()) {
+		t.Fatalf("got %d body=%s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+)
+
+// GroupID identifica o grupo; usa o mesmo formato UUIDv7 dos usuários
+type GroupID string
+
+var (
+	ErrGroupNameRequired = errors.New("group name is required")
+	ErrGroupNameTooLong  = errors.New("group name must have at most 100 characters")
+	ErrInvalidGroupID    = errors.New("invalid group id")
+)
+
+func NewGroupID() (GroupID, error) {
+	id, err := vo.NewUserID()
+	return GroupID(id), err
+}
+
+// ParseGroupID valida e normaliza o identificador recebido na rota
+func ParseGroupID(value string) (GroupID, error) {
+	id, err := vo.ParseUserID(value)
+	if err != nil {
+		return "", ErrInvalidGroupID
+	}
+	return GroupID(id), nil
+}
+
+// Group reúne usuários de uma mesma organização
+type Group struct {
+	ID          GroupID
+	OrgID       string
+	Name        string
+	Description string
+	// Members guarda os IDs dos usuários, sem repetição e em ordem crescente
+	Members []vo.UserID
+
+	// Version cresce a cada escrita e serve para controle de concorrência otimista
+	Version   uint64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func NewGroup(name, description string) (Group, error) {
+	id, err := NewGroupID()
+	if err != nil {
+		return Group{}, err
+	}
+
+	g := Group{ID: id, Description: strings.TrimSpace(description)}
+	if err := g.Rename(name); err != nil {
+		return Group{}, err
+	}
+	return g, nil
+}
+
+// Rename troca o nome, que não pode ficar vazio
+func (g *Group) Rename(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrGroupNameRequired
+	}
+	if len([]rune(name)) > 100 {
+		return ErrGroupNameTooLong
+	}
+	g.Name = name
+	return nil
+}
+
+func (g Group) HasMember(id vo.UserID) bool {
+	_, found := slices.BinarySearch(g.Members, id)
+	return found
+}
+
+// AddMember inclui o usuário; devolve false se ele já era membro
+func (g *Group) AddMember(id vo.UserID) bool {
+	i, found := slices.BinarySearch(g.Members, id)
+	if found {
+		return false
+	}
+	g.Members = slices.Insert(slices.Clone(g.Members), i, id)
+	return true
+}
+
+// RemoveMember retira o usuário; devolve false se ele não era membro
+func (g *Group) RemoveMember(id vo.UserID) bool {
+	i, found := slices.BinarySearch(g.Members, id)
+	if !found {
+		return false
+	}
+	g.Members = slices.Delete(slices.Clone(g.Members), i, i+1)
+	return true
+}
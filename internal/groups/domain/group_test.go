@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+)
+
+func TestNewGroup(t *testing.T) {
+	g, err := NewGroup("  Engenharia ", " Time de produto ")
+	if err != nil {
+		t.Fatalf("NewGroup: %v", err)
+	}
+	if g.Name != "Engenharia" || g.Description != "Time de produto" || g.ID == "" {
+		t.Fatalf("group: %+v", g)
+	}
+	if _, err := ParseGroupID(string(g.ID)); err != nil {
+		t.Fatalf("ParseGroupID(%q): %v", g.ID, err)
+	}
+
+	if _, err := NewGroup("  ", ""); err != ErrGroupNameRequired {
+		t.Fatalf("empty name: got %v", err)
+	}
+	if _, err := NewGroup(strings.Repeat("a", 101), ""); err != ErrGroupNameTooLong {
+		t.Fatalf("long name: got %v", err)
+	}
+	if _, err := ParseGroupID("not-a-uuid"); err != ErrInvalidGroupID {
+		t.Fatalf("ParseGroupID invalid: got %v", err)
+	}
+}
+
+func TestGroup_Members(t *testing.T) {
+	g, _ := NewGroup("Eng", "")
+	a, b := vo.UserID("0000000a-0000-7000-8000-000000000000"), vo.UserID("0000000b-0000-7000-8000-000000000000")
+
+	if !g.AddMember(b) || !g.AddMember(a) || g.AddMember(a) {
+		t.Fatalf("AddMember should report only new members")
+	}
+	if len(g.Members) != 2 || g.Members[0] != a || g.Members[1] != b {
+		t.Fatalf("members should be sorted and unique: %v", g.Members)
+	}
+
+	copied := g
+	if !g.RemoveMember(a) || g.RemoveMember(a) {
+		t.Fatalf("RemoveMember should report only existing members")
+	}
+	if g.HasMember(a) || !g.HasMember(b) {
+		t.Fatalf("members after remove: %v", g.Members)
+	}
+	if !copied.HasMember(a) {
+		t.Fatalf("copies must not share the member slice")
+	}
+}
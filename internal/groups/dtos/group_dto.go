@@ -0,0 +1,12 @@
+package dtos
+
+type CreateGroupRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=500"`
+}
+
+// UpdateGroupRequest altera apenas os campos enviados
+type UpdateGroupRequest struct {
+	Name        *string `json:"name" binding:"omitempty,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=500"`
+}
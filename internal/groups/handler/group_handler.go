@@ -0,0 +1,268 @@
+package group_handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/groups/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/groups/dtos"
+	"github.com/williamkoller/cloud-architecture-golang/internal/groups/mappers"
+	"github.com/williamkoller/cloud-architecture-golang/internal/groups/repository"
+	org "github.com/williamkoller/cloud-architecture-golang/internal/org/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/tenant"
+	usr "github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/validation"
+)
+
+// UserFinder busca usuários não excluídos da organização pelo ID ou email
+type UserFinder interface {
+	FindUser(ctx context.Context, orgID, ref string) (usr.User, bool, error)
+}
+
+type GroupHandler struct {
+	repo           repository.GroupRepository
+	users          UserFinder
+	requestTimeout time.Duration
+}
+
+func NewGroupHandler(repo repository.GroupRepository, users UserFinder) *GroupHandler {
+	return &GroupHandler{
+		repo:           repo,
+		users:          users,
+		requestTimeout: 5 * time.Second,
+	}
+}
+
+func (h *GroupHandler) ctx(c *gin.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(c.Request.Context(), h.requestTimeout)
+}
+
+// orgOf devolve a organização resolvida pelo tenant.Middleware ou a padrão
+func orgOf(ctx context.Context) string {
+	if id, ok := tenant.OrgFrom(ctx); ok {
+		return id
+	}
+	return org.DefaultOrgID
+}
+
+func respondRepoError(c *gin.Context, err error) {
+	switch err {
+	case repository.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
+	case repository.ErrAlreadyExists:
+		c.JSON(http.StatusConflict, gin.H{"error": "a group with this name already exists"})
+	case repository.ErrVersionConflict:
+		c.JSON(http.StatusConflict, gin.H{"error": "group was modified concurrently; retry"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// groupID interpreta o parâmetro :group e responde 400 quando é inválido
+func groupID(c *gin.Context) (domain.GroupID, bool) {
+	id, err := domain.ParseGroupID(c.Param("group"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", false
+	}
+	return id, true
+}
+
+// findUser resolve o identificador (ID ou email) e responde 404 quando o usuário não existe
+func (h *GroupHandler) findUser(ctx context.Context, c *gin.Context, ref string) (usr.User, bool) {
+	u, ok, err := h.users.FindUser(ctx, orgOf(ctx), strings.TrimSpace(ref))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return usr.User{}, false
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return usr.User{}, false
+	}
+	return u, true
+}
+
+func (h *GroupHandler) CreateGroup(c *gin.Context) {
+	var req dtos.CreateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.RespondValidationError(c, err)
+		return
+	}
+
+	g, err := domain.NewGroup(req.Name, req.Description)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	g.OrgID = orgOf(ctx)
+	g, err = h.repo.Create(ctx, g)
+	if err != nil {
+		respondRepoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, mappers.ToGroupResponse(g))
+}
+
+func (h *GroupHandler) ListGroups(c *gin.Context) {
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	groups, err := h.repo.List(ctx, orgOf(ctx))
+	if err != nil {
+		respondRepoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mappers.ToGroupResponses(groups))
+}
+
+func (h *GroupHandler) GetGroup(c *gin.Context) {
+	id, ok := groupID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	g, found, err := h.repo.Get(ctx, orgOf(ctx), id)
+	if err != nil {
+		respondRepoError(c, err)
+		return
+	}
+	if !found {
+		respondRepoError(c, repository.ErrNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, mappers.ToGroupResponse(g))
+}
+
+func (h *GroupHandler) UpdateGroup(c *gin.Context) {
+	var req dtos.UpdateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.RespondValidationError(c, err)
+		return
+	}
+	id, ok := groupID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	g, found, err := h.repo.Get(ctx, orgOf(ctx), id)
+	if err != nil {
+		respondRepoError(c, err)
+		return
+	}
+	if !found {
+		respondRepoError(c, repository.ErrNotFound)
+		return
+	}
+
+	if req.Name != nil {
+		if err := g.Rename(*req.Name); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.Description != nil {
+		g.Description = strings.TrimSpace(*req.Description)
+	}
+
+	g, err = h.repo.Update(ctx, g)
+	if err != nil {
+		respondRepoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mappers.ToGroupResponse(g))
+}
+
+func (h *GroupHandler) DeleteGroup(c *gin.Context) {
+	id, ok := groupID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	if err := h.repo.Delete(ctx, orgOf(ctx), id); err != nil {
+		respondRepoError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AddMember inclui o usuário (ID ou email) no grupo; incluir quem já é membro não tem efeito
+func (h *GroupHandler) AddMember(c *gin.Context) {
+	h.changeMember(c, true)
+}
+
+// RemoveMember retira o usuário (ID ou email) do grupo
+func (h *GroupHandler) RemoveMember(c *gin.Context) {
+	h.changeMember(c, false)
+}
+
+func (h *GroupHandler) changeMember(c *gin.Context, add bool) {
+	id, ok := groupID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	u, ok := h.findUser(ctx, c, c.Param("user"))
+	if !ok {
+		return
+	}
+
+	var (
+		g   domain.Group
+		err error
+	)
+	if add {
+		g, err = h.repo.AddMember(ctx, orgOf(ctx), id, u.ID)
+	} else {
+		g, err = h.repo.RemoveMember(ctx, orgOf(ctx), id, u.ID)
+	}
+	if err != nil {
+		respondRepoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mappers.ToGroupResponse(g))
+}
+
+// ListUserGroups lista os grupos de que o usuário (ID ou email) participa
+func (h *GroupHandler) ListUserGroups(c *gin.Context) {
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	u, ok := h.findUser(ctx, c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	groups, err := h.repo.ListByMember(ctx, orgOf(ctx), u.ID)
+	if err != nil {
+		respondRepoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mappers.ToGroupResponses(groups))
+}
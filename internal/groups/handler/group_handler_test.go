@@ -0,0 +1,158 @@
+package group_handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/groups/mappers"
+	"github.com/williamkoller/cloud-architecture-golang/internal/groups/repository"
+	"github.com/williamkoller/cloud-architecture-golang/internal/tenant"
+	usr "github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	usr_repository "github.com/williamkoller/cloud-architecture-golang/internal/usr/repository"
+)
+
+func routerWithGroupRoutes(h *GroupHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if org := c.GetHeader("X-Test-Org"); org != "" {
+			c.Request = c.Request.WithContext(tenant.WithOrg(c.Request.Context(), org))
+		}
+		c.Next()
+	})
+	r.POST("/groups", h.CreateGroup)
+	r.GET("/groups", h.ListGroups)
+	r.GET("/groups/:group", h.GetGroup)
+	r.PATCH("/groups/:group", h.UpdateGroup)
+	r.DELETE("/groups/:group", h.DeleteGroup)
+	r.PUT("/groups/:group/members/:user", h.AddMember)
+	r.DELETE("/groups/:group/members/:user", h.RemoveMember)
+	r.GET("/users/:id/groups", h.ListUserGroups)
+	return r
+}
+
+func doJSON(t *testing.T, r http.Handler, method, path string, body any, org string) *httptest.ResponseRecorder {
+	t.Helper()
+	buf := bytes.NewBuffer(nil)
+	if body != nil {
+		if err := json.NewEncoder(buf).Encode(body); err != nil {
+			t.Fatalf("marshal body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, path, buf)
+	req.Header.Set("Content-Type", "application/json")
+	if org != "" {
+		req.Header.Set("X-Test-Org", org)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodeGroup(t *testing.T, w *httptest.ResponseRecorder) mappers.GroupResponse {
+	t.Helper()
+	var out mappers.GroupResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatalf("unmarshal: %v body=%s", err, w.Body.String())
+	}
+	return out
+}
+
+func seedUser(t *testing.T, users *usr_repository.TenantRepositories, orgID, email string) usr.User {
+	t.Helper()
+	u, err := usr.NewUser("Ana", email, "secret123", true, usr.UserTypeUser)
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+	u, err = users.For(orgID).Create(context.Background(), u)
+	if err != nil {
+		t.Fatalf("Create user: %v", err)
+	}
+	return u
+}
+
+func TestGroupHandler_CRUDAndMembership(t *testing.T) {
+	users := usr_repository.NewTenantRepositories()
+	ana := seedUser(t, users, "acme", "ana@example.com")
+	r := routerWithGroupRoutes(NewGroupHandler(repository.NewInMemoryGroupRepository(), users))
+
+	w := doJSON(t, r, http.MethodPost, "/groups", map[string]any{"name": "Eng"}, "acme")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: got %d body=%s", w.Code, w.Body.String())
+	}
+	g := decodeGroup(t, w)
+	if g.OrgID != "acme" {
+		t.Fatalf("orgId: got %q", g.OrgID)
+	}
+
+	if w = doJSON(t, r, http.MethodPost, "/groups", map[string]any{"name": "eng"}, "acme"); w.Code != http.StatusConflict {
+		t.Fatalf("duplicate: got %d", w.Code)
+	}
+
+	// Membro pelo email e depois pelo ID
+	w = doJSON(t, r, http.MethodPut, "/groups/"+g.ID+"/members/ana@example.com", nil, "acme")
+	if w.Code != http.StatusOK || len(decodeGroup(t, w).Members) != 1 {
+		t.Fatalf("add member: got %d body=%s", w.Code, w.Body.String())
+	}
+
+	w = doJSON(t, r, http.MethodGet, "/users/"+string(ana.ID)+"/groups", nil, "acme")
+	var mine []mappers.GroupResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &mine); err != nil || len(mine) != 1 || mine[0].ID != g.ID {
+		t.Fatalf("user groups: %s err=%v", w.Body.String(), err)
+	}
+
+	w = doJSON(t, r, http.MethodDelete, "/groups/"+g.ID+"/members/"+string(ana.ID), nil, "acme")
+	if w.Code != http.StatusOK || len(decodeGroup(t, w).Members) != 0 {
+		t.Fatalf("remove member: got %d body=%s", w.Code, w.Body.String())
+	}
+
+	w = doJSON(t, r, http.MethodPatch, "/groups/"+g.ID, map[string]any{"name": "Engenharia"}, "acme")
+	if w.Code != http.StatusOK || decodeGroup(t, w).Name != "Engenharia" {
+		t.Fatalf("update: got %d body=%s", w.Code, w.Body.String())
+	}
+
+	if w = doJSON(t, r, http.MethodDelete, "/groups/"+g.ID, nil, "acme"); w.Code != http.StatusNoContent {
+		t.Fatalf("delete: got %d", w.Code)
+	}
+	if w = doJSON(t, r, http.MethodGet, "/groups/"+g.ID, nil, "acme"); w.Code != http.StatusNotFound {
+		t.Fatalf("get deleted: got %d", w.Code)
+	}
+}
+
+func TestGroupHandler_ErrorScenarios(t *testing.T) {
+	users := usr_repository.NewTenantRepositories()
+	seedUser(t, users, "globex", "bia@example.com")
+	r := routerWithGroupRoutes(NewGroupHandler(repository.NewInMemoryGroupRepository(), users))
+
+	w := doJSON(t, r, http.MethodPost, "/groups", map[string]any{"name": "Eng"}, "acme")
+	g := decodeGroup(t, w)
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		org    string
+		want   int
+	}{
+		{"missing name", http.MethodPost, "/groups", map[string]any{}, "acme", http.StatusBadRequest},
+		{"invalid group id", http.MethodGet, "/groups/nope", nil, "acme", http.StatusBadRequest},
+		{"group from other org", http.MethodGet, "/groups/" + g.ID, nil, "globex", http.StatusNotFound},
+		{"member from other org", http.MethodPut, "/groups/" + g.ID + "/members/bia@example.com", nil, "acme", http.StatusNotFound},
+		{"unknown user groups", http.MethodGet, "/users/ghost@example.com/groups", nil, "acme", http.StatusNotFound},
+		{"blank rename", http.MethodPatch, "/groups/" + g.ID, map[string]any{"name": " "}, "acme", http.StatusUnprocessableEntity},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if w := doJSON(t, r, tc.method, tc.path, tc.body, tc.org); w.Code != tc.want {
+				t.Fatalf("status: got %d, want %d body=%s", w.Code, tc.want, w.Body.String())
+			}
+		})
+	}
+}
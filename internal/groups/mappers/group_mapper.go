@@ -0,0 +1,44 @@
+package mappers
+
+import (
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/groups/domain"
+)
+
+type GroupResponse struct {
+	ID          string   `json:"id"`
+	OrgID       string   `json:"orgId,omitempty"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Members     []string `json:"members"`
+	Version     uint64   `json:"version"`
+	CreatedAt   string   `json:"createdAt"`
+	UpdatedAt   string   `json:"updatedAt"`
+}
+
+func ToGroupResponse(g domain.Group) GroupResponse {
+	members := make([]string, len(g.Members))
+	for i, id := range g.Members {
+		members[i] = string(id)
+	}
+
+	return GroupResponse{
+		ID:          string(g.ID),
+		OrgID:       g.OrgID,
+		Name:        g.Name,
+		Description: g.Description,
+		Members:     members,
+		Version:     g.Version,
+		CreatedAt:   g.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:   g.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func ToGroupResponses(groups []domain.Group) []GroupResponse {
+	out := make([]GroupResponse, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, ToGroupResponse(g))
+	}
+	return out
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	org "github.com/williamkoller/cloud-architecture-golang/internal/org/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/events"
+)
+
+// RemoveDeletedMembers é o assinante de domain.EventUserDeleted que retira o
+// usuário excluído de todos os grupos da organização. Restaurar o usuário não
+// o devolve aos grupos. É idempotente, como o outbox exige.
+func RemoveDeletedMembers(repo GroupRepository) events.Subscriber {
+	return func(ctx context.Context, e domain.Event) error {
+		if e.EventName() != domain.EventUserDeleted {
+			return nil
+		}
+		orgID, ok := events.OrgID(ctx)
+		if !ok {
+			orgID = org.DefaultOrgID
+		}
+		_, err := repo.RemoveFromAll(ctx, orgID, e.AggregateID())
+		return err
+	}
+}
@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/groups/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+)
+
+var (
+	ErrNotFound = errors.New("group not found")
+	// ErrAlreadyExists indica outro grupo com o mesmo nome na organização
+	ErrAlreadyExists   = errors.New("group already exists")
+	ErrVersionConflict = errors.New("group version conflict")
+)
+
+// GroupRepository persiste grupos por organização. Nomes são únicos dentro da
+// organização, sem diferenciar maiúsculas. AddMember e RemoveMember são atômicos
+// e não exigem a versão atual, ao contrário de Update.
+type GroupRepository interface {
+	Create(ctx context.Context, g domain.Group) (domain.Group, error)
+	Get(ctx context.Context, orgID string, id domain.GroupID) (domain.Group, bool, error)
+	List(ctx context.Context, orgID string) ([]domain.Group, error)
+	// ListByMember devolve os grupos de que o usuário participa
+	ListByMember(ctx context.Context, orgID string, userID vo.UserID) ([]domain.Group, error)
+	Update(ctx context.Context, g domain.Group) (domain.Group, error)
+	Delete(ctx context.Context, orgID string, id domain.GroupID) error
+	AddMember(ctx context.Context, orgID string, id domain.GroupID, userID vo.UserID) (domain.Group, error)
+	RemoveMember(ctx context.Context, orgID string, id domain.GroupID, userID vo.UserID) (domain.Group, error)
+	// RemoveFromAll retira o usuário de todos os grupos da organização e
+	// devolve em quantos ele estava
+	RemoveFromAll(ctx context.Context, orgID string, userID vo.UserID) (int, error)
+}
+
+// inMemoryGroupRepo guarda os grupos de cada organização sob um único lock;
+// o volume de grupos é pequeno perto do de usuários
+type inMemoryGroupRepo struct {
+	mu     sync.RWMutex
+	groups map[string]map[domain.GroupID]domain.Group
+	now    func() time.Time
+}
+
+// Option configura o repositório em memória
+type Option func(*inMemoryGroupRepo)
+
+// WithClock substitui o relógio usado para as datas de criação e alteração
+func WithClock(now func() time.Time) Option {
+	return func(r *inMemoryGroupRepo) {
+		r.now = now
+	}
+}
+
+func NewInMemoryGroupRepository(opts ...Option) GroupRepository {
+	r := &inMemoryGroupRepo{
+		groups: make(map[string]map[domain.GroupID]domain.Group),
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *inMemoryGroupRepo) timestamp() time.Time {
+	return r.now().UTC().Truncate(time.Second)
+}
+
+// clone evita que quem chama altere a lista de membros guardada
+func clone(g domain.Group) domain.Group {
+	g.Members = append([]vo.UserID(nil), g.Members...)
+	return g
+}
+
+// nameTaken confere a unicidade do nome na organização. Deve ser chamado com o lock.
+func (r *inMemoryGroupRepo) nameTaken(orgID, name string, except domain.GroupID) bool {
+	for id, g := range r.groups[orgID] {
+		if id != except && strings.EqualFold(g.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *inMemoryGroupRepo) Create(ctx context.Context, g domain.Group) (domain.Group, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Group{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	org := r.groups[g.OrgID]
+	if org == nil {
+		org = make(map[domain.GroupID]domain.Group)
+		r.groups[g.OrgID] = org
+	}
+	if _, ok := org[g.ID]; ok || r.nameTaken(g.OrgID, g.Name, "") {
+		return domain.Group{}, ErrAlreadyExists
+	}
+
+	now := r.timestamp()
+	g.Version = 1
+	g.CreatedAt = now
+	g.UpdatedAt = now
+	org[g.ID] = clone(g)
+	return clone(g), nil
+}
+
+func (r *inMemoryGroupRepo) Get(ctx context.Context, orgID string, id domain.GroupID) (domain.Group, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Group{}, false, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	g, ok := r.groups[orgID][id]
+	if !ok {
+		return domain.Group{}, false, nil
+	}
+	return clone(g), true, nil
+}
+
+func (r *inMemoryGroupRepo) list(ctx context.Context, orgID string, match func(domain.Group) bool) ([]domain.Group, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	out := make([]domain.Group, 0, len(r.groups[orgID]))
+	for _, g := range r.groups[orgID] {
+		if match(g) {
+			out = append(out, clone(g))
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (r *inMemoryGroupRepo) List(ctx context.Context, orgID string) ([]domain.Group, error) {
+	return r.list(ctx, orgID, func(domain.Group) bool { return true })
+}
+
+func (r *inMemoryGroupRepo) ListByMember(ctx context.Context, orgID string, userID vo.UserID) ([]domain.Group, error) {
+	return r.list(ctx, orgID, func(g domain.Group) bool { return g.HasMember(userID) })
+}
+
+// Update grava nome e descrição se g.Version for a versão atual; os membros
+// só mudam por AddMember, RemoveMember e RemoveFromAll
+func (r *inMemoryGroupRepo) Update(ctx context.Context, g domain.Group) (domain.Group, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Group{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.groups[g.OrgID][g.ID]
+	if !ok {
+		return domain.Group{}, ErrNotFound
+	}
+	if g.Version != current.Version {
+		return domain.Group{}, ErrVersionConflict
+	}
+	if r.nameTaken(g.OrgID, g.Name, g.ID) {
+		return domain.Group{}, ErrAlreadyExists
+	}
+
+	current.Name = g.Name
+	current.Description = g.Description
+	current.Version++
+	current.UpdatedAt = r.timestamp()
+	r.groups[g.OrgID][g.ID] = current
+	return clone(current), nil
+}
+
+func (r *inMemoryGroupRepo) Delete(ctx context.Context, orgID string, id domain.GroupID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.groups[orgID][id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.groups[orgID], id)
+	return nil
+}
+
+// changeMembers aplica a mudança de membros sob lock; sem mudança, nada é gravado
+func (r *inMemoryGroupRepo) changeMembers(ctx context.Context, orgID string, id domain.GroupID, change func(*domain.Group) bool) (domain.Group, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Group{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	g, ok := r.groups[orgID][id]
+	if !ok {
+		return domain.Group{}, ErrNotFound
+	}
+	if change(&g) {
+		g.Version++
+		g.UpdatedAt = r.timestamp()
+		r.groups[orgID][id] = g
+	}
+	return clone(g), nil
+}
+
+func (r *inMemoryGroupRepo) AddMember(ctx context.Context, orgID string, id domain.GroupID, userID vo.UserID) (domain.Group, error) {
+	return r.changeMembers(ctx, orgID, id, func(g *domain.Group) bool { return g.AddMember(userID) })
+}
+
+func (r *inMemoryGroupRepo) RemoveMember(ctx context.Context, orgID string, id domain.GroupID, userID vo.UserID) (domain.Group, error) {
+	return r.changeMembers(ctx, orgID, id, func(g *domain.Group) bool { return g.RemoveMember(userID) })
+}
+
+func (r *inMemoryGroupRepo) RemoveFromAll(ctx context.Context, orgID string, userID vo.UserID) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.timestamp()
+	removed := 0
+	for id, g := range r.groups[orgID] {
+		if g.RemoveMember(userID) {
+			g.Version++
+			g.UpdatedAt = now
+			r.groups[orgID][id] = g
+			removed++
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/groups/domain"
+	usr "github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/events"
+	usr_repository "github.com/williamkoller/cloud-architecture-golang/internal/usr/repository"
+)
+
+func mustGroup(t *testing.T, repo GroupRepository, orgID, name string) domain.Group {
+	t.Helper()
+	g, err := domain.NewGroup(name, "")
+	if err != nil {
+		t.Fatalf("NewGroup: %v", err)
+	}
+	g.OrgID = orgID
+	g, err = repo.Create(context.Background(), g)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return g
+}
+
+func TestGroupRepository_NamesAreUniquePerOrganization(t *testing.T) {
+	repo := NewInMemoryGroupRepository()
+	ctx := context.Background()
+
+	eng := mustGroup(t, repo, "acme", "Eng")
+	mustGroup(t, repo, "globex", "Eng")
+
+	dup, _ := domain.NewGroup("ENG", "")
+	dup.OrgID = "acme"
+	if _, err := repo.Create(ctx, dup); err != ErrAlreadyExists {
+		t.Fatalf("duplicate name: got %v", err)
+	}
+
+	if _, ok, _ := repo.Get(ctx, "globex", eng.ID); ok {
+		t.Fatalf("group visible from another organization")
+	}
+
+	ops := mustGroup(t, repo, "acme", "Ops")
+	ops.Name = "eng"
+	if _, err := repo.Update(ctx, ops); err != ErrAlreadyExists {
+		t.Fatalf("rename to taken name: got %v", err)
+	}
+	ops.Name = "Operações"
+	ops.Version = 99
+	if _, err := repo.Update(ctx, ops); err != ErrVersionConflict {
+		t.Fatalf("stale update: got %v", err)
+	}
+}
+
+func TestGroupRepository_Membership(t *testing.T) {
+	repo := NewInMemoryGroupRepository()
+	ctx := context.Background()
+	ana := vo.UserID("0000000a-0000-7000-8000-000000000000")
+
+	eng := mustGroup(t, repo, "acme", "Eng")
+	ops := mustGroup(t, repo, "acme", "Ops")
+	mustGroup(t, repo, "acme", "Sales")
+
+	for _, id := range []domain.GroupID{eng.ID, ops.ID} {
+		if _, err := repo.AddMember(ctx, "acme", id, ana); err != nil {
+			t.Fatalf("AddMember: %v", err)
+		}
+	}
+	again, _ := repo.AddMember(ctx, "acme", eng.ID, ana)
+	if again.Version != 2 {
+		t.Fatalf("repeated AddMember should not bump the version: %d", again.Version)
+	}
+
+	groups, _ := repo.ListByMember(ctx, "acme", ana)
+	if len(groups) != 2 || groups[0].Name != "Eng" || groups[1].Name != "Ops" {
+		t.Fatalf("ListByMember: %+v", groups)
+	}
+
+	if _, err := repo.AddMember(ctx, "acme", "ghost", ana); err != ErrNotFound {
+		t.Fatalf("AddMember unknown group: got %v", err)
+	}
+
+	// A exclusão do usuário, entregue pelo outbox, o retira de todos os grupos
+	d := events.NewDispatcher()
+	d.Subscribe(usr.EventUserDeleted, RemoveDeletedMembers(repo))
+	msg := usr_repository.OutboxMessage{
+		ID:    string(ana) + ":2:0",
+		OrgID: "acme",
+		Event: usr.UserDeleted{EventMeta: usr.EventMeta{UserID: ana}},
+	}
+	if err := events.OutboxPublisher(d).Publish(ctx, msg); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if groups, _ := repo.ListByMember(ctx, "acme", ana); len(groups) != 0 {
+		t.Fatalf("user still member of %d groups after delete", len(groups))
+	}
+}
@@ -0,0 +1,51 @@
+package group_router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	group_handler "github.com/williamkoller/cloud-architecture-golang/internal/groups/handler"
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+)
+
+// RouteOption configura o registro das rotas de grupos
+type RouteOption func(*routeConfig)
+
+type routeConfig struct {
+	resolver auth.PermissionResolver
+}
+
+// WithPermissionGuards exige groups:read para consultas e groups:write para alterações
+func WithPermissionGuards(resolver auth.PermissionResolver) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.resolver = resolver
+	}
+}
+
+func (cfg routeConfig) guard(perm rbac.Permission, h gin.HandlerFunc) []gin.HandlerFunc {
+	if cfg.resolver == nil {
+		return []gin.HandlerFunc{h}
+	}
+	return []gin.HandlerFunc{auth.RequirePermission(cfg.resolver, perm), h}
+}
+
+// RegisterGroupRoutes registra os grupos e a listagem dos grupos de um usuário.
+// Membros são informados pelo ID ou pelo email, como nas rotas de usuário.
+func RegisterGroupRoutes(group *gin.RouterGroup, h *group_handler.GroupHandler, opts ...RouteOption) {
+	var cfg routeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	groups := group.Group("/groups")
+	{
+		groups.POST("", cfg.guard(rbac.PermGroupsWrite, h.CreateGroup)...)
+		groups.GET("", cfg.guard(rbac.PermGroupsRead, h.ListGroups)...)
+		groups.GET("/:group", cfg.guard(rbac.PermGroupsRead, h.GetGroup)...)
+		groups.PATCH("/:group", cfg.guard(rbac.PermGroupsWrite, h.UpdateGroup)...)
+		groups.DELETE("/:group", cfg.guard(rbac.PermGroupsWrite, h.DeleteGroup)...)
+		groups.PUT("/:group/members/:user", cfg.guard(rbac.PermGroupsWrite, h.AddMember)...)
+		groups.DELETE("/:group/members/:user", cfg.guard(rbac.PermGroupsWrite, h.RemoveMember)...)
+	}
+
+	group.GET("/users/:id/groups", cfg.guard(rbac.PermGroupsRead, h.ListUserGroups)...)
+}
@@ -0,0 +1,42 @@
+package group_router
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	group_handler "github.com/williamkoller/cloud-architecture-golang/internal/groups/handler"
+)
+
+func TestRegisterGroupRoutes_RegistersAllExpectedRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	RegisterGroupRoutes(r.Group("/api/v1"), &group_handler.GroupHandler{})
+
+	expected := map[string]string{
+		"POST /api/v1/groups":                        ".CreateGroup",
+		"GET /api/v1/groups":                         ".ListGroups",
+		"GET /api/v1/groups/:group":                  ".GetGroup",
+		"PATCH /api/v1/groups/:group":                ".UpdateGroup",
+		"DELETE /api/v1/groups/:group":               ".DeleteGroup",
+		"PUT /api/v1/groups/:group/members/:user":    ".AddMember",
+		"DELETE /api/v1/groups/:group/members/:user": ".RemoveMember",
+		"GET /api/v1/users/:id/groups":               ".ListUserGroups",
+	}
+	found := 0
+	for _, ri := range r.Routes() {
+		want, ok := expected[ri.Method+" "+ri.Path]
+		if !ok {
+			continue
+		}
+		found++
+		if !strings.Contains(ri.Handler, want) {
+			t.Fatalf("handler mismatch for %s %s: got %q, want %q", ri.Method, ri.Path, ri.Handler, want)
+		}
+	}
+	if found != len(expected) {
+		t.Fatalf("found %d of %d routes", found, len(expected))
+	}
+}
@@ -24,10 +24,28 @@ var (
 	httpReqDurationByClass *prometheus.HistogramVec
 	httpRespSizeBytes      *prometheus.HistogramVec
 
-	// Métricas de domínio simplificadas
-	usersCreatedTotal *prometheus.CounterVec
-	usersUpdatedTotal *prometheus.CounterVec
-	usersDeletedTotal *prometheus.CounterVec
+	// Métricas de domínio simplificadas, por organização (label tenant)
+	usersCreatedTotal  *prometheus.CounterVec
+	usersUpdatedTotal  *prometheus.CounterVec
+	usersDeletedTotal  *prometheus.CounterVec
+	usersRestoredTotal *prometheus.CounterVec
+	usersPurgedTotal   *prometheus.CounterVec
+
+	// Recuperação de senha
+	passwordResetRequestsTotal    *prometheus.CounterVec
+	passwordResetCompletionsTotal *prometheus.CounterVec
+	authLoginsTotal               *prometheus.CounterVec
+	authRefreshTotal              *prometheus.CounterVec
+	refreshTokensSweptTotal       *prometheus.CounterVec
+	signingKeyRotationsTotal      *prometheus.CounterVec
+
+	// Outbox de eventos de domínio
+	outboxPending        *prometheus.GaugeVec
+	outboxOldestAge      *prometheus.GaugeVec
+	outboxPublishedTotal *prometheus.CounterVec
+	outboxFailuresTotal  *prometheus.CounterVec
+	outboxParkedTotal    *prometheus.CounterVec
+	outboxDeadLetters    *prometheus.GaugeVec
 
 	appInfo             prometheus.Gauge
 	panicRecoveredTotal *prometheus.CounterVec
@@ -91,13 +109,13 @@ func Init(service, version string) {
 		[]string{"method", "route", "status", "service", "version"},
 	)
 
-	// Métricas de domínio simplificadas
+	// Métricas de domínio simplificadas, por organização (label tenant)
 	usersCreatedTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "users_created_total",
 			Help: "Total users created.",
 		},
-		[]string{"service", "version"},
+		[]string{"tenant", "service", "version"},
 	)
 
 	usersUpdatedTotal = prometheus.NewCounterVec(
@@ -105,7 +123,7 @@ func Init(service, version string) {
 			Name: "users_updated_total",
 			Help: "Total users updated.",
 		},
-		[]string{"service", "version"},
+		[]string{"tenant", "service", "version"},
 	)
 
 	usersDeletedTotal = prometheus.NewCounterVec(
@@ -113,6 +131,118 @@ func Init(service, version string) {
 			Name: "users_deleted_total",
 			Help: "Total users deleted.",
 		},
+		[]string{"tenant", "service", "version"},
+	)
+
+	usersRestoredTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "users_restored_total",
+			Help: "Total soft-deleted users restored.",
+		},
+		[]string{"tenant", "service", "version"},
+	)
+
+	usersPurgedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "users_purged_total",
+			Help: "Total soft-deleted users permanently purged.",
+		},
+		[]string{"tenant", "service", "version"},
+	)
+
+	passwordResetRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "password_reset_requests_total",
+			Help: "Total password reset requests by outcome (sent, ignored, throttled, failed).",
+		},
+		[]string{"tenant", "outcome", "service", "version"},
+	)
+
+	passwordResetCompletionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "password_reset_completions_total",
+			Help: "Total password reset attempts by result (success, invalid_token, rejected_password).",
+		},
+		[]string{"tenant", "result", "service", "version"},
+	)
+
+	authLoginsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "auth_logins_total",
+			Help: "Total login attempts by result (success, invalid_credentials, inactive, mfa_required, invalid_mfa, mfa_locked, failed).",
+		},
+		[]string{"tenant", "result", "service", "version"},
+	)
+
+	authRefreshTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "auth_token_refreshes_total",
+			Help: "Total refresh token exchanges by result (success, invalid, reused).",
+		},
+		[]string{"tenant", "result", "service", "version"},
+	)
+
+	refreshTokensSweptTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "auth_refresh_tokens_swept_total",
+			Help: "Total expired refresh tokens removed by the background sweep.",
+		},
+		[]string{"service", "version"},
+	)
+
+	signingKeyRotationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "auth_signing_key_rotations_total",
+			Help: "Total times a new key started signing access tokens.",
+		},
+		[]string{"service", "version"},
+	)
+
+	outboxPending = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "outbox_pending_messages",
+			Help: "Domain events waiting in the outbox.",
+		},
+		[]string{"service", "version"},
+	)
+
+	outboxOldestAge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "outbox_oldest_message_age_seconds",
+			Help: "Age of the oldest unpublished outbox message (outbox lag).",
+		},
+		[]string{"service", "version"},
+	)
+
+	outboxPublishedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_published_total",
+			Help: "Total outbox messages published.",
+		},
+		[]string{"service", "version"},
+	)
+
+	outboxFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_publish_failures_total",
+			Help: "Total failed outbox publish attempts.",
+		},
+		[]string{"service", "version"},
+	)
+
+	outboxParkedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_parked_total",
+			Help: "Total outbox messages parked (dead-lettered) after exhausting their attempts.",
+		},
+		[]string{"service", "version"},
+	)
+
+	outboxDeadLetters = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "outbox_dead_letter_messages",
+			Help: "Parked outbox messages waiting to be requeued.",
+		},
 		[]string{"service", "version"},
 	)
 
@@ -145,6 +275,22 @@ func Init(service, version string) {
 		usersCreatedTotal,
 		usersUpdatedTotal,
 		usersDeletedTotal,
+		usersRestoredTotal,
+		usersPurgedTotal,
+
+		passwordResetRequestsTotal,
+		passwordResetCompletionsTotal,
+		authLoginsTotal,
+		authRefreshTotal,
+		refreshTokensSweptTotal,
+		signingKeyRotationsTotal,
+
+		outboxPending,
+		outboxOldestAge,
+		outboxPublishedTotal,
+		outboxFailuresTotal,
+		outboxParkedTotal,
+		outboxDeadLetters,
 
 		appInfo,
 		panicRecoveredTotal,
@@ -203,17 +349,73 @@ func RecoveryMiddleware() gin.HandlerFunc {
 	})
 }
 
+// tenantLabel devolve o valor do label tenant; usuários sem organização contam como default
+func tenantLabel(orgID string) string {
+	if orgID == "" {
+		return "default"
+	}
+	return orgID
+}
+
 // Helpers de domínio otimizados (chamadas diretas sem overhead)
-func UsersCreatedInc() {
-	usersCreatedTotal.WithLabelValues(serviceLabel, versionLabel).Inc()
+func UsersCreatedInc(orgID string) {
+	usersCreatedTotal.WithLabelValues(tenantLabel(orgID), serviceLabel, versionLabel).Inc()
+}
+
+func UsersUpdatedInc(orgID string) {
+	usersUpdatedTotal.WithLabelValues(tenantLabel(orgID), serviceLabel, versionLabel).Inc()
+}
+
+func UsersDeletedInc(orgID string) {
+	usersDeletedTotal.WithLabelValues(tenantLabel(orgID), serviceLabel, versionLabel).Inc()
+}
+
+func UsersRestoredInc(orgID string) {
+	usersRestoredTotal.WithLabelValues(tenantLabel(orgID), serviceLabel, versionLabel).Inc()
+}
+
+func UsersPurgedAdd(orgID string, n int) {
+	usersPurgedTotal.WithLabelValues(tenantLabel(orgID), serviceLabel, versionLabel).Add(float64(n))
+}
+
+// PasswordResetRequestedInc conta um pedido de reset; o resultado não é exposto ao cliente
+func PasswordResetRequestedInc(orgID, outcome string) {
+	passwordResetRequestsTotal.WithLabelValues(tenantLabel(orgID), outcome, serviceLabel, versionLabel).Inc()
+}
+
+// PasswordResetCompletedInc conta uma tentativa de redefinir a senha com um token
+func PasswordResetCompletedInc(orgID, result string) {
+	passwordResetCompletionsTotal.WithLabelValues(tenantLabel(orgID), result, serviceLabel, versionLabel).Inc()
+}
+
+// LoginInc conta uma tentativa de login em POST /auth/login ou /auth/mfa/verify
+func LoginInc(orgID, result string) {
+	authLoginsTotal.WithLabelValues(tenantLabel(orgID), result, serviceLabel, versionLabel).Inc()
+}
+
+// TokenRefreshInc conta uma troca de refresh token em POST /auth/refresh
+func TokenRefreshInc(orgID, result string) {
+	authRefreshTotal.WithLabelValues(tenantLabel(orgID), result, serviceLabel, versionLabel).Inc()
+}
+
+// RefreshTokensSweptAdd soma os refresh tokens vencidos removidos numa rodada
+func RefreshTokensSweptAdd(n int) {
+	refreshTokensSweptTotal.WithLabelValues(serviceLabel, versionLabel).Add(float64(n))
 }
 
-func UsersUpdatedInc() {
-	usersUpdatedTotal.WithLabelValues(serviceLabel, versionLabel).Inc()
+// SigningKeyRotationsInc conta a troca da chave que assina os tokens
+func SigningKeyRotationsInc() {
+	signingKeyRotationsTotal.WithLabelValues(serviceLabel, versionLabel).Inc()
 }
 
-func UsersDeletedInc() {
-	usersDeletedTotal.WithLabelValues(serviceLabel, versionLabel).Inc()
+// ObserveOutbox registra o resultado de uma rodada do relay do outbox
+func ObserveOutbox(published, failed, parked, pending, deadLetters int, oldestAge time.Duration) {
+	outboxPublishedTotal.WithLabelValues(serviceLabel, versionLabel).Add(float64(published))
+	outboxFailuresTotal.WithLabelValues(serviceLabel, versionLabel).Add(float64(failed))
+	outboxParkedTotal.WithLabelValues(serviceLabel, versionLabel).Add(float64(parked))
+	outboxDeadLetters.WithLabelValues(serviceLabel, versionLabel).Set(float64(deadLetters))
+	outboxPending.WithLabelValues(serviceLabel, versionLabel).Set(float64(pending))
+	outboxOldestAge.WithLabelValues(serviceLabel, versionLabel).Set(oldestAge.Seconds())
 }
 
 func PanicRecoveredInc() {
@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Webhook entrega as notificações como POST JSON a um serviço de envio
+// (email, SMS), que monta e despacha a mensagem. O token, quando houver,
+// vai no header Authorization.
+type Webhook struct {
+	URL    string
+	Token  string
+	Client *http.Client
+}
+
+func NewWebhook(url, token string) *Webhook {
+	return &Webhook{URL: url, Token: token, Client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+type payload struct {
+	Type    string `json:"type"`
+	Message any    `json:"message"`
+}
+
+// Send publica {"type": kind, "message": message}. Respostas fora de 2xx são
+// erro, para que o outbox tente de novo.
+func (w *Webhook) Send(ctx context.Context, kind string, message any) error {
+	body, err := json.Marshal(payload{Type: kind, Message: message})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+w.Token)
+	}
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notification webhook answered %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhook_PostsTheMessage(t *testing.T) {
+	var got struct {
+		Type    string            `json:"type"`
+		Message map[string]string `json:"message"`
+	}
+	var authorization string
+	status := http.StatusAccepted
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	hook := NewWebhook(srv.URL, "s3cret")
+	if err := hook.Send(context.Background(), "verification", map[string]string{"to": "ana@example.com"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got.Type != "verification" || got.Message["to"] != "ana@example.com" || authorization != "Bearer s3cret" {
+		t.Fatalf("request: %+v authorization=%q", got, authorization)
+	}
+
+	status = http.StatusServiceUnavailable
+	if err := hook.Send(context.Background(), "verification", nil); err == nil {
+		t.Fatalf("non-2xx answers should be errors")
+	}
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultOrgID é a organização das rotas sem organização explícita, onde ficam
+// os usuários anteriores ao multi-tenant
+const DefaultOrgID = "default"
+
+var (
+	ErrInvalidOrgID       = errors.New("organization id must be 2-63 lowercase letters, digits or '-' starting with a letter or digit")
+	ErrOrgNameRequired    = errors.New("organization name is required")
+	ErrDefaultOrgInactive = errors.New("the default organization cannot be deactivated")
+)
+
+var orgIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+// ValidOrgID indica se o identificador pode ser usado em URLs e subdomínios
+func ValidOrgID(id string) bool {
+	return orgIDPattern.MatchString(id)
+}
+
+// Organization é o tenant: cada uma tem seus próprios usuários e emails
+type Organization struct {
+	// ID é o slug estável usado em /orgs/:org, no header e no subdomínio
+	ID     string
+	Name   string
+	Active bool
+	// SelfSignup abre o cadastro anônimo (POST /users) pelo header X-Org-ID ou
+	// pela rota /orgs/:org; sem ele, só a organização padrão e o subdomínio aceitam
+	SelfSignup bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func NewOrganization(id, name string) (Organization, error) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if !ValidOrgID(id) {
+		return Organization{}, ErrInvalidOrgID
+	}
+
+	o := Organization{ID: id, Active: true}
+	if err := o.Rename(name); err != nil {
+		return Organization{}, err
+	}
+	return o, nil
+}
+
+func (o *Organization) Rename(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrOrgNameRequired
+	}
+	o.Name = name
+	return nil
+}
+
+// SetActive ativa ou desativa a organização; desativada, suas rotas de usuário deixam de responder
+func (o *Organization) SetActive(active bool) error {
+	if !active && o.ID == DefaultOrgID {
+		return ErrDefaultOrgInactive
+	}
+	o.Active = active
+	return nil
+}
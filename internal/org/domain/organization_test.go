@@ -0,0 +1,34 @@
+package domain
+
+import "testing"
+
+func TestNewOrganization(t *testing.T) {
+	o, err := NewOrganization(" Acme-BR ", " Acme Brasil ")
+	if err != nil {
+		t.Fatalf("NewOrganization: %v", err)
+	}
+	if o.ID != "acme-br" || o.Name != "Acme Brasil" || !o.Active {
+		t.Fatalf("org: %+v", o)
+	}
+
+	for _, id := range []string{"", "a", "-acme", "acme.br", "acme br"} {
+		if _, err := NewOrganization(id, "Acme"); err != ErrInvalidOrgID {
+			t.Fatalf("NewOrganization(%q): got %v, want ErrInvalidOrgID", id, err)
+		}
+	}
+	if _, err := NewOrganization("acme", "  "); err != ErrOrgNameRequired {
+		t.Fatalf("empty name: got %v", err)
+	}
+}
+
+func TestOrganization_DefaultCannotBeDeactivated(t *testing.T) {
+	def, _ := NewOrganization(DefaultOrgID, "Default")
+	if err := def.SetActive(false); err != ErrDefaultOrgInactive {
+		t.Fatalf("SetActive(false) on default: got %v", err)
+	}
+
+	o, _ := NewOrganization("acme", "Acme")
+	if err := o.SetActive(false); err != nil || o.Active {
+		t.Fatalf("SetActive(false): active=%v err=%v", o.Active, err)
+	}
+}
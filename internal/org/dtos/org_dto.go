@@ -0,0 +1,14 @@
+package dtos
+
+type CreateOrgRequest struct {
+	ID         string `json:"id" binding:"required"`
+	Name       string `json:"name" binding:"required"`
+	SelfSignup bool   `json:"selfSignup"`
+}
+
+// UpdateOrgRequest altera apenas os campos enviados
+type UpdateOrgRequest struct {
+	Name       *string `json:"name"`
+	Active     *bool   `json:"active"`
+	SelfSignup *bool   `json:"selfSignup"`
+}
@@ -0,0 +1,190 @@
+package org_handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	"github.com/williamkoller/cloud-architecture-golang/internal/org/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/org/dtos"
+	"github.com/williamkoller/cloud-architecture-golang/internal/org/mappers"
+	"github.com/williamkoller/cloud-architecture-golang/internal/org/repository"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/validation"
+)
+
+// OrgHandler expõe a administração das organizações (tenants)
+type OrgHandler struct {
+	repo           repository.OrgRepository
+	requestTimeout time.Duration
+}
+
+func NewOrgHandler(repo repository.OrgRepository) *OrgHandler {
+	return &OrgHandler{
+		repo:           repo,
+		requestTimeout: 5 * time.Second,
+	}
+}
+
+func (h *OrgHandler) ctx(c *gin.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(c.Request.Context(), h.requestTimeout)
+}
+
+func respondRepoError(c *gin.Context, err error) {
+	switch err {
+	case repository.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+	case repository.ErrAlreadyExists:
+		c.JSON(http.StatusConflict, gin.H{"error": "organization already exists"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// restrictedTo devolve a organização a que o chamador está restrito, ou vazio
+// para a plataforma e para requisições sem principal (rotas sem guardas)
+func restrictedTo(ctx context.Context) string {
+	p, ok := auth.PrincipalFrom(ctx)
+	if !ok || p.Platform() {
+		return ""
+	}
+	return p.OrgID
+}
+
+// visible indica se o chamador pode ver a organização; as alheias são
+// tratadas como inexistentes, sem revelar quais existem
+func visible(ctx context.Context, id string) bool {
+	org := restrictedTo(ctx)
+	return org == "" || org == id
+}
+
+func (h *OrgHandler) CreateOrg(c *gin.Context) {
+	var req dtos.CreateOrgRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.RespondValidationError(c, err)
+		return
+	}
+
+	o, err := domain.NewOrganization(req.ID, req.Name)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	o.SelfSignup = req.SelfSignup
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	if restrictedTo(ctx) != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only platform administrators can create organizations"})
+		return
+	}
+
+	o, err = h.repo.Create(ctx, o)
+	if err != nil {
+		respondRepoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, mappers.ToOrgResponse(o))
+}
+
+func (h *OrgHandler) ListOrgs(c *gin.Context) {
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	orgs, err := h.repo.List(ctx)
+	if err != nil {
+		respondRepoError(c, err)
+		return
+	}
+
+	out := make([]mappers.OrgResponse, 0, len(orgs))
+	for _, o := range orgs {
+		if !visible(ctx, o.ID) {
+			continue
+		}
+		out = append(out, mappers.ToOrgResponse(o))
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *OrgHandler) GetOrg(c *gin.Context) {
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	id := strings.TrimSpace(c.Param("org"))
+	if !visible(ctx, id) {
+		respondRepoError(c, repository.ErrNotFound)
+		return
+	}
+	o, ok, err := h.repo.Get(ctx, id)
+	if err != nil {
+		respondRepoError(c, err)
+		return
+	}
+	if !ok {
+		respondRepoError(c, repository.ErrNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, mappers.ToOrgResponse(o))
+}
+
+func (h *OrgHandler) UpdateOrg(c *gin.Context) {
+	var req dtos.UpdateOrgRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.RespondValidationError(c, err)
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	id := strings.TrimSpace(c.Param("org"))
+	if !visible(ctx, id) {
+		respondRepoError(c, repository.ErrNotFound)
+		return
+	}
+	// Desativar a organização barra todos os seus usuários; só a plataforma decide
+	if req.Active != nil && restrictedTo(ctx) != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only platform administrators can activate or deactivate organizations"})
+		return
+	}
+	o, ok, err := h.repo.Get(ctx, id)
+	if err != nil {
+		respondRepoError(c, err)
+		return
+	}
+	if !ok {
+		respondRepoError(c, repository.ErrNotFound)
+		return
+	}
+
+	if req.Name != nil {
+		if err := o.Rename(*req.Name); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.Active != nil {
+		if err := o.SetActive(*req.Active); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.SelfSignup != nil {
+		o.SelfSignup = *req.SelfSignup
+	}
+
+	o, err = h.repo.Update(ctx, o)
+	if err != nil {
+		respondRepoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mappers.ToOrgResponse(o))
+}
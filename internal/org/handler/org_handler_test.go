@@ -0,0 +1,158 @@
+package org_handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	"github.com/williamkoller/cloud-architecture-golang/internal/org/mappers"
+	"github.com/williamkoller/cloud-architecture-golang/internal/org/repository"
+)
+
+func routerWithOrgRoutes(h *OrgHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/orgs", h.CreateOrg)
+	r.GET("/orgs", h.ListOrgs)
+	r.GET("/orgs/:org", h.GetOrg)
+	r.PATCH("/orgs/:org", h.UpdateOrg)
+	return r
+}
+
+func doJSON(t *testing.T, r http.Handler, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	buf := bytes.NewBuffer(nil)
+	if body != nil {
+		if err := json.NewEncoder(buf).Encode(body); err != nil {
+			t.Fatalf("marshal body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, path, buf)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestOrgHandler_CreateGetUpdate(t *testing.T) {
+	r := routerWithOrgRoutes(NewOrgHandler(repository.NewInMemoryOrgRepository()))
+
+	w := doJSON(t, r, http.MethodPost, "/orgs", map[string]any{"id": "acme", "name": "Acme"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: got %d body=%s", w.Code, w.Body.String())
+	}
+	if w = doJSON(t, r, http.MethodPost, "/orgs", map[string]any{"id": "acme", "name": "Acme"}); w.Code != http.StatusConflict {
+		t.Fatalf("duplicate: got %d", w.Code)
+	}
+
+	w = doJSON(t, r, http.MethodPatch, "/orgs/acme", map[string]any{"name": "Acme Corp", "active": false})
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: got %d body=%s", w.Code, w.Body.String())
+	}
+
+	w = doJSON(t, r, http.MethodGet, "/orgs/acme", nil)
+	var got mappers.OrgResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got.Name != "Acme Corp" || got.Active {
+		t.Fatalf("org: %+v", got)
+	}
+}
+
+func TestOrgHandler_ErrorScenarios(t *testing.T) {
+	r := routerWithOrgRoutes(NewOrgHandler(repository.NewInMemoryOrgRepository()))
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		want   int
+	}{
+		{"missing fields", http.MethodPost, "/orgs", map[string]any{"id": "acme"}, http.StatusBadRequest},
+		{"invalid id", http.MethodPost, "/orgs", map[string]any{"id": "a.b", "name": "AB"}, http.StatusUnprocessableEntity},
+		{"get unknown", http.MethodGet, "/orgs/ghost", nil, http.StatusNotFound},
+		{"update unknown", http.MethodPatch, "/orgs/ghost", map[string]any{"name": "x"}, http.StatusNotFound},
+		{"deactivate default", http.MethodPatch, "/orgs/default", map[string]any{"active": false}, http.StatusUnprocessableEntity},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if w := doJSON(t, r, tc.method, tc.path, tc.body); w.Code != tc.want {
+				t.Fatalf("status: got %d, want %d body=%s", w.Code, tc.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestOrgHandler_TenantAdminsOnlyReachTheirOwnOrg(t *testing.T) {
+	h := NewOrgHandler(repository.NewInMemoryOrgRepository())
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if org := c.GetHeader("X-Test-Org"); org != "" {
+			auth.SetPrincipal(c, auth.Principal{UserID: "u1", Roles: []string{"admin"}, OrgID: org})
+		}
+		c.Next()
+	})
+	r.POST("/orgs", h.CreateOrg)
+	r.GET("/orgs", h.ListOrgs)
+	r.GET("/orgs/:org", h.GetOrg)
+	r.PATCH("/orgs/:org", h.UpdateOrg)
+
+	as := func(org, method, path string, body any) *httptest.ResponseRecorder {
+		buf := bytes.NewBuffer(nil)
+		_ = json.NewEncoder(buf).Encode(body)
+		req := httptest.NewRequest(method, path, buf)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Test-Org", org)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, id := range []string{"acme", "globex"} {
+		if w := as("default", http.MethodPost, "/orgs", map[string]any{"id": id, "name": id}); w.Code != http.StatusCreated {
+			t.Fatalf("platform create %s: got %d body=%s", id, w.Code, w.Body.String())
+		}
+	}
+
+	if w := as("acme", http.MethodPost, "/orgs", map[string]any{"id": "initech", "name": "Initech"}); w.Code != http.StatusForbidden {
+		t.Fatalf("tenant create: got %d", w.Code)
+	}
+	if w := as("acme", http.MethodPatch, "/orgs/globex", map[string]any{"active": false}); w.Code != http.StatusNotFound {
+		t.Fatalf("deactivate other org: got %d", w.Code)
+	}
+	if w := as("acme", http.MethodPatch, "/orgs/globex", map[string]any{"name": "Pwned"}); w.Code != http.StatusNotFound {
+		t.Fatalf("rename other org: got %d", w.Code)
+	}
+	if w := as("acme", http.MethodGet, "/orgs/globex", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("get other org: got %d", w.Code)
+	}
+
+	var listed []mappers.OrgResponse
+	_ = json.Unmarshal(as("acme", http.MethodGet, "/orgs", nil).Body.Bytes(), &listed)
+	if len(listed) != 1 || listed[0].ID != "acme" {
+		t.Fatalf("tenant list: %+v", listed)
+	}
+
+	if w := as("acme", http.MethodPatch, "/orgs/acme", map[string]any{"name": "Acme Corp"}); w.Code != http.StatusOK {
+		t.Fatalf("rename own org: got %d body=%s", w.Code, w.Body.String())
+	}
+	if w := as("acme", http.MethodPatch, "/orgs/acme", map[string]any{"active": false}); w.Code != http.StatusForbidden {
+		t.Fatalf("deactivate own org: got %d", w.Code)
+	}
+
+	// globex ficou intacta e a plataforma ainda administra qualquer organização
+	w := as("default", http.MethodPatch, "/orgs/globex", map[string]any{"active": false})
+	var got mappers.OrgResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if w.Code != http.StatusOK || got.Name != "globex" || got.Active {
+		t.Fatalf("platform update: got %d %+v", w.Code, got)
+	}
+}
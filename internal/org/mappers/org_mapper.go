@@ -0,0 +1,27 @@
+package mappers
+
+import (
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/org/domain"
+)
+
+type OrgResponse struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Active     bool   `json:"active"`
+	SelfSignup bool   `json:"selfSignup"`
+	CreatedAt  string `json:"createdAt"`
+	UpdatedAt  string `json:"updatedAt"`
+}
+
+func ToOrgResponse(o domain.Organization) OrgResponse {
+	return OrgResponse{
+		ID:         o.ID,
+		Name:       o.Name,
+		Active:     o.Active,
+		SelfSignup: o.SelfSignup,
+		CreatedAt:  o.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:  o.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/org/domain"
+)
+
+var (
+	ErrNotFound      = errors.New("organization not found")
+	ErrAlreadyExists = errors.New("organization already exists")
+)
+
+type OrgRepository interface {
+	Create(ctx context.Context, o domain.Organization) (domain.Organization, error)
+	Get(ctx context.Context, id string) (domain.Organization, bool, error)
+	List(ctx context.Context) ([]domain.Organization, error)
+	Update(ctx context.Context, o domain.Organization) (domain.Organization, error)
+}
+
+type inMemoryOrgRepo struct {
+	mu   sync.RWMutex
+	orgs map[string]domain.Organization
+	now  func() time.Time
+}
+
+// Option configura o repositório em memória
+type Option func(*inMemoryOrgRepo)
+
+// WithClock substitui o relógio usado para as datas de criação e alteração
+func WithClock(now func() time.Time) Option {
+	return func(r *inMemoryOrgRepo) {
+		r.now = now
+	}
+}
+
+// NewInMemoryOrgRepository cria o repositório já com a organização padrão
+func NewInMemoryOrgRepository(opts ...Option) OrgRepository {
+	r := &inMemoryOrgRepo{
+		orgs: make(map[string]domain.Organization),
+		now:  time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	def, _ := domain.NewOrganization(domain.DefaultOrgID, "Default")
+	def.CreatedAt = r.timestamp()
+	def.UpdatedAt = def.CreatedAt
+	r.orgs[def.ID] = def
+	return r
+}
+
+func (r *inMemoryOrgRepo) timestamp() time.Time {
+	return r.now().UTC().Truncate(time.Second)
+}
+
+func (r *inMemoryOrgRepo) Create(ctx context.Context, o domain.Organization) (domain.Organization, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Organization{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.orgs[o.ID]; ok {
+		return domain.Organization{}, ErrAlreadyExists
+	}
+	o.CreatedAt = r.timestamp()
+	o.UpdatedAt = o.CreatedAt
+	r.orgs[o.ID] = o
+	return o, nil
+}
+
+func (r *inMemoryOrgRepo) Get(ctx context.Context, id string) (domain.Organization, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Organization{}, false, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	o, ok := r.orgs[id]
+	return o, ok, nil
+}
+
+func (r *inMemoryOrgRepo) List(ctx context.Context) ([]domain.Organization, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	out := make([]domain.Organization, 0, len(r.orgs))
+	for _, o := range r.orgs {
+		out = append(out, o)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// Update grava nome e situação; o ID e a data de criação não mudam
+func (r *inMemoryOrgRepo) Update(ctx context.Context, o domain.Organization) (domain.Organization, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Organization{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.orgs[o.ID]
+	if !ok {
+		return domain.Organization{}, ErrNotFound
+	}
+	o.CreatedAt = current.CreatedAt
+	o.UpdatedAt = r.timestamp()
+	r.orgs[o.ID] = o
+	return o, nil
+}
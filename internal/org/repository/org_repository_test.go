@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/org/domain"
+)
+
+func TestOrgRepository(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := NewInMemoryOrgRepository(WithClock(func() time.Time { return now }))
+	ctx := context.Background()
+
+	if _, ok, _ := repo.Get(ctx, domain.DefaultOrgID); !ok {
+		t.Fatalf("default organization should be seeded")
+	}
+
+	acme, _ := domain.NewOrganization("acme", "Acme")
+	if _, err := repo.Create(ctx, acme); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := repo.Create(ctx, acme); err != ErrAlreadyExists {
+		t.Fatalf("duplicate Create: got %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	acme.Name = "Acme Corp"
+	updated, err := repo.Update(ctx, acme)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.CreatedAt.Equal(now) || !updated.UpdatedAt.Equal(now) {
+		t.Fatalf("timestamps: %+v", updated)
+	}
+
+	ghost, _ := domain.NewOrganization("ghost", "Ghost")
+	if _, err := repo.Update(ctx, ghost); err != ErrNotFound {
+		t.Fatalf("Update unknown: got %v", err)
+	}
+
+	orgs, _ := repo.List(ctx)
+	if len(orgs) != 2 || orgs[0].ID != "acme" || orgs[1].ID != domain.DefaultOrgID {
+		t.Fatalf("List: %+v", orgs)
+	}
+}
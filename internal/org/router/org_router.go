@@ -0,0 +1,55 @@
+package org_router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	org_handler "github.com/williamkoller/cloud-architecture-golang/internal/org/handler"
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+)
+
+// RouteOption configura o registro das rotas de organização
+type RouteOption func(*routeConfig)
+
+type routeConfig struct {
+	resolver auth.PermissionResolver
+}
+
+// WithPermissionGuards exige orgs:read para consultas e orgs:write para
+// alterações; criar organizações também exige um principal da plataforma
+func WithPermissionGuards(resolver auth.PermissionResolver) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.resolver = resolver
+	}
+}
+
+func (cfg routeConfig) guard(perm rbac.Permission, h gin.HandlerFunc) []gin.HandlerFunc {
+	if cfg.resolver == nil {
+		return []gin.HandlerFunc{h}
+	}
+	return []gin.HandlerFunc{auth.RequirePermission(cfg.resolver, perm), h}
+}
+
+func (cfg routeConfig) platformGuard(perm rbac.Permission, h gin.HandlerFunc) []gin.HandlerFunc {
+	if cfg.resolver == nil {
+		return []gin.HandlerFunc{h}
+	}
+	return []gin.HandlerFunc{auth.RequirePermission(cfg.resolver, perm), auth.RequirePlatform(), h}
+}
+
+// RegisterOrgRoutes registra a administração das organizações. O parâmetro
+// :org é o mesmo das rotas /orgs/:org/users. Fora da plataforma, cada
+// credencial só enxerga e renomeia a própria organização (OrgHandler).
+func RegisterOrgRoutes(group *gin.RouterGroup, h *org_handler.OrgHandler, opts ...RouteOption) {
+	var cfg routeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	orgs := group.Group("/orgs")
+	{
+		orgs.POST("", cfg.platformGuard(rbac.PermOrgsWrite, h.CreateOrg)...)
+		orgs.GET("", cfg.guard(rbac.PermOrgsRead, h.ListOrgs)...)
+		orgs.GET("/:org", cfg.guard(rbac.PermOrgsRead, h.GetOrg)...)
+		orgs.PATCH("/:org", cfg.guard(rbac.PermOrgsWrite, h.UpdateOrg)...)
+	}
+}
@@ -0,0 +1,38 @@
+package org_router
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	org_handler "github.com/williamkoller/cloud-architecture-golang/internal/org/handler"
+)
+
+func TestRegisterOrgRoutes_RegistersAllExpectedRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	RegisterOrgRoutes(r.Group("/api/v1"), &org_handler.OrgHandler{})
+
+	expected := map[string]string{
+		"POST /api/v1/orgs":       ".CreateOrg",
+		"GET /api/v1/orgs":        ".ListOrgs",
+		"GET /api/v1/orgs/:org":   ".GetOrg",
+		"PATCH /api/v1/orgs/:org": ".UpdateOrg",
+	}
+	found := 0
+	for _, ri := range r.Routes() {
+		want, ok := expected[ri.Method+" "+ri.Path]
+		if !ok {
+			continue
+		}
+		found++
+		if !strings.Contains(ri.Handler, want) {
+			t.Fatalf("handler mismatch for %s %s: got %q, want %q", ri.Method, ri.Path, ri.Handler, want)
+		}
+	}
+	if found != len(expected) {
+		t.Fatalf("found %d of %d routes", found, len(expected))
+	}
+}
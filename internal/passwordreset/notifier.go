@@ -0,0 +1,38 @@
+package passwordreset
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Message é o link de redefinição a ser entregue ao usuário
+type Message struct {
+	To        string    `json:"to"`
+	UserID    string    `json:"userId"`
+	OrgID     string    `json:"orgId,omitempty"`
+	Link      string    `json:"link"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Notifier entrega o link de redefinição de senha
+type Notifier interface {
+	SendPasswordReset(ctx context.Context, m Message) error
+}
+
+// NotifierFunc adapta uma função para Notifier
+type NotifierFunc func(ctx context.Context, m Message) error
+
+func (f NotifierFunc) SendPasswordReset(ctx context.Context, m Message) error {
+	return f(ctx, m)
+}
+
+// LogNotifier escreve o link no log, para desenvolvimento local
+type LogNotifier struct{}
+
+func (LogNotifier) SendPasswordReset(ctx context.Context, m Message) error {
+	log.Printf("password reset email to=%s user=%s org=%s link=%s expires=%s",
+		m.To, m.UserID, m.OrgID, m.Link, m.ExpiresAt.Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,181 @@
+package passwordreset
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/verification"
+)
+
+// ErrInvalidToken cobre tokens desconhecidos, expirados, já usados ou
+// substituídos; o motivo não é exposto a quem tenta o reset
+var ErrInvalidToken = errors.New("invalid or expired password reset token")
+
+// Recipient identifica o usuário que pediu o reset
+type Recipient struct {
+	UserID string
+	OrgID  string
+	Email  string
+}
+
+func (r Recipient) key() string {
+	return r.OrgID + "/" + r.UserID
+}
+
+// entry é um token pendente; só o hash do token é guardado
+type entry struct {
+	recipient Recipient
+	expiresAt time.Time
+}
+
+// Service emite e resgata tokens de redefinição de senha. Os tokens são
+// aleatórios e guardados apenas como SHA-256, valem por pouco tempo e são de
+// uso único; um novo pedido substitui o token anterior do usuário.
+type Service struct {
+	notifier Notifier
+	ttl      time.Duration
+	linkBase string
+	now      func() time.Time
+	throttle *verification.Throttle
+
+	mu     sync.Mutex
+	tokens map[string]entry  // hash do token -> pedido
+	byUser map[string]string // Recipient.key -> hash do token vigente
+}
+
+// Option configura o Service
+type Option func(*Service)
+
+// WithTTL define a validade dos tokens (padrão 30 minutos)
+func WithTTL(d time.Duration) Option {
+	return func(s *Service) {
+		s.ttl = d
+	}
+}
+
+// WithLinkBase define a URL do link enviado; o token vai no parâmetro token
+func WithLinkBase(base string) Option {
+	return func(s *Service) {
+		s.linkBase = base
+	}
+}
+
+// WithClock substitui o relógio, para testes
+func WithClock(now func() time.Time) Option {
+	return func(s *Service) {
+		s.now = now
+	}
+}
+
+// WithRequestLimit define o intervalo mínimo entre pedidos e o teto por hora
+// para um mesmo endereço (padrão 1 minuto e 5 por hora)
+func WithRequestLimit(interval time.Duration, perHour int) Option {
+	return func(s *Service) {
+		s.throttle = verification.NewThrottle(interval, perHour, time.Hour)
+	}
+}
+
+func NewService(notifier Notifier, opts ...Option) *Service {
+	s := &Service{
+		notifier: notifier,
+		ttl:      30 * time.Minute,
+		now:      time.Now,
+		throttle: verification.NewThrottle(time.Minute, 5, time.Hour),
+		tokens:   make(map[string]entry),
+		byUser:   make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// AllowRequest aplica o limite de pedidos ao endereço, exista ele ou não
+func (s *Service) AllowRequest(orgID, email string) (time.Duration, bool) {
+	return s.throttle.Allow(orgID+"/"+strings.ToLower(email), s.now())
+}
+
+// Send emite um token novo para o usuário, invalidando o anterior, e o
+// entrega pelo notifier
+func (s *Service) Send(ctx context.Context, r Recipient) error {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	now := s.now().UTC()
+	expiresAt := now.Add(s.ttl).Truncate(time.Second)
+
+	s.mu.Lock()
+	for h, e := range s.tokens {
+		if !now.Before(e.expiresAt) {
+			delete(s.tokens, h)
+			delete(s.byUser, e.recipient.key())
+		}
+	}
+	if old, ok := s.byUser[r.key()]; ok {
+		delete(s.tokens, old)
+	}
+	h := hashToken(token)
+	s.tokens[h] = entry{recipient: r, expiresAt: expiresAt}
+	s.byUser[r.key()] = h
+	s.mu.Unlock()
+
+	return s.notifier.SendPasswordReset(ctx, Message{
+		To:        r.Email,
+		UserID:    r.UserID,
+		OrgID:     r.OrgID,
+		Link:      s.link(token),
+		Token:     token,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// Lookup devolve o pedido do token sem consumi-lo, para que uma senha
+// rejeitada pela política não desperdice o link
+func (s *Service) Lookup(token string) (Recipient, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.tokens[hashToken(strings.TrimSpace(token))]
+	if !ok || !s.now().Before(e.expiresAt) {
+		return Recipient{}, ErrInvalidToken
+	}
+	return e.recipient, nil
+}
+
+// Consume inutiliza o token
+func (s *Service) Consume(token string) {
+	h := hashToken(strings.TrimSpace(token))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.tokens[h]; ok {
+		delete(s.tokens, h)
+		if s.byUser[e.recipient.key()] == h {
+			delete(s.byUser, e.recipient.key())
+		}
+	}
+}
+
+func (s *Service) link(token string) string {
+	if s.linkBase == "" {
+		return token
+	}
+	sep := "?"
+	if strings.Contains(s.linkBase, "?") {
+		sep = "&"
+	}
+	return s.linkBase + sep + "token=" + url.QueryEscape(token)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,81 @@
+package passwordreset
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+type inbox struct {
+	msgs []Message
+}
+
+func (b *inbox) SendPasswordReset(ctx context.Context, m Message) error {
+	b.msgs = append(b.msgs, m)
+	return nil
+}
+
+func TestService_TokensAreHashedSingleUseAndExpire(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	box := &inbox{}
+	s := NewService(box,
+		WithTTL(30*time.Minute),
+		WithLinkBase("https://app.example.com/reset?lang=pt"),
+		WithClock(func() time.Time { return now }),
+	)
+	r := Recipient{UserID: "u1", OrgID: "acme", Email: "ana@example.com"}
+
+	if err := s.Send(context.Background(), r); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	token := box.msgs[0].Token
+	if !strings.HasPrefix(box.msgs[0].Link, "https://app.example.com/reset?lang=pt&token=") {
+		t.Fatalf("link: %q", box.msgs[0].Link)
+	}
+	// Só o hash fica guardado
+	if _, ok := s.tokens[token]; ok {
+		t.Fatalf("raw token stored")
+	}
+
+	got, err := s.Lookup(token)
+	if err != nil || got != r {
+		t.Fatalf("Lookup: %+v err=%v", got, err)
+	}
+	// Lookup não consome; Consume sim
+	if _, err := s.Lookup(token); err != nil {
+		t.Fatalf("second Lookup: %v", err)
+	}
+	s.Consume(token)
+	if _, err := s.Lookup(token); err != ErrInvalidToken {
+		t.Fatalf("consumed token: got %v", err)
+	}
+
+	// Um novo pedido substitui o anterior
+	_ = s.Send(context.Background(), r)
+	_ = s.Send(context.Background(), r)
+	if _, err := s.Lookup(box.msgs[1].Token); err != ErrInvalidToken {
+		t.Fatalf("replaced token: got %v", err)
+	}
+	if _, err := s.Lookup(box.msgs[2].Token); err != nil {
+		t.Fatalf("current token: %v", err)
+	}
+
+	now = now.Add(30 * time.Minute)
+	if _, err := s.Lookup(box.msgs[2].Token); err != ErrInvalidToken {
+		t.Fatalf("expired token: got %v", err)
+	}
+	if _, err := s.Lookup("garbage"); err != ErrInvalidToken {
+		t.Fatalf("unknown token: got %v", err)
+	}
+}
+
+func TestService_AllowRequestIsPerAddress(t *testing.T) {
+	s := NewService(&inbox{}, WithRequestLimit(time.Minute, 5))
+	if _, ok := s.AllowRequest("acme", "ana@example.com"); !ok {
+		t.Fatalf("first request must be allowed")
+	}
+	if _, ok := s.AllowRequest("acme", "ANA@example.com"); ok {
+		t.Fatalf("second request within the interval must be throttled")
+	}
+}
@@ -0,0 +1,26 @@
+package passwordreset
+
+import (
+	"context"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/events"
+)
+
+// SendOnRequested é o assinante de domain.EventUserPasswordResetRequested que
+// emite o token e entrega o link. Uma reentrega do outbox gera um token novo,
+// que substitui o anterior.
+func SendOnRequested(s *Service) events.Subscriber {
+	return func(ctx context.Context, e domain.Event) error {
+		requested, ok := e.(domain.UserPasswordResetRequested)
+		if !ok {
+			return nil
+		}
+		orgID, _ := events.OrgID(ctx)
+		return s.Send(ctx, Recipient{
+			UserID: string(requested.UserID),
+			OrgID:  orgID,
+			Email:  string(requested.Email),
+		})
+	}
+}
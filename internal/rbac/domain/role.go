@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Permission é uma ação autorizável no formato recurso:ação
+type Permission string
+
+const (
+	PermUsersRead   Permission = "users:read"
+	PermUsersWrite  Permission = "users:write"
+	PermUsersDelete Permission = "users:delete"
+	PermRolesRead   Permission = "roles:read"
+	PermRolesWrite  Permission = "roles:write"
+	PermOrgsRead    Permission = "orgs:read"
+	PermOrgsWrite   Permission = "orgs:write"
+	PermGroupsRead  Permission = "groups:read"
+	PermGroupsWrite Permission = "groups:write"
+	// API keys de serviço da organização
+	PermAPIKeysRead  Permission = "apikeys:read"
+	PermAPIKeysWrite Permission = "apikeys:write"
+)
+
+// KnownPermissions lista todas as permissões que podem ser atribuídas a papéis
+var KnownPermissions = []Permission{
+	PermUsersRead,
+	PermUsersWrite,
+	PermUsersDelete,
+	PermRolesRead,
+	PermRolesWrite,
+	PermOrgsRead,
+	PermOrgsWrite,
+	PermGroupsRead,
+	PermGroupsWrite,
+	PermAPIKeysRead,
+	PermAPIKeysWrite,
+}
+
+// Papéis embutidos: admin tem todas as permissões e não pode ser alterado;
+// user é o papel base de todo usuário e pode ter suas permissões ajustadas
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+var (
+	ErrInvalidRoleName = errors.New("role name must be 2-32 lowercase letters, digits, '-' or '_' starting with a letter")
+	ErrBuiltInRole     = errors.New("built-in role cannot be changed")
+)
+
+var rolePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,31}$`)
+
+var knownPermissionIndex = func() map[Permission]bool {
+	m := make(map[Permission]bool, len(KnownPermissions))
+	for _, p := range KnownPermissions {
+		m[p] = true
+	}
+	return m
+}()
+
+// ValidRoleName indica se o nome segue o formato aceito para papéis
+func ValidRoleName(name string) bool {
+	return rolePattern.MatchString(name)
+}
+
+// Valid indica se a permissão é conhecida
+func (p Permission) Valid() bool {
+	return knownPermissionIndex[p]
+}
+
+// PermissionSet é o conjunto de permissões efetivas de um conjunto de papéis
+type PermissionSet map[Permission]bool
+
+// Has indica se a permissão está no conjunto
+func (s PermissionSet) Has(p Permission) bool {
+	return s[p]
+}
+
+type Role struct {
+	Name        string
+	Description string
+	Permissions []Permission
+	BuiltIn     bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// NewRole valida o nome e as permissões e devolve o papel com permissões
+// ordenadas e sem repetição
+func NewRole(name, description string, perms []Permission) (Role, error) {
+	name = strings.TrimSpace(name)
+	if !ValidRoleName(name) {
+		return Role{}, ErrInvalidRoleName
+	}
+
+	r := Role{Name: name, Description: strings.TrimSpace(description)}
+	if err := r.SetPermissions(perms); err != nil {
+		return Role{}, err
+	}
+	return r, nil
+}
+
+// SetPermissions substitui as permissões do papel
+func (r *Role) SetPermissions(perms []Permission) error {
+	if r.Name == RoleAdmin && r.BuiltIn {
+		return ErrBuiltInRole
+	}
+
+	seen := make(map[Permission]bool, len(perms))
+	out := make([]Permission, 0, len(perms))
+	var unknown []string
+	for _, p := range perms {
+		p = Permission(strings.TrimSpace(string(p)))
+		if !p.Valid() {
+			unknown = append(unknown, string(p))
+			continue
+		}
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown permissions: %s", strings.Join(unknown, ", "))
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	r.Permissions = out
+	return nil
+}
+
+// BuiltInRoles devolve os papéis que todo catálogo começa tendo
+func BuiltInRoles() []Role {
+	admin, _ := NewRole(RoleAdmin, "Full access to users and roles", KnownPermissions)
+	user, _ := NewRole(RoleUser, "Base role assigned to every user", nil)
+	admin.BuiltIn = true
+	user.BuiltIn = true
+	return []Role{admin, user}
+}
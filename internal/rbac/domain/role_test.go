@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewRole_NormalizesPermissions(t *testing.T) {
+	r, err := NewRole(" support ", " Suporte ", []Permission{PermUsersWrite, PermUsersRead, PermUsersWrite})
+	if err != nil {
+		t.Fatalf("NewRole: %v", err)
+	}
+	if r.Name != "support" || r.Description != "Suporte" {
+		t.Fatalf("role: %+v", r)
+	}
+	want := []Permission{PermUsersRead, PermUsersWrite}
+	if !reflect.DeepEqual(r.Permissions, want) {
+		t.Fatalf("permissions: got %v, want %v", r.Permissions, want)
+	}
+}
+
+func TestNewRole_RejectsInvalidNameAndUnknownPermission(t *testing.T) {
+	for _, name := range []string{"", "A", "Support", "1abc", "has space", strings.Repeat("a", 33)} {
+		if _, err := NewRole(name, "", nil); err != ErrInvalidRoleName {
+			t.Fatalf("NewRole(%q): got %v, want ErrInvalidRoleName", name, err)
+		}
+	}
+
+	_, err := NewRole("support", "", []Permission{PermUsersRead, "users:fly"})
+	if err == nil || !strings.Contains(err.Error(), "users:fly") {
+		t.Fatalf("unknown permission: got %v", err)
+	}
+}
+
+func TestBuiltInRoles(t *testing.T) {
+	roles := BuiltInRoles()
+	if len(roles) != 2 || roles[0].Name != RoleAdmin || roles[1].Name != RoleUser {
+		t.Fatalf("built-in roles: %+v", roles)
+	}
+	if len(roles[0].Permissions) != len(KnownPermissions) || len(roles[1].Permissions) != 0 {
+		t.Fatalf("built-in permissions: admin=%v user=%v", roles[0].Permissions, roles[1].Permissions)
+	}
+
+	admin := roles[0]
+	if err := admin.SetPermissions(nil); err != ErrBuiltInRole {
+		t.Fatalf("admin SetPermissions: got %v, want ErrBuiltInRole", err)
+	}
+	user := roles[1]
+	if err := user.SetPermissions([]Permission{PermUsersRead}); err != nil {
+		t.Fatalf("user SetPermissions: %v", err)
+	}
+}
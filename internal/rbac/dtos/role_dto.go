@@ -0,0 +1,13 @@
+package dtos
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" binding:"omitempty,dive,required"`
+}
+
+// UpdateRoleRequest altera apenas os campos enviados; permissions substitui a lista inteira
+type UpdateRoleRequest struct {
+	Description *string   `json:"description"`
+	Permissions *[]string `json:"permissions" binding:"omitempty,dive,required"`
+}
@@ -0,0 +1,161 @@
+package rbac_handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/rbac/dtos"
+	"github.com/williamkoller/cloud-architecture-golang/internal/rbac/mappers"
+	"github.com/williamkoller/cloud-architecture-golang/internal/rbac/repository"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/validation"
+)
+
+// RoleHandler expõe a administração do catálogo de papéis
+type RoleHandler struct {
+	repo           repository.RoleRepository
+	requestTimeout time.Duration
+}
+
+func NewRoleHandler(repo repository.RoleRepository) *RoleHandler {
+	return &RoleHandler{
+		repo:           repo,
+		requestTimeout: 5 * time.Second,
+	}
+}
+
+func (h *RoleHandler) ctx(c *gin.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(c.Request.Context(), h.requestTimeout)
+}
+
+// respondRepoError traduz os erros do repositório de papéis
+func respondRepoError(c *gin.Context, err error) {
+	switch err {
+	case repository.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "role not found"})
+	case repository.ErrAlreadyExists:
+		c.JSON(http.StatusConflict, gin.H{"error": "role already exists"})
+	case domain.ErrBuiltInRole:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req dtos.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.RespondValidationError(c, err)
+		return
+	}
+
+	role, err := domain.NewRole(req.Name, req.Description, mappers.ToPermissions(req.Permissions))
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	role, err = h.repo.Create(ctx, role)
+	if err != nil {
+		respondRepoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, mappers.ToRoleResponse(role))
+}
+
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	roles, err := h.repo.List(ctx)
+	if err != nil {
+		respondRepoError(c, err)
+		return
+	}
+
+	out := make([]mappers.RoleResponse, 0, len(roles))
+	for _, r := range roles {
+		out = append(out, mappers.ToRoleResponse(r))
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	role, ok, err := h.repo.Get(ctx, strings.TrimSpace(c.Param("name")))
+	if err != nil {
+		respondRepoError(c, err)
+		return
+	}
+	if !ok {
+		respondRepoError(c, repository.ErrNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, mappers.ToRoleResponse(role))
+}
+
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	var req dtos.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.RespondValidationError(c, err)
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	role, ok, err := h.repo.Get(ctx, strings.TrimSpace(c.Param("name")))
+	if err != nil {
+		respondRepoError(c, err)
+		return
+	}
+	if !ok {
+		respondRepoError(c, repository.ErrNotFound)
+		return
+	}
+
+	if req.Description != nil {
+		role.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Permissions != nil {
+		if err := role.SetPermissions(mappers.ToPermissions(*req.Permissions)); err != nil {
+			if err == domain.ErrBuiltInRole {
+				respondRepoError(c, err)
+				return
+			}
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	role, err = h.repo.Update(ctx, role)
+	if err != nil {
+		respondRepoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mappers.ToRoleResponse(role))
+}
+
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	if err := h.repo.Delete(ctx, strings.TrimSpace(c.Param("name"))); err != nil {
+		respondRepoError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
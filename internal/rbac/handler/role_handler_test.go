@@ -0,0 +1,118 @@
+package rbac_handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/rbac/mappers"
+	"github.com/williamkoller/cloud-architecture-golang/internal/rbac/repository"
+)
+
+func routerWithRoleRoutes(h *RoleHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/roles", h.CreateRole)
+	r.GET("/roles", h.ListRoles)
+	r.GET("/roles/:name", h.GetRole)
+	r.PATCH("/roles/:name", h.UpdateRole)
+	r.DELETE("/roles/:name", h.DeleteRole)
+	return r
+}
+
+func doJSON(t *testing.T, r http.Handler, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	buf := bytes.NewBuffer(nil)
+	if body != nil {
+		if err := json.NewEncoder(buf).Encode(body); err != nil {
+			t.Fatalf("marshal body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, path, buf)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodeRole(t *testing.T, w *httptest.ResponseRecorder) mappers.RoleResponse {
+	t.Helper()
+	var out mappers.RoleResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatalf("unmarshal: %v body=%s", err, w.Body.String())
+	}
+	return out
+}
+
+func TestRoleHandler_Lifecycle(t *testing.T) {
+	r := routerWithRoleRoutes(NewRoleHandler(repository.NewInMemoryRoleRepository()))
+
+	w := doJSON(t, r, http.MethodPost, "/roles", map[string]any{
+		"name": "support", "description": "Atendimento", "permissions": []string{"users:read"},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: got %d body=%s", w.Code, w.Body.String())
+	}
+	if got := decodeRole(t, w); got.Name != "support" || len(got.Permissions) != 1 || got.BuiltIn {
+		t.Fatalf("created: %+v", got)
+	}
+
+	w = doJSON(t, r, http.MethodPost, "/roles", map[string]any{"name": "support"})
+	if w.Code != http.StatusConflict {
+		t.Fatalf("duplicate create: got %d", w.Code)
+	}
+
+	w = doJSON(t, r, http.MethodPatch, "/roles/support", map[string]any{
+		"permissions": []string{"users:read", "users:write"},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: got %d body=%s", w.Code, w.Body.String())
+	}
+	if got := decodeRole(t, w); len(got.Permissions) != 2 || got.Description != "Atendimento" {
+		t.Fatalf("updated: %+v", got)
+	}
+
+	w = doJSON(t, r, http.MethodGet, "/roles", nil)
+	var list []mappers.RoleResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || len(list) != 3 {
+		t.Fatalf("list: %s err=%v", w.Body.String(), err)
+	}
+
+	if w = doJSON(t, r, http.MethodDelete, "/roles/support", nil); w.Code != http.StatusNoContent {
+		t.Fatalf("delete: got %d", w.Code)
+	}
+	if w = doJSON(t, r, http.MethodGet, "/roles/support", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("get deleted: got %d", w.Code)
+	}
+}
+
+func TestRoleHandler_ErrorScenarios(t *testing.T) {
+	r := routerWithRoleRoutes(NewRoleHandler(repository.NewInMemoryRoleRepository()))
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		want   int
+	}{
+		{"missing name", http.MethodPost, "/roles", map[string]any{}, http.StatusBadRequest},
+		{"invalid name", http.MethodPost, "/roles", map[string]any{"name": "Bad Name"}, http.StatusUnprocessableEntity},
+		{"unknown permission", http.MethodPost, "/roles", map[string]any{"name": "x1", "permissions": []string{"users:fly"}}, http.StatusUnprocessableEntity},
+		{"update unknown role", http.MethodPatch, "/roles/ghost", map[string]any{"description": "x"}, http.StatusNotFound},
+		{"update admin", http.MethodPatch, "/roles/admin", map[string]any{"permissions": []string{}}, http.StatusConflict},
+		{"delete built-in", http.MethodDelete, "/roles/user", nil, http.StatusConflict},
+		{"delete unknown", http.MethodDelete, "/roles/ghost", nil, http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if w := doJSON(t, r, tc.method, tc.path, tc.body); w.Code != tc.want {
+				t.Fatalf("status: got %d, want %d body=%s", w.Code, tc.want, w.Body.String())
+			}
+		})
+	}
+}
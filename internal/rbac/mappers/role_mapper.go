@@ -0,0 +1,41 @@
+package mappers
+
+import (
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+)
+
+type RoleResponse struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Permissions []string `json:"permissions"`
+	BuiltIn     bool     `json:"builtIn"`
+	CreatedAt   string   `json:"createdAt"`
+	UpdatedAt   string   `json:"updatedAt"`
+}
+
+func ToRoleResponse(r domain.Role) RoleResponse {
+	perms := make([]string, len(r.Permissions))
+	for i, p := range r.Permissions {
+		perms[i] = string(p)
+	}
+
+	return RoleResponse{
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: perms,
+		BuiltIn:     r.BuiltIn,
+		CreatedAt:   r.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:   r.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// ToPermissions converte as permissões recebidas na API
+func ToPermissions(in []string) []domain.Permission {
+	out := make([]domain.Permission, len(in))
+	for i, p := range in {
+		out[i] = domain.Permission(p)
+	}
+	return out
+}
@@ -0,0 +1,30 @@
+package mappers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+)
+
+func TestToRoleResponse(t *testing.T) {
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.FixedZone("BRT", -3*3600))
+	r, err := domain.NewRole("support", "Atendimento", ToPermissions([]string{"users:write", "users:read"}))
+	if err != nil {
+		t.Fatalf("NewRole: %v", err)
+	}
+	r.CreatedAt, r.UpdatedAt = at, at
+
+	got := ToRoleResponse(r)
+	want := RoleResponse{
+		Name:        "support",
+		Description: "Atendimento",
+		Permissions: []string{"users:read", "users:write"},
+		CreatedAt:   "2025-01-02T06:04:05Z",
+		UpdatedAt:   "2025-01-02T06:04:05Z",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
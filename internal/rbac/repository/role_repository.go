@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+)
+
+var (
+	ErrNotFound      = errors.New("role not found")
+	ErrAlreadyExists = errors.New("role already exists")
+)
+
+// RoleRepository guarda o catálogo de papéis e resolve permissões efetivas
+type RoleRepository interface {
+	Create(ctx context.Context, r domain.Role) (domain.Role, error)
+	Get(ctx context.Context, name string) (domain.Role, bool, error)
+	List(ctx context.Context) ([]domain.Role, error)
+	Update(ctx context.Context, r domain.Role) (domain.Role, error)
+	Delete(ctx context.Context, name string) error
+	// RoleExists informa se o papel pode ser atribuído a usuários
+	RoleExists(ctx context.Context, name string) (bool, error)
+	// Permissions une as permissões dos papéis; papéis desconhecidos não concedem nada
+	Permissions(ctx context.Context, roles []string) (domain.PermissionSet, error)
+}
+
+type inMemoryRoleRepo struct {
+	mu    sync.RWMutex
+	roles map[string]domain.Role
+	now   func() time.Time
+}
+
+// Option configura o repositório em memória
+type Option func(*inMemoryRoleRepo)
+
+// WithClock substitui o relógio usado para as datas de criação e alteração
+func WithClock(now func() time.Time) Option {
+	return func(r *inMemoryRoleRepo) {
+		r.now = now
+	}
+}
+
+// NewInMemoryRoleRepository cria o catálogo já com os papéis embutidos
+func NewInMemoryRoleRepository(opts ...Option) RoleRepository {
+	r := &inMemoryRoleRepo{
+		roles: make(map[string]domain.Role),
+		now:   time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	now := r.timestamp()
+	for _, role := range domain.BuiltInRoles() {
+		role.CreatedAt = now
+		role.UpdatedAt = now
+		r.roles[role.Name] = role
+	}
+	return r
+}
+
+func (r *inMemoryRoleRepo) timestamp() time.Time {
+	return r.now().UTC().Truncate(time.Second)
+}
+
+// clone evita que quem chama altere as permissões guardadas
+func clone(role domain.Role) domain.Role {
+	role.Permissions = append([]domain.Permission(nil), role.Permissions...)
+	return role
+}
+
+func (r *inMemoryRoleRepo) Create(ctx context.Context, role domain.Role) (domain.Role, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Role{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.roles[role.Name]; ok {
+		return domain.Role{}, ErrAlreadyExists
+	}
+
+	now := r.timestamp()
+	role.BuiltIn = false
+	role.CreatedAt = now
+	role.UpdatedAt = now
+	r.roles[role.Name] = clone(role)
+	return clone(role), nil
+}
+
+func (r *inMemoryRoleRepo) Get(ctx context.Context, name string) (domain.Role, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Role{}, false, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	role, ok := r.roles[name]
+	if !ok {
+		return domain.Role{}, false, nil
+	}
+	return clone(role), true, nil
+}
+
+func (r *inMemoryRoleRepo) List(ctx context.Context) ([]domain.Role, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	out := make([]domain.Role, 0, len(r.roles))
+	for _, role := range r.roles {
+		out = append(out, clone(role))
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// Update troca descrição e permissões; nome, origem e data de criação são preservados
+func (r *inMemoryRoleRepo) Update(ctx context.Context, role domain.Role) (domain.Role, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Role{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.roles[role.Name]
+	if !ok {
+		return domain.Role{}, ErrNotFound
+	}
+	if current.BuiltIn && current.Name == domain.RoleAdmin {
+		return domain.Role{}, domain.ErrBuiltInRole
+	}
+
+	role.BuiltIn = current.BuiltIn
+	role.CreatedAt = current.CreatedAt
+	role.UpdatedAt = r.timestamp()
+	r.roles[role.Name] = clone(role)
+	return clone(role), nil
+}
+
+// Delete remove papéis personalizados. Usuários que ainda o tenham deixam de
+// receber suas permissões, já que Permissions ignora papéis desconhecidos.
+func (r *inMemoryRoleRepo) Delete(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	role, ok := r.roles[name]
+	if !ok {
+		return ErrNotFound
+	}
+	if role.BuiltIn {
+		return domain.ErrBuiltInRole
+	}
+	delete(r.roles, name)
+	return nil
+}
+
+func (r *inMemoryRoleRepo) RoleExists(ctx context.Context, name string) (bool, error) {
+	_, ok, err := r.Get(ctx, name)
+	return ok, err
+}
+
+func (r *inMemoryRoleRepo) Permissions(ctx context.Context, roles []string) (domain.PermissionSet, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	set := make(domain.PermissionSet)
+	for _, name := range roles {
+		for _, p := range r.roles[name].Permissions {
+			set[p] = true
+		}
+	}
+	return set, nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+)
+
+func mustRole(t *testing.T, name string, perms ...domain.Permission) domain.Role {
+	t.Helper()
+	r, err := domain.NewRole(name, "", perms)
+	if err != nil {
+		t.Fatalf("NewRole: %v", err)
+	}
+	return r
+}
+
+func TestRoleRepository_SeededWithBuiltIns(t *testing.T) {
+	repo := NewInMemoryRoleRepository()
+	ctx := context.Background()
+
+	roles, err := repo.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(roles) != 2 || roles[0].Name != domain.RoleAdmin || roles[1].Name != domain.RoleUser {
+		t.Fatalf("roles: %+v", roles)
+	}
+
+	if err := repo.Delete(ctx, domain.RoleUser); err != domain.ErrBuiltInRole {
+		t.Fatalf("Delete built-in: got %v", err)
+	}
+	admin, _, _ := repo.Get(ctx, domain.RoleAdmin)
+	if _, err := repo.Update(ctx, admin); err != domain.ErrBuiltInRole {
+		t.Fatalf("Update admin: got %v", err)
+	}
+}
+
+func TestRoleRepository_CRUD(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := NewInMemoryRoleRepository(WithClock(func() time.Time { return now }))
+	ctx := context.Background()
+
+	created, err := repo.Create(ctx, mustRole(t, "support", domain.PermUsersRead))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !created.CreatedAt.Equal(now) || created.BuiltIn {
+		t.Fatalf("created: %+v", created)
+	}
+	if _, err := repo.Create(ctx, mustRole(t, "support")); err != ErrAlreadyExists {
+		t.Fatalf("duplicate Create: got %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	created.Permissions = append(created.Permissions, domain.PermUsersWrite)
+	updated, err := repo.Update(ctx, created)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if !updated.UpdatedAt.Equal(now) || updated.CreatedAt.Equal(now) || len(updated.Permissions) != 2 {
+		t.Fatalf("updated: %+v", updated)
+	}
+
+	if err := repo.Delete(ctx, "support"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := repo.Delete(ctx, "support"); err != ErrNotFound {
+		t.Fatalf("second Delete: got %v", err)
+	}
+	if ok, _ := repo.RoleExists(ctx, "support"); ok {
+		t.Fatalf("deleted role still exists")
+	}
+}
+
+func TestRoleRepository_PermissionsUnionAndStoredCopies(t *testing.T) {
+	repo := NewInMemoryRoleRepository()
+	ctx := context.Background()
+
+	support, err := repo.Create(ctx, mustRole(t, "support", domain.PermUsersRead))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	// Alterar a cópia devolvida não pode mudar o catálogo
+	support.Permissions[0] = domain.PermRolesWrite
+
+	set, err := repo.Permissions(ctx, []string{domain.RoleUser, "support", "ghost"})
+	if err != nil {
+		t.Fatalf("Permissions: %v", err)
+	}
+	if len(set) != 1 || !set.Has(domain.PermUsersRead) {
+		t.Fatalf("permissions: %v", set)
+	}
+
+	set, _ = repo.Permissions(ctx, []string{domain.RoleAdmin})
+	for _, p := range domain.KnownPermissions {
+		if !set.Has(p) {
+			t.Fatalf("admin is missing %s", p)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package rbac_router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	"github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+	rbac_handler "github.com/williamkoller/cloud-architecture-golang/internal/rbac/handler"
+)
+
+// RouteOption configura o registro das rotas de papéis
+type RouteOption func(*routeConfig)
+
+type routeConfig struct {
+	resolver auth.PermissionResolver
+}
+
+// WithPermissionGuards exige roles:read para consultas e roles:write para
+// alterações. O catálogo é compartilhado por todas as organizações, então
+// alterá-lo também exige um principal da plataforma (auth.RequirePlatform).
+func WithPermissionGuards(resolver auth.PermissionResolver) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.resolver = resolver
+	}
+}
+
+func (cfg routeConfig) guard(perm domain.Permission, h gin.HandlerFunc) []gin.HandlerFunc {
+	if cfg.resolver == nil {
+		return []gin.HandlerFunc{h}
+	}
+	return []gin.HandlerFunc{auth.RequirePermission(cfg.resolver, perm), h}
+}
+
+// platformGuard é o guard das alterações no catálogo compartilhado
+func (cfg routeConfig) platformGuard(perm domain.Permission, h gin.HandlerFunc) []gin.HandlerFunc {
+	if cfg.resolver == nil {
+		return []gin.HandlerFunc{h}
+	}
+	return []gin.HandlerFunc{auth.RequirePermission(cfg.resolver, perm), auth.RequirePlatform(), h}
+}
+
+// RegisterRoleRoutes registra a administração do catálogo de papéis
+func RegisterRoleRoutes(group *gin.RouterGroup, h *rbac_handler.RoleHandler, opts ...RouteOption) {
+	var cfg routeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	roles := group.Group("/roles")
+	{
+		roles.POST("", cfg.platformGuard(domain.PermRolesWrite, h.CreateRole)...)
+		roles.GET("", cfg.guard(domain.PermRolesRead, h.ListRoles)...)
+		roles.GET("/:name", cfg.guard(domain.PermRolesRead, h.GetRole)...)
+		roles.PATCH("/:name", cfg.platformGuard(domain.PermRolesWrite, h.UpdateRole)...)
+		roles.DELETE("/:name", cfg.platformGuard(domain.PermRolesWrite, h.DeleteRole)...)
+	}
+}
@@ -0,0 +1,114 @@
+package rbac_router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	rbac_handler "github.com/williamkoller/cloud-architecture-golang/internal/rbac/handler"
+	"github.com/williamkoller/cloud-architecture-golang/internal/rbac/repository"
+)
+
+func TestRegisterRoleRoutes_RegistersAllExpectedRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	RegisterRoleRoutes(r.Group("/api/v1"), &rbac_handler.RoleHandler{})
+
+	expected := map[string]string{
+		"POST /api/v1/roles":         ".CreateRole",
+		"GET /api/v1/roles":          ".ListRoles",
+		"GET /api/v1/roles/:name":    ".GetRole",
+		"PATCH /api/v1/roles/:name":  ".UpdateRole",
+		"DELETE /api/v1/roles/:name": ".DeleteRole",
+	}
+	found := 0
+	for _, ri := range r.Routes() {
+		want, ok := expected[ri.Method+" "+ri.Path]
+		if !ok {
+			continue
+		}
+		found++
+		if !strings.Contains(ri.Handler, want) {
+			t.Fatalf("handler mismatch for %s %s: got %q, want %q", ri.Method, ri.Path, ri.Handler, want)
+		}
+	}
+	if found != len(expected) {
+		t.Fatalf("found %d of %d routes", found, len(expected))
+	}
+}
+
+func TestRegisterRoleRoutes_WithPermissionGuards(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	roles := repository.NewInMemoryRoleRepository()
+	r := gin.New()
+	api := r.Group("/api/v1")
+	api.Use(func(c *gin.Context) {
+		if role := c.GetHeader("X-Test-Role"); role != "" {
+			auth.SetPrincipal(c, auth.Principal{UserID: "u1", Roles: []string{role}})
+		}
+		c.Next()
+	})
+	RegisterRoleRoutes(api, rbac_handler.NewRoleHandler(roles), WithPermissionGuards(roles))
+
+	cases := []struct {
+		role string
+		want int
+	}{
+		{"", http.StatusUnauthorized},
+		{"user", http.StatusForbidden},
+		{"admin", http.StatusOK},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/roles", nil)
+		req.Header.Set("X-Test-Role", tc.role)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Fatalf("role %q: got %d, want %d", tc.role, w.Code, tc.want)
+		}
+	}
+}
+
+func TestRegisterRoleRoutes_SharedCatalogIsPlatformOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	roles := repository.NewInMemoryRoleRepository()
+	r := gin.New()
+	api := r.Group("/api/v1")
+	api.Use(func(c *gin.Context) {
+		auth.SetPrincipal(c, auth.Principal{UserID: "u1", Roles: []string{"admin"}, OrgID: c.GetHeader("X-Test-Org")})
+		c.Next()
+	})
+	RegisterRoleRoutes(api, rbac_handler.NewRoleHandler(roles), WithPermissionGuards(roles))
+
+	cases := []struct {
+		org, method, path, body string
+		want                    int
+	}{
+		{"acme", http.MethodGet, "/api/v1/roles", "", http.StatusOK},
+		{"acme", http.MethodPatch, "/api/v1/roles/user", `{"permissions":["users:write"]}`, http.StatusForbidden},
+		{"acme", http.MethodPost, "/api/v1/roles", `{"name":"auditor","permissions":["users:read"]}`, http.StatusForbidden},
+		{"acme", http.MethodDelete, "/api/v1/roles/auditor", "", http.StatusForbidden},
+		{"default", http.MethodPost, "/api/v1/roles", `{"name":"auditor","permissions":["users:read"]}`, http.StatusCreated},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Test-Org", tc.org)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Fatalf("%s %s as %s: got %d, want %d body=%s", tc.method, tc.path, tc.org, w.Code, tc.want, w.Body.String())
+		}
+	}
+	if role, _, _ := roles.Get(context.Background(), "user"); len(role.Permissions) != 0 {
+		t.Fatalf("tenant admin changed the shared user role: %+v", role)
+	}
+}
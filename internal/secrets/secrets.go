@@ -0,0 +1,117 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var ErrNotFound = errors.New("secret not found")
+
+// Provider lê segredos pelo nome. Local e Lambda usam os mesmos nomes; só
+// muda de onde o valor vem.
+type Provider interface {
+	Secret(ctx context.Context, name string) ([]byte, error)
+}
+
+// Env lê o segredo da variável de ambiente com o nome informado
+type Env struct{}
+
+func (Env) Secret(ctx context.Context, name string) ([]byte, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok || v == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return []byte(v), nil
+}
+
+// Files lê o segredo de um arquivo; nomes relativos são resolvidos em Dir
+type Files struct {
+	Dir string
+}
+
+func (f Files) Secret(ctx context.Context, name string) ([]byte, error) {
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(f.Dir, name)
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return data, err
+}
+
+// LambdaExtension lê do Secrets Manager pela extensão AWS Parameters and
+// Secrets do Lambda, que guarda os valores em cache no próprio ambiente de
+// execução. O nome é o secretId (nome ou ARN).
+type LambdaExtension struct {
+	Endpoint string
+	Token    string
+	Client   *http.Client
+}
+
+// NewLambdaExtension usa a porta da extensão (PARAMETERS_SECRETS_EXTENSION_HTTP_PORT,
+// padrão 2773) e o token da sessão do Lambda
+func NewLambdaExtension() LambdaExtension {
+	port := os.Getenv("PARAMETERS_SECRETS_EXTENSION_HTTP_PORT")
+	if port == "" {
+		port = "2773"
+	}
+	return LambdaExtension{
+		Endpoint: "http://localhost:" + port,
+		Token:    os.Getenv("AWS_SESSION_TOKEN"),
+		Client:   &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (l LambdaExtension) Secret(ctx context.Context, name string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		l.Endpoint+"/secretsmanager/get?secretId="+url.QueryEscape(name), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Aws-Parameters-Secrets-Token", l.Token)
+
+	client := l.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusNotFound || strings.Contains(string(body), "ResourceNotFoundException") {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return nil, fmt.Errorf("secrets extension: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var out struct {
+		SecretString *string `json:"SecretString"`
+		SecretBinary string  `json:"SecretBinary"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("secrets extension: %w", err)
+	}
+	if out.SecretString != nil {
+		return []byte(*out.SecretString), nil
+	}
+	return base64.StdEncoding.DecodeString(out.SecretBinary)
+}
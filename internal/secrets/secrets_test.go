@@ -0,0 +1,71 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvAndFiles(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("TEST_SECRET", "s3cr3t")
+
+	if got, err := (Env{}).Secret(ctx, "TEST_SECRET"); err != nil || string(got) != "s3cr3t" {
+		t.Fatalf("Env: got %q, %v", got, err)
+	}
+	if _, err := (Env{}).Secret(ctx, "TEST_SECRET_MISSING"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Env missing: got %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "jwt.pem"), []byte("pem"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	files := Files{Dir: dir}
+	if got, err := files.Secret(ctx, "jwt.pem"); err != nil || string(got) != "pem" {
+		t.Fatalf("Files relative: got %q, %v", got, err)
+	}
+	if got, err := files.Secret(ctx, filepath.Join(dir, "jwt.pem")); err != nil || string(got) != "pem" {
+		t.Fatalf("Files absolute: got %q, %v", got, err)
+	}
+	if _, err := files.Secret(ctx, "missing.pem"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Files missing: got %v", err)
+	}
+}
+
+func TestLambdaExtension(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Aws-Parameters-Secrets-Token") != "session" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Query().Get("secretId") {
+		case "jwt/signing-key":
+			_, _ = w.Write([]byte(`{"Name":"jwt/signing-key","SecretString":"pem"}`))
+		case "binary":
+			_, _ = w.Write([]byte(`{"SecretBinary":"cGVt"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`ResourceNotFoundException: secret not found`))
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	ext := LambdaExtension{Endpoint: srv.URL, Token: "session"}
+	for _, name := range []string{"jwt/signing-key", "binary"} {
+		if got, err := ext.Secret(ctx, name); err != nil || string(got) != "pem" {
+			t.Fatalf("%s: got %q, %v", name, got, err)
+		}
+	}
+	if _, err := ext.Secret(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing: got %v", err)
+	}
+	if _, err := (LambdaExtension{Endpoint: srv.URL}).Secret(ctx, "jwt/signing-key"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("without token: got %v", err)
+	}
+}
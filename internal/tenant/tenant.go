@@ -0,0 +1,143 @@
+package tenant
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	org "github.com/williamkoller/cloud-architecture-golang/internal/org/domain"
+)
+
+// DefaultHeader é o header usado quando Resolver.Header não é informado
+const DefaultHeader = "X-Org-ID"
+
+type (
+	tenantKey       struct{}
+	signupClosedKey struct{}
+)
+
+// WithOrg anexa a organização resolvida ao contexto
+func WithOrg(ctx context.Context, orgID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, orgID)
+}
+
+// OrgFrom devolve a organização da requisição, se o middleware a resolveu
+func OrgFrom(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(tenantKey{}).(string)
+	return id, ok && id != ""
+}
+
+// SignupClosed indica que a organização foi escolhida por uma requisição
+// anônima (header ou rota) e não aceita cadastro anônimo (ver org.SelfSignup)
+func SignupClosed(ctx context.Context) bool {
+	closed, _ := ctx.Value(signupClosedKey{}).(bool)
+	return closed
+}
+
+// Directory consulta as organizações cadastradas
+type Directory interface {
+	Get(ctx context.Context, id string) (org.Organization, bool, error)
+}
+
+// Resolver descobre a organização da requisição, nesta ordem: parâmetro :org
+// da rota, header, subdomínio de BaseDomain e, por fim, a claim org do principal.
+// Sem nenhuma delas vale Default; Default vazio torna a organização obrigatória.
+type Resolver struct {
+	Header     string
+	BaseDomain string
+	Default    string
+}
+
+// explicit devolve a organização pedida pela própria requisição e se ela veio
+// do subdomínio
+func (r Resolver) explicit(c *gin.Context) (string, bool) {
+	if id := c.Param("org"); id != "" {
+		return strings.ToLower(id), false
+	}
+
+	header := r.Header
+	if header == "" {
+		header = DefaultHeader
+	}
+	if id := strings.TrimSpace(c.GetHeader(header)); id != "" {
+		return strings.ToLower(id), false
+	}
+
+	sub := r.subdomain(c.Request.Host)
+	return sub, sub != ""
+}
+
+// subdomain extrai "acme" de "acme.api.example.com" quando BaseDomain é "api.example.com"
+func (r Resolver) subdomain(host string) string {
+	if r.BaseDomain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+	suffix := "." + strings.ToLower(r.BaseDomain)
+	if !strings.HasSuffix(host, suffix) {
+		return ""
+	}
+	sub := strings.TrimSuffix(host, suffix)
+	if strings.Contains(sub, ".") {
+		return ""
+	}
+	return sub
+}
+
+// Middleware resolve a organização, confere se existe e está ativa e a anexa
+// ao contexto. Uma credencial restrita a uma organização não acessa outra (403).
+// Requisições anônimas que escolhem pelo header ou pela rota uma organização
+// fechada ao cadastro anônimo ficam marcadas (SignupClosed).
+func Middleware(r Resolver, dir Directory) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, fromSubdomain := r.explicit(c)
+
+		p, authenticated := auth.PrincipalFrom(c.Request.Context())
+		if authenticated && p.OrgID != "" {
+			if id != "" && id != p.OrgID {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "credential does not belong to organization " + id})
+				return
+			}
+			id = p.OrgID
+		}
+		if id == "" {
+			id = r.Default
+		}
+		if id == "" {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "organization is required"})
+			return
+		}
+		if !org.ValidOrgID(id) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": org.ErrInvalidOrgID.Error()})
+			return
+		}
+
+		o, ok, err := dir.Get(c.Request.Context(), id)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+			return
+		}
+		if !o.Active {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "organization is inactive"})
+			return
+		}
+
+		ctx := WithOrg(c.Request.Context(), o.ID)
+		if !authenticated && !fromSubdomain && o.ID != r.Default && !o.SelfSignup {
+			ctx = context.WithValue(ctx, signupClosedKey{}, true)
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,158 @@
+package tenant
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	org "github.com/williamkoller/cloud-architecture-golang/internal/org/domain"
+)
+
+type directory map[string]org.Organization
+
+func (d directory) Get(ctx context.Context, id string) (org.Organization, bool, error) {
+	o, ok := d[id]
+	return o, ok, nil
+}
+
+func TestMiddleware_ResolvesOrganization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	dir := directory{
+		"default": {ID: "default", Active: true},
+		"acme":    {ID: "acme", Active: true},
+		"globex":  {ID: "globex", Active: true},
+		"closed":  {ID: "closed", Active: false},
+	}
+	res := Resolver{BaseDomain: "api.example.com", Default: "default"}
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if claim := c.GetHeader("X-Test-Claim"); claim != "" {
+			auth.SetPrincipal(c, auth.Principal{UserID: "u1", OrgID: claim})
+		}
+		c.Next()
+	})
+	handler := func(c *gin.Context) {
+		id, _ := OrgFrom(c.Request.Context())
+		c.String(http.StatusOK, id)
+	}
+	r.GET("/users", Middleware(res, dir), handler)
+	r.GET("/orgs/:org/users", Middleware(res, dir), handler)
+
+	cases := []struct {
+		name    string
+		path    string
+		host    string
+		headers map[string]string
+		want    int
+		wantOrg string
+	}{
+		{"default", "/users", "", nil, http.StatusOK, "default"},
+		{"path", "/orgs/acme/users", "", nil, http.StatusOK, "acme"},
+		{"path wins over header", "/orgs/acme/users", "", map[string]string{"X-Org-ID": "globex"}, http.StatusOK, "acme"},
+		{"header", "/users", "", map[string]string{"X-Org-ID": "ACME"}, http.StatusOK, "acme"},
+		{"subdomain", "/users", "globex.api.example.com:443", nil, http.StatusOK, "globex"},
+		{"foreign host ignored", "/users", "globex.other.com", nil, http.StatusOK, "default"},
+		{"claim", "/users", "", map[string]string{"X-Test-Claim": "acme"}, http.StatusOK, "acme"},
+		{"claim matches path", "/orgs/acme/users", "", map[string]string{"X-Test-Claim": "acme"}, http.StatusOK, "acme"},
+		{"claim conflicts with path", "/orgs/globex/users", "", map[string]string{"X-Test-Claim": "acme"}, http.StatusForbidden, ""},
+		{"unknown", "/orgs/ghost/users", "", nil, http.StatusNotFound, ""},
+		{"inactive", "/orgs/closed/users", "", nil, http.StatusForbidden, ""},
+		{"invalid", "/users", "", map[string]string{"X-Org-ID": "a.b"}, http.StatusBadRequest, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.host != "" {
+				req.Host = tc.host
+			}
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tc.want {
+				t.Fatalf("status: got %d, want %d body=%s", w.Code, tc.want, w.Body.String())
+			}
+			if tc.want == http.StatusOK && w.Body.String() != tc.wantOrg {
+				t.Fatalf("org: got %q, want %q", w.Body.String(), tc.wantOrg)
+			}
+		})
+	}
+}
+
+func TestMiddleware_RequiresOrganizationWithoutDefault(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/users", Middleware(Resolver{}, directory{}), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status: got %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestMiddleware_MarksOrganizationsClosedToAnonymousSignup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	dir := directory{
+		"default": {ID: "default", Active: true},
+		"acme":    {ID: "acme", Active: true},
+		"open":    {ID: "open", Active: true, SelfSignup: true},
+	}
+	res := Resolver{BaseDomain: "api.example.com", Default: "default"}
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if c.GetHeader("X-Test-Auth") != "" {
+			auth.SetPrincipal(c, auth.Principal{UserID: "u1"})
+		}
+		c.Next()
+	})
+	handler := func(c *gin.Context) {
+		if SignupClosed(c.Request.Context()) {
+			c.String(http.StatusOK, "closed")
+			return
+		}
+		c.String(http.StatusOK, "open")
+	}
+	r.POST("/users", Middleware(res, dir), handler)
+	r.POST("/orgs/:org/users", Middleware(res, dir), handler)
+
+	cases := []struct {
+		name    string
+		path    string
+		host    string
+		headers map[string]string
+		want    string
+	}{
+		{"default", "/users", "", nil, "open"},
+		{"bare header", "/users", "", map[string]string{"X-Org-ID": "acme"}, "closed"},
+		{"path", "/orgs/acme/users", "", nil, "closed"},
+		{"subdomain", "/users", "acme.api.example.com", nil, "open"},
+		{"org open to signup", "/users", "", map[string]string{"X-Org-ID": "open"}, "open"},
+		{"authenticated", "/users", "", map[string]string{"X-Org-ID": "acme", "X-Test-Auth": "1"}, "open"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.path, nil)
+			if tc.host != "" {
+				req.Host = tc.host
+			}
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusOK || w.Body.String() != tc.want {
+				t.Fatalf("got %d %q, want %q", w.Code, w.Body.String(), tc.want)
+			}
+		})
+	}
+}
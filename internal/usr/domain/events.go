@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+)
+
+// Nomes dos eventos, usados para assinar tipos específicos
+const (
+	EventUserCreated                = "user.created"
+	EventUserRenamed                = "user.renamed"
+	EventUserEmailChanged           = "user.email_changed"
+	EventUserEmailVerified          = "user.email_verified"
+	EventUserPasswordChanged        = "user.password_changed"
+	EventUserPasswordResetRequested = "user.password_reset_requested"
+	EventUserSessionsRevoked        = "user.sessions_revoked"
+	EventUserActivated              = "user.activated"
+	EventUserDeactivated            = "user.deactivated"
+	EventUserStatusChanged          = "user.status_changed"
+	EventUserTypeChanged            = "user.type_changed"
+	EventUserRoleAssigned           = "user.role_assigned"
+	EventUserRoleRevoked            = "user.role_revoked"
+	EventUserDeleted                = "user.deleted"
+	EventUserRestored               = "user.restored"
+)
+
+// Event é um fato ocorrido no agregado User
+type Event interface {
+	EventName() string
+	AggregateID() vo.UserID
+	OccurredAt() time.Time
+}
+
+// EventMeta são os dados comuns a todos os eventos
+type EventMeta struct {
+	UserID vo.UserID `json:"userId"`
+	At     time.Time `json:"occurredAt"`
+}
+
+func (m EventMeta) AggregateID() vo.UserID { return m.UserID }
+func (m EventMeta) OccurredAt() time.Time  { return m.At }
+
+type UserCreated struct {
+	EventMeta
+	Email    vo.Email `json:"email"`
+	UserType UserType `json:"userType"`
+	Active   bool     `json:"active"`
+	Status   Status   `json:"status"`
+}
+
+func (UserCreated) EventName() string { return EventUserCreated }
+
+type UserRenamed struct {
+	EventMeta
+	Previous string `json:"previous"`
+	Name     string `json:"name"`
+}
+
+func (UserRenamed) EventName() string { return EventUserRenamed }
+
+type UserEmailChanged struct {
+	EventMeta
+	Previous vo.Email `json:"previous"`
+	Email    vo.Email `json:"email"`
+}
+
+func (UserEmailChanged) EventName() string { return EventUserEmailChanged }
+
+type UserEmailVerified struct {
+	EventMeta
+	Email vo.Email `json:"email"`
+}
+
+func (UserEmailVerified) EventName() string { return EventUserEmailVerified }
+
+// UserPasswordChanged não carrega a senha nem o hash
+type UserPasswordChanged struct {
+	EventMeta
+}
+
+func (UserPasswordChanged) EventName() string { return EventUserPasswordChanged }
+
+// UserPasswordResetRequested pede o link de redefinição. O token é emitido
+// pelo assinante, fora da requisição, e nunca viaja no evento.
+type UserPasswordResetRequested struct {
+	EventMeta
+	Email vo.Email `json:"email"`
+}
+
+func (UserPasswordResetRequested) EventName() string { return EventUserPasswordResetRequested }
+
+// UserSessionsRevoked indica que as credenciais emitidas até OccurredAt não valem mais
+type UserSessionsRevoked struct {
+	EventMeta
+}
+
+func (UserSessionsRevoked) EventName() string { return EventUserSessionsRevoked }
+
+type UserActivated struct {
+	EventMeta
+}
+
+func (UserActivated) EventName() string { return EventUserActivated }
+
+type UserDeactivated struct {
+	EventMeta
+}
+
+func (UserDeactivated) EventName() string { return EventUserDeactivated }
+
+// UserStatusChanged é emitido a cada transição da máquina de estados
+type UserStatusChanged struct {
+	EventMeta
+	Previous Status `json:"previous"`
+	Status   Status `json:"status"`
+	Reason   string `json:"reason,omitempty"`
+	Actor    string `json:"actor,omitempty"`
+}
+
+func (UserStatusChanged) EventName() string { return EventUserStatusChanged }
+
+type UserTypeChanged struct {
+	EventMeta
+	Previous UserType `json:"previous"`
+	UserType UserType `json:"userType"`
+}
+
+func (UserTypeChanged) EventName() string { return EventUserTypeChanged }
+
+type UserRoleAssigned struct {
+	EventMeta
+	Role string `json:"role"`
+}
+
+func (UserRoleAssigned) EventName() string { return EventUserRoleAssigned }
+
+type UserRoleRevoked struct {
+	EventMeta
+	Role string `json:"role"`
+}
+
+func (UserRoleRevoked) EventName() string { return EventUserRoleRevoked }
+
+type UserDeleted struct {
+	EventMeta
+	Email vo.Email `json:"email"`
+}
+
+func (UserDeleted) EventName() string { return EventUserDeleted }
+
+type UserRestored struct {
+	EventMeta
+}
+
+func (UserRestored) EventName() string { return EventUserRestored }
+
+// meta monta os dados comuns de um evento do usuário
+func (u *User) meta(at time.Time) EventMeta {
+	if at.IsZero() {
+		at = time.Now()
+	}
+	return EventMeta{UserID: u.ID, At: at.UTC()}
+}
+
+// record acumula o evento até que alguém o retire com PullEvents
+func (u *User) record(e Event) {
+	// Expressão de fatia completa: cópias do User não compartilham o append
+	u.events = append(u.events[:len(u.events):len(u.events)], e)
+}
+
+// PullEvents devolve os eventos pendentes e os remove do usuário
+func (u *User) PullEvents() []Event {
+	evts := u.events
+	u.events = nil
+	return evts
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func eventNames(evts []Event) []string {
+	names := make([]string, len(evts))
+	for i, e := range evts {
+		names[i] = e.EventName()
+	}
+	return names
+}
+
+func TestNewUser_RecordsUserCreated(t *testing.T) {
+	u, err := NewUser("Ana", "ana@example.com", "secret123", true, UserTypeUser)
+	if err != nil {
+		t.Fatalf("NewUser error: %v", err)
+	}
+
+	evts := u.PullEvents()
+	if len(evts) != 1 {
+		t.Fatalf("events: got %v, want one user.created", eventNames(evts))
+	}
+	created, ok := evts[0].(UserCreated)
+	if !ok {
+		t.Fatalf("event type: got %T, want UserCreated", evts[0])
+	}
+	if created.AggregateID() != u.ID || created.Email != u.Email || created.OccurredAt().IsZero() {
+		t.Fatalf("unexpected event: %+v", created)
+	}
+
+	if rest := u.PullEvents(); len(rest) != 0 {
+		t.Fatalf("PullEvents should clear pending events, got %v", eventNames(rest))
+	}
+}
+
+func TestUser_BehaviourMethods_RecordEventsOnlyOnChange(t *testing.T) {
+	u, err := NewUser("Ana", "ana@example.com", "secret123", true, UserTypeUser)
+	if err != nil {
+		t.Fatalf("NewUser error: %v", err)
+	}
+	u.PullEvents()
+
+	// Sem mudança de estado: nenhum evento
+	_ = u.Rename("Ana")
+	_ = u.ChangeType(UserTypeUser)
+	_ = u.Activate("")
+	if evts := u.PullEvents(); len(evts) != 0 {
+		t.Fatalf("no-op changes recorded events: %v", eventNames(evts))
+	}
+
+	_ = u.Rename("Ana Paula")
+	_ = u.ChangeType(UserTypeAdmin)
+	_ = u.Deactivate("")
+	_ = u.ChangePassword("n3w-s3cret")
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	u.MarkDeleted(at)
+	u.MarkDeleted(at)
+	u.Restore(at)
+
+	got := eventNames(u.PullEvents())
+	want := []string{
+		EventUserRenamed, EventUserTypeChanged, EventUserStatusChanged, EventUserDeactivated,
+		EventUserPasswordChanged, EventUserDeleted, EventUserRestored,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("events: got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("events: got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestUser_EventsAreNotSharedBetweenCopies(t *testing.T) {
+	u, err := NewUser("Ana", "ana@example.com", "secret123", true, UserTypeUser)
+	if err != nil {
+		t.Fatalf("NewUser error: %v", err)
+	}
+
+	a, b := u, u
+	_ = a.Rename("Ana A")
+	_ = b.Rename("Ana B")
+
+	evA, evB := a.PullEvents(), b.PullEvents()
+	if len(evA) != 2 || len(evB) != 2 {
+		t.Fatalf("events: a=%v b=%v", eventNames(evA), eventNames(evB))
+	}
+	if evA[1].(UserRenamed).Name != "Ana A" || evB[1].(UserRenamed).Name != "Ana B" {
+		t.Fatalf("copies overwrote each other's events: a=%+v b=%+v", evA[1], evB[1])
+	}
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+)
+
+var ErrBaseRoleRequired = errors.New("the user role cannot be revoked")
+
+// rolesForType devolve os papéis iniciais: todo usuário tem o papel base e
+// administradores recebem também o papel admin
+func rolesForType(t UserType) []string {
+	if t == UserTypeAdmin {
+		return []string{rbac.RoleAdmin, rbac.RoleUser}
+	}
+	return []string{rbac.RoleUser}
+}
+
+// typeForRoles calcula a projeção de UserType a partir dos papéis
+func typeForRoles(roles []string) UserType {
+	if slices.Contains(roles, rbac.RoleAdmin) {
+		return UserTypeAdmin
+	}
+	return UserTypeUser
+}
+
+// withRole devolve uma nova slice ordenada contendo o papel
+func withRole(roles []string, role string) []string {
+	out := append([]string(nil), roles...)
+	if !slices.Contains(out, role) {
+		out = append(out, role)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// withoutRole devolve uma nova slice sem o papel
+func withoutRole(roles []string, role string) []string {
+	out := make([]string, 0, len(roles))
+	for _, r := range roles {
+		if r != role {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// EffectiveRoles devolve os papéis do usuário. Registros anteriores aos papéis
+// só têm UserType, então os papéis são derivados dele.
+func (u User) EffectiveRoles() []string {
+	if len(u.Roles) == 0 {
+		return rolesForType(u.UserType)
+	}
+	return append([]string(nil), u.Roles...)
+}
+
+// HasRole indica se o usuário tem o papel
+func (u User) HasRole(role string) bool {
+	return slices.Contains(u.EffectiveRoles(), role)
+}
+
+// AssignRole concede o papel e atualiza a projeção de UserType
+func (u *User) AssignRole(role string) error {
+	if !rbac.ValidRoleName(role) {
+		return rbac.ErrInvalidRoleName
+	}
+	if u.HasRole(role) {
+		return nil
+	}
+
+	u.Roles = withRole(u.EffectiveRoles(), role)
+	u.record(UserRoleAssigned{EventMeta: u.meta(time.Time{}), Role: role})
+	u.syncUserType()
+	return nil
+}
+
+// RevokeRole retira o papel; o papel base não pode ser retirado
+func (u *User) RevokeRole(role string) error {
+	if role == rbac.RoleUser {
+		return ErrBaseRoleRequired
+	}
+	if !u.HasRole(role) {
+		return nil
+	}
+
+	u.Roles = withoutRole(u.EffectiveRoles(), role)
+	u.record(UserRoleRevoked{EventMeta: u.meta(time.Time{}), Role: role})
+	u.syncUserType()
+	return nil
+}
+
+// syncUserType mantém a projeção e registra a mudança de tipo, se houver
+func (u *User) syncUserType() {
+	t := typeForRoles(u.Roles)
+	if t == u.UserType {
+		return
+	}
+	u.record(UserTypeChanged{EventMeta: u.meta(time.Time{}), Previous: u.UserType, UserType: t})
+	u.UserType = t
+}
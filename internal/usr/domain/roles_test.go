@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+)
+
+func mustNewUser(t *testing.T, ut UserType) User {
+	t.Helper()
+	u, err := NewUser("Ana", "ana@example.com", "secret123", true, ut)
+	if err != nil {
+		t.Fatalf("NewUser error: %v", err)
+	}
+	return u
+}
+
+func TestUser_RolesFollowUserType(t *testing.T) {
+	u := mustNewUser(t, UserTypeAdmin)
+	if !reflect.DeepEqual(u.Roles, []string{rbac.RoleAdmin, rbac.RoleUser}) {
+		t.Fatalf("admin roles: %v", u.Roles)
+	}
+
+	// Registros antigos, sem papéis gravados, derivam os papéis do tipo
+	legacy := User{UserType: UserTypeUser}
+	if !reflect.DeepEqual(legacy.EffectiveRoles(), []string{rbac.RoleUser}) {
+		t.Fatalf("legacy roles: %v", legacy.EffectiveRoles())
+	}
+}
+
+func TestUser_AssignAndRevokeRole_KeepUserTypeProjection(t *testing.T) {
+	u := mustNewUser(t, UserTypeUser)
+	u.PullEvents()
+
+	if err := u.AssignRole("support"); err != nil {
+		t.Fatalf("AssignRole(support): %v", err)
+	}
+	if u.UserType != UserTypeUser {
+		t.Fatalf("custom role changed userType to %s", u.UserType)
+	}
+	if err := u.AssignRole(rbac.RoleAdmin); err != nil {
+		t.Fatalf("AssignRole(admin): %v", err)
+	}
+	if u.UserType != UserTypeAdmin {
+		t.Fatalf("userType after admin role: %s", u.UserType)
+	}
+	if err := u.AssignRole(rbac.RoleAdmin); err != nil {
+		t.Fatalf("repeated AssignRole: %v", err)
+	}
+
+	if err := u.RevokeRole(rbac.RoleAdmin); err != nil {
+		t.Fatalf("RevokeRole(admin): %v", err)
+	}
+	if u.UserType != UserTypeUser || !reflect.DeepEqual(u.Roles, []string{"support", rbac.RoleUser}) {
+		t.Fatalf("after revoke: type=%s roles=%v", u.UserType, u.Roles)
+	}
+	if err := u.RevokeRole(rbac.RoleUser); err != ErrBaseRoleRequired {
+		t.Fatalf("RevokeRole(user): got %v", err)
+	}
+	if err := u.AssignRole("Bad Name"); err != rbac.ErrInvalidRoleName {
+		t.Fatalf("AssignRole(invalid): got %v", err)
+	}
+
+	names := eventNames(u.PullEvents())
+	want := []string{
+		EventUserRoleAssigned,
+		EventUserRoleAssigned, EventUserTypeChanged,
+		EventUserRoleRevoked, EventUserTypeChanged,
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("events: got %v, want %v", names, want)
+	}
+}
+
+func TestUser_ChangeTypeUpdatesAdminRole(t *testing.T) {
+	u := mustNewUser(t, UserTypeUser)
+	_ = u.AssignRole("support")
+
+	if err := u.ChangeType(UserTypeAdmin); err != nil {
+		t.Fatalf("ChangeType: %v", err)
+	}
+	if !u.HasRole(rbac.RoleAdmin) || !u.HasRole("support") {
+		t.Fatalf("roles after promotion: %v", u.Roles)
+	}
+	if err := u.ChangeType(UserTypeUser); err != nil {
+		t.Fatalf("ChangeType: %v", err)
+	}
+	if u.HasRole(rbac.RoleAdmin) || !u.HasRole("support") {
+		t.Fatalf("roles after demotion: %v", u.Roles)
+	}
+}
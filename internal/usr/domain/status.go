@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Status é a situação da conta. Active é uma projeção mantida por
+// compatibilidade: true apenas em StatusActive.
+type Status string
+
+const (
+	StatusPendingVerification Status = "pending_verification"
+	StatusActive              Status = "active"
+	StatusSuspended           Status = "suspended"
+	StatusLocked              Status = "locked"
+	StatusDeactivated         Status = "deactivated"
+)
+
+var (
+	ErrInvalidStatus           = errors.New("status must be one of pending_verification, active, suspended, locked, deactivated")
+	ErrInvalidStatusTransition = errors.New("status transition not allowed")
+	ErrAccountNotActive        = errors.New("account is not active")
+)
+
+// transitions lista, para cada estado, os estados que podem vir em seguida
+var transitions = map[Status][]Status{
+	StatusPendingVerification: {StatusActive, StatusDeactivated},
+	StatusActive:              {StatusSuspended, StatusLocked, StatusDeactivated},
+	StatusSuspended:           {StatusActive, StatusDeactivated},
+	StatusLocked:              {StatusActive, StatusDeactivated},
+	StatusDeactivated:         {StatusActive},
+}
+
+// Valid indica se o status é um dos estados conhecidos
+func (s Status) Valid() bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// CanTransitionTo indica se a máquina de estados permite ir de s para to
+func (s Status) CanTransitionTo(to Status) bool {
+	for _, next := range transitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusChange registra uma transição: de onde, para onde, por quê e por quem
+type StatusChange struct {
+	From   Status
+	To     Status
+	Reason string
+	Actor  string // ID de quem fez a transição; vazio quando foi o sistema
+	At     time.Time
+}
+
+// EffectiveStatus devolve o status do usuário; registros anteriores à máquina
+// de estados não têm Status e o derivam de Active
+func (u User) EffectiveStatus() Status {
+	if u.Status != "" {
+		return u.Status
+	}
+	if u.Active {
+		return StatusActive
+	}
+	return StatusDeactivated
+}
+
+// ChangeStatus aplica uma transição da máquina de estados e a registra no
+// histórico. Active acompanha o novo status, e UserActivated/UserDeactivated
+// continuam sendo emitidos para quem assina apenas esses eventos.
+func (u *User) ChangeStatus(to Status, reason, actor string) error {
+	if !to.Valid() {
+		return ErrInvalidStatus
+	}
+	from := u.EffectiveStatus()
+	if from == to {
+		return nil
+	}
+	if !from.CanTransitionTo(to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, from, to)
+	}
+
+	meta := u.meta(time.Time{})
+	u.Status = to
+	u.StatusHistory = append(u.StatusHistory[:len(u.StatusHistory):len(u.StatusHistory)], StatusChange{
+		From:   from,
+		To:     to,
+		Reason: reason,
+		Actor:  actor,
+		At:     meta.At,
+	})
+	u.record(UserStatusChanged{EventMeta: meta, Previous: from, Status: to, Reason: reason, Actor: actor})
+
+	wasActive := u.Active
+	u.Active = to == StatusActive
+	switch {
+	case u.Active && !wasActive:
+		u.record(UserActivated{EventMeta: meta})
+	case !u.Active && wasActive:
+		u.record(UserDeactivated{EventMeta: meta})
+	}
+	return nil
+}
+
+// LastStatusChange devolve a transição mais recente, se houver
+func (u User) LastStatusChange() (StatusChange, bool) {
+	if len(u.StatusHistory) == 0 {
+		return StatusChange{}, false
+	}
+	return u.StatusHistory[len(u.StatusHistory)-1], true
+}
+
+// CanAuthenticate indica se o usuário pode fazer login: só contas ativas e não
+// excluídas. O erro traz o status, para quem já provou conhecer a senha.
+func (u User) CanAuthenticate() error {
+	if u.IsDeleted() {
+		return ErrAccountNotActive
+	}
+	if s := u.EffectiveStatus(); s != StatusActive {
+		return fmt.Errorf("%w: %s", ErrAccountNotActive, s)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStatus_NewUserDerivesFromActive(t *testing.T) {
+	if u := mustNewUser(t, UserTypeUser); u.Status != StatusActive {
+		t.Fatalf("active user: status %q", u.Status)
+	}
+	u, err := NewUser("Ana", "ana@example.com", "secret123", false, UserTypeUser)
+	if err != nil {
+		t.Fatalf("NewUser error: %v", err)
+	}
+	if u.Status != StatusDeactivated {
+		t.Fatalf("inactive user: status %q", u.Status)
+	}
+
+	// Registros antigos, sem status gravado, derivam o status de Active
+	if s := (User{Active: true}).EffectiveStatus(); s != StatusActive {
+		t.Fatalf("legacy active: %q", s)
+	}
+	if s := (User{}).EffectiveStatus(); s != StatusDeactivated {
+		t.Fatalf("legacy inactive: %q", s)
+	}
+}
+
+func TestStatus_TransitionsAreEnforced(t *testing.T) {
+	cases := []struct {
+		from, to Status
+		ok       bool
+	}{
+		{StatusPendingVerification, StatusActive, true},
+		{StatusPendingVerification, StatusSuspended, false},
+		{StatusActive, StatusSuspended, true},
+		{StatusActive, StatusLocked, true},
+		{StatusActive, StatusPendingVerification, false},
+		{StatusSuspended, StatusLocked, false},
+		{StatusSuspended, StatusActive, true},
+		{StatusLocked, StatusActive, true},
+		{StatusDeactivated, StatusSuspended, false},
+		{StatusDeactivated, StatusActive, true},
+	}
+	for _, tc := range cases {
+		u := mustNewUser(t, UserTypeUser)
+		u.Status = tc.from
+		err := u.ChangeStatus(tc.to, "", "")
+		if tc.ok && err != nil {
+			t.Fatalf("%s -> %s: unexpected error %v", tc.from, tc.to, err)
+		}
+		if !tc.ok && !errors.Is(err, ErrInvalidStatusTransition) {
+			t.Fatalf("%s -> %s: got %v, want ErrInvalidStatusTransition", tc.from, tc.to, err)
+		}
+		if !tc.ok && u.Status != tc.from {
+			t.Fatalf("%s -> %s: rejected transition changed status to %q", tc.from, tc.to, u.Status)
+		}
+	}
+
+	u := mustNewUser(t, UserTypeUser)
+	if err := u.ChangeStatus("banned", "", ""); err != ErrInvalidStatus {
+		t.Fatalf("unknown status: got %v", err)
+	}
+}
+
+func TestStatus_ChangeRecordsHistoryAndEvents(t *testing.T) {
+	u := mustNewUser(t, UserTypeUser)
+	u.PullEvents()
+
+	if err := u.ChangeStatus(StatusSuspended, "chargeback", "admin-1"); err != nil {
+		t.Fatalf("suspend: %v", err)
+	}
+	if u.Active {
+		t.Fatalf("suspended user still active")
+	}
+	last, ok := u.LastStatusChange()
+	if !ok || last.From != StatusActive || last.To != StatusSuspended || last.Reason != "chargeback" || last.Actor != "admin-1" || last.At.IsZero() {
+		t.Fatalf("history: %+v", u.StatusHistory)
+	}
+
+	evts := u.PullEvents()
+	if got := eventNames(evts); len(got) != 2 || got[0] != EventUserStatusChanged || got[1] != EventUserDeactivated {
+		t.Fatalf("events: %v", got)
+	}
+	changed := evts[0].(UserStatusChanged)
+	if changed.Previous != StatusActive || changed.Status != StatusSuspended || changed.Reason != "chargeback" || changed.Actor != "admin-1" {
+		t.Fatalf("event: %+v", changed)
+	}
+
+	// Mesmo status: nada muda
+	if err := u.ChangeStatus(StatusSuspended, "again", "admin-1"); err != nil || len(u.StatusHistory) != 1 || len(u.PullEvents()) != 0 {
+		t.Fatalf("no-op transition: err=%v history=%d", err, len(u.StatusHistory))
+	}
+}
+
+func TestStatus_ActivateOnlyLeavesDeactivated(t *testing.T) {
+	u := mustNewUser(t, UserTypeUser)
+	if err := u.ChangeStatus(StatusLocked, "too many attempts", ""); err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	if err := u.Activate("admin-1"); !errors.Is(err, ErrInvalidStatusTransition) {
+		t.Fatalf("Activate from locked: got %v", err)
+	}
+	if err := u.Deactivate("admin-1"); err != nil || u.Status != StatusDeactivated {
+		t.Fatalf("Deactivate from locked: err=%v status=%q", err, u.Status)
+	}
+	if err := u.Activate("admin-1"); err != nil || !u.Active {
+		t.Fatalf("Activate from deactivated: err=%v active=%v", err, u.Active)
+	}
+}
+
+func TestStatus_CanAuthenticateOnlyWhenActive(t *testing.T) {
+	u := mustNewUser(t, UserTypeUser)
+	if err := u.CanAuthenticate(); err != nil {
+		t.Fatalf("active user: %v", err)
+	}
+
+	if err := u.ChangeStatus(StatusSuspended, "", ""); err != nil {
+		t.Fatalf("suspend: %v", err)
+	}
+	if err := u.CanAuthenticate(); !errors.Is(err, ErrAccountNotActive) {
+		t.Fatalf("suspended user: got %v", err)
+	}
+
+	if err := u.ChangeStatus(StatusActive, "", ""); err != nil {
+		t.Fatalf("reactivate: %v", err)
+	}
+	u.MarkDeleted(time.Now())
+	if err := u.CanAuthenticate(); !errors.Is(err, ErrAccountNotActive) {
+		t.Fatalf("deleted user: got %v", err)
+	}
+}
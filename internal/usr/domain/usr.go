@@ -1,9 +1,12 @@
 package domain
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
 	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
 )
 
@@ -14,12 +17,64 @@ const (
 	UserTypeUser  UserType = "User"
 )
 
+var (
+	ErrNameRequired    = errors.New("name is required")
+	ErrInvalidUserType = errors.New("userType must be Admin or User")
+	// ErrEmailAlreadyVerified indica que o endereço atual já foi confirmado
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+)
+
+// Valid indica se o tipo é um dos tipos conhecidos
+func (t UserType) Valid() bool {
+	return t == UserTypeAdmin || t == UserTypeUser
+}
+
+// EmailChange registra um endereço anterior do usuário para auditoria
+type EmailChange struct {
+	Previous  vo.Email
+	ChangedAt time.Time
+}
+
 type User struct {
-	Name     string
-	Email    vo.Email
+	ID           vo.UserID
+	OrgID        string // organização dona do usuário; preenchida pelo repositório e imutável
+	Name         string
+	Email        vo.Email
+	DisplayEmail string
 	Password     vo.Password
-	Active   bool
-	UserType UserType
+	// Status é a situação da conta (ver ChangeStatus); Active é derivado dele
+	Status        Status
+	StatusHistory []StatusChange
+	Active        bool
+	// UserType é uma projeção dos papéis mantida por compatibilidade:
+	// Admin quando o usuário tem o papel admin, User caso contrário
+	UserType     UserType
+	Roles        []string
+	EmailHistory []EmailChange
+
+	// Version cresce a cada escrita e serve para controle de concorrência otimista
+	Version uint64
+
+	// Datas de ciclo de vida, mantidas pelo repositório
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	LastLoginAt   *time.Time
+	DeactivatedAt *time.Time
+	DeletedAt     *time.Time
+
+	// EmailVerifiedAt é quando o endereço atual foi confirmado (ver VerifyEmail);
+	// trocar o email o limpa
+	EmailVerifiedAt *time.Time
+	// SessionsRevokedAt invalida as credenciais emitidas antes dele (ver RevokeSessions)
+	SessionsRevokedAt *time.Time
+
+	// events guarda os eventos de domínio ainda não publicados (ver PullEvents)
+	events []Event
+}
+
+// IsDeleted indica se o usuário foi excluído logicamente
+func (u User) IsDeleted() bool {
+	return u.DeletedAt != nil
 }
 
 func (u User) Validate() error {
@@ -45,26 +100,212 @@ func (u User) Validate() error {
 }
 
 func NewUser(name, emailRaw, passRaw string, active bool, userType UserType) (User, error) {
-	email, err := vo.NewEmail(emailRaw)
+	status := StatusDeactivated
+	if active {
+		status = StatusActive
+	}
+	return newUser(name, emailRaw, passRaw, status, userType)
+}
+
+// NewPendingUser cria o usuário em pending_verification: ele só fica ativo
+// depois de confirmar o email (ver VerifyEmail)
+func NewPendingUser(name, emailRaw, passRaw string, userType UserType) (User, error) {
+	return newUser(name, emailRaw, passRaw, StatusPendingVerification, userType)
+}
+
+func newUser(name, emailRaw, passRaw string, status Status, userType UserType) (User, error) {
+	addr, err := vo.ParseEmail(emailRaw)
 	if err != nil {
 		return User{}, err
 	}
 
-	pass, err := vo.NewPassword(passRaw)
+	pass, err := vo.NewPassword(passRaw, name, string(addr.Email))
 	if err != nil {
 		return User{}, err
 	}
+
+	id, err := vo.NewUserID()
+	if err != nil {
+		return User{}, err
+	}
+
 	u := User{
-		Name:     name,
-		Email:    email,
+		ID:           id,
+		Name:         name,
+		Email:        addr.Email,
+		DisplayEmail: addr.Display,
 		Password:     pass,
-		Active:   active,
-		UserType: userType,
+		Active:       status == StatusActive,
+		Status:       status,
+		UserType:     userType,
+		Roles:        rolesForType(userType),
 	}
 
 	if err := u.Validate(); err != nil {
 		return User{}, err
 	}
 
+	u.record(UserCreated{EventMeta: u.meta(time.Time{}), Email: u.Email, UserType: u.UserType, Active: u.Active, Status: u.Status})
 	return u, nil
 }
+
+// Rename troca o nome, que não pode ficar vazio
+func (u *User) Rename(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrNameRequired
+	}
+	if name == u.Name {
+		return nil
+	}
+	u.record(UserRenamed{EventMeta: u.meta(time.Time{}), Previous: u.Name, Name: name})
+	u.Name = name
+	return nil
+}
+
+// ChangePassword aplica a política e substitui o hash; é o único caminho que
+// troca a senha, então o hash existente nunca é re-hasheado por engano
+func (u *User) ChangePassword(raw string) error {
+	pass, err := vo.NewPassword(raw, u.Name, string(u.Email))
+	if err != nil {
+		return err
+	}
+	u.Password = pass
+	u.record(UserPasswordChanged{EventMeta: u.meta(time.Time{})})
+	return nil
+}
+
+// ResetPassword troca a senha esquecida e derruba as sessões existentes, já
+// que quem pediu o reset pode estar recuperando uma conta comprometida
+func (u *User) ResetPassword(raw string, at time.Time) error {
+	if err := u.ChangePassword(raw); err != nil {
+		return err
+	}
+	u.RevokeSessions(at)
+	return nil
+}
+
+// RevokeSessions invalida todas as credenciais emitidas até at
+func (u *User) RevokeSessions(at time.Time) {
+	u.SessionsRevokedAt = &at
+	u.record(UserSessionsRevoked{EventMeta: u.meta(at)})
+}
+
+// RequestPasswordReset registra o pedido de redefinição de senha; o link sai
+// pelo assinante do evento
+func (u *User) RequestPasswordReset(at time.Time) {
+	u.record(UserPasswordResetRequested{EventMeta: u.meta(at), Email: u.Email})
+}
+
+// Activate reativa um usuário desativado (o antigo PATCH active=true). Contas
+// pendentes, suspensas ou bloqueadas só saem desses estados por ChangeStatus.
+func (u *User) Activate(actor string) error {
+	if from := u.EffectiveStatus(); from != StatusActive && from != StatusDeactivated {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, from, StatusActive)
+	}
+	return u.ChangeStatus(StatusActive, "", actor)
+}
+
+// Deactivate desativa o usuário; DeactivatedAt é registrado pelo repositório na transição
+func (u *User) Deactivate(actor string) error {
+	return u.ChangeStatus(StatusDeactivated, "", actor)
+}
+
+// ChangeType troca o tipo do usuário entre os tipos conhecidos
+func (u *User) ChangeType(t UserType) error {
+	if !t.Valid() {
+		return ErrInvalidUserType
+	}
+	if t == u.UserType {
+		return nil
+	}
+
+	// O tipo é projeção dos papéis: mudar o tipo concede ou retira o papel admin
+	roles := u.EffectiveRoles()
+	if t == UserTypeAdmin {
+		roles = withRole(roles, rbac.RoleAdmin)
+	} else {
+		roles = withoutRole(roles, rbac.RoleAdmin)
+	}
+	u.Roles = roles
+	u.record(UserTypeChanged{EventMeta: u.meta(time.Time{}), Previous: u.UserType, UserType: t})
+	u.UserType = t
+	return nil
+}
+
+// VerifyPassword confere a senha e, se o hash estiver em algoritmo ou custo
+// desatualizados, o regrava com o hasher atual. upgraded indica que o usuário
+// precisa ser persistido; uma falha no novo hash mantém o anterior.
+func (u *User) VerifyPassword(raw string) (matched, upgraded bool) {
+	if !u.Password.Compare(raw) {
+		return false, false
+	}
+	if !u.Password.NeedsRehash() {
+		return true, false
+	}
+
+	rehashed, err := u.Password.Rehash(raw)
+	if err != nil {
+		return true, false
+	}
+	u.Password = rehashed
+	return true, true
+}
+
+// EmailForDisplay devolve o email como o usuário o digitou, ou o canônico
+func (u User) EmailForDisplay() string {
+	if u.DisplayEmail != "" {
+		return u.DisplayEmail
+	}
+	return string(u.Email)
+}
+
+// ChangeEmail troca o endereço do usuário guardando o anterior no histórico.
+// Trocar para o mesmo endereço canônico só atualiza a forma de exibição.
+func (u *User) ChangeEmail(addr vo.Address, at time.Time) {
+	u.DisplayEmail = addr.Display
+	email := addr.Email
+	if email == u.Email {
+		return
+	}
+
+	// Nova slice para não compartilhar o array com cópias anteriores do usuário
+	history := make([]EmailChange, 0, len(u.EmailHistory)+1)
+	history = append(history, u.EmailHistory...)
+	u.EmailHistory = append(history, EmailChange{Previous: u.Email, ChangedAt: at})
+	u.record(UserEmailChanged{EventMeta: u.meta(at), Previous: u.Email, Email: email})
+	u.Email = email
+	u.EmailVerifiedAt = nil
+}
+
+// VerifyEmail confirma o endereço atual. Uma conta em pending_verification
+// passa a active, tendo o próprio usuário como autor da transição.
+func (u *User) VerifyEmail(at time.Time) error {
+	if u.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+	u.EmailVerifiedAt = &at
+	u.record(UserEmailVerified{EventMeta: u.meta(at), Email: u.Email})
+	if u.EffectiveStatus() == StatusPendingVerification {
+		return u.ChangeStatus(StatusActive, "email verified", string(u.ID))
+	}
+	return nil
+}
+
+// MarkDeleted exclui o usuário logicamente; não tem efeito se já estiver excluído
+func (u *User) MarkDeleted(at time.Time) {
+	if u.IsDeleted() {
+		return
+	}
+	u.DeletedAt = &at
+	u.record(UserDeleted{EventMeta: u.meta(at), Email: u.Email})
+}
+
+// Restore desfaz a exclusão lógica; não tem efeito se o usuário não estiver excluído
+func (u *User) Restore(at time.Time) {
+	if !u.IsDeleted() {
+		return
+	}
+	u.DeletedAt = nil
+	u.record(UserRestored{EventMeta: u.meta(at)})
+}
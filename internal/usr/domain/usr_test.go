@@ -1,8 +1,10 @@
 package domain
 
 import (
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
 )
@@ -48,6 +50,9 @@ func TestNewUser_Success(t *testing.T) {
 		t.Fatalf("NewUser unexpected error: %v", err)
 	}
 
+	if _, err := vo.ParseUserID(string(u.ID)); err != nil {
+		t.Fatalf("ID: expected generated UUID, got %q (%v)", u.ID, err)
+	}
 	if u.Name != "Ana" {
 		t.Fatalf("Name: got %q, want %q", u.Name, "Ana")
 	}
@@ -90,8 +95,223 @@ func TestNewUser_EmptyPassword_ReturnsError(t *testing.T) {
 	}
 }
 
+func TestNewUser_PasswordWithPersonalInfo_ReturnsPolicyError(t *testing.T) {
+	_, err := NewUser("Maria Souza", "msouza@example.com", "msouza2024", true, UserTypeUser)
+
+	var pe *vo.PolicyError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected *vo.PolicyError, got %T (%v)", err, err)
+	}
+	if len(pe.Violations) != 1 || pe.Violations[0].Code != vo.ViolationPersonalInfo {
+		t.Fatalf("violations: got %+v", pe.Violations)
+	}
+}
+
 func TestNewUser_NameOnlySpaces_ReturnsError(t *testing.T) {
 	if _, err := NewUser(strings.Repeat(" ", 3), "ana@example.com", "secret123", true, UserTypeUser); err == nil {
 		t.Fatalf("expected error for name with only spaces, got nil")
 	}
 }
+
+func TestNewUser_GeneratesDistinctIDs(t *testing.T) {
+	u1, err := NewUser("Ana", "ana@example.com", "secret123", true, UserTypeUser)
+	if err != nil {
+		t.Fatalf("NewUser #1: %v", err)
+	}
+	u2, err := NewUser("Ana", "ana@example.com", "secret123", true, UserTypeUser)
+	if err != nil {
+		t.Fatalf("NewUser #2: %v", err)
+	}
+	if u1.ID == u2.ID {
+		t.Fatalf("expected distinct IDs, both were %q", u1.ID)
+	}
+}
+
+func TestUser_ChangeEmail_RecordsPreviousAddress(t *testing.T) {
+	u, err := NewUser("Ana", "ana@example.com", "secret123", true, UserTypeUser)
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+	snapshot := u
+
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	u.ChangeEmail(vo.Address{Email: "ana.paula@example.com"}, at)
+
+	if u.Email != "ana.paula@example.com" {
+		t.Fatalf("Email: got %q, want %q", u.Email, "ana.paula@example.com")
+	}
+	if len(u.EmailHistory) != 1 {
+		t.Fatalf("EmailHistory length: got %d, want 1", len(u.EmailHistory))
+	}
+	if h := u.EmailHistory[0]; h.Previous != "ana@example.com" || !h.ChangedAt.Equal(at) {
+		t.Fatalf("EmailHistory[0]: got %+v", h)
+	}
+	if len(snapshot.EmailHistory) != 0 {
+		t.Fatalf("previous copy was mutated: %+v", snapshot.EmailHistory)
+	}
+
+	u.ChangeEmail(vo.Address{Email: "ana.paula@example.com"}, at)
+	if len(u.EmailHistory) != 1 {
+		t.Fatalf("changing to the same address should be a no-op, history=%+v", u.EmailHistory)
+	}
+}
+
+func TestUser_VerifyPassword_UpgradesOutdatedHash(t *testing.T) {
+	t.Cleanup(func() { vo.ConfigureHasher(vo.DefaultBcryptHasher()) })
+
+	vo.ConfigureHasher(vo.BcryptHasher{Cost: 4})
+	u, err := NewUser("Ana", "ana@example.com", "secret123", true, UserTypeUser)
+	if err != nil {
+		t.Fatalf("NewUser error: %v", err)
+	}
+
+	if matched, upgraded := u.VerifyPassword("secret123"); !matched || upgraded {
+		t.Fatalf("same hasher: matched=%v upgraded=%v, want true/false", matched, upgraded)
+	}
+
+	vo.ConfigureHasher(vo.ScryptHasher{LogN: 4, R: 8, P: 1, KeyLen: 16, SaltLen: 8})
+	if matched, upgraded := u.VerifyPassword("wrongpass"); matched || upgraded {
+		t.Fatalf("wrong password: matched=%v upgraded=%v", matched, upgraded)
+	}
+	if !strings.HasPrefix(string(u.Password), "$2") {
+		t.Fatalf("failed verification must not touch the hash")
+	}
+
+	matched, upgraded := u.VerifyPassword("secret123")
+	if !matched || !upgraded {
+		t.Fatalf("outdated hash: matched=%v upgraded=%v, want true/true", matched, upgraded)
+	}
+	if !strings.HasPrefix(string(u.Password), "$scrypt$") {
+		t.Fatalf("hash was not upgraded: %q", u.Password)
+	}
+	if !u.Password.Compare("secret123") {
+		t.Fatalf("upgraded hash should verify")
+	}
+}
+
+func TestUser_Rename(t *testing.T) {
+	u, err := NewUser("Ana", "ana@example.com", "secret123", true, UserTypeUser)
+	if err != nil {
+		t.Fatalf("NewUser error: %v", err)
+	}
+
+	if err := u.Rename("  Ana Paula "); err != nil {
+		t.Fatalf("Rename error: %v", err)
+	}
+	if u.Name != "Ana Paula" {
+		t.Fatalf("Name: got %q, want %q", u.Name, "Ana Paula")
+	}
+	if err := u.Rename("   "); err != ErrNameRequired {
+		t.Fatalf("Rename blank: got %v, want %v", err, ErrNameRequired)
+	}
+	if u.Name != "Ana Paula" {
+		t.Fatalf("failed Rename must not change the name, got %q", u.Name)
+	}
+}
+
+func TestUser_ChangePassword(t *testing.T) {
+	u, err := NewUser("Ana", "ana@example.com", "secret123", true, UserTypeUser)
+	if err != nil {
+		t.Fatalf("NewUser error: %v", err)
+	}
+	before := u.Password
+
+	if err := u.ChangePassword("123"); err == nil {
+		t.Fatalf("expected policy error for short password")
+	}
+	if u.Password != before {
+		t.Fatalf("failed ChangePassword must keep the previous hash")
+	}
+
+	if err := u.ChangePassword("n3w-s3cret"); err != nil {
+		t.Fatalf("ChangePassword error: %v", err)
+	}
+	if !u.Password.Compare("n3w-s3cret") || u.Password.Compare("secret123") {
+		t.Fatalf("password was not replaced")
+	}
+}
+
+func TestUser_ActivateDeactivateAndChangeType(t *testing.T) {
+	u, err := NewUser("Ana", "ana@example.com", "secret123", true, UserTypeUser)
+	if err != nil {
+		t.Fatalf("NewUser error: %v", err)
+	}
+
+	if err := u.Deactivate(""); err != nil || u.Active || u.Status != StatusDeactivated {
+		t.Fatalf("Deactivate: err=%v active=%v status=%q", err, u.Active, u.Status)
+	}
+	if err := u.Activate(""); err != nil || !u.Active || u.Status != StatusActive {
+		t.Fatalf("Activate: err=%v active=%v status=%q", err, u.Active, u.Status)
+	}
+
+	if err := u.ChangeType(UserTypeAdmin); err != nil || u.UserType != UserTypeAdmin {
+		t.Fatalf("ChangeType Admin: err=%v type=%q", err, u.UserType)
+	}
+	if err := u.ChangeType("Root"); err != ErrInvalidUserType {
+		t.Fatalf("ChangeType invalid: got %v, want %v", err, ErrInvalidUserType)
+	}
+	if u.UserType != UserTypeAdmin {
+		t.Fatalf("failed ChangeType must keep the type, got %q", u.UserType)
+	}
+}
+
+func TestUser_VerifyEmail_ActivatesPendingUser(t *testing.T) {
+	u, err := NewPendingUser("Ana", "ana@example.com", "secret123", UserTypeUser)
+	if err != nil {
+		t.Fatalf("NewPendingUser error: %v", err)
+	}
+	if u.Active || u.Status != StatusPendingVerification {
+		t.Fatalf("pending user: active=%v status=%q", u.Active, u.Status)
+	}
+	created := u.PullEvents()[0].(UserCreated)
+	if created.Status != StatusPendingVerification || created.Active {
+		t.Fatalf("UserCreated: %+v", created)
+	}
+
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := u.VerifyEmail(at); err != nil {
+		t.Fatalf("VerifyEmail: %v", err)
+	}
+	if !u.Active || u.Status != StatusActive || u.EmailVerifiedAt == nil || !u.EmailVerifiedAt.Equal(at) {
+		t.Fatalf("verified user: active=%v status=%q verifiedAt=%v", u.Active, u.Status, u.EmailVerifiedAt)
+	}
+	if last, _ := u.LastStatusChange(); last.Actor != string(u.ID) {
+		t.Fatalf("transition actor: got %q, want the user itself", last.Actor)
+	}
+	if got := eventNames(u.PullEvents()); len(got) != 3 || got[0] != EventUserEmailVerified {
+		t.Fatalf("events: %v", got)
+	}
+	if err := u.VerifyEmail(at); err != ErrEmailAlreadyVerified {
+		t.Fatalf("second VerifyEmail: got %v", err)
+	}
+
+	// Um novo endereço precisa ser confirmado de novo, sem mudar o status
+	u.ChangeEmail(vo.Address{Email: "ana.paula@example.com"}, at)
+	if u.EmailVerifiedAt != nil || u.Status != StatusActive {
+		t.Fatalf("after ChangeEmail: verifiedAt=%v status=%q", u.EmailVerifiedAt, u.Status)
+	}
+}
+
+func TestUser_ResetPassword_RevokesSessions(t *testing.T) {
+	u := mustNewUser(t, UserTypeUser)
+	u.PullEvents()
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := u.ResetPassword("123", at); err == nil {
+		t.Fatalf("weak password must be rejected")
+	}
+	if u.SessionsRevokedAt != nil || len(u.PullEvents()) != 0 {
+		t.Fatalf("rejected reset must not revoke sessions")
+	}
+
+	if err := u.ResetPassword("n3w-s3cret", at); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	if !u.Password.Compare("n3w-s3cret") || u.SessionsRevokedAt == nil || !u.SessionsRevokedAt.Equal(at) {
+		t.Fatalf("after reset: revokedAt=%v", u.SessionsRevokedAt)
+	}
+	got := eventNames(u.PullEvents())
+	if len(got) != 2 || got[0] != EventUserPasswordChanged || got[1] != EventUserSessionsRevoked {
+		t.Fatalf("events: %v", got)
+	}
+}
@@ -0,0 +1,124 @@
+package vo
+
+import (
+	"bufio"
+	"hash/fnv"
+	"math"
+	"os"
+	"strings"
+)
+
+// DenyList identifica senhas comuns que não devem ser aceitas
+type DenyList interface {
+	Contains(raw string) bool
+}
+
+// normalizeDenied compara sem diferenciar maiúsculas nem espaços nas pontas
+func normalizeDenied(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// setDenyList guarda as senhas num mapa: exato, mas ocupa memória proporcional à lista
+type setDenyList map[string]struct{}
+
+// NewDenyList cria uma deny-list exata a partir das senhas informadas
+func NewDenyList(passwords []string) DenyList {
+	set := make(setDenyList, len(passwords))
+	for _, p := range passwords {
+		if p = normalizeDenied(p); p != "" {
+			set[p] = struct{}{}
+		}
+	}
+	return set
+}
+
+func (s setDenyList) Contains(raw string) bool {
+	_, ok := s[normalizeDenied(raw)]
+	return ok
+}
+
+// bloomDenyList troca exatidão por memória: pode recusar uma senha que não
+// está na lista (falso positivo), mas nunca aceita uma que está
+type bloomDenyList struct {
+	bits   []uint64
+	m      uint64
+	hashes uint64
+}
+
+// NewBloomDenyList cria uma deny-list probabilística dimensionada para a taxa de
+// falsos positivos informada (ex.: 0.001)
+func NewBloomDenyList(passwords []string, falsePositiveRate float64) DenyList {
+	n := float64(len(passwords))
+	if n < 1 {
+		n = 1
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.001
+	}
+
+	m := uint64(math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	k := uint64(math.Max(1, math.Round(float64(m)/n*math.Ln2)))
+
+	b := &bloomDenyList{bits: make([]uint64, (m+63)/64), m: m, hashes: k}
+	for _, p := range passwords {
+		if p = normalizeDenied(p); p != "" {
+			b.add(p)
+		}
+	}
+	return b
+}
+
+// positions usa double hashing (h1 + i*h2) para derivar k posições de um único hash de 64 bits
+func (b *bloomDenyList) positions(s string) (uint64, uint64) {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	sum := h.Sum64()
+	return sum & 0xffffffff, (sum >> 32) | 1
+}
+
+func (b *bloomDenyList) add(s string) {
+	h1, h2 := b.positions(s)
+	for i := uint64(0); i < b.hashes; i++ {
+		pos := (h1 + i*h2) % b.m
+		b.bits[pos/64] |= 1 << (pos % 64)
+	}
+}
+
+func (b *bloomDenyList) Contains(raw string) bool {
+	h1, h2 := b.positions(normalizeDenied(raw))
+	for i := uint64(0); i < b.hashes; i++ {
+		pos := (h1 + i*h2) % b.m
+		if b.bits[pos/64]&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// LoadDenyList lê um arquivo com uma senha por linha (linhas vazias e iniciadas
+// por # são ignoradas). Com useBloom, devolve um filtro de Bloom em vez do mapa.
+func LoadDenyList(path string, useBloom bool) (DenyList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var passwords []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords = append(passwords, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if useBloom {
+		return NewBloomDenyList(passwords, 0.001), nil
+	}
+	return NewDenyList(passwords), nil
+}
@@ -0,0 +1,62 @@
+package vo
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestLoadDenyList_SkipsCommentsAndBlankLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "common.txt")
+	content := "# senhas comuns\n123456\n\n  Password  \nletmein\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	for _, bloom := range []bool{false, true} {
+		list, err := LoadDenyList(path, bloom)
+		if err != nil {
+			t.Fatalf("LoadDenyList(bloom=%v): %v", bloom, err)
+		}
+		for _, w := range []string{"123456", "password", "LETMEIN"} {
+			if !list.Contains(w) {
+				t.Fatalf("bloom=%v: expected %q to be denied", bloom, w)
+			}
+		}
+		if list.Contains("# senhas comuns") {
+			t.Fatalf("bloom=%v: comment line should not be loaded", bloom)
+		}
+	}
+}
+
+func TestLoadDenyList_MissingFile(t *testing.T) {
+	if _, err := LoadDenyList(filepath.Join(t.TempDir(), "missing.txt"), false); err == nil {
+		t.Fatalf("expected error for missing file, got nil")
+	}
+}
+
+func TestBloomDenyList_FalsePositiveRate(t *testing.T) {
+	words := make([]string, 1000)
+	for i := range words {
+		words[i] = "common-" + strconv.Itoa(i)
+	}
+	list := NewBloomDenyList(words, 0.01)
+
+	for _, w := range words {
+		if !list.Contains(w) {
+			t.Fatalf("bloom filter must not have false negatives: %q", w)
+		}
+	}
+
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if list.Contains("other-" + strconv.Itoa(i)) {
+			falsePositives++
+		}
+	}
+	// Margem folgada sobre o 1% configurado
+	if falsePositives > 300 {
+		t.Fatalf("false positives: got %d of 10000, want about 1%%", falsePositives)
+	}
+}
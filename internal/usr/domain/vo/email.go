@@ -5,19 +5,61 @@ import (
 	"fmt"
 	"net/mail"
 	"strings"
+	"sync/atomic"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
 )
 
+// Email é a forma canônica do endereço, usada como chave de repositório e de cache:
+// domínio em minúsculas e em ASCII (punycode) e, se configurado, parte local com case folding
 type Email string
 
+// Address guarda o email canônico junto com a forma original para exibição
+type Address struct {
+	Email   Email
+	Display string
+}
+
+// EmailOptions configura a canonicalização de emails
+type EmailOptions struct {
+	// FoldLocalPart aplica case folding Unicode na parte local (antes do @).
+	// O RFC 5321 permite partes locais sensíveis a maiúsculas, mas na prática
+	// os provedores as ignoram.
+	FoldLocalPart bool
+}
+
+var emailOptions atomic.Pointer[EmailOptions]
+
+func init() {
+	emailOptions.Store(&EmailOptions{FoldLocalPart: true})
+}
+
+// ConfigureEmail troca as opções de canonicalização; deve ser chamado na inicialização
+func ConfigureEmail(opts EmailOptions) {
+	emailOptions.Store(&opts)
+}
+
+// NewEmail valida o endereço e devolve sua forma canônica
 func NewEmail(value string) (Email, error) {
+	addr, err := ParseEmail(value)
+	if err != nil {
+		return "", err
+	}
+	return addr.Email, nil
+}
+
+// ParseEmail valida o endereço e devolve a forma canônica e a de exibição
+func ParseEmail(value string) (Address, error) {
 	trimmed := strings.TrimSpace(value)
 	if trimmed == "" {
-		return "", errors.New("invalid email: empty")
+		return Address{}, errors.New("invalid email: empty")
 	}
 
 	addr, err := mail.ParseAddress(trimmed)
 	if err != nil {
-		return "", fmt.Errorf("invalid email: %w", err)
+		return Address{}, fmt.Errorf("invalid email: %w", err)
 	}
 
 	addrSpec := trimmed
@@ -28,10 +70,34 @@ func NewEmail(value string) (Email, error) {
 	}
 
 	if strings.ContainsAny(addrSpec, " \t\r\n") {
-		return "", errors.New("invalid email: whitespace inside address")
+		return Address{}, errors.New("invalid email: whitespace inside address")
+	}
+
+	canonical, err := canonicalize(addr.Address)
+	if err != nil {
+		return Address{}, err
+	}
+
+	return Address{Email: canonical, Display: norm.NFC.String(addr.Address)}, nil
+}
+
+func canonicalize(address string) (Email, error) {
+	at := strings.LastIndex(address, "@")
+	if at <= 0 || at == len(address)-1 {
+		return "", errors.New("invalid email: missing local part or domain")
+	}
+
+	local := norm.NFC.String(address[:at])
+	if emailOptions.Load().FoldLocalPart {
+		local = cases.Fold().String(local)
+	}
+
+	domain, err := idna.Lookup.ToASCII(address[at+1:])
+	if err != nil {
+		return "", fmt.Errorf("invalid email domain: %w", err)
 	}
 
-	return Email(addr.Address), nil
+	return Email(local + "@" + strings.ToLower(domain)), nil
 }
 
 func (e Email) String() string {
@@ -61,3 +61,62 @@ func TestEmail_String(t *testing.T) {
 		t.Fatalf("String(): got %q, want %q", got, "user@example.com")
 	}
 }
+
+func TestNewEmail_CanonicalForm(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "lowercases domain and local part", input: "Ana@Example.COM", want: "ana@example.com"},
+		{name: "unicode domain becomes punycode", input: "user@Bücher.de", want: "user@xn--bcher-kva.de"},
+		{name: "punycode domain is kept", input: "user@xn--bcher-kva.de", want: "user@xn--bcher-kva.de"},
+		{name: "unicode local part is folded", input: "JOSÉ@example.com", want: "josé@example.com"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NewEmail(tc.input)
+			if err != nil {
+				t.Fatalf("NewEmail(%q) unexpected error: %v", tc.input, err)
+			}
+			if string(got) != tc.want {
+				t.Fatalf("canonical: got %q, want %q", string(got), tc.want)
+			}
+		})
+	}
+}
+
+func TestParseEmail_PreservesDisplayForm(t *testing.T) {
+	addr, err := ParseEmail("Ana Silva <Ana@Bücher.de>")
+	if err != nil {
+		t.Fatalf("ParseEmail error: %v", err)
+	}
+	if addr.Email != "ana@xn--bcher-kva.de" {
+		t.Fatalf("Email: got %q", addr.Email)
+	}
+	if addr.Display != "Ana@Bücher.de" {
+		t.Fatalf("Display: got %q, want %q", addr.Display, "Ana@Bücher.de")
+	}
+}
+
+func TestConfigureEmail_LocalPartFoldingIsOptional(t *testing.T) {
+	t.Cleanup(func() { ConfigureEmail(EmailOptions{FoldLocalPart: true}) })
+
+	ConfigureEmail(EmailOptions{FoldLocalPart: false})
+	got, err := NewEmail("Ana.Silva@Example.com")
+	if err != nil {
+		t.Fatalf("NewEmail error: %v", err)
+	}
+	// Domínio continua em minúsculas, parte local preservada
+	if got != "Ana.Silva@example.com" {
+		t.Fatalf("got %q, want %q", got, "Ana.Silva@example.com")
+	}
+}
+
+func TestNewEmail_InvalidDomainLabel(t *testing.T) {
+	if _, err := NewEmail("ana@exa_mple.com"); err == nil {
+		t.Fatalf("expected error for domain rejected by IDNA lookup profile")
+	}
+}
@@ -0,0 +1,280 @@
+package vo
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Algoritmos suportados
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmScrypt   = "scrypt"
+)
+
+var ErrUnknownHashFormat = errors.New("unknown password hash format")
+
+// Hasher gera e verifica hashes de senha. Os parâmetros usados ficam gravados
+// no próprio hash (formato PHC; o bcrypt mantém seu formato nativo $2a$),
+// então qualquer hasher da mesma família verifica hashes de outros custos.
+type Hasher interface {
+	Algorithm() string
+	Hash(raw string) (string, error)
+	Verify(hash, raw string) (bool, error)
+	// NeedsRehash indica se o hash foi gerado com outro algoritmo ou outros parâmetros
+	NeedsRehash(hash string) bool
+}
+
+var currentHasher atomic.Pointer[Hasher]
+
+func init() {
+	var h Hasher = DefaultBcryptHasher()
+	currentHasher.Store(&h)
+}
+
+// ConfigureHasher define o algoritmo usado para novos hashes; deve ser chamado na inicialização
+func ConfigureHasher(h Hasher) {
+	currentHasher.Store(&h)
+}
+
+// CurrentHasher devolve o hasher usado para novos hashes
+func CurrentHasher() Hasher {
+	return *currentHasher.Load()
+}
+
+// MaxPasswordBytes devolve o limite de bytes de senha do hasher, quando ele
+// tem um (MaxBytes); zero indica sem limite
+func MaxPasswordBytes(h Hasher) int {
+	if l, ok := h.(interface{ MaxBytes() int }); ok {
+		return l.MaxBytes()
+	}
+	return 0
+}
+
+// NewHasher cria o hasher padrão de um algoritmo pelo nome
+func NewHasher(algorithm string) (Hasher, error) {
+	switch strings.ToLower(strings.TrimSpace(algorithm)) {
+	case AlgorithmBcrypt:
+		return DefaultBcryptHasher(), nil
+	case AlgorithmArgon2id:
+		return DefaultArgon2idHasher(), nil
+	case AlgorithmScrypt:
+		return DefaultScryptHasher(), nil
+	}
+	return nil, fmt.Errorf("unknown password hash algorithm %q", algorithm)
+}
+
+// hasherFor escolhe, pelo prefixo, a família capaz de verificar o hash
+func hasherFor(hash string) (Hasher, error) {
+	switch {
+	case strings.HasPrefix(hash, "$2"):
+		return BcryptHasher{}, nil
+	case strings.HasPrefix(hash, "$"+AlgorithmArgon2id+"$"):
+		return Argon2idHasher{}, nil
+	case strings.HasPrefix(hash, "$"+AlgorithmScrypt+"$"):
+		return ScryptHasher{}, nil
+	}
+	return nil, ErrUnknownHashFormat
+}
+
+func randomSalt(n int) ([]byte, error) {
+	salt := make([]byte, n)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// b64 é a codificação do PHC: base64 padrão sem padding
+var b64 = base64.RawStdEncoding
+
+// splitPHC separa "$alg$params...$salt$hash" e devolve salt e hash decodificados
+func splitPHC(hash, algorithm string, fields int) ([]string, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != fields || parts[0] != "" || parts[1] != algorithm {
+		return nil, nil, nil, ErrUnknownHashFormat
+	}
+	salt, err := b64.DecodeString(parts[fields-2])
+	if err != nil {
+		return nil, nil, nil, ErrUnknownHashFormat
+	}
+	key, err := b64.DecodeString(parts[fields-1])
+	if err != nil || len(key) == 0 {
+		return nil, nil, nil, ErrUnknownHashFormat
+	}
+	return parts[2 : fields-2], salt, key, nil
+}
+
+// BcryptHasher usa bcrypt, limitado a 72 bytes de senha
+type BcryptHasher struct {
+	Cost int
+}
+
+func DefaultBcryptHasher() BcryptHasher {
+	return BcryptHasher{Cost: bcrypt.DefaultCost}
+}
+
+func (BcryptHasher) Algorithm() string { return AlgorithmBcrypt }
+
+// MaxBytes é o limite do bcrypt; bytes além dele seriam ignorados no hash
+func (BcryptHasher) MaxBytes() int { return BcryptMaxBytes }
+
+func (h BcryptHasher) cost() int {
+	if h.Cost == 0 {
+		return bcrypt.DefaultCost
+	}
+	return h.Cost
+}
+
+func (h BcryptHasher) Hash(raw string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(raw), h.cost())
+	return string(hash), err
+}
+
+func (BcryptHasher) Verify(hash, raw string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(raw))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return false, nil
+	}
+	return false, err
+}
+
+func (h BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.cost()
+}
+
+// Argon2idHasher usa argon2id no formato $argon2id$v=19$m=...,t=...,p=...$salt$hash
+type Argon2idHasher struct {
+	Time    uint32
+	Memory  uint32 // em KiB
+	Threads uint8
+	KeyLen  uint32
+	SaltLen int
+}
+
+// DefaultArgon2idHasher segue a recomendação da OWASP (19 MiB, 2 iterações)
+func DefaultArgon2idHasher() Argon2idHasher {
+	return Argon2idHasher{Time: 2, Memory: 19 * 1024, Threads: 1, KeyLen: 32, SaltLen: 16}
+}
+
+func (Argon2idHasher) Algorithm() string { return AlgorithmArgon2id }
+
+func (h Argon2idHasher) Hash(raw string) (string, error) {
+	salt, err := randomSalt(h.SaltLen)
+	if err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(raw), salt, h.Time, h.Memory, h.Threads, h.KeyLen)
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		AlgorithmArgon2id, argon2.Version, h.Memory, h.Time, h.Threads,
+		b64.EncodeToString(salt), b64.EncodeToString(key)), nil
+}
+
+// parse lê os parâmetros gravados no hash
+func (Argon2idHasher) parse(hash string) (Argon2idHasher, []byte, []byte, error) {
+	params, salt, key, err := splitPHC(hash, AlgorithmArgon2id, 6)
+	if err != nil {
+		return Argon2idHasher{}, nil, nil, err
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(params[0], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2idHasher{}, nil, nil, ErrUnknownHashFormat
+	}
+	var p Argon2idHasher
+	if _, err := fmt.Sscanf(params[1], "m=%d,t=%d,p=%d", &p.Memory, &p.Time, &p.Threads); err != nil || p.Time == 0 || p.Threads == 0 {
+		return Argon2idHasher{}, nil, nil, ErrUnknownHashFormat
+	}
+	p.KeyLen = uint32(len(key))
+	p.SaltLen = len(salt)
+	return p, salt, key, nil
+}
+
+func (h Argon2idHasher) Verify(hash, raw string) (bool, error) {
+	p, salt, key, err := h.parse(hash)
+	if err != nil {
+		return false, err
+	}
+	got := argon2.IDKey([]byte(raw), salt, p.Time, p.Memory, p.Threads, p.KeyLen)
+	return subtle.ConstantTimeCompare(got, key) == 1, nil
+}
+
+func (h Argon2idHasher) NeedsRehash(hash string) bool {
+	p, _, _, err := h.parse(hash)
+	return err != nil || p != h
+}
+
+// ScryptHasher usa scrypt no formato $scrypt$ln=...,r=...,p=...$salt$hash (N = 2^ln)
+type ScryptHasher struct {
+	LogN    uint8
+	R       int
+	P       int
+	KeyLen  int
+	SaltLen int
+}
+
+// DefaultScryptHasher segue a recomendação da OWASP (N=2^17, r=8, p=1)
+func DefaultScryptHasher() ScryptHasher {
+	return ScryptHasher{LogN: 17, R: 8, P: 1, KeyLen: 32, SaltLen: 16}
+}
+
+func (ScryptHasher) Algorithm() string { return AlgorithmScrypt }
+
+func (h ScryptHasher) Hash(raw string) (string, error) {
+	salt, err := randomSalt(h.SaltLen)
+	if err != nil {
+		return "", err
+	}
+	key, err := scrypt.Key([]byte(raw), salt, 1<<h.LogN, h.R, h.P, h.KeyLen)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("$%s$ln=%d,r=%d,p=%d$%s$%s",
+		AlgorithmScrypt, h.LogN, h.R, h.P,
+		b64.EncodeToString(salt), b64.EncodeToString(key)), nil
+}
+
+func (ScryptHasher) parse(hash string) (ScryptHasher, []byte, []byte, error) {
+	params, salt, key, err := splitPHC(hash, AlgorithmScrypt, 5)
+	if err != nil {
+		return ScryptHasher{}, nil, nil, err
+	}
+
+	var p ScryptHasher
+	if _, err := fmt.Sscanf(params[0], "ln=%d,r=%d,p=%d", &p.LogN, &p.R, &p.P); err != nil || p.LogN == 0 || p.LogN > 30 {
+		return ScryptHasher{}, nil, nil, ErrUnknownHashFormat
+	}
+	p.KeyLen = len(key)
+	p.SaltLen = len(salt)
+	return p, salt, key, nil
+}
+
+func (h ScryptHasher) Verify(hash, raw string) (bool, error) {
+	p, salt, key, err := h.parse(hash)
+	if err != nil {
+		return false, err
+	}
+	got, err := scrypt.Key([]byte(raw), salt, 1<<p.LogN, p.R, p.P, p.KeyLen)
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(got, key) == 1, nil
+}
+
+func (h ScryptHasher) NeedsRehash(hash string) bool {
+	p, _, _, err := h.parse(hash)
+	return err != nil || p != h
+}
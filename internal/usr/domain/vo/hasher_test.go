@@ -0,0 +1,141 @@
+package vo
+
+import (
+	"strings"
+	"testing"
+)
+
+// Parâmetros baixos para manter os testes rápidos
+func fastHashers() []Hasher {
+	return []Hasher{
+		BcryptHasher{Cost: 4},
+		Argon2idHasher{Time: 1, Memory: 64, Threads: 1, KeyLen: 16, SaltLen: 8},
+		ScryptHasher{LogN: 4, R: 8, P: 1, KeyLen: 16, SaltLen: 8},
+	}
+}
+
+func TestHashers_HashAndVerify(t *testing.T) {
+	prefixes := map[string]string{
+		AlgorithmBcrypt:   "$2",
+		AlgorithmArgon2id: "$argon2id$v=19$m=64,t=1,p=1$",
+		AlgorithmScrypt:   "$scrypt$ln=4,r=8,p=1$",
+	}
+
+	for _, h := range fastHashers() {
+		hash, err := h.Hash("secret123")
+		if err != nil {
+			t.Fatalf("%s: Hash error: %v", h.Algorithm(), err)
+		}
+		if !strings.HasPrefix(hash, prefixes[h.Algorithm()]) {
+			t.Fatalf("%s: unexpected format %q", h.Algorithm(), hash)
+		}
+
+		ok, err := h.Verify(hash, "secret123")
+		if err != nil || !ok {
+			t.Fatalf("%s: Verify correct: ok=%v err=%v", h.Algorithm(), ok, err)
+		}
+		ok, err = h.Verify(hash, "wrongpass")
+		if err != nil || ok {
+			t.Fatalf("%s: Verify wrong: ok=%v err=%v", h.Algorithm(), ok, err)
+		}
+
+		// Compare detecta o algoritmo pelo prefixo
+		if !Password(hash).Compare("secret123") {
+			t.Fatalf("%s: Password.Compare should accept the correct password", h.Algorithm())
+		}
+		if h.NeedsRehash(hash) {
+			t.Fatalf("%s: fresh hash should not need rehash", h.Algorithm())
+		}
+	}
+}
+
+func TestHashers_NeedsRehashOnParameterOrAlgorithmChange(t *testing.T) {
+	hashers := fastHashers()
+	hashes := make([]string, len(hashers))
+	for i, h := range hashers {
+		var err error
+		if hashes[i], err = h.Hash("secret123"); err != nil {
+			t.Fatalf("%s: Hash error: %v", h.Algorithm(), err)
+		}
+	}
+
+	for i, h := range hashers {
+		for j, hash := range hashes {
+			if i != j && !h.NeedsRehash(hash) {
+				t.Fatalf("%s should flag %s hash for rehash", h.Algorithm(), hashers[j].Algorithm())
+			}
+		}
+	}
+
+	stronger := []Hasher{
+		BcryptHasher{Cost: 5},
+		Argon2idHasher{Time: 2, Memory: 64, Threads: 1, KeyLen: 16, SaltLen: 8},
+		ScryptHasher{LogN: 5, R: 8, P: 1, KeyLen: 16, SaltLen: 8},
+	}
+	for i, h := range stronger {
+		if !h.NeedsRehash(hashes[i]) {
+			t.Fatalf("%s: hash with weaker parameters should need rehash", h.Algorithm())
+		}
+	}
+}
+
+func TestPassword_CompareMalformedPHC(t *testing.T) {
+	malformed := []Password{
+		"$argon2id$v=19$m=64,t=0,p=1$c2FsdA$a2V5",
+		"$argon2id$v=18$m=64,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=64,t=1,p=1$!!$a2V5",
+		"$scrypt$ln=99,r=8,p=1$c2FsdA$a2V5",
+		"$scrypt$ln=4,r=8,p=1$c2FsdA",
+		"$md5$abc",
+	}
+	for _, p := range malformed {
+		if p.Compare("anything") {
+			t.Fatalf("Compare on %q should be false", p)
+		}
+	}
+}
+
+func TestConfigureHasher_NewPasswordAndRehash(t *testing.T) {
+	t.Cleanup(func() { ConfigureHasher(DefaultBcryptHasher()) })
+
+	ConfigureHasher(BcryptHasher{Cost: 4})
+	old, err := NewPassword("secret123")
+	if err != nil {
+		t.Fatalf("NewPassword error: %v", err)
+	}
+
+	ConfigureHasher(Argon2idHasher{Time: 1, Memory: 64, Threads: 1, KeyLen: 16, SaltLen: 8})
+	if !old.NeedsRehash() {
+		t.Fatalf("bcrypt hash should need rehash after switching to argon2id")
+	}
+	// O hash antigo continua válido
+	if !old.Compare("secret123") {
+		t.Fatalf("old bcrypt hash should still verify")
+	}
+
+	upgraded, err := old.Rehash("secret123")
+	if err != nil {
+		t.Fatalf("Rehash error: %v", err)
+	}
+	if !strings.HasPrefix(string(upgraded), "$argon2id$") || upgraded.NeedsRehash() {
+		t.Fatalf("unexpected rehash result %q", upgraded)
+	}
+	if !upgraded.Compare("secret123") {
+		t.Fatalf("upgraded hash should verify")
+	}
+}
+
+func TestNewHasher_ByName(t *testing.T) {
+	for _, name := range []string{"bcrypt", "Argon2id", " scrypt "} {
+		h, err := NewHasher(name)
+		if err != nil {
+			t.Fatalf("NewHasher(%q): %v", name, err)
+		}
+		if h.Algorithm() != strings.ToLower(strings.TrimSpace(name)) {
+			t.Fatalf("NewHasher(%q).Algorithm() = %q", name, h.Algorithm())
+		}
+	}
+	if _, err := NewHasher("md5"); err == nil {
+		t.Fatalf("expected error for unknown algorithm")
+	}
+}
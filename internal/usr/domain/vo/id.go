@@ -0,0 +1,66 @@
+package vo
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+// UserID é o identificador estável e imutável do usuário (UUIDv7)
+type UserID string
+
+var ErrInvalidUserID = errors.New("invalid user id")
+
+// NewUserID gera um UUIDv7: 48 bits de timestamp em ms seguidos de bits aleatórios,
+// o que mantém os IDs ordenáveis pela data de criação
+func NewUserID() (UserID, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", err
+	}
+
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(time.Now().UnixMilli()))
+	copy(b[0:6], ts[2:])
+
+	b[6] = (b[6] & 0x0f) | 0x70 // versão 7
+	b[8] = (b[8] & 0x3f) | 0x80 // variante RFC 4122
+
+	return UserID(formatUUID(b)), nil
+}
+
+// ParseUserID valida o formato textual de um UUID e o normaliza em minúsculas
+func ParseUserID(value string) (UserID, error) {
+	s := strings.ToLower(strings.TrimSpace(value))
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return "", ErrInvalidUserID
+	}
+
+	raw := s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	if _, err := hex.DecodeString(raw); err != nil {
+		return "", ErrInvalidUserID
+	}
+
+	return UserID(s), nil
+}
+
+func formatUUID(b [16]byte) string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], b[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], b[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], b[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], b[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], b[10:])
+	return string(buf[:])
+}
+
+func (id UserID) String() string {
+	return string(id)
+}
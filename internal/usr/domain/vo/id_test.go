@@ -0,0 +1,83 @@
+package vo
+
+import (
+	"testing"
+)
+
+func TestNewUserID_FormatAndVersion(t *testing.T) {
+	id, err := NewUserID()
+	if err != nil {
+		t.Fatalf("NewUserID error: %v", err)
+	}
+
+	s := string(id)
+	if len(s) != 36 {
+		t.Fatalf("length: got %d, want 36 (%q)", len(s), s)
+	}
+	if s[14] != '7' {
+		t.Fatalf("version nibble: got %q, want '7' (%q)", s[14], s)
+	}
+	if c := s[19]; c != '8' && c != '9' && c != 'a' && c != 'b' {
+		t.Fatalf("variant nibble: got %q (%q)", c, s)
+	}
+
+	parsed, err := ParseUserID(s)
+	if err != nil {
+		t.Fatalf("ParseUserID(%q) error: %v", s, err)
+	}
+	if parsed != id {
+		t.Fatalf("round trip: got %q, want %q", parsed, id)
+	}
+}
+
+func TestNewUserID_UniqueAndSortable(t *testing.T) {
+	prev, err := NewUserID()
+	if err != nil {
+		t.Fatalf("NewUserID error: %v", err)
+	}
+
+	seen := map[UserID]bool{prev: true}
+	for i := 0; i < 100; i++ {
+		id, err := NewUserID()
+		if err != nil {
+			t.Fatalf("NewUserID error: %v", err)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate id generated: %q", id)
+		}
+		seen[id] = true
+
+		// Os 48 bits de timestamp nunca devem regredir
+		if id[:13] < prev[:13] {
+			t.Fatalf("ids are not time ordered: %q after %q", id, prev)
+		}
+		prev = id
+	}
+}
+
+func TestParseUserID_Normalizes(t *testing.T) {
+	got, err := ParseUserID("  0190F0C2-8B5A-7C3D-9E4F-0123456789AB ")
+	if err != nil {
+		t.Fatalf("ParseUserID error: %v", err)
+	}
+	if want := UserID("0190f0c2-8b5a-7c3d-9e4f-0123456789ab"); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseUserID_Invalid(t *testing.T) {
+	invalids := []string{
+		"",
+		"ana@example.com",
+		"invalid-email",
+		"0190f0c2-8b5a-7c3d-9e4f-0123456789a",
+		"0190f0c2x8b5a-7c3d-9e4f-0123456789ab",
+		"zzzzzzzz-8b5a-7c3d-9e4f-0123456789ab",
+	}
+
+	for _, in := range invalids {
+		if _, err := ParseUserID(in); err == nil {
+			t.Fatalf("ParseUserID(%q) expected error, got nil", in)
+		}
+	}
+}
@@ -1,19 +1,16 @@
 package vo
 
-import (
-	"errors"
-
-	"golang.org/x/crypto/bcrypt"
-)
-
+// Password guarda o hash da senha; o algoritmo é identificado pelo prefixo
 type Password string
 
-func NewPassword(raw string) (Password, error) {
-	if len(raw) < 6 {
-		return "", errors.New("password must be at least 6 characters long")
+// NewPassword valida a senha contra a política configurada e gera o hash com o
+// hasher atual. personal recebe dados do usuário (nome, email) que a senha não pode conter.
+func NewPassword(raw string, personal ...string) (Password, error) {
+	if err := CurrentPasswordPolicy().Validate(raw, personal...); err != nil {
+		return "", err
 	}
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(raw), bcrypt.DefaultCost)
+	hash, err := CurrentHasher().Hash(raw)
 	if err != nil {
 		return "", err
 	}
@@ -21,9 +18,29 @@ func NewPassword(raw string) (Password, error) {
 	return Password(hash), nil
 }
 
+// Compare verifica a senha com o algoritmo em que o hash foi gerado
 func (p Password) Compare(raw string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(p), []byte(raw))
-	return err == nil
+	h, err := hasherFor(string(p))
+	if err != nil {
+		return false
+	}
+	ok, err := h.Verify(string(p), raw)
+	return err == nil && ok
+}
+
+// NeedsRehash indica se o hash difere do algoritmo ou custo configurados
+func (p Password) NeedsRehash() bool {
+	return CurrentHasher().NeedsRehash(string(p))
+}
+
+// Rehash gera um novo hash com o hasher atual, sem reaplicar a política:
+// serve para migrar senhas já aceitas depois de um Compare bem-sucedido
+func (p Password) Rehash(raw string) (Password, error) {
+	hash, err := CurrentHasher().Hash(raw)
+	if err != nil {
+		return "", err
+	}
+	return Password(hash), nil
 }
 
 func (p Password) String() string {
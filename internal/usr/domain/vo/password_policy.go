@@ -0,0 +1,176 @@
+package vo
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+)
+
+// BcryptMaxBytes é o limite do bcrypt: bytes além disso seriam ignorados no hash
+const BcryptMaxBytes = 72
+
+// Códigos de violação devolvidos pela API
+const (
+	ViolationTooShort       = "too_short"
+	ViolationTooLong        = "too_long"
+	ViolationTooManyBytes   = "too_many_bytes"
+	ViolationMissingUpper   = "missing_uppercase"
+	ViolationMissingLower   = "missing_lowercase"
+	ViolationMissingDigit   = "missing_digit"
+	ViolationMissingSymbol  = "missing_symbol"
+	ViolationCommonPassword = "common_password"
+	ViolationPersonalInfo   = "contains_personal_info"
+)
+
+// minPersonalFragmentRunes evita rejeitar senhas por fragmentos curtos como "li" ou "an"
+const minPersonalFragmentRunes = 3
+
+// PasswordViolation descreve uma regra da política que a senha não cumpre
+type PasswordViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PolicyError agrega todas as violações encontradas de uma vez
+type PolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PolicyError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// PasswordPolicy define as regras aplicadas a senhas novas.
+// Os comprimentos são contados em runes; zero desativa o limite.
+type PasswordPolicy struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// DenyList rejeita senhas comuns; nil desativa a verificação
+	DenyList DenyList
+	// RejectPersonalInfo rejeita senhas que contenham o nome ou o email do usuário
+	RejectPersonalInfo bool
+}
+
+// DefaultPasswordPolicy mantém o comportamento histórico (mínimo de 6 caracteres)
+// e acrescenta as verificações que não dependem de configuração
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:          6,
+		RejectPersonalInfo: true,
+	}
+}
+
+var passwordPolicy atomic.Pointer[PasswordPolicy]
+
+func init() {
+	p := DefaultPasswordPolicy()
+	passwordPolicy.Store(&p)
+}
+
+// ConfigurePasswordPolicy troca a política usada por NewPassword; deve ser chamado na inicialização
+func ConfigurePasswordPolicy(p PasswordPolicy) {
+	passwordPolicy.Store(&p)
+}
+
+// CurrentPasswordPolicy devolve a política em uso
+func CurrentPasswordPolicy() PasswordPolicy {
+	return *passwordPolicy.Load()
+}
+
+// Validate verifica a senha contra a política. personal recebe dados do
+// usuário (nome, email) que não podem aparecer na senha.
+func (p PasswordPolicy) Validate(raw string, personal ...string) error {
+	var violations []PasswordViolation
+	add := func(code, format string, args ...any) {
+		violations = append(violations, PasswordViolation{Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	runes := utf8.RuneCountInString(raw)
+	if p.MinLength > 0 && runes < p.MinLength {
+		add(ViolationTooShort, "password must be at least %d characters long", p.MinLength)
+	}
+	if p.MaxLength > 0 && runes > p.MaxLength {
+		add(ViolationTooLong, "password must be at most %d characters long", p.MaxLength)
+	}
+	// O limite de bytes é do hasher em uso (só o bcrypt tem um)
+	if max := MaxPasswordBytes(CurrentHasher()); max > 0 && len(raw) > max {
+		add(ViolationTooManyBytes, "password must be at most %d bytes long", max)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range raw {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		add(ViolationMissingUpper, "password must contain an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		add(ViolationMissingLower, "password must contain a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		add(ViolationMissingDigit, "password must contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		add(ViolationMissingSymbol, "password must contain a symbol")
+	}
+
+	if p.DenyList != nil && p.DenyList.Contains(raw) {
+		add(ViolationCommonPassword, "password is too common")
+	}
+
+	if p.RejectPersonalInfo && containsPersonalInfo(raw, personal) {
+		add(ViolationPersonalInfo, "password must not contain your name or email")
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// containsPersonalInfo procura, sem diferenciar maiúsculas, o email completo,
+// as partes do seu trecho local e cada palavra do nome com pelo menos 3 caracteres
+func containsPersonalInfo(raw string, personal []string) bool {
+	haystack := strings.ToLower(raw)
+	for _, info := range personal {
+		info = strings.ToLower(strings.TrimSpace(info))
+		if info == "" {
+			continue
+		}
+
+		var fragments []string
+		if at := strings.LastIndex(info, "@"); at > 0 {
+			fragments = append(fragments, info, info[:at])
+			info = info[:at]
+		}
+		fragments = append(fragments, strings.FieldsFunc(info, func(r rune) bool {
+			return unicode.IsSpace(r) || r == '.' || r == '-' || r == '_' || r == '+'
+		})...)
+
+		for _, f := range fragments {
+			if utf8.RuneCountInString(f) >= minPersonalFragmentRunes && strings.Contains(haystack, f) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package vo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func violationCodes(t *testing.T, err error) []string {
+	t.Helper()
+	var pe *PolicyError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected *PolicyError, got %T (%v)", err, err)
+	}
+	codes := make([]string, len(pe.Violations))
+	for i, v := range pe.Violations {
+		codes[i] = v.Code
+	}
+	return codes
+}
+
+func TestPasswordPolicy_LengthCountsRunes(t *testing.T) {
+	p := PasswordPolicy{MinLength: 6, MaxLength: 8}
+
+	// 6 runes, 12 bytes: passaria por bytes mesmo se fosse mais curta
+	if err := p.Validate("ççççç1"); err != nil {
+		t.Fatalf("6-rune password rejected: %v", err)
+	}
+	if got := violationCodes(t, p.Validate("çççç")); strings.Join(got, ",") != ViolationTooShort {
+		t.Fatalf("codes: got %v, want [%s]", got, ViolationTooShort)
+	}
+	if got := violationCodes(t, p.Validate("123456789")); strings.Join(got, ",") != ViolationTooLong {
+		t.Fatalf("codes: got %v, want [%s]", got, ViolationTooLong)
+	}
+}
+
+func TestPasswordPolicy_RejectsBeyondBcryptLimit(t *testing.T) {
+	p := PasswordPolicy{}
+
+	// 40 runes de 2 bytes: 80 bytes, o bcrypt truncaria
+	raw := strings.Repeat("é", 40)
+	got := violationCodes(t, p.Validate(raw))
+	if strings.Join(got, ",") != ViolationTooManyBytes {
+		t.Fatalf("codes: got %v, want [%s]", got, ViolationTooManyBytes)
+	}
+
+	if err := p.Validate(strings.Repeat("a", BcryptMaxBytes)); err != nil {
+		t.Fatalf("72-byte password rejected: %v", err)
+	}
+}
+
+func TestPasswordPolicy_ByteLimitFollowsTheHasher(t *testing.T) {
+	p := PasswordPolicy{}
+	raw := strings.Repeat("é", 40)
+
+	for _, h := range []Hasher{DefaultArgon2idHasher(), DefaultScryptHasher()} {
+		ConfigureHasher(h)
+		if err := p.Validate(raw); err != nil {
+			t.Fatalf("%s has no byte limit: %v", h.Algorithm(), err)
+		}
+	}
+	ConfigureHasher(DefaultBcryptHasher())
+	if err := p.Validate(raw); err == nil {
+		t.Fatalf("bcrypt should reject 80-byte passwords")
+	}
+}
+
+func TestPasswordPolicy_CharacterClasses(t *testing.T) {
+	p := PasswordPolicy{RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true}
+
+	got := violationCodes(t, p.Validate("abcdef"))
+	want := []string{ViolationMissingUpper, ViolationMissingDigit, ViolationMissingSymbol}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("codes: got %v, want %v", got, want)
+	}
+
+	if err := p.Validate("Ábcdé1!"); err != nil {
+		t.Fatalf("compliant password rejected: %v", err)
+	}
+}
+
+func TestPasswordPolicy_PersonalInfo(t *testing.T) {
+	p := PasswordPolicy{RejectPersonalInfo: true}
+
+	rejected := []string{"ANA.SILVA!", "xxsilva99", "ana.silva@example.com"}
+	for _, raw := range rejected {
+		got := violationCodes(t, p.Validate(raw, "Ana Silva", "ana.silva@example.com"))
+		if strings.Join(got, ",") != ViolationPersonalInfo {
+			t.Fatalf("Validate(%q) codes: got %v, want [%s]", raw, got, ViolationPersonalInfo)
+		}
+	}
+
+	// Fragmentos curtos e o domínio do email não contam
+	for _, raw := range []string{"an-example-x", "correct horse"} {
+		if err := p.Validate(raw, "Ana Li", "ana.li@example.com"); err != nil {
+			t.Fatalf("Validate(%q) unexpected error: %v", raw, err)
+		}
+	}
+}
+
+func TestPasswordPolicy_DenyList(t *testing.T) {
+	words := []string{"password", "123456", "qwerty"}
+	for name, list := range map[string]DenyList{
+		"set":   NewDenyList(words),
+		"bloom": NewBloomDenyList(words, 0.001),
+	} {
+		p := PasswordPolicy{DenyList: list}
+
+		got := violationCodes(t, p.Validate("PassWord"))
+		if strings.Join(got, ",") != ViolationCommonPassword {
+			t.Fatalf("%s: codes: got %v, want [%s]", name, got, ViolationCommonPassword)
+		}
+		if err := p.Validate("tr0ub4dor&3"); err != nil {
+			t.Fatalf("%s: uncommon password rejected: %v", name, err)
+		}
+	}
+}
+
+func TestPasswordPolicy_ErrorListsAllMessages(t *testing.T) {
+	p := PasswordPolicy{MinLength: 10, RequireDigit: true}
+
+	err := p.Validate("abc")
+	if err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "at least 10") || !strings.Contains(err.Error(), "digit") {
+		t.Fatalf("unexpected message: %v", err)
+	}
+}
+
+func TestConfigurePasswordPolicy_AppliesToNewPassword(t *testing.T) {
+	t.Cleanup(func() { ConfigurePasswordPolicy(DefaultPasswordPolicy()) })
+
+	ConfigurePasswordPolicy(PasswordPolicy{MinLength: 10})
+	if _, err := NewPassword("secret123"); err == nil {
+		t.Fatalf("expected error with MinLength 10, got nil")
+	}
+	if _, err := NewPassword("secret1234"); err != nil {
+		t.Fatalf("NewPassword error: %v", err)
+	}
+}
+
+func TestNewPassword_RejectsPersonalInfoByDefault(t *testing.T) {
+	_, err := NewPassword("anasilva1", "Ana Silva", "ana@example.com")
+	if got := violationCodes(t, err); strings.Join(got, ",") != ViolationPersonalInfo {
+		t.Fatalf("codes: got %v, want [%s]", got, ViolationPersonalInfo)
+	}
+}
@@ -144,3 +144,32 @@ func TestUpdateUserRequest_FieldRules(t *testing.T) {
 		t.Fatalf("expected valid UpdateUserRequest with all fields present, got error: %v", err)
 	}
 }
+
+func TestChangeEmailRequest_Rules(t *testing.T) {
+	v := newValidator()
+
+	if err := v.Struct(ChangeEmailRequest{}); err == nil {
+		t.Fatalf("expected error when Email is missing")
+	}
+	if err := v.Struct(ChangeEmailRequest{Email: "invalid-email"}); err == nil {
+		t.Fatalf("expected error when Email is invalid")
+	}
+	if err := v.Struct(ChangeEmailRequest{Email: "ana@example.com"}); err != nil {
+		t.Fatalf("expected valid ChangeEmailRequest, got error: %v", err)
+	}
+}
+
+func TestListUsersQuery_SortRule(t *testing.T) {
+	v := newValidator()
+
+	for _, sort := range []string{"", "createdAt", "-createdAt", "updatedAt", "-lastLoginAt", "deactivatedAt"} {
+		if err := v.Struct(ListUsersQuery{Sort: sort}); err != nil {
+			t.Fatalf("sort %q: expected valid, got error: %v", sort, err)
+		}
+	}
+	for _, sort := range []string{"name", "+createdAt", "created_at"} {
+		if err := v.Struct(ListUsersQuery{Sort: sort}); err == nil {
+			t.Fatalf("sort %q: expected validation error", sort)
+		}
+	}
+}
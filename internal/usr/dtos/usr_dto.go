@@ -1,5 +1,7 @@
 package dtos
 
+import "time"
+
 type CreateUserRequest struct {
 	Name     string `json:"name" binding:"required,min=1"`
 	Email    string `json:"email" binding:"required,email"`
@@ -14,3 +16,55 @@ type UpdateUserRequest struct {
 	Active   *bool   `json:"active"`
 	UserType *string `json:"userType" binding:"omitempty,oneof=Admin User"`
 }
+
+// StatusTransitionRequest é o corpo opcional das rotas de status (activate, suspend, lock, deactivate)
+type StatusTransitionRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// VerifyEmailRequest consome o token enviado no link de verificação
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ResendVerificationRequest pede um novo link para o endereço informado
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ForgotPasswordRequest pede um link de redefinição de senha
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest troca a senha com o token recebido por email
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// ChangePasswordRequest troca a senha do próprio usuário (POST /me/password)
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required,max=200"`
+	NewPassword     string `json:"newPassword" binding:"required,min=6"`
+}
+
+type ChangeEmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ListUsersQuery são os filtros de GET /users; datas em RFC 3339, sort com "-"
+// para ordem decrescente e status repetível (?status=locked&status=suspended)
+type ListUsersQuery struct {
+	IncludeDeleted    bool      `form:"includeDeleted"`
+	Status            []string  `form:"status" binding:"omitempty,dive,oneof=pending_verification active suspended locked deactivated"`
+	Sort              string    `form:"sort" binding:"omitempty,oneof=createdAt -createdAt updatedAt -updatedAt lastLoginAt -lastLoginAt deactivatedAt -deactivatedAt"`
+	CreatedAfter      time.Time `form:"createdAfter" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedBefore     time.Time `form:"createdBefore" time_format:"2006-01-02T15:04:05Z07:00"`
+	UpdatedAfter      time.Time `form:"updatedAfter" time_format:"2006-01-02T15:04:05Z07:00"`
+	UpdatedBefore     time.Time `form:"updatedBefore" time_format:"2006-01-02T15:04:05Z07:00"`
+	LastLoginAfter    time.Time `form:"lastLoginAfter" time_format:"2006-01-02T15:04:05Z07:00"`
+	LastLoginBefore   time.Time `form:"lastLoginBefore" time_format:"2006-01-02T15:04:05Z07:00"`
+	DeactivatedAfter  time.Time `form:"deactivatedAfter" time_format:"2006-01-02T15:04:05Z07:00"`
+	DeactivatedBefore time.Time `form:"deactivatedBefore" time_format:"2006-01-02T15:04:05Z07:00"`
+}
@@ -0,0 +1,20 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+)
+
+// AuditLog registra cada evento no log padrão, com o payload em JSON
+func AuditLog(ctx context.Context, e domain.Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	org, _ := OrgID(ctx)
+	log.Printf("audit: %s org=%s user=%s %s", e.EventName(), org, e.AggregateID(), payload)
+	return nil
+}
@@ -0,0 +1,69 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+)
+
+// Subscriber reage a um evento de domínio. Erros não interrompem os demais assinantes.
+type Subscriber func(ctx context.Context, e domain.Event) error
+
+// Dispatcher entrega eventos de domínio, de forma síncrona e em processo,
+// aos assinantes registrados para o nome do evento ou para todos os eventos
+type Dispatcher struct {
+	mu     sync.RWMutex
+	byName map[string][]Subscriber
+	all    []Subscriber
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{byName: make(map[string][]Subscriber)}
+}
+
+// Subscribe registra o assinante para os eventos com o nome informado (ex.: domain.EventUserDeleted)
+func (d *Dispatcher) Subscribe(name string, fn Subscriber) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.byName[name] = append(d.byName[name], fn)
+}
+
+// SubscribeAll registra o assinante para todos os eventos (auditoria, webhooks)
+func (d *Dispatcher) SubscribeAll(fn Subscriber) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.all = append(d.all, fn)
+}
+
+// Dispatch entrega os eventos na ordem em que ocorreram. Um assinante que
+// falha (ou entra em pânico) não impede a entrega aos demais; os erros são
+// devolvidos agregados.
+func (d *Dispatcher) Dispatch(ctx context.Context, evts ...domain.Event) error {
+	var errs []error
+	for _, e := range evts {
+		d.mu.RLock()
+		subs := make([]Subscriber, 0, len(d.all)+len(d.byName[e.EventName()]))
+		subs = append(subs, d.byName[e.EventName()]...)
+		subs = append(subs, d.all...)
+		d.mu.RUnlock()
+
+		for _, fn := range subs {
+			if err := deliver(ctx, fn, e); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", e.EventName(), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func deliver(ctx context.Context, fn Subscriber, e domain.Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("subscriber panic: %v", r)
+		}
+	}()
+	return fn(ctx, e)
+}
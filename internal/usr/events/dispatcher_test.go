@@ -0,0 +1,73 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+)
+
+func TestDispatcher_DeliversByNameAndToAll(t *testing.T) {
+	d := NewDispatcher()
+
+	var deleted, all []string
+	d.Subscribe(domain.EventUserDeleted, func(ctx context.Context, e domain.Event) error {
+		deleted = append(deleted, string(e.AggregateID()))
+		return nil
+	})
+	d.SubscribeAll(func(ctx context.Context, e domain.Event) error {
+		all = append(all, e.EventName())
+		return nil
+	})
+
+	err := d.Dispatch(context.Background(),
+		domain.UserCreated{EventMeta: domain.EventMeta{UserID: "u1"}},
+		domain.UserDeleted{EventMeta: domain.EventMeta{UserID: "u1"}},
+	)
+	if err != nil {
+		t.Fatalf("Dispatch error: %v", err)
+	}
+
+	if len(deleted) != 1 || deleted[0] != "u1" {
+		t.Fatalf("deleted subscriber: got %v", deleted)
+	}
+	if strings.Join(all, ",") != domain.EventUserCreated+","+domain.EventUserDeleted {
+		t.Fatalf("all subscriber: got %v", all)
+	}
+}
+
+func TestDispatcher_FailingSubscriberDoesNotStopOthers(t *testing.T) {
+	d := NewDispatcher()
+
+	d.SubscribeAll(func(ctx context.Context, e domain.Event) error {
+		return errors.New("webhook down")
+	})
+	d.SubscribeAll(func(ctx context.Context, e domain.Event) error {
+		panic("boom")
+	})
+	delivered := 0
+	d.SubscribeAll(func(ctx context.Context, e domain.Event) error {
+		delivered++
+		return nil
+	})
+
+	err := d.Dispatch(context.Background(), domain.UserRestored{EventMeta: domain.EventMeta{UserID: "u1"}})
+	if err == nil {
+		t.Fatalf("expected aggregated error, got nil")
+	}
+	if !strings.Contains(err.Error(), "webhook down") || !strings.Contains(err.Error(), "panic: boom") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if delivered != 1 {
+		t.Fatalf("healthy subscriber: got %d deliveries, want 1", delivered)
+	}
+}
+
+func TestDispatcher_NoSubscribers(t *testing.T) {
+	d := NewDispatcher()
+	if err := d.Dispatch(context.Background(), domain.UserActivated{}); err != nil {
+		t.Fatalf("Dispatch without subscribers: %v", err)
+	}
+}
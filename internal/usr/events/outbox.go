@@ -0,0 +1,36 @@
+package events
+
+import (
+	"context"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/repository"
+)
+
+type (
+	messageIDKey struct{}
+	orgIDKey     struct{}
+)
+
+// MessageID devolve o ID de deduplicação da mensagem do outbox sendo entregue,
+// para assinantes que precisam ignorar reentregas
+func MessageID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(messageIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// OrgID devolve a organização do usuário cujo evento está sendo entregue
+func OrgID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(orgIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// OutboxPublisher entrega as mensagens do outbox aos assinantes do dispatcher.
+// Se algum assinante falhar a mensagem volta a ser entregue a todos na próxima
+// rodada do relay, então os assinantes devem ser idempotentes (ver MessageID).
+func OutboxPublisher(d *Dispatcher) repository.Publisher {
+	return repository.PublisherFunc(func(ctx context.Context, msg repository.OutboxMessage) error {
+		ctx = context.WithValue(ctx, messageIDKey{}, msg.ID)
+		ctx = context.WithValue(ctx, orgIDKey{}, msg.OrgID)
+		return d.Dispatch(ctx, msg.Event)
+	})
+}
@@ -0,0 +1,59 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/repository"
+)
+
+func TestOutboxPublisher_ExposesMessageID(t *testing.T) {
+	d := NewDispatcher()
+
+	var ids []string
+	d.Subscribe(domain.EventUserCreated, func(ctx context.Context, e domain.Event) error {
+		id, ok := MessageID(ctx)
+		if !ok {
+			return errors.New("missing message id")
+		}
+		if org, _ := OrgID(ctx); org != "acme" {
+			return errors.New("missing organization")
+		}
+		ids = append(ids, id)
+		return nil
+	})
+
+	pub := OutboxPublisher(d)
+	msg := repository.OutboxMessage{
+		ID:    "u1:1:0",
+		OrgID: "acme",
+		Event: domain.UserCreated{EventMeta: domain.EventMeta{UserID: "u1"}},
+	}
+	if err := pub.Publish(context.Background(), msg); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "u1:1:0" {
+		t.Fatalf("message ids: got %v", ids)
+	}
+
+	if _, ok := MessageID(context.Background()); ok {
+		t.Fatalf("MessageID outside a delivery should be absent")
+	}
+}
+
+func TestOutboxPublisher_SubscriberErrorTriggersRetry(t *testing.T) {
+	d := NewDispatcher()
+	d.SubscribeAll(func(ctx context.Context, e domain.Event) error {
+		return errors.New("webhook down")
+	})
+
+	err := OutboxPublisher(d).Publish(context.Background(), repository.OutboxMessage{
+		ID:    "u1:1:0",
+		Event: domain.UserCreated{EventMeta: domain.EventMeta{UserID: "u1"}},
+	})
+	if err == nil {
+		t.Fatalf("expected error so the relay retries the message")
+	}
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+)
+
+// etagFor gera a ETag forte de uma versão do usuário
+func etagFor(version uint64) string {
+	return `"` + strconv.FormatUint(version, 10) + `"`
+}
+
+// ifMatchAllows avalia o cabeçalho If-Match contra a versão atual.
+// Aceita "*" e listas separadas por vírgula; o prefixo W/ é ignorado.
+func ifMatchAllows(header string, version uint64) bool {
+	current := etagFor(version)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == current {
+			return true
+		}
+	}
+	return false
+}
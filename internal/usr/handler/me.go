@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/dtos"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/mappers"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/validation"
+)
+
+// loadMe carrega o usuário do token; API keys não têm conta própria
+func (h *UserHandler) loadMe(ctx context.Context, c *gin.Context) (domain.User, bool) {
+	p, ok := auth.PrincipalFrom(ctx)
+	if !ok || p.UserID == "" || p.KeyID != "" {
+		c.Header("WWW-Authenticate", "Bearer")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return domain.User{}, false
+	}
+
+	u, ok, err := h.repoFor(ctx).GetByID(ctx, p.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return domain.User{}, false
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return domain.User{}, false
+	}
+	return u, true
+}
+
+// GetMe devolve o usuário autenticado (GET /me)
+func (h *UserHandler) GetMe(c *gin.Context) {
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	u, ok := h.loadMe(ctx, c)
+	if !ok {
+		return
+	}
+
+	response := mappers.ToUserResponse(u)
+	c.Header("ETag", etagFor(response.Version))
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateMe altera o próprio usuário (PATCH /me) com as regras do PATCH
+// /users/:id. Tipo e ativação exigem users:write, como numa conta alheia; a
+// senha só muda por POST /me/password, que confere a atual.
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	var req dtos.UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.RespondValidationError(c, err)
+		return
+	}
+	if req.Password != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "use POST /me/password to change the password"})
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	current, ok := h.loadMe(ctx, c)
+	if !ok {
+		return
+	}
+	if (req.UserType != nil || req.Active != nil) && !h.allowed(c, rbac.PermUsersWrite) {
+		return
+	}
+	h.update(c, ctx, current, req)
+}
+
+// ChangeMyPassword troca a senha do próprio usuário mediante a senha atual
+// (POST /me/password); um token roubado não basta para tomar a conta. As
+// sessões abertas caem, inclusive a atual: quem troca a senha por suspeita
+// de vazamento não deixa o invasor logado.
+func (h *UserHandler) ChangeMyPassword(c *gin.Context) {
+	var req dtos.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.RespondValidationError(c, err)
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	current, ok := h.loadMe(ctx, c)
+	if !ok {
+		return
+	}
+	if !current.Password.Compare(req.CurrentPassword) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "current password is incorrect"})
+		return
+	}
+
+	updated := current
+	if err := updated.ChangePassword(req.NewPassword); err != nil {
+		respondDomainError(c, err)
+		return
+	}
+	updated.RevokeSessions(time.Now().UTC())
+	if _, ok := h.save(c, ctx, current, updated, false); !ok {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteMe encerra a própria conta (DELETE /me). É a mesma exclusão lógica do
+// DELETE /users/:id: as sessões caem e um administrador ainda pode restaurá-la.
+func (h *UserHandler) DeleteMe(c *gin.Context) {
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	u, ok := h.loadMe(ctx, c)
+	if !ok {
+		return
+	}
+	h.remove(c, ctx, u)
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/repository"
+)
+
+func routerWithMeRoutes(t *testing.T) (*gin.Engine, repository.UserRepository, domain.User) {
+	t.Helper()
+	repo := repository.NewInMemoryUserRepository()
+	me, err := repo.Create(context.Background(), mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	resolver := permissionsFunc(func(ctx context.Context, roles []string) (rbac.PermissionSet, error) {
+		set := rbac.PermissionSet{}
+		for _, r := range roles {
+			if r == rbac.RoleAdmin {
+				set[rbac.PermUsersWrite] = true
+			}
+		}
+		return set, nil
+	})
+
+	h := NewUserHandler(repo, WithPermissionResolver(resolver))
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if roles := c.GetHeader("X-Test-Roles"); roles != "" {
+			auth.SetPrincipal(c, auth.Principal{UserID: me.ID, Roles: strings.Split(roles, ",")})
+		}
+		c.Next()
+	})
+	r.GET("/me", h.GetMe)
+	r.PATCH("/me", h.UpdateMe)
+	r.POST("/me/password", h.ChangeMyPassword)
+	r.DELETE("/me", h.DeleteMe)
+	r.PATCH("/users/:id", h.UpdateUser)
+	return r, repo, me
+}
+
+func TestMe_ReadAndUpdateOwnAccount(t *testing.T) {
+	r, _, me := routerWithMeRoutes(t)
+	user := map[string]string{"X-Test-Roles": "user"}
+
+	if w := doJSON(t, r, http.MethodGet, "/me", nil); w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") == "" {
+		t.Fatalf("anonymous: got %d", w.Code)
+	}
+	w := doJSONWithHeaders(t, r, http.MethodGet, "/me", nil, user)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), string(me.ID)) || w.Header().Get("ETag") == "" {
+		t.Fatalf("get: got %d body=%s", w.Code, w.Body.String())
+	}
+
+	if w := doJSONWithHeaders(t, r, http.MethodPatch, "/me", map[string]any{"name": "Ana Paula"}, user); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Ana Paula") {
+		t.Fatalf("rename: got %d body=%s", w.Code, w.Body.String())
+	}
+	for _, body := range []map[string]any{{"userType": "User"}, {"active": false}} {
+		if w := doJSONWithHeaders(t, r, http.MethodPatch, "/me", body, user); w.Code != http.StatusForbidden {
+			t.Fatalf("%v as user: got %d", body, w.Code)
+		}
+	}
+	if w := doJSONWithHeaders(t, r, http.MethodPatch, "/me", map[string]any{"password": "another-secret-1"}, user); w.Code != http.StatusBadRequest {
+		t.Fatalf("password through PATCH /me: got %d", w.Code)
+	}
+	if w := doJSONWithHeaders(t, r, http.MethodPatch, "/me", map[string]any{"active": false}, map[string]string{"X-Test-Roles": "admin"}); w.Code != http.StatusOK {
+		t.Fatalf("admin deactivating own account: got %d body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestMe_ChangePasswordRequiresTheCurrentOne(t *testing.T) {
+	r, repo, me := routerWithMeRoutes(t)
+	user := map[string]string{"X-Test-Roles": "user"}
+
+	wrong := map[string]any{"currentPassword": "not-my-password", "newPassword": "another-secret-1"}
+	if w := doJSONWithHeaders(t, r, http.MethodPost, "/me/password", wrong, user); w.Code != http.StatusForbidden {
+		t.Fatalf("wrong current password: got %d", w.Code)
+	}
+	weak := map[string]any{"currentPassword": "secret123", "newPassword": "ana@example.com"}
+	if w := doJSONWithHeaders(t, r, http.MethodPost, "/me/password", weak, user); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("weak new password: got %d body=%s", w.Code, w.Body.String())
+	}
+
+	ok := map[string]any{"currentPassword": "secret123", "newPassword": "another-secret-1"}
+	if w := doJSONWithHeaders(t, r, http.MethodPost, "/me/password", ok, user); w.Code != http.StatusNoContent {
+		t.Fatalf("change password: got %d body=%s", w.Code, w.Body.String())
+	}
+	stored, _, _ := repo.GetByID(context.Background(), me.ID)
+	if !stored.Password.Compare("another-secret-1") || stored.Password.Compare("secret123") {
+		t.Fatalf("password was not replaced")
+	}
+	// As sessões abertas com a senha antiga caem
+	if stored.SessionsRevokedAt == nil || stored.SessionsRevokedAt.Before(me.CreatedAt) {
+		t.Fatalf("sessions were not revoked: %v", stored.SessionsRevokedAt)
+	}
+}
+
+func TestUpdateUser_OwnPasswordGoesThroughMe(t *testing.T) {
+	r, repo, me := routerWithMeRoutes(t)
+	other, err := repo.Create(context.Background(), mustUser(t, "Bia", "bia@example.com", true, domain.UserTypeUser))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	admin := map[string]string{"X-Test-Roles": "admin"}
+	body := map[string]any{"password": "another-secret-1"}
+
+	// PATCH /users/:id na própria conta não escapa da senha atual
+	if w := doJSONWithHeaders(t, r, http.MethodPatch, "/users/"+string(me.ID), body, map[string]string{"X-Test-Roles": "user"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("own password through PATCH /users/:id: got %d", w.Code)
+	}
+	if w := doJSONWithHeaders(t, r, http.MethodPatch, "/users/"+string(me.ID), body, admin); w.Code != http.StatusBadRequest {
+		t.Fatalf("admin's own password through PATCH /users/:id: got %d", w.Code)
+	}
+	stored, _, _ := repo.GetByID(context.Background(), me.ID)
+	if !stored.Password.Compare("secret123") {
+		t.Fatalf("password should not have changed")
+	}
+
+	if w := doJSONWithHeaders(t, r, http.MethodPatch, "/users/"+string(other.ID), body, admin); w.Code != http.StatusOK {
+		t.Fatalf("admin resetting someone else's password: got %d body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestMe_DeleteClosesOwnAccount(t *testing.T) {
+	r, repo, me := routerWithMeRoutes(t)
+
+	if w := doJSONWithHeaders(t, r, http.MethodDelete, "/me", nil, map[string]string{"X-Test-Roles": "user"}); w.Code != http.StatusNoContent {
+		t.Fatalf("delete: got %d body=%s", w.Code, w.Body.String())
+	}
+	if _, found, _ := repo.GetByID(context.Background(), me.ID); found {
+		t.Fatalf("closed account should no longer be found")
+	}
+	if w := doJSONWithHeaders(t, r, http.MethodGet, "/me", nil, map[string]string{"X-Test-Roles": "user"}); w.Code != http.StatusNotFound {
+		t.Fatalf("get after delete: got %d", w.Code)
+	}
+}
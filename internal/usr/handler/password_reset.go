@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/metrics"
+	"github.com/williamkoller/cloud-architecture-golang/internal/passwordreset"
+	"github.com/williamkoller/cloud-architecture-golang/internal/tenant"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/dtos"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/repository"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/validation"
+)
+
+// WithPasswordReset habilita a recuperação de senha por email. O link é
+// entregue pelo assinante passwordreset.SendOnRequested do dispatcher.
+func WithPasswordReset(s *passwordreset.Service) Option {
+	return func(h *UserHandler) {
+		h.resets = s
+	}
+}
+
+// ForgotPassword pede o link de redefinição (POST /password/forgot). A
+// resposta é a mesma exista ou não o endereço; o limite vale por endereço.
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	if h.resets == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "password reset is not enabled"})
+		return
+	}
+
+	var req dtos.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.RespondValidationError(c, err)
+		return
+	}
+	email, err := vo.NewEmail(req.Email)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	orgID, _ := tenant.OrgFrom(ctx)
+	if wait, ok := h.resets.AllowRequest(orgID, string(email)); !ok {
+		metrics.PasswordResetRequestedInc(orgID, "throttled")
+		respondThrottled(c, wait, "too many password reset requests; retry later")
+		return
+	}
+
+	u, ok, err := h.repoFor(ctx).GetByEmail(ctx, email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// O token e o email saem pelo evento (SendOnRequested), fora da requisição:
+	// o tempo de resposta não depende do notifier nem revela o cadastro
+	outcome := "ignored"
+	if ok && canResetPassword(u) {
+		outcome = "sent"
+		requested := u
+		requested.RequestPasswordReset(time.Now().UTC())
+		saved, err := h.repoFor(ctx).Update(ctx, requested)
+		if err != nil {
+			// Falhar aqui revelaria que o endereço existe
+			outcome = "failed"
+			log.Printf("password reset request for user %s failed: %v", u.ID, err)
+		} else {
+			h.invalidateCache(u)
+			h.publish(ctx, &saved)
+		}
+	}
+	metrics.PasswordResetRequestedInc(orgID, outcome)
+
+	c.JSON(http.StatusAccepted, gin.H{"status": "if the address is registered, a reset link was sent"})
+}
+
+// ResetPassword troca a senha com o token do link (POST /password/reset) e
+// invalida as sessões existentes do usuário
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	if h.resets == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "password reset is not enabled"})
+		return
+	}
+
+	var req dtos.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.RespondValidationError(c, err)
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	orgID, _ := tenant.OrgFrom(ctx)
+	recipient, err := h.resets.Lookup(req.Token)
+	if err != nil {
+		metrics.PasswordResetCompletedInc(orgID, "invalid_token")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Token de um usuário que sumiu, trocou de email ou não pode mais redefinir
+	// a senha: descartado, com a mesma resposta de um token inválido
+	current, ok, err := h.findResetUser(ctx, recipient)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !ok {
+		h.resets.Consume(req.Token)
+		metrics.PasswordResetCompletedInc(orgID, "invalid_token")
+		c.JSON(http.StatusBadRequest, gin.H{"error": passwordreset.ErrInvalidToken.Error()})
+		return
+	}
+
+	updated := current
+	if err := updated.ResetPassword(req.Password, time.Now().UTC()); err != nil {
+		metrics.PasswordResetCompletedInc(current.OrgID, "rejected_password")
+		respondDomainError(c, err)
+		return
+	}
+
+	updated, err = h.repoFor(ctx).Update(ctx, updated)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			c.JSON(http.StatusBadRequest, gin.H{"error": passwordreset.ErrInvalidToken.Error()})
+		case repository.ErrVersionConflict:
+			c.JSON(http.StatusConflict, gin.H{"error": "user was modified concurrently; retry"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	h.resets.Consume(req.Token)
+
+	h.invalidateCache(current)
+	metrics.UsersUpdatedInc(updated.OrgID)
+	metrics.PasswordResetCompletedInc(updated.OrgID, "success")
+	h.publish(ctx, &updated)
+
+	c.Status(http.StatusNoContent)
+}
+
+// findResetUser carrega o usuário do token e confere se o link ainda se aplica a ele
+func (h *UserHandler) findResetUser(ctx context.Context, r passwordreset.Recipient) (domain.User, bool, error) {
+	id, err := vo.ParseUserID(r.UserID)
+	if err != nil {
+		return domain.User{}, false, nil
+	}
+	u, ok, err := h.repoFor(ctx).GetByID(ctx, id)
+	if err != nil || !ok {
+		return domain.User{}, false, err
+	}
+	if string(u.Email) != r.Email || !canResetPassword(u) {
+		return domain.User{}, false, nil
+	}
+	return u, true, nil
+}
+
+// canResetPassword indica se o usuário pode recuperar a senha por email.
+// Contas suspensas, bloqueadas ou desativadas dependem de um administrador.
+func canResetPassword(u domain.User) bool {
+	if u.IsDeleted() {
+		return false
+	}
+	s := u.EffectiveStatus()
+	return s == domain.StatusActive || s == domain.StatusPendingVerification
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/mappers"
+)
+
+// RoleCatalog informa quais papéis existem e podem ser atribuídos
+type RoleCatalog interface {
+	RoleExists(ctx context.Context, name string) (bool, error)
+}
+
+// WithRoleCatalog valida as atribuições de papel contra o catálogo; sem ele só
+// os papéis embutidos são aceitos
+func WithRoleCatalog(rc RoleCatalog) Option {
+	return func(h *UserHandler) {
+		h.roles = rc
+	}
+}
+
+// WithPermissionResolver ativa verificações que dependem do conteúdo da
+// requisição, como exigir roles:write para promover alguém a Admin via userType
+func WithPermissionResolver(r auth.PermissionResolver) Option {
+	return func(h *UserHandler) {
+		h.permissions = r
+	}
+}
+
+// roleExists consulta o catálogo ou, sem ele, os papéis embutidos
+func (h *UserHandler) roleExists(ctx context.Context, name string) (bool, error) {
+	if h.roles == nil {
+		return name == rbac.RoleAdmin || name == rbac.RoleUser, nil
+	}
+	return h.roles.RoleExists(ctx, name)
+}
+
+// allowed confere uma permissão extra do principal e responde 401/403 quando
+// falta; sem resolver configurado não há verificação
+func (h *UserHandler) allowed(c *gin.Context, perm rbac.Permission) bool {
+	if h.permissions == nil {
+		return true
+	}
+	return auth.Authorize(c, h.permissions, perm)
+}
+
+// AssignRole concede um papel ao usuário (PUT /users/:id/roles/:role)
+func (h *UserHandler) AssignRole(c *gin.Context) {
+	h.changeRole(c, true)
+}
+
+// RevokeRole retira um papel do usuário (DELETE /users/:id/roles/:role)
+func (h *UserHandler) RevokeRole(c *gin.Context) {
+	h.changeRole(c, false)
+}
+
+// changeRole concede ou retira o papel; respeita If-Match como o PATCH
+func (h *UserHandler) changeRole(c *gin.Context, assign bool) {
+	role := strings.TrimSpace(c.Param("role"))
+	if !rbac.ValidRoleName(role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": rbac.ErrInvalidRoleName.Error()})
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	current, ok := h.loadUser(ctx, c)
+	if !ok {
+		return
+	}
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch != "" && !ifMatchAllows(ifMatch, current.Version) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "user was modified; reload and retry"})
+		return
+	}
+
+	updated := current
+	var err error
+	if assign {
+		exists, lookupErr := h.roleExists(ctx, role)
+		if lookupErr != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": lookupErr.Error()})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "role not found"})
+			return
+		}
+		err = updated.AssignRole(role)
+	} else {
+		err = updated.RevokeRole(role)
+	}
+	if err != nil {
+		respondDomainError(c, err)
+		return
+	}
+
+	updated, ok = h.save(c, ctx, current, updated, ifMatch != "")
+	if !ok {
+		return
+	}
+
+	response := mappers.ToUserResponse(updated)
+	h.setCachedUser(updated, response)
+
+	c.Header("ETag", etagFor(response.Version))
+	c.JSON(http.StatusOK, response)
+}
+
+// allowedOver confere se o principal tem todas as permissões do usuário antes
+// de trocar a senha ou o email dele (ver auth.AuthorizeOver); sem resolver
+// configurado não há verificação
+func (h *UserHandler) allowedOver(c *gin.Context, target domain.User) bool {
+	if h.permissions == nil {
+		return true
+	}
+	return auth.AuthorizeOver(c, h.permissions, target.EffectiveRoles())
+}
+
+// grantsAdmin indica se a mudança de tipo concede o papel admin
+func grantsAdmin(current domain.User, t domain.UserType) bool {
+	return t == domain.UserTypeAdmin && !current.HasRole(rbac.RoleAdmin)
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/dtos"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/mappers"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/validation"
+)
+
+// ActivateUser leva o usuário para active (POST /users/:id/activate)
+func (h *UserHandler) ActivateUser(c *gin.Context) {
+	h.changeStatus(c, domain.StatusActive)
+}
+
+// SuspendUser suspende a conta (POST /users/:id/suspend)
+func (h *UserHandler) SuspendUser(c *gin.Context) {
+	h.changeStatus(c, domain.StatusSuspended)
+}
+
+// LockUser bloqueia a conta (POST /users/:id/lock)
+func (h *UserHandler) LockUser(c *gin.Context) {
+	h.changeStatus(c, domain.StatusLocked)
+}
+
+// DeactivateUser desativa a conta (POST /users/:id/deactivate)
+func (h *UserHandler) DeactivateUser(c *gin.Context) {
+	h.changeStatus(c, domain.StatusDeactivated)
+}
+
+// changeStatus aplica a transição com o motivo do corpo (opcional) e o
+// principal da requisição como autor. Respeita If-Match como o PATCH.
+func (h *UserHandler) changeStatus(c *gin.Context, to domain.Status) {
+	var req dtos.StatusTransitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		validation.RespondValidationError(c, err)
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	current, ok := h.loadUser(ctx, c)
+	if !ok {
+		return
+	}
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch != "" && !ifMatchAllows(ifMatch, current.Version) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "user was modified; reload and retry"})
+		return
+	}
+
+	updated := current
+	if err := updated.ChangeStatus(to, req.Reason, actorOf(ctx)); err != nil {
+		respondStatusError(c, err)
+		return
+	}
+
+	updated, ok = h.save(c, ctx, current, updated, ifMatch != "")
+	if !ok {
+		return
+	}
+
+	response := mappers.ToUserResponse(updated)
+	h.setCachedUser(updated, response)
+
+	c.Header("ETag", etagFor(response.Version))
+	c.JSON(http.StatusOK, response)
+}
+
+// respondStatusError responde 409 para transições que o status atual não
+// permite e 422 para os demais erros de domínio
+func respondStatusError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrInvalidStatusTransition) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	respondDomainError(c, err)
+}
+
+// actorOf identifica quem fez a mudança; vazio quando não há autenticação e
+// "apikey:<prefixo>" quando a credencial é uma API key
+func actorOf(ctx context.Context) string {
+	p, ok := auth.PrincipalFrom(ctx)
+	switch {
+	case !ok:
+		return ""
+	case p.UserID == "" && p.KeyID != "":
+		return "apikey:" + p.KeyID
+	}
+	return string(p.UserID)
+}
@@ -2,6 +2,8 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
 	"strings"
 	"sync"
@@ -9,13 +11,19 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
 	"github.com/williamkoller/cloud-architecture-golang/internal/metrics"
+	"github.com/williamkoller/cloud-architecture-golang/internal/passwordreset"
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/tenant"
 	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
 	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
 	"github.com/williamkoller/cloud-architecture-golang/internal/usr/dtos"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/events"
 	"github.com/williamkoller/cloud-architecture-golang/internal/usr/mappers"
 	"github.com/williamkoller/cloud-architecture-golang/internal/usr/repository"
 	"github.com/williamkoller/cloud-architecture-golang/internal/usr/validation"
+	"github.com/williamkoller/cloud-architecture-golang/internal/verification"
 )
 
 // CacheItem representa um item no cache com TTL
@@ -35,18 +43,75 @@ type UserHandler struct {
 	// Configurações de performance
 	cacheTTL       time.Duration
 	requestTimeout time.Duration
+	// dispatcher recebe os eventos de domínio após cada escrita bem-sucedida
+	dispatcher *events.Dispatcher
+	// roles e permissions são opcionais (ver WithRoleCatalog e WithPermissionResolver)
+	roles       RoleCatalog
+	permissions auth.PermissionResolver
+	// tenants, quando presente, escolhe o repositório pela organização da requisição
+	tenants *repository.TenantRepositories
+	// verifier, quando presente, cria usuários pendentes de verificação de email
+	verifier *verification.Service
+	// resets habilita /password/forgot e /password/reset
+	resets *passwordreset.Service
 }
 
-func NewUserHandler(repo repository.UserRepository) *UserHandler {
+// Option configura dependências opcionais do handler
+type Option func(*UserHandler)
+
+// WithEventDispatcher publica os eventos de domínio direto no dispatcher, após a
+// escrita. Não é confiável contra quedas do processo: com o repositório usando
+// outbox (repository.WithOutbox), deixe a entrega para o OutboxRelay.
+func WithEventDispatcher(d *events.Dispatcher) Option {
+	return func(h *UserHandler) {
+		h.dispatcher = d
+	}
+}
+
+// WithTenants atende cada requisição com o repositório da organização resolvida
+// pelo tenant.Middleware; requisições sem organização usam o repositório padrão
+func WithTenants(t *repository.TenantRepositories) Option {
+	return func(h *UserHandler) {
+		h.tenants = t
+	}
+}
+
+func NewUserHandler(repo repository.UserRepository, opts ...Option) *UserHandler {
 	handler := &UserHandler{
 		repo:           repo,
 		cacheTTL:       30 * time.Second, // TTL mais curto para consistência
 		requestTimeout: 5 * time.Second,  // Timeout mais generoso
 	}
 
+	for _, opt := range opts {
+		opt(handler)
+	}
+
 	return handler
 }
 
+// publish retira os eventos pendentes do usuário e os entrega aos assinantes.
+// A escrita já foi confirmada, então falhas dos assinantes só são registradas.
+func (h *UserHandler) publish(ctx context.Context, u *domain.User) {
+	evts := u.PullEvents()
+	if h.dispatcher == nil || len(evts) == 0 {
+		return
+	}
+	if err := h.dispatcher.Dispatch(ctx, evts...); err != nil {
+		log.Printf("user event subscribers failed: %v", err)
+	}
+}
+
+// repoFor devolve o repositório da organização da requisição
+func (h *UserHandler) repoFor(ctx context.Context) repository.UserRepository {
+	if h.tenants != nil {
+		if orgID, ok := tenant.OrgFrom(ctx); ok {
+			return h.tenants.For(orgID)
+		}
+	}
+	return h.repo
+}
+
 // startCacheCleanup inicia limpeza periódica do cache (removido - pode causar crashes)
 
 // ctx cria um contexto com timeout otimizado
@@ -54,32 +119,104 @@ func (h *UserHandler) ctx(c *gin.Context) (context.Context, context.CancelFunc)
 	return context.WithTimeout(c.Request.Context(), h.requestTimeout)
 }
 
-// getCachedUser busca usuário no cache
-func (h *UserHandler) getCachedUser(email string) (mappers.UserResponse, bool) {
-	if cached, ok := h.cache.Load(email); ok {
+// cacheKey separa as entradas do cache por organização, já que o mesmo email
+// pode existir em mais de uma
+func cacheKey(orgID, key string) string {
+	if orgID == "" {
+		return key
+	}
+	return orgID + "/" + key
+}
+
+// getCachedUser busca usuário no cache pelo ID ou email, na organização da requisição
+func (h *UserHandler) getCachedUser(ctx context.Context, ref string) (mappers.UserResponse, bool) {
+	orgID, _ := tenant.OrgFrom(ctx)
+	key := cacheKey(orgID, ref)
+	if cached, ok := h.cache.Load(key); ok {
 		if item, ok := cached.(*CacheItem); ok {
 			if !item.IsExpired() {
 				return item.Value, true
 			}
 			// Remove item expirado de forma segura
-			h.cache.Delete(email)
+			h.cache.Delete(key)
 		}
 	}
 	return mappers.UserResponse{}, false
 }
 
-// setCachedUser armazena usuário no cache
-func (h *UserHandler) setCachedUser(email string, user mappers.UserResponse) {
+// setCachedUser armazena a resposta no cache sob o ID e o email canônico
+func (h *UserHandler) setCachedUser(u domain.User, resp mappers.UserResponse) {
 	item := &CacheItem{
-		Value:     user,
+		Value:     resp,
 		ExpiresAt: time.Now().Add(h.cacheTTL),
 	}
-	h.cache.Store(email, item)
+	h.cache.Store(cacheKey(u.OrgID, string(u.ID)), item)
+	h.cache.Store(cacheKey(u.OrgID, string(u.Email)), item)
+}
+
+// invalidateCache remove as entradas do usuário do cache
+func (h *UserHandler) invalidateCache(u domain.User) {
+	h.cache.Delete(cacheKey(u.OrgID, string(u.ID)))
+	h.cache.Delete(cacheKey(u.OrgID, string(u.Email)))
 }
 
-// invalidateCache remove usuário do cache
-func (h *UserHandler) invalidateCache(email string) {
-	h.cache.Delete(email)
+// respondDomainError responde 422; violações da política de senha seguem
+// estruturadas para que o cliente possa exibi-las regra a regra
+func respondDomainError(c *gin.Context, err error) {
+	var policyErr *vo.PolicyError
+	if errors.As(err, &policyErr) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":      err.Error(),
+			"violations": policyErr.Violations,
+		})
+		return
+	}
+	c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+}
+
+// parseUserRef interpreta o parâmetro de rota, que pode ser o ID do usuário
+// ou, enquanto durar a migração das rotas antigas, o email (já canonicalizado)
+func parseUserRef(ref string) (vo.UserID, vo.Email, error) {
+	if strings.Contains(ref, "@") {
+		email, err := vo.NewEmail(ref)
+		return "", email, err
+	}
+	id, err := vo.ParseUserID(ref)
+	return id, "", err
+}
+
+// findUser busca o usuário pelo ID ou email já interpretados
+func (h *UserHandler) findUser(ctx context.Context, id vo.UserID, email vo.Email) (domain.User, bool, error) {
+	if id != "" {
+		return h.repoFor(ctx).GetByID(ctx, id)
+	}
+	return h.repoFor(ctx).GetByEmail(ctx, email)
+}
+
+// loadUser resolve o parâmetro :id e responde com o erro adequado quando falha
+func (h *UserHandler) loadUser(ctx context.Context, c *gin.Context) (domain.User, bool) {
+	ref := strings.TrimSpace(c.Param("id"))
+	if ref == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user identifier is required"})
+		return domain.User{}, false
+	}
+
+	id, email, err := parseUserRef(ref)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user identifier"})
+		return domain.User{}, false
+	}
+
+	u, ok, err := h.findUser(ctx, id, email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return domain.User{}, false
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return domain.User{}, false
+	}
+	return u, true
 }
 
 func (h *UserHandler) CreateUser(c *gin.Context) {
@@ -95,27 +232,41 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
+	// O cadastro anônimo só entra em organizações abertas a ele; quem escolhe
+	// outra pelo header ou pela rota precisa de credencial
+	if _, ok := auth.PrincipalFrom(c.Request.Context()); !ok && tenant.SignupClosed(c.Request.Context()) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "self-signup is not enabled for this organization"})
+		return
+	}
+
 	active := true
 	if req.Active != nil {
 		active = *req.Active
 	}
 
-	u, err := domain.NewUser(
-		strings.TrimSpace(req.Name),
-		strings.TrimSpace(req.Email),
-		req.Password,
-		active,
-		domain.UserType(strings.TrimSpace(req.UserType)),
-	)
+	userType := domain.UserType(strings.TrimSpace(req.UserType))
+	if userType == domain.UserTypeAdmin && !h.allowed(c, rbac.PermRolesWrite) {
+		return
+	}
+
+	var u domain.User
+	var err error
+	if active && h.verifier != nil {
+		// O link é enviado pelo assinante de UserCreated (verification.SendOnCreated)
+		u, err = domain.NewPendingUser(strings.TrimSpace(req.Name), strings.TrimSpace(req.Email), req.Password, userType)
+	} else {
+		u, err = domain.NewUser(strings.TrimSpace(req.Name), strings.TrimSpace(req.Email), req.Password, active, userType)
+	}
 	if err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		respondDomainError(c, err)
 		return
 	}
 
 	ctx, cancel := h.ctx(c)
 	defer cancel()
 
-	if err := h.repo.Create(ctx, u); err != nil {
+	u, err = h.repoFor(ctx).Create(ctx, u)
+	if err != nil {
 		if err == repository.ErrAlreadyExists {
 			c.JSON(http.StatusConflict, gin.H{"error": "user already exists"})
 			return
@@ -125,20 +276,47 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 	}
 
 	// Atualizar métricas de forma síncrona e eficiente
-	metrics.UsersCreatedInc()
+	metrics.UsersCreatedInc(u.OrgID)
+	h.publish(ctx, &u)
 
 	response := mappers.ToUserResponse(u)
 	// Cachear o usuário criado
-	h.setCachedUser(string(u.Email), response)
+	h.setCachedUser(u, response)
 
+	c.Header("ETag", etagFor(response.Version))
 	c.JSON(http.StatusCreated, response)
 }
 
+// listQueryFrom converte os filtros da query string para a consulta do repositório
+func listQueryFrom(req dtos.ListUsersQuery) repository.ListQuery {
+	sortBy := strings.TrimPrefix(req.Sort, "-")
+	var statuses []domain.Status
+	for _, s := range req.Status {
+		statuses = append(statuses, domain.Status(s))
+	}
+	return repository.ListQuery{
+		IncludeDeleted: req.IncludeDeleted,
+		Statuses:       statuses,
+		Created:        repository.TimeRange{From: req.CreatedAfter, To: req.CreatedBefore},
+		Updated:        repository.TimeRange{From: req.UpdatedAfter, To: req.UpdatedBefore},
+		LastLogin:      repository.TimeRange{From: req.LastLoginAfter, To: req.LastLoginBefore},
+		Deactivated:    repository.TimeRange{From: req.DeactivatedAfter, To: req.DeactivatedBefore},
+		SortBy:         repository.SortField(sortBy),
+		SortDesc:       strings.HasPrefix(req.Sort, "-"),
+	}
+}
+
 func (h *UserHandler) ListUsers(c *gin.Context) {
+	var req dtos.ListUsersQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		validation.RespondValidationError(c, err)
+		return
+	}
+
 	ctx, cancel := h.ctx(c)
 	defer cancel()
 
-	users, err := h.repo.List(ctx)
+	users, err := h.repoFor(ctx).List(ctx, listQueryFrom(req))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -154,48 +332,50 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 }
 
 func (h *UserHandler) GetUser(c *gin.Context) {
-	emailParam := strings.TrimSpace(c.Param("email"))
-	if emailParam == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "email parameter is required"})
+	ref := strings.TrimSpace(c.Param("id"))
+	if ref == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user identifier is required"})
 		return
 	}
 
-	// Verificar cache primeiro
-	if cached, found := h.getCachedUser(emailParam); found {
-		c.JSON(http.StatusOK, cached)
+	id, email, err := parseUserRef(ref)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user identifier"})
 		return
 	}
 
-	email, err := vo.NewEmail(emailParam)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid email format"})
+	// Verificar cache primeiro, sempre pela chave canônica
+	key := string(id)
+	if id == "" {
+		key = string(email)
+	}
+	if cached, found := h.getCachedUser(c.Request.Context(), key); found {
+		c.Header("ETag", etagFor(cached.Version))
+		c.JSON(http.StatusOK, cached)
 		return
 	}
 
 	ctx, cancel := h.ctx(c)
 	defer cancel()
 
-	u, ok, err := h.repo.GetByEmail(ctx, email)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
+	u, ok := h.loadUser(ctx, c)
 	if !ok {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 		return
 	}
 
 	userResp := mappers.ToUserResponse(u)
 	// Cachear resultado
-	h.setCachedUser(emailParam, userResp)
+	h.setCachedUser(u, userResp)
 
+	c.Header("ETag", etagFor(userResp.Version))
 	c.JSON(http.StatusOK, userResp)
 }
 
-func (h *UserHandler) UpdateUser(c *gin.Context) {
-	emailParam := strings.TrimSpace(c.Param("email"))
+// LookupUser busca um usuário pelo email via query string (?email=)
+func (h *UserHandler) LookupUser(c *gin.Context) {
+	emailParam := strings.TrimSpace(c.Query("email"))
 	if emailParam == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "email parameter is required"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email query parameter is required"})
 		return
 	}
 
@@ -205,16 +385,16 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	var req dtos.UpdateUserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		validation.RespondValidationError(c, err)
+	if cached, found := h.getCachedUser(c.Request.Context(), string(email)); found {
+		c.Header("ETag", etagFor(cached.Version))
+		c.JSON(http.StatusOK, cached)
 		return
 	}
 
 	ctx, cancel := h.ctx(c)
 	defer cancel()
 
-	current, ok, err := h.repo.GetByEmail(ctx, email)
+	u, ok, err := h.repoFor(ctx).GetByEmail(ctx, email)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -224,67 +404,242 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	// Aplicar mudanças parciais
-	name := current.Name
-	if req.Name != nil {
-		name = strings.TrimSpace(*req.Name)
+	userResp := mappers.ToUserResponse(u)
+	h.setCachedUser(u, userResp)
+
+	c.Header("ETag", etagFor(userResp.Version))
+	c.JSON(http.StatusOK, userResp)
+}
+
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	var req dtos.UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.RespondValidationError(c, err)
+		return
 	}
-	active := current.Active
-	if req.Active != nil {
-		active = *req.Active
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	current, ok := h.loadUser(ctx, c)
+	if !ok {
+		return
+	}
+	h.update(c, ctx, current, req)
+}
+
+// update aplica o PATCH ao usuário já carregado; atende /users/:id e /me
+func (h *UserHandler) update(c *gin.Context, ctx context.Context, current domain.User, req dtos.UpdateUserRequest) {
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch != "" && !ifMatchAllows(ifMatch, current.Version) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "user was modified; reload and retry"})
+		return
+	}
+
+	// Aplicar mudanças parciais pelos métodos do domínio; a senha só é
+	// trocada quando uma nova é enviada
+	updated := current
+	if req.Name != nil {
+		if err := updated.Rename(*req.Name); err != nil {
+			respondDomainError(c, err)
+			return
+		}
 	}
-	userType := current.UserType
 	if req.UserType != nil {
-		userType = domain.UserType(strings.TrimSpace(*req.UserType))
+		userType := domain.UserType(strings.TrimSpace(*req.UserType))
+		if grantsAdmin(current, userType) && !h.allowed(c, rbac.PermRolesWrite) {
+			return
+		}
+		if err := updated.ChangeType(userType); err != nil {
+			respondDomainError(c, err)
+			return
+		}
+	}
+	if req.Active != nil {
+		// A rota aceita o próprio usuário sem users:write, mas ativar ou
+		// desativar a conta continua exigindo a permissão
+		if !h.allowed(c, rbac.PermUsersWrite) {
+			return
+		}
+		// Compatibilidade: active só alterna entre active e deactivated; os
+		// demais status têm rotas próprias (ver status.go)
+		var err error
+		if *req.Active {
+			err = updated.Activate(actorOf(ctx))
+		} else {
+			err = updated.Deactivate(actorOf(ctx))
+		}
+		if err != nil {
+			respondStatusError(c, err)
+			return
+		}
 	}
-	password := string(current.Password)
 	if req.Password != nil && *req.Password != "" {
-		password = *req.Password
+		// Para a própria conta vale a regra do PATCH /me: a senha só muda
+		// conferindo a atual
+		if p, ok := auth.PrincipalFrom(ctx); ok && p.UserID != "" && p.UserID == current.ID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "use POST /me/password to change the password"})
+			return
+		}
+		if !h.allowedOver(c, current) {
+			return
+		}
+		if err := updated.ChangePassword(*req.Password); err != nil {
+			respondDomainError(c, err)
+			return
+		}
+	}
+
+	updated, ok := h.save(c, ctx, current, updated, ifMatch != "")
+	if !ok {
+		return
+	}
+
+	response := mappers.ToUserResponse(updated)
+	// Cachear o usuário atualizado
+	h.setCachedUser(updated, response)
+
+	c.Header("ETag", etagFor(response.Version))
+	c.JSON(http.StatusOK, response)
+}
+
+// save grava a alteração, invalida o cache e publica os eventos; responde o
+// erro quando falha. conditional indica um If-Match, que muda a resposta do conflito.
+func (h *UserHandler) save(c *gin.Context, ctx context.Context, current, updated domain.User, conditional bool) (domain.User, bool) {
+	updated, err := h.repoFor(ctx).Update(ctx, updated)
+	if err != nil {
+		switch {
+		case err == repository.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		case err == repository.ErrVersionConflict && conditional:
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "user was modified; reload and retry"})
+		case err == repository.ErrVersionConflict:
+			c.JSON(http.StatusConflict, gin.H{"error": "user was modified concurrently; retry"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return domain.User{}, false
+	}
+
+	// Invalidar cache e atualizar métricas
+	h.invalidateCache(current)
+	metrics.UsersUpdatedInc(updated.OrgID)
+	h.publish(ctx, &updated)
+	return updated, true
+}
+
+// ChangeEmail troca o email do usuário, rejeitando endereços já em uso
+func (h *UserHandler) ChangeEmail(c *gin.Context) {
+	var req dtos.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.RespondValidationError(c, err)
+		return
 	}
 
-	updated, err := domain.NewUser(name, string(email), password, active, userType)
+	addr, err := vo.ParseEmail(req.Email)
 	if err != nil {
 		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := h.repo.Update(ctx, updated); err != nil {
-		if err == repository.ErrNotFound {
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	current, ok := h.loadUser(ctx, c)
+	if !ok {
+		return
+	}
+	// O email recebe a redefinição de senha: trocá-lo também toma a conta
+	if !h.allowedOver(c, current) {
+		return
+	}
+
+	updated, err := h.repoFor(ctx).ChangeEmail(ctx, current.ID, addr)
+	if err != nil {
+		switch err {
+		case repository.ErrAlreadyExists:
+			c.JSON(http.StatusConflict, gin.H{"error": "email already in use"})
+		case repository.ErrNotFound:
 			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
-			return
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Invalidar cache e atualizar métricas
-	h.invalidateCache(emailParam)
-	metrics.UsersUpdatedInc()
+	// Invalidar as entradas do email antigo e do novo
+	h.invalidateCache(current)
+	h.invalidateCache(updated)
+	metrics.UsersUpdatedInc(updated.OrgID)
+	h.publish(ctx, &updated)
 
 	response := mappers.ToUserResponse(updated)
-	// Cachear o usuário atualizado
-	h.setCachedUser(emailParam, response)
+	h.setCachedUser(updated, response)
 
+	c.Header("ETag", etagFor(response.Version))
 	c.JSON(http.StatusOK, response)
 }
 
 func (h *UserHandler) DeleteUser(c *gin.Context) {
-	emailParam := strings.TrimSpace(c.Param("email"))
-	if emailParam == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "email parameter is required"})
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	u, ok := h.loadUser(ctx, c)
+	if !ok {
 		return
 	}
+	h.remove(c, ctx, u)
+}
 
-	email, err := vo.NewEmail(emailParam)
+// remove exclui logicamente o usuário já carregado; atende /users/:id e /me
+func (h *UserHandler) remove(c *gin.Context, ctx context.Context, u domain.User) {
+	deleted, err := h.repoFor(ctx).Delete(ctx, u.ID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid email format"})
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Invalidar cache e atualizar métricas
+	h.invalidateCache(u)
+	metrics.UsersDeletedInc(u.OrgID)
+	h.publish(ctx, &deleted)
+
+	c.Status(http.StatusNoContent)
+}
+
+// RestoreUser desfaz a exclusão lógica de um usuário (rota administrativa).
+// Aceita o ID ou o email, como as demais rotas.
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	ref := strings.TrimSpace(c.Param("id"))
+	id, email, err := parseUserRef(ref)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user identifier"})
 		return
 	}
 
 	ctx, cancel := h.ctx(c)
 	defer cancel()
 
-	if err := h.repo.Delete(ctx, email); err != nil {
+	// Usuários excluídos não aparecem em GetByEmail: resolver pela listagem
+	if id == "" {
+		matches, err := h.repoFor(ctx).List(ctx, repository.ListQuery{Email: email, IncludeDeleted: true})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if len(matches) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		id = matches[0].ID
+	}
+
+	restored, err := h.repoFor(ctx).Restore(ctx, id)
+	if err != nil {
 		if err == repository.ErrNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 			return
@@ -293,9 +648,13 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	// Invalidar cache e atualizar métricas
-	h.invalidateCache(emailParam)
-	metrics.UsersDeletedInc()
+	h.invalidateCache(restored)
+	metrics.UsersRestoredInc(restored.OrgID)
+	h.publish(ctx, &restored)
 
-	c.Status(http.StatusNoContent)
+	response := mappers.ToUserResponse(restored)
+	h.setCachedUser(restored, response)
+
+	c.Header("ETag", etagFor(response.Version))
+	c.JSON(http.StatusOK, response)
 }
@@ -9,14 +9,22 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
 	"github.com/williamkoller/cloud-architecture-golang/internal/metrics"
+	org "github.com/williamkoller/cloud-architecture-golang/internal/org/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/passwordreset"
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/tenant"
 	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
 	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/events"
 	"github.com/williamkoller/cloud-architecture-golang/internal/usr/mappers"
 	"github.com/williamkoller/cloud-architecture-golang/internal/usr/repository"
+	"github.com/williamkoller/cloud-architecture-golang/internal/verification"
 )
 
 // init metrics once for all tests
@@ -27,18 +35,27 @@ func init() {
 // ---- stub repo ----
 
 type stubRepo struct {
-	createFn func(ctx context.Context, u domain.User) error
-	getFn    func(ctx context.Context, email vo.Email) (domain.User, bool, error)
-	listFn   func(ctx context.Context) ([]domain.User, error)
-	updateFn func(ctx context.Context, u domain.User) error
-	deleteFn func(ctx context.Context, email vo.Email) error
+	createFn  func(ctx context.Context, u domain.User) error
+	getByIDFn func(ctx context.Context, id vo.UserID) (domain.User, bool, error)
+	getFn     func(ctx context.Context, email vo.Email) (domain.User, bool, error)
+	listFn    func(ctx context.Context, q repository.ListQuery) ([]domain.User, error)
+	updateFn  func(ctx context.Context, u domain.User) error
+	changeFn  func(ctx context.Context, id vo.UserID, addr vo.Address) (domain.User, error)
+	deleteFn  func(ctx context.Context, id vo.UserID) error
+	restoreFn func(ctx context.Context, id vo.UserID) (domain.User, error)
 }
 
-func (s *stubRepo) Create(ctx context.Context, u domain.User) error {
+func (s *stubRepo) Create(ctx context.Context, u domain.User) (domain.User, error) {
 	if s.createFn != nil {
-		return s.createFn(ctx, u)
+		return u, s.createFn(ctx, u)
 	}
-	return nil
+	return u, nil
+}
+func (s *stubRepo) GetByID(ctx context.Context, id vo.UserID) (domain.User, bool, error) {
+	if s.getByIDFn != nil {
+		return s.getByIDFn(ctx, id)
+	}
+	return domain.User{}, false, nil
 }
 func (s *stubRepo) GetByEmail(ctx context.Context, email vo.Email) (domain.User, bool, error) {
 	if s.getFn != nil {
@@ -46,23 +63,46 @@ func (s *stubRepo) GetByEmail(ctx context.Context, email vo.Email) (domain.User,
 	}
 	return domain.User{}, false, nil
 }
-func (s *stubRepo) List(ctx context.Context) ([]domain.User, error) {
+func (s *stubRepo) List(ctx context.Context, q repository.ListQuery) ([]domain.User, error) {
 	if s.listFn != nil {
-		return s.listFn(ctx)
+		return s.listFn(ctx, q)
 	}
 	return nil, nil
 }
-func (s *stubRepo) Update(ctx context.Context, u domain.User) error {
+func (s *stubRepo) Update(ctx context.Context, u domain.User) (domain.User, error) {
 	if s.updateFn != nil {
-		return s.updateFn(ctx, u)
+		return u, s.updateFn(ctx, u)
+	}
+	return u, nil
+}
+func (s *stubRepo) RecordLogin(ctx context.Context, id vo.UserID) (domain.User, error) {
+	return domain.User{ID: id}, nil
+}
+func (s *stubRepo) ChangeEmail(ctx context.Context, id vo.UserID, addr vo.Address) (domain.User, error) {
+	if s.changeFn != nil {
+		return s.changeFn(ctx, id, addr)
 	}
-	return nil
+	return domain.User{}, repository.ErrNotFound
 }
-func (s *stubRepo) Delete(ctx context.Context, email vo.Email) error {
+func (s *stubRepo) Delete(ctx context.Context, id vo.UserID) (domain.User, error) {
 	if s.deleteFn != nil {
-		return s.deleteFn(ctx, email)
+		if err := s.deleteFn(ctx, id); err != nil {
+			return domain.User{}, err
+		}
+	}
+	u := domain.User{ID: id}
+	u.MarkDeleted(time.Now())
+	return u, nil
+}
+
+func (s *stubRepo) Restore(ctx context.Context, id vo.UserID) (domain.User, error) {
+	if s.restoreFn != nil {
+		return s.restoreFn(ctx, id)
 	}
-	return nil
+	return domain.User{}, repository.ErrNotFound
+}
+func (s *stubRepo) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	return 0, nil
 }
 
 func routerWithUserRoutes(h *UserHandler) *gin.Engine {
@@ -70,9 +110,18 @@ func routerWithUserRoutes(h *UserHandler) *gin.Engine {
 	r := gin.New()
 	r.POST("/users", h.CreateUser)
 	r.GET("/users", h.ListUsers)
-	r.GET("/users/:email", h.GetUser)
-	r.PATCH("/users/:email", h.UpdateUser)
-	r.DELETE("/users/:email", h.DeleteUser)
+	r.GET("/users/lookup", h.LookupUser)
+	r.GET("/users/:id", h.GetUser)
+	r.PATCH("/users/:id", h.UpdateUser)
+	r.PUT("/users/:id/email", h.ChangeEmail)
+	r.POST("/users/:id/activate", h.ActivateUser)
+	r.POST("/users/:id/suspend", h.SuspendUser)
+	r.POST("/users/:id/lock", h.LockUser)
+	r.POST("/users/:id/deactivate", h.DeactivateUser)
+	r.DELETE("/users/:id", h.DeleteUser)
+	r.POST("/users/:id/restore", h.RestoreUser)
+	r.PUT("/users/:id/roles/:role", h.AssignRole)
+	r.DELETE("/users/:id/roles/:role", h.RevokeRole)
 	return r
 }
 
@@ -192,6 +241,41 @@ func TestCreateUser_DomainError_Returns422(t *testing.T) {
 	}
 }
 
+func TestCreateUser_PasswordPolicy_ReturnsViolations(t *testing.T) {
+	repo := &stubRepo{}
+	h := NewUserHandler(repo)
+	r := routerWithUserRoutes(h)
+
+	body := map[string]any{
+		"name":     "Ana Silva",
+		"email":    "ana@example.com",
+		"password": "Silva2024",
+		"userType": "User",
+	}
+	w := doJSON(t, r, http.MethodPost, "/users", body)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status: got %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+
+	var resp struct {
+		Error      string `json:"error"`
+		Violations []struct {
+			Code string `json:"code"`
+		} `json:"violations"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	var codes []string
+	for _, v := range resp.Violations {
+		codes = append(codes, v.Code)
+	}
+	want := []string{vo.ViolationPersonalInfo}
+	if strings.Join(codes, ",") != strings.Join(want, ",") {
+		t.Fatalf("violation codes: got %v, want %v", codes, want)
+	}
+}
+
 func TestCreateUser_InternalError_Returns500(t *testing.T) {
 	repo := &stubRepo{
 		createFn: func(ctx context.Context, u domain.User) error {
@@ -218,7 +302,7 @@ func TestListUsers_Success(t *testing.T) {
 	u2 := mustUser(t, "Bob", "bob@example.com", false, domain.UserTypeAdmin)
 
 	repo := &stubRepo{
-		listFn: func(ctx context.Context) ([]domain.User, error) {
+		listFn: func(ctx context.Context, q repository.ListQuery) ([]domain.User, error) {
 			return []domain.User{u1, u2}, nil
 		},
 	}
@@ -240,7 +324,7 @@ func TestListUsers_Success(t *testing.T) {
 
 func TestListUsers_InternalError(t *testing.T) {
 	repo := &stubRepo{
-		listFn: func(ctx context.Context) ([]domain.User, error) {
+		listFn: func(ctx context.Context, q repository.ListQuery) ([]domain.User, error) {
 			return nil, errors.New("db down")
 		},
 	}
@@ -339,6 +423,41 @@ func TestUpdateUser_Success(t *testing.T) {
 	}
 }
 
+func TestUpdateUser_KeepsPasswordHashUnlessNewPasswordSent(t *testing.T) {
+	current := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+
+	var saved domain.User
+	repo := &stubRepo{
+		getFn: func(ctx context.Context, email vo.Email) (domain.User, bool, error) {
+			return current, true, nil
+		},
+		updateFn: func(ctx context.Context, u domain.User) error {
+			saved = u
+			return nil
+		},
+	}
+	r := routerWithUserRoutes(NewUserHandler(repo))
+
+	w := doJSON(t, r, http.MethodPatch, "/users/ana@example.com", map[string]any{"name": "Ana Paula"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("rename: got %d, want %d", w.Code, http.StatusOK)
+	}
+	if saved.Password != current.Password {
+		t.Fatalf("password hash changed on rename: %q -> %q", current.Password, saved.Password)
+	}
+	if !saved.Password.Compare("secret123") {
+		t.Fatalf("original password must keep working after rename")
+	}
+
+	w = doJSON(t, r, http.MethodPatch, "/users/ana@example.com", map[string]any{"password": "n3w-s3cret"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("change password: got %d, want %d", w.Code, http.StatusOK)
+	}
+	if !saved.Password.Compare("n3w-s3cret") || saved.Password.Compare("secret123") {
+		t.Fatalf("password was not replaced")
+	}
+}
+
 func TestUpdateUser_BindError_And_DomainError(t *testing.T) {
 	current := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
 
@@ -432,8 +551,14 @@ func TestDeleteUser_Scenarios(t *testing.T) {
 		t.Fatalf("invalid email: got %d, want %d", w.Code, http.StatusBadRequest)
 	}
 
+	current := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	found := func(ctx context.Context, email vo.Email) (domain.User, bool, error) {
+		return current, true, nil
+	}
+
 	repo1 := &stubRepo{
-		deleteFn: func(ctx context.Context, email vo.Email) error {
+		getFn: found,
+		deleteFn: func(ctx context.Context, id vo.UserID) error {
 			return repository.ErrNotFound
 		},
 	}
@@ -444,7 +569,8 @@ func TestDeleteUser_Scenarios(t *testing.T) {
 	}
 
 	repo2 := &stubRepo{
-		deleteFn: func(ctx context.Context, email vo.Email) error {
+		getFn: found,
+		deleteFn: func(ctx context.Context, id vo.UserID) error {
 			return errors.New("boom")
 		},
 	}
@@ -454,8 +580,11 @@ func TestDeleteUser_Scenarios(t *testing.T) {
 		t.Fatalf("internal: got %d, want %d", w.Code, http.StatusInternalServerError)
 	}
 
+	var deletedID vo.UserID
 	repo3 := &stubRepo{
-		deleteFn: func(ctx context.Context, email vo.Email) error {
+		getFn: found,
+		deleteFn: func(ctx context.Context, id vo.UserID) error {
+			deletedID = id
 			return nil
 		},
 	}
@@ -464,4 +593,1157 @@ func TestDeleteUser_Scenarios(t *testing.T) {
 	if w := doJSON(t, r3, http.MethodDelete, "/users/ana@example.com", nil); w.Code != http.StatusNoContent {
 		t.Fatalf("success: got %d, want %d", w.Code, http.StatusNoContent)
 	}
+	if deletedID != current.ID {
+		t.Fatalf("deleted id: got %q, want %q", deletedID, current.ID)
+	}
+
+	repo4 := &stubRepo{}
+	h4 := NewUserHandler(repo4)
+	r4 := routerWithUserRoutes(h4)
+	if w := doJSON(t, r4, http.MethodDelete, "/users/"+string(current.ID), nil); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown id: got %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestGetUser_ByID(t *testing.T) {
+	u := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	repo := &stubRepo{
+		getByIDFn: func(ctx context.Context, id vo.UserID) (domain.User, bool, error) {
+			if id != u.ID {
+				return domain.User{}, false, nil
+			}
+			return u, true, nil
+		},
+		getFn: func(ctx context.Context, email vo.Email) (domain.User, bool, error) {
+			t.Fatalf("GetByEmail should not be called for id lookups")
+			return domain.User{}, false, nil
+		},
+	}
+	h := NewUserHandler(repo)
+	r := routerWithUserRoutes(h)
+
+	w := doJSON(t, r, http.MethodGet, "/users/"+string(u.ID), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", w.Code, http.StatusOK)
+	}
+	var resp mappers.UserResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if resp.ID != string(u.ID) || resp.Email != "ana@example.com" {
+		t.Fatalf("response mismatch: %+v", resp)
+	}
+}
+
+func TestLookupUser_ByEmailQuery(t *testing.T) {
+	u := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	repo := &stubRepo{
+		getFn: func(ctx context.Context, email vo.Email) (domain.User, bool, error) {
+			if email != u.Email {
+				return domain.User{}, false, nil
+			}
+			return u, true, nil
+		},
+	}
+	h := NewUserHandler(repo)
+	r := routerWithUserRoutes(h)
+
+	w := doJSON(t, r, http.MethodGet, "/users/lookup?email=ana@example.com", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", w.Code, http.StatusOK)
+	}
+	var resp mappers.UserResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if resp.ID != string(u.ID) {
+		t.Fatalf("id mismatch: got %q, want %q", resp.ID, u.ID)
+	}
+	if got := w.Header().Get("ETag"); got != etagFor(resp.Version) {
+		t.Fatalf("ETag: got %q, want %q", got, etagFor(resp.Version))
+	}
+
+	if w := doJSON(t, r, http.MethodGet, "/users/lookup?email=bob@example.com", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("missing: got %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if w := doJSON(t, r, http.MethodGet, "/users/lookup", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("no email: got %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if w := doJSON(t, r, http.MethodGet, "/users/lookup?email=invalid", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid email: got %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestUpdateUser_PreservesID(t *testing.T) {
+	current := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+
+	var saved domain.User
+	repo := &stubRepo{
+		getByIDFn: func(ctx context.Context, id vo.UserID) (domain.User, bool, error) {
+			return current, true, nil
+		},
+		updateFn: func(ctx context.Context, u domain.User) error {
+			saved = u
+			return nil
+		},
+	}
+	h := NewUserHandler(repo)
+	r := routerWithUserRoutes(h)
+
+	w := doJSON(t, r, http.MethodPatch, "/users/"+string(current.ID), map[string]any{"name": "Ana Paula"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", w.Code, http.StatusOK)
+	}
+	if saved.ID != current.ID {
+		t.Fatalf("ID changed on update: got %q, want %q", saved.ID, current.ID)
+	}
+}
+
+func TestChangeEmail_Success_InvalidatesBothCacheEntries(t *testing.T) {
+	current := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	moved := current
+	moved.ChangeEmail(vo.Address{Email: "ana.paula@example.com", Display: "ana.paula@example.com"}, time.Now())
+
+	stored := current
+	repo := &stubRepo{
+		getByIDFn: func(ctx context.Context, id vo.UserID) (domain.User, bool, error) {
+			return stored, true, nil
+		},
+		getFn: func(ctx context.Context, email vo.Email) (domain.User, bool, error) {
+			if email != stored.Email {
+				return domain.User{}, false, nil
+			}
+			return stored, true, nil
+		},
+		changeFn: func(ctx context.Context, id vo.UserID, addr vo.Address) (domain.User, error) {
+			if id != current.ID || addr.Email != "ana.paula@example.com" {
+				return domain.User{}, errors.New("unexpected change args")
+			}
+			stored = moved
+			return moved, nil
+		},
+	}
+	h := NewUserHandler(repo)
+	r := routerWithUserRoutes(h)
+
+	// Popular o cache com o email antigo
+	if w := doJSON(t, r, http.MethodGet, "/users/ana@example.com", nil); w.Code != http.StatusOK {
+		t.Fatalf("warm cache: got %d", w.Code)
+	}
+
+	w := doJSON(t, r, http.MethodPut, "/users/"+string(current.ID)+"/email", map[string]any{"email": "ana.paula@example.com"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d (%s)", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp mappers.UserResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if resp.Email != "ana.paula@example.com" || len(resp.PreviousEmails) != 1 || resp.PreviousEmails[0] != "ana@example.com" {
+		t.Fatalf("response mismatch: %+v", resp)
+	}
+
+	if w := doJSON(t, r, http.MethodGet, "/users/ana@example.com", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("old email still served (stale cache?): got %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestChangeEmail_ErrorScenarios(t *testing.T) {
+	current := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	found := func(ctx context.Context, id vo.UserID) (domain.User, bool, error) {
+		return current, true, nil
+	}
+	path := "/users/" + string(current.ID) + "/email"
+
+	h0 := NewUserHandler(&stubRepo{getByIDFn: found})
+	if w := doJSON(t, routerWithUserRoutes(h0), http.MethodPut, path, map[string]any{"email": "invalid"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("bind error: got %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	h1 := NewUserHandler(&stubRepo{
+		getByIDFn: found,
+		changeFn: func(ctx context.Context, id vo.UserID, addr vo.Address) (domain.User, error) {
+			return domain.User{}, repository.ErrAlreadyExists
+		},
+	})
+	if w := doJSON(t, routerWithUserRoutes(h1), http.MethodPut, path, map[string]any{"email": "bob@example.com"}); w.Code != http.StatusConflict {
+		t.Fatalf("collision: got %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	h2 := NewUserHandler(&stubRepo{})
+	if w := doJSON(t, routerWithUserRoutes(h2), http.MethodPut, path, map[string]any{"email": "bob@example.com"}); w.Code != http.StatusNotFound {
+		t.Fatalf("not found: got %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	h3 := NewUserHandler(&stubRepo{
+		getByIDFn: found,
+		changeFn: func(ctx context.Context, id vo.UserID, addr vo.Address) (domain.User, error) {
+			return domain.User{}, errors.New("boom")
+		},
+	})
+	if w := doJSON(t, routerWithUserRoutes(h3), http.MethodPut, path, map[string]any{"email": "bob@example.com"}); w.Code != http.StatusInternalServerError {
+		t.Fatalf("internal: got %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestListUsers_QueryFiltersAndSort(t *testing.T) {
+	var got repository.ListQuery
+	repo := &stubRepo{
+		listFn: func(ctx context.Context, q repository.ListQuery) ([]domain.User, error) {
+			got = q
+			return nil, nil
+		},
+	}
+	h := NewUserHandler(repo)
+	r := routerWithUserRoutes(h)
+
+	w := doJSON(t, r, http.MethodGet, "/users?sort=-updatedAt&createdAfter=2025-01-01T00:00:00Z&createdBefore=2025-02-01T00:00:00Z", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d (%s)", w.Code, http.StatusOK, w.Body.String())
+	}
+	if got.SortBy != repository.SortByUpdatedAt || !got.SortDesc {
+		t.Fatalf("sort: got %q desc=%v", got.SortBy, got.SortDesc)
+	}
+	wantFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	wantTo := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	if !got.Created.From.Equal(wantFrom) || !got.Created.To.Equal(wantTo) {
+		t.Fatalf("created range: got %+v", got.Created)
+	}
+
+	if w := doJSON(t, r, http.MethodGet, "/users?status=locked&status=suspended", nil); w.Code != http.StatusOK {
+		t.Fatalf("status filter: got %d (%s)", w.Code, w.Body.String())
+	}
+	if len(got.Statuses) != 2 || got.Statuses[0] != domain.StatusLocked || got.Statuses[1] != domain.StatusSuspended {
+		t.Fatalf("statuses: got %v", got.Statuses)
+	}
+
+	if w := doJSON(t, r, http.MethodGet, "/users?status=banned", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid status: got %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if w := doJSON(t, r, http.MethodGet, "/users?sort=name", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid sort: got %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if w := doJSON(t, r, http.MethodGet, "/users?createdAfter=yesterday", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid date: got %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func doJSONWithHeaders(t *testing.T, r http.Handler, method, path string, body any, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	bts, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("marshal body: %v", err)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewBuffer(bts))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestGetUser_SetsETagFromVersion(t *testing.T) {
+	u := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	u.Version = 7
+	repo := &stubRepo{
+		getByIDFn: func(ctx context.Context, id vo.UserID) (domain.User, bool, error) {
+			return u, true, nil
+		},
+	}
+	h := NewUserHandler(repo)
+	r := routerWithUserRoutes(h)
+
+	// A segunda chamada vem do cache e deve manter a ETag
+	for i := 0; i < 2; i++ {
+		w := doJSON(t, r, http.MethodGet, "/users/"+string(u.ID), nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status: got %d, want %d", w.Code, http.StatusOK)
+		}
+		if got := w.Header().Get("ETag"); got != `"7"` {
+			t.Fatalf("ETag: got %q, want %q", got, `"7"`)
+		}
+	}
+}
+
+func TestUpdateUser_IfMatch(t *testing.T) {
+	current := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	current.Version = 3
+	path := "/users/" + string(current.ID)
+
+	var updates int
+	repo := &stubRepo{
+		getByIDFn: func(ctx context.Context, id vo.UserID) (domain.User, bool, error) {
+			return current, true, nil
+		},
+		updateFn: func(ctx context.Context, u domain.User) error {
+			updates++
+			if u.Version != current.Version {
+				return repository.ErrVersionConflict
+			}
+			return nil
+		},
+	}
+	h := NewUserHandler(repo)
+	r := routerWithUserRoutes(h)
+
+	w := doJSONWithHeaders(t, r, http.MethodPatch, path, map[string]any{"name": "Ana Paula"}, map[string]string{"If-Match": `"2"`})
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("stale If-Match: got %d, want %d", w.Code, http.StatusPreconditionFailed)
+	}
+	if updates != 0 {
+		t.Fatalf("repository should not be written on precondition failure")
+	}
+
+	for _, tag := range []string{`"3"`, `W/"3"`, `"1", "3"`, `*`} {
+		w = doJSONWithHeaders(t, r, http.MethodPatch, path, map[string]any{"name": "Ana Paula"}, map[string]string{"If-Match": tag})
+		if w.Code != http.StatusOK {
+			t.Fatalf("If-Match %s: got %d, want %d", tag, w.Code, http.StatusOK)
+		}
+		if got := w.Header().Get("ETag"); got == "" {
+			t.Fatalf("If-Match %s: missing ETag on response", tag)
+		}
+	}
+}
+
+func TestUpdateUser_VersionConflictFromRepository(t *testing.T) {
+	current := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	current.Version = 3
+	path := "/users/" + string(current.ID)
+
+	repo := &stubRepo{
+		getByIDFn: func(ctx context.Context, id vo.UserID) (domain.User, bool, error) {
+			return current, true, nil
+		},
+		updateFn: func(ctx context.Context, u domain.User) error {
+			return repository.ErrVersionConflict
+		},
+	}
+	h := NewUserHandler(repo)
+	r := routerWithUserRoutes(h)
+
+	if w := doJSON(t, r, http.MethodPatch, path, map[string]any{"name": "Ana Paula"}); w.Code != http.StatusConflict {
+		t.Fatalf("without If-Match: got %d, want %d", w.Code, http.StatusConflict)
+	}
+	w := doJSONWithHeaders(t, r, http.MethodPatch, path, map[string]any{"name": "Ana Paula"}, map[string]string{"If-Match": `"3"`})
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("with If-Match: got %d, want %d", w.Code, http.StatusPreconditionFailed)
+	}
+}
+
+func TestRestoreUser_ByIDAndEmail(t *testing.T) {
+	deleted := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	now := time.Now()
+	deleted.DeletedAt = &now
+
+	var listed repository.ListQuery
+	var restoredID vo.UserID
+	repo := &stubRepo{
+		listFn: func(ctx context.Context, q repository.ListQuery) ([]domain.User, error) {
+			listed = q
+			if q.Email == deleted.Email {
+				return []domain.User{deleted}, nil
+			}
+			return nil, nil
+		},
+		restoreFn: func(ctx context.Context, id vo.UserID) (domain.User, error) {
+			restoredID = id
+			if id != deleted.ID {
+				return domain.User{}, repository.ErrNotFound
+			}
+			u := deleted
+			u.DeletedAt = nil
+			return u, nil
+		},
+	}
+	r := routerWithUserRoutes(NewUserHandler(repo))
+
+	w := doJSON(t, r, http.MethodPost, "/users/ana@example.com/restore", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("by email: got %d, want %d (%s)", w.Code, http.StatusOK, w.Body.String())
+	}
+	if !listed.IncludeDeleted || restoredID != deleted.ID {
+		t.Fatalf("by email: list query %+v restored %q", listed, restoredID)
+	}
+	var resp mappers.UserResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if resp.DeletedAt != nil {
+		t.Fatalf("restored user still has deletedAt: %v", *resp.DeletedAt)
+	}
+
+	if w := doJSON(t, r, http.MethodPost, "/users/"+string(deleted.ID)+"/restore", nil); w.Code != http.StatusOK {
+		t.Fatalf("by id: got %d, want %d", w.Code, http.StatusOK)
+	}
+	if w := doJSON(t, r, http.MethodPost, "/users/bob@example.com/restore", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown email: got %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if w := doJSON(t, r, http.MethodPost, "/users/not-an-id/restore", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid ref: got %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestListUsers_IncludeDeleted(t *testing.T) {
+	var got repository.ListQuery
+	repo := &stubRepo{
+		listFn: func(ctx context.Context, q repository.ListQuery) ([]domain.User, error) {
+			got = q
+			return nil, nil
+		},
+	}
+	r := routerWithUserRoutes(NewUserHandler(repo))
+
+	if w := doJSON(t, r, http.MethodGet, "/users", nil); w.Code != http.StatusOK || got.IncludeDeleted {
+		t.Fatalf("default: code=%d includeDeleted=%v", w.Code, got.IncludeDeleted)
+	}
+	if w := doJSON(t, r, http.MethodGet, "/users?includeDeleted=true", nil); w.Code != http.StatusOK || !got.IncludeDeleted {
+		t.Fatalf("includeDeleted=true: code=%d includeDeleted=%v", w.Code, got.IncludeDeleted)
+	}
+}
+
+func TestGetUser_EmailPathIsCanonicalized(t *testing.T) {
+	u := mustUser(t, "Ana", "Ana@Example.com", true, domain.UserTypeUser)
+
+	var lookups []vo.Email
+	repo := &stubRepo{
+		getFn: func(ctx context.Context, email vo.Email) (domain.User, bool, error) {
+			lookups = append(lookups, email)
+			return u, true, nil
+		},
+	}
+	r := routerWithUserRoutes(NewUserHandler(repo))
+
+	for _, path := range []string{"/users/ANA@example.com", "/users/ana@EXAMPLE.com"} {
+		w := doJSON(t, r, http.MethodGet, path, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: got %d, want %d", path, w.Code, http.StatusOK)
+		}
+		var resp mappers.UserResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		if resp.Email != "Ana@Example.com" {
+			t.Fatalf("display email: got %q", resp.Email)
+		}
+	}
+
+	// A segunda variação deve vir do cache, que usa a chave canônica
+	if len(lookups) != 1 || lookups[0] != "ana@example.com" {
+		t.Fatalf("repository lookups: got %v, want one lookup for the canonical email", lookups)
+	}
+}
+
+func TestUserHandler_PublishesDomainEvents(t *testing.T) {
+	current := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	current.PullEvents()
+
+	repo := &stubRepo{
+		getFn: func(ctx context.Context, email vo.Email) (domain.User, bool, error) {
+			return current, true, nil
+		},
+	}
+	dispatcher := events.NewDispatcher()
+	var got []string
+	dispatcher.SubscribeAll(func(ctx context.Context, e domain.Event) error {
+		got = append(got, e.EventName())
+		return nil
+	})
+	r := routerWithUserRoutes(NewUserHandler(repo, WithEventDispatcher(dispatcher)))
+
+	w := doJSON(t, r, http.MethodPost, "/users", map[string]any{
+		"name": "Bia", "email": "bia@example.com", "password": "secret123", "userType": "User",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: got %d", w.Code)
+	}
+
+	w = doJSON(t, r, http.MethodPatch, "/users/ana@example.com", map[string]any{
+		"userType": "Admin", "active": false,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: got %d", w.Code)
+	}
+
+	w = doJSON(t, r, http.MethodDelete, "/users/ana@example.com", nil)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("delete: got %d", w.Code)
+	}
+
+	want := []string{
+		domain.EventUserCreated,
+		domain.EventUserTypeChanged, domain.EventUserStatusChanged, domain.EventUserDeactivated,
+		domain.EventUserDeleted,
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("events: got %v, want %v", got, want)
+	}
+}
+
+func TestUserHandler_FailedWriteDoesNotPublish(t *testing.T) {
+	repo := &stubRepo{
+		createFn: func(ctx context.Context, u domain.User) error {
+			return repository.ErrAlreadyExists
+		},
+	}
+	dispatcher := events.NewDispatcher()
+	published := 0
+	dispatcher.SubscribeAll(func(ctx context.Context, e domain.Event) error {
+		published++
+		return nil
+	})
+	r := routerWithUserRoutes(NewUserHandler(repo, WithEventDispatcher(dispatcher)))
+
+	w := doJSON(t, r, http.MethodPost, "/users", map[string]any{
+		"name": "Ana", "email": "ana@example.com", "password": "secret123", "userType": "User",
+	})
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status: got %d, want %d", w.Code, http.StatusConflict)
+	}
+	if published != 0 {
+		t.Fatalf("events published for a failed write: %d", published)
+	}
+}
+
+type roleCatalogFunc func(ctx context.Context, name string) (bool, error)
+
+func (f roleCatalogFunc) RoleExists(ctx context.Context, name string) (bool, error) {
+	return f(ctx, name)
+}
+
+type permissionsFunc func(ctx context.Context, roles []string) (rbac.PermissionSet, error)
+
+func (f permissionsFunc) Permissions(ctx context.Context, roles []string) (rbac.PermissionSet, error) {
+	return f(ctx, roles)
+}
+
+func TestAssignAndRevokeRole(t *testing.T) {
+	current := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	current.PullEvents()
+
+	var saved domain.User
+	repo := &stubRepo{
+		getFn: func(ctx context.Context, email vo.Email) (domain.User, bool, error) {
+			return current, true, nil
+		},
+		updateFn: func(ctx context.Context, u domain.User) error {
+			saved = u
+			return nil
+		},
+	}
+	catalog := roleCatalogFunc(func(ctx context.Context, name string) (bool, error) {
+		return name == "support" || name == rbac.RoleAdmin, nil
+	})
+	r := routerWithUserRoutes(NewUserHandler(repo, WithRoleCatalog(catalog)))
+
+	w := doJSON(t, r, http.MethodPut, "/users/ana@example.com/roles/admin", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("assign: got %d body=%s", w.Code, w.Body.String())
+	}
+	var resp mappers.UserResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if resp.UserType != domain.UserTypeAdmin || strings.Join(resp.Roles, ",") != "admin,user" {
+		t.Fatalf("response: type=%s roles=%v", resp.UserType, resp.Roles)
+	}
+	if !saved.HasRole(rbac.RoleAdmin) {
+		t.Fatalf("repository did not receive the new role: %v", saved.Roles)
+	}
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+	}{
+		{"unknown role", http.MethodPut, "/users/ana@example.com/roles/ghost", http.StatusNotFound},
+		{"invalid role", http.MethodPut, "/users/ana@example.com/roles/Bad!", http.StatusBadRequest},
+		{"revoke base role", http.MethodDelete, "/users/ana@example.com/roles/user", http.StatusUnprocessableEntity},
+		{"revoke absent role", http.MethodDelete, "/users/ana@example.com/roles/support", http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if w := doJSON(t, r, tc.method, tc.path, nil); w.Code != tc.want {
+				t.Fatalf("status: got %d, want %d body=%s", w.Code, tc.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestUserType_AdminRequiresRolesWrite(t *testing.T) {
+	current := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	repo := &stubRepo{
+		getFn: func(ctx context.Context, email vo.Email) (domain.User, bool, error) {
+			return current, true, nil
+		},
+	}
+	resolver := permissionsFunc(func(ctx context.Context, roles []string) (rbac.PermissionSet, error) {
+		set := rbac.PermissionSet{rbac.PermUsersWrite: true}
+		for _, r := range roles {
+			if r == rbac.RoleAdmin {
+				set[rbac.PermRolesWrite] = true
+			}
+		}
+		return set, nil
+	})
+
+	h := NewUserHandler(repo, WithPermissionResolver(resolver))
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		auth.SetPrincipal(c, auth.Principal{UserID: "caller", Roles: strings.Split(c.GetHeader("X-Test-Roles"), ",")})
+		c.Next()
+	})
+	r.POST("/users", h.CreateUser)
+	r.PATCH("/users/:id", h.UpdateUser)
+
+	create := map[string]any{"name": "Bia", "email": "bia@example.com", "password": "secret123", "userType": "Admin"}
+	promote := map[string]any{"userType": "Admin"}
+
+	for _, tc := range []struct {
+		roles string
+		want  [2]int
+	}{
+		{"user", [2]int{http.StatusForbidden, http.StatusForbidden}},
+		{"admin,user", [2]int{http.StatusCreated, http.StatusOK}},
+	} {
+		hdr := map[string]string{"X-Test-Roles": tc.roles}
+		if w := doJSONWithHeaders(t, r, http.MethodPost, "/users", create, hdr); w.Code != tc.want[0] {
+			t.Fatalf("create as %s: got %d, want %d", tc.roles, w.Code, tc.want[0])
+		}
+		if w := doJSONWithHeaders(t, r, http.MethodPatch, "/users/ana@example.com", promote, hdr); w.Code != tc.want[1] {
+			t.Fatalf("promote as %s: got %d, want %d", tc.roles, w.Code, tc.want[1])
+		}
+	}
+}
+
+func TestCredentials_RequireEveryPermissionOfTheTarget(t *testing.T) {
+	repo := repository.NewInMemoryUserRepository()
+	admin, err := repo.Create(context.Background(), mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeAdmin))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := repo.Create(context.Background(), mustUser(t, "Bia", "bia@example.com", true, domain.UserTypeUser)); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	// support só tem users:write; admin tem também roles:write
+	resolver := permissionsFunc(func(ctx context.Context, roles []string) (rbac.PermissionSet, error) {
+		set := rbac.PermissionSet{}
+		for _, r := range roles {
+			switch r {
+			case "support":
+				set[rbac.PermUsersWrite] = true
+			case rbac.RoleAdmin:
+				set[rbac.PermUsersWrite] = true
+				set[rbac.PermRolesWrite] = true
+			}
+		}
+		return set, nil
+	})
+
+	h := NewUserHandler(repo, WithPermissionResolver(resolver))
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		auth.SetPrincipal(c, auth.Principal{UserID: "caller", Roles: strings.Split(c.GetHeader("X-Test-Roles"), ",")})
+		c.Next()
+	})
+	r.PATCH("/users/:id", h.UpdateUser)
+	r.PUT("/users/:id/email", h.ChangeEmail)
+
+	hdr := map[string]string{"X-Test-Roles": "support"}
+	if w := doJSONWithHeaders(t, r, http.MethodPatch, "/users/ana@example.com", map[string]any{"password": "taken123"}, hdr); w.Code != http.StatusForbidden {
+		t.Fatalf("support sets admin password: got %d", w.Code)
+	}
+	if w := doJSONWithHeaders(t, r, http.MethodPut, "/users/ana@example.com/email", map[string]any{"email": "mallory@example.com"}, hdr); w.Code != http.StatusForbidden {
+		t.Fatalf("support changes admin email: got %d", w.Code)
+	}
+	stored, _, _ := repo.GetByID(context.Background(), admin.ID)
+	if stored.Email.String() != "ana@example.com" || !stored.Password.Compare("secret123") {
+		t.Fatalf("admin credentials changed: %+v", stored)
+	}
+
+	// Sobre um usuário comum, users:write basta; admin pode mexer em admin
+	if w := doJSONWithHeaders(t, r, http.MethodPatch, "/users/bia@example.com", map[string]any{"password": "newpass123"}, hdr); w.Code != http.StatusOK {
+		t.Fatalf("support sets user password: got %d body=%s", w.Code, w.Body.String())
+	}
+	if w := doJSONWithHeaders(t, r, http.MethodPut, "/users/bia@example.com/email", map[string]any{"email": "bia.souza@example.com"}, hdr); w.Code != http.StatusOK {
+		t.Fatalf("support changes user email: got %d body=%s", w.Code, w.Body.String())
+	}
+	hdr["X-Test-Roles"] = "admin"
+	if w := doJSONWithHeaders(t, r, http.MethodPatch, "/users/ana@example.com", map[string]any{"password": "newpass123"}, hdr); w.Code != http.StatusOK {
+		t.Fatalf("admin sets admin password: got %d body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestUpdateUser_SelfServiceCannotChangeActive(t *testing.T) {
+	current := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	repo := &stubRepo{
+		getFn: func(ctx context.Context, email vo.Email) (domain.User, bool, error) {
+			return current, true, nil
+		},
+	}
+	resolver := permissionsFunc(func(ctx context.Context, roles []string) (rbac.PermissionSet, error) {
+		set := rbac.PermissionSet{}
+		for _, r := range roles {
+			if r == rbac.RoleAdmin {
+				set[rbac.PermUsersWrite] = true
+			}
+		}
+		return set, nil
+	})
+
+	h := NewUserHandler(repo, WithPermissionResolver(resolver))
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		auth.SetPrincipal(c, auth.Principal{UserID: current.ID, Roles: strings.Split(c.GetHeader("X-Test-Roles"), ",")})
+		c.Next()
+	})
+	r.PATCH("/users/:id", h.UpdateUser)
+
+	// O próprio usuário (liberado pelo guarda da rota) muda o nome, mas não o status
+	hdr := map[string]string{"X-Test-Roles": "user"}
+	if w := doJSONWithHeaders(t, r, http.MethodPatch, "/users/ana@example.com", map[string]any{"name": "Ana Paula"}, hdr); w.Code != http.StatusOK {
+		t.Fatalf("self rename: got %d body=%s", w.Code, w.Body.String())
+	}
+	if w := doJSONWithHeaders(t, r, http.MethodPatch, "/users/ana@example.com", map[string]any{"active": false}, hdr); w.Code != http.StatusForbidden {
+		t.Fatalf("self deactivate: got %d", w.Code)
+	}
+	hdr["X-Test-Roles"] = "admin"
+	if w := doJSONWithHeaders(t, r, http.MethodPatch, "/users/ana@example.com", map[string]any{"active": false}, hdr); w.Code != http.StatusOK {
+		t.Fatalf("admin deactivate: got %d body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestUserHandler_TenantsAreIsolated(t *testing.T) {
+	tenants := repository.NewTenantRepositories()
+	h := NewUserHandler(tenants.For("default"), WithTenants(tenants))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if org := c.GetHeader("X-Test-Org"); org != "" {
+			c.Request = c.Request.WithContext(tenant.WithOrg(c.Request.Context(), org))
+		}
+		c.Next()
+	})
+	r.POST("/users", h.CreateUser)
+	r.GET("/users/:id", h.GetUser)
+
+	body := map[string]any{"name": "Ana", "email": "ana@example.com", "password": "secret123", "userType": "User"}
+	ids := map[string]string{}
+	for _, org := range []string{"acme", "globex"} {
+		w := doJSONWithHeaders(t, r, http.MethodPost, "/users", body, map[string]string{"X-Test-Org": org})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("create in %s: got %d body=%s", org, w.Code, w.Body.String())
+		}
+		var resp mappers.UserResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp.OrgID != org {
+			t.Fatalf("orgId: got %q, want %q", resp.OrgID, org)
+		}
+		ids[org] = resp.ID
+	}
+
+	// O cache por email não pode vazar o usuário de outra organização
+	w := doJSONWithHeaders(t, r, http.MethodGet, "/users/ana@example.com", nil, map[string]string{"X-Test-Org": "globex"})
+	var resp mappers.UserResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.ID != ids["globex"] {
+		t.Fatalf("globex lookup: got %d id=%s, want %s", w.Code, resp.ID, ids["globex"])
+	}
+
+	w = doJSONWithHeaders(t, r, http.MethodGet, "/users/"+ids["acme"], nil, map[string]string{"X-Test-Org": "globex"})
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("cross-tenant get by id: got %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	// Sem organização no contexto vale o repositório padrão
+	if w = doJSON(t, r, http.MethodGet, "/users/ana@example.com", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("default org lookup: got %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+type orgDirectory map[string]org.Organization
+
+func (d orgDirectory) Get(ctx context.Context, id string) (org.Organization, bool, error) {
+	o, ok := d[id]
+	return o, ok, nil
+}
+
+func TestCreateUser_AnonymousSignupOnlyInOpenOrgs(t *testing.T) {
+	tenants := repository.NewTenantRepositories()
+	h := NewUserHandler(tenants.For("default"), WithTenants(tenants))
+	dir := orgDirectory{
+		"default": {ID: "default", Active: true},
+		"acme":    {ID: "acme", Active: true},
+		"open":    {ID: "open", Active: true, SelfSignup: true},
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if c.GetHeader("X-Test-Auth") != "" {
+			auth.SetPrincipal(c, auth.Principal{UserID: "admin-1"})
+		}
+		c.Next()
+	}, tenant.Middleware(tenant.Resolver{Default: "default"}, dir))
+	r.POST("/users", h.CreateUser)
+
+	body := map[string]any{"name": "Ana", "email": "ana@example.com", "password": "secret123", "userType": "User"}
+	if w := doJSONWithHeaders(t, r, http.MethodPost, "/users", body, map[string]string{"X-Org-ID": "acme"}); w.Code != http.StatusForbidden {
+		t.Fatalf("anonymous signup in acme: got %d", w.Code)
+	}
+	if _, found, _ := tenants.For("acme").GetByEmail(context.Background(), "ana@example.com"); found {
+		t.Fatalf("user should not have been created in acme")
+	}
+	for _, hdr := range []map[string]string{
+		nil,
+		{"X-Org-ID": "open"},
+		{"X-Org-ID": "acme", "X-Test-Auth": "1"},
+	} {
+		if w := doJSONWithHeaders(t, r, http.MethodPost, "/users", body, hdr); w.Code != http.StatusCreated {
+			t.Fatalf("signup with %v: got %d body=%s", hdr, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestChangeStatus_TransitionsRecordReasonAndActor(t *testing.T) {
+	current := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	current.PullEvents()
+
+	repo := &stubRepo{
+		getFn: func(ctx context.Context, email vo.Email) (domain.User, bool, error) {
+			return current, true, nil
+		},
+		updateFn: func(ctx context.Context, u domain.User) error {
+			current = u
+			current.PullEvents()
+			return nil
+		},
+	}
+	h := NewUserHandler(repo)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		auth.SetPrincipal(c, auth.Principal{UserID: "admin-1"})
+		c.Next()
+	})
+	r.POST("/users/:id/suspend", h.SuspendUser)
+	r.POST("/users/:id/lock", h.LockUser)
+	r.POST("/users/:id/activate", h.ActivateUser)
+	r.PATCH("/users/:id", h.UpdateUser)
+
+	w := doJSON(t, r, http.MethodPost, "/users/ana@example.com/suspend", map[string]any{"reason": "chargeback"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("suspend: got %d body=%s", w.Code, w.Body.String())
+	}
+	var resp mappers.UserResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if resp.Status != domain.StatusSuspended || resp.Active || len(resp.StatusHistory) != 1 {
+		t.Fatalf("response: status=%s active=%v history=%v", resp.Status, resp.Active, resp.StatusHistory)
+	}
+	if sc := resp.StatusHistory[0]; sc.Reason != "chargeback" || sc.Actor != "admin-1" || sc.From != domain.StatusActive {
+		t.Fatalf("history entry: %+v", sc)
+	}
+
+	// PATCH active=true não tira a conta de suspended; só a rota própria
+	if w := doJSON(t, r, http.MethodPatch, "/users/ana@example.com", map[string]any{"active": true}); w.Code != http.StatusConflict {
+		t.Fatalf("patch active on suspended: got %d, want %d", w.Code, http.StatusConflict)
+	}
+	if w := doJSON(t, r, http.MethodPost, "/users/ana@example.com/lock", nil); w.Code != http.StatusConflict {
+		t.Fatalf("suspended -> locked: got %d, want %d", w.Code, http.StatusConflict)
+	}
+	if w := doJSON(t, r, http.MethodPost, "/users/ana@example.com/activate", nil); w.Code != http.StatusOK || current.Status != domain.StatusActive {
+		t.Fatalf("activate without body: got %d status=%s body=%s", w.Code, current.Status, w.Body.String())
+	}
+
+	long := strings.Repeat("x", 501)
+	if w := doJSON(t, r, http.MethodPost, "/users/ana@example.com/lock", map[string]any{"reason": long}); w.Code != http.StatusBadRequest {
+		t.Fatalf("long reason: got %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestChangeStatus_HonorsIfMatch(t *testing.T) {
+	current := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	current.Version = 3
+	path := "/users/" + string(current.ID) + "/suspend"
+
+	var updates int
+	conflict := false
+	repo := &stubRepo{
+		getByIDFn: func(ctx context.Context, id vo.UserID) (domain.User, bool, error) {
+			return current, true, nil
+		},
+		updateFn: func(ctx context.Context, u domain.User) error {
+			updates++
+			if conflict {
+				return repository.ErrVersionConflict
+			}
+			return nil
+		},
+	}
+	h := NewUserHandler(repo)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/users/:id/suspend", h.SuspendUser)
+
+	if w := doJSONWithHeaders(t, r, http.MethodPost, path, nil, map[string]string{"If-Match": `"2"`}); w.Code != http.StatusPreconditionFailed || updates != 0 {
+		t.Fatalf("stale If-Match: got %d after %d updates", w.Code, updates)
+	}
+
+	// Conflito na gravação: 412 com If-Match, 409 sem
+	conflict = true
+	if w := doJSONWithHeaders(t, r, http.MethodPost, path, nil, map[string]string{"If-Match": `"3"`}); w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("conditional conflict: got %d", w.Code)
+	}
+	if w := doJSON(t, r, http.MethodPost, path, nil); w.Code != http.StatusConflict {
+		t.Fatalf("unconditional conflict: got %d", w.Code)
+	}
+
+	conflict = false
+	w := doJSONWithHeaders(t, r, http.MethodPost, path, nil, map[string]string{"If-Match": `"3"`})
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == "" {
+		t.Fatalf("matching If-Match: got %d body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestChangeRole_HonorsIfMatch(t *testing.T) {
+	current := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	current.Version = 3
+	path := "/users/" + string(current.ID) + "/roles/admin"
+
+	var updates int
+	conflict := false
+	repo := &stubRepo{
+		getByIDFn: func(ctx context.Context, id vo.UserID) (domain.User, bool, error) {
+			return current, true, nil
+		},
+		updateFn: func(ctx context.Context, u domain.User) error {
+			updates++
+			if conflict {
+				return repository.ErrVersionConflict
+			}
+			return nil
+		},
+	}
+	h := NewUserHandler(repo)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.PUT("/users/:id/roles/:role", h.AssignRole)
+
+	if w := doJSONWithHeaders(t, r, http.MethodPut, path, nil, map[string]string{"If-Match": `"2"`}); w.Code != http.StatusPreconditionFailed || updates != 0 {
+		t.Fatalf("stale If-Match: got %d after %d updates", w.Code, updates)
+	}
+
+	conflict = true
+	if w := doJSONWithHeaders(t, r, http.MethodPut, path, nil, map[string]string{"If-Match": `"3"`}); w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("conditional conflict: got %d", w.Code)
+	}
+	if w := doJSON(t, r, http.MethodPut, path, nil); w.Code != http.StatusConflict {
+		t.Fatalf("unconditional conflict: got %d", w.Code)
+	}
+
+	conflict = false
+	w := doJSONWithHeaders(t, r, http.MethodPut, path, nil, map[string]string{"If-Match": `"3"`})
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == "" {
+		t.Fatalf("matching If-Match: got %d body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestEmailVerification_CreateVerifyAndResend(t *testing.T) {
+	signer, err := verification.NewSigner([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+	var sent []verification.Message
+	verifier := verification.NewService(signer, verification.NotifierFunc(func(ctx context.Context, m verification.Message) error {
+		sent = append(sent, m)
+		return nil
+	}))
+	dispatcher := events.NewDispatcher()
+	dispatcher.Subscribe(domain.EventUserCreated, verification.SendOnCreated(verifier))
+
+	h := NewUserHandler(repository.NewInMemoryUserRepository(), WithEmailVerification(verifier), WithEventDispatcher(dispatcher))
+	r := routerWithUserRoutes(h)
+	r.POST("/users/verify", h.VerifyEmail)
+	r.POST("/users/verify/resend", h.ResendVerification)
+
+	w := doJSON(t, r, http.MethodPost, "/users", map[string]any{
+		"name": "Ana", "email": "ana@example.com", "password": "secret123", "userType": "User",
+	})
+	var created mappers.UserResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	if w.Code != http.StatusCreated || created.Status != domain.StatusPendingVerification || created.Active {
+		t.Fatalf("create: got %d status=%s active=%v", w.Code, created.Status, created.Active)
+	}
+	if len(sent) != 1 || sent[0].To != "ana@example.com" {
+		t.Fatalf("verification messages: %+v", sent)
+	}
+
+	if w := doJSON(t, r, http.MethodPost, "/users/verify", map[string]any{"token": "forged.token"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("forged token: got %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	w = doJSON(t, r, http.MethodPost, "/users/verify", map[string]any{"token": sent[0].Token})
+	var verified mappers.UserResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &verified)
+	if w.Code != http.StatusOK || verified.Status != domain.StatusActive || !verified.Active || verified.EmailVerifiedAt == nil {
+		t.Fatalf("verify: got %d body=%s", w.Code, w.Body.String())
+	}
+	if w := doJSON(t, r, http.MethodPost, "/users/verify", map[string]any{"token": sent[0].Token}); w.Code != http.StatusBadRequest {
+		t.Fatalf("reused token: got %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	// Endereço já verificado ou inexistente: 202 sem envio; o limite vale igual
+	for _, email := range []string{"ana@example.com", "ghost@example.com"} {
+		w := doJSON(t, r, http.MethodPost, "/users/verify/resend", map[string]any{"email": email})
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("resend %s: got %d", email, w.Code)
+		}
+		w = doJSON(t, r, http.MethodPost, "/users/verify/resend", map[string]any{"email": email})
+		if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+			t.Fatalf("throttled resend %s: got %d retry-after=%q", email, w.Code, w.Header().Get("Retry-After"))
+		}
+	}
+	if len(sent) != 1 {
+		t.Fatalf("resend must not send to verified or unknown addresses: %d messages", len(sent))
+	}
+
+	w = doJSON(t, r, http.MethodPost, "/users", map[string]any{
+		"name": "Bia", "email": "bia@example.com", "password": "secret123", "userType": "User",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create bia: got %d", w.Code)
+	}
+	if w := doJSON(t, r, http.MethodPost, "/users/verify/resend", map[string]any{"email": "bia@example.com"}); w.Code != http.StatusAccepted {
+		t.Fatalf("resend bia: got %d", w.Code)
+	}
+	if len(sent) != 3 || sent[2].To != "bia@example.com" {
+		t.Fatalf("resend to pending user: %+v", sent)
+	}
+	if w := doJSON(t, r, http.MethodPost, "/users/verify", map[string]any{"token": sent[1].Token}); w.Code != http.StatusBadRequest {
+		t.Fatalf("token replaced by resend: got %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestEmailVerification_DisabledRoutesReturn404(t *testing.T) {
+	h := NewUserHandler(&stubRepo{})
+	r := gin.New()
+	r.POST("/users/verify", h.VerifyEmail)
+	r.POST("/users/verify/resend", h.ResendVerification)
+
+	for _, path := range []string{"/users/verify", "/users/verify/resend"} {
+		if w := doJSON(t, r, http.MethodPost, path, map[string]any{}); w.Code != http.StatusNotFound {
+			t.Fatalf("%s: got %d, want %d", path, w.Code, http.StatusNotFound)
+		}
+	}
+}
+
+func TestPasswordReset_ForgotAndReset(t *testing.T) {
+	var sent []passwordreset.Message
+	resets := passwordreset.NewService(passwordreset.NotifierFunc(func(ctx context.Context, m passwordreset.Message) error {
+		sent = append(sent, m)
+		return nil
+	}))
+	repo := repository.NewInMemoryUserRepository()
+	u, err := repo.Create(context.Background(), mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	dispatcher := events.NewDispatcher()
+	dispatcher.Subscribe(domain.EventUserPasswordResetRequested, passwordreset.SendOnRequested(resets))
+
+	h := NewUserHandler(repo, WithPasswordReset(resets), WithEventDispatcher(dispatcher))
+	r := gin.New()
+	r.POST("/password/forgot", h.ForgotPassword)
+	r.POST("/password/reset", h.ResetPassword)
+
+	// Endereço cadastrado ou não: mesma resposta
+	known := doJSON(t, r, http.MethodPost, "/password/forgot", map[string]any{"email": "ana@example.com"})
+	unknown := doJSON(t, r, http.MethodPost, "/password/forgot", map[string]any{"email": "ghost@example.com"})
+	if known.Code != http.StatusAccepted || unknown.Code != known.Code || unknown.Body.String() != known.Body.String() {
+		t.Fatalf("forgot: known=%d %s unknown=%d %s", known.Code, known.Body.String(), unknown.Code, unknown.Body.String())
+	}
+	if len(sent) != 1 || sent[0].UserID != string(u.ID) {
+		t.Fatalf("reset messages: %+v", sent)
+	}
+	if w := doJSON(t, r, http.MethodPost, "/password/forgot", map[string]any{"email": "ana@example.com"}); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("throttled forgot: got %d", w.Code)
+	}
+
+	token := sent[0].Token
+	if w := doJSON(t, r, http.MethodPost, "/password/reset", map[string]any{"token": token, "password": "ana@example.com"}); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("policy violation: got %d body=%s", w.Code, w.Body.String())
+	}
+	// A senha rejeitada não gasta o token
+	if w := doJSON(t, r, http.MethodPost, "/password/reset", map[string]any{"token": token, "password": "n3w-s3cret"}); w.Code != http.StatusNoContent {
+		t.Fatalf("reset: got %d body=%s", w.Code, w.Body.String())
+	}
+	saved, _, _ := repo.GetByID(context.Background(), u.ID)
+	if !saved.Password.Compare("n3w-s3cret") || saved.SessionsRevokedAt == nil {
+		t.Fatalf("after reset: password replaced=%v revokedAt=%v", saved.Password.Compare("n3w-s3cret"), saved.SessionsRevokedAt)
+	}
+	if w := doJSON(t, r, http.MethodPost, "/password/reset", map[string]any{"token": token, "password": "0ther-s3cret"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("reused token: got %d", w.Code)
+	}
+	if w := doJSON(t, r, http.MethodPost, "/password/reset", map[string]any{"token": "garbage", "password": "0ther-s3cret"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown token: got %d", w.Code)
+	}
+}
+
+func TestPasswordReset_ForgotLeavesDeliveryToTheOutbox(t *testing.T) {
+	var sent []passwordreset.Message
+	resets := passwordreset.NewService(passwordreset.NotifierFunc(func(ctx context.Context, m passwordreset.Message) error {
+		sent = append(sent, m)
+		return nil
+	}))
+	dispatcher := events.NewDispatcher()
+	dispatcher.Subscribe(domain.EventUserPasswordResetRequested, passwordreset.SendOnRequested(resets))
+
+	outbox := repository.NewOutbox()
+	repo := repository.NewInMemoryUserRepository(repository.WithOutbox(outbox))
+	u, err := repo.Create(context.Background(), mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	outbox.Ack(outbox.Pending(0)[0].ID)
+
+	h := NewUserHandler(repo, WithPasswordReset(resets))
+	r := gin.New()
+	r.POST("/password/forgot", h.ForgotPassword)
+
+	// Nenhum dos dois pedidos chama o notifier; só o cadastrado vira evento
+	for _, email := range []string{"ghost@example.com", "ana@example.com"} {
+		if w := doJSON(t, r, http.MethodPost, "/password/forgot", map[string]any{"email": email}); w.Code != http.StatusAccepted {
+			t.Fatalf("forgot %s: got %d", email, w.Code)
+		}
+	}
+	if len(sent) != 0 {
+		t.Fatalf("the request must not deliver the link: %+v", sent)
+	}
+	pending := outbox.Pending(0)
+	if len(pending) != 1 || pending[0].Event.EventName() != domain.EventUserPasswordResetRequested {
+		t.Fatalf("outbox: %+v", pending)
+	}
+	if payload, _ := pending[0].Payload(); strings.Contains(string(payload), "token") {
+		t.Fatalf("the event must not carry a token: %s", payload)
+	}
+
+	relay := repository.NewOutboxRelay(outbox, events.OutboxPublisher(dispatcher), time.Second, 10, nil)
+	if _, err := relay.RelayOnce(context.Background()); err != nil {
+		t.Fatalf("RelayOnce: %v", err)
+	}
+	if len(sent) != 1 || sent[0].UserID != string(u.ID) || sent[0].Token == "" {
+		t.Fatalf("reset messages: %+v", sent)
+	}
+	if _, err := resets.Lookup(sent[0].Token); err != nil {
+		t.Fatalf("delivered token should be valid: %v", err)
+	}
 }
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/metrics"
+	"github.com/williamkoller/cloud-architecture-golang/internal/tenant"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/dtos"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/mappers"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/repository"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/validation"
+	"github.com/williamkoller/cloud-architecture-golang/internal/verification"
+)
+
+// WithEmailVerification faz CreateUser criar usuários em pending_verification
+// e habilita as rotas de verificação. O link inicial é enviado pelo assinante
+// verification.SendOnCreated, registrado no dispatcher de eventos.
+func WithEmailVerification(v *verification.Service) Option {
+	return func(h *UserHandler) {
+		h.verifier = v
+	}
+}
+
+// VerifyEmail consome o token de verificação (POST /users/verify). Erros de
+// token não dizem se o usuário existe.
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	if h.verifier == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "email verification is not enabled"})
+		return
+	}
+
+	var req dtos.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.RespondValidationError(c, err)
+		return
+	}
+
+	claims, err := h.verifier.Verify(req.Token)
+	if err != nil {
+		respondTokenError(c, err)
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	id, err := vo.ParseUserID(claims.UserID)
+	if err != nil {
+		respondTokenError(c, verification.ErrInvalidToken)
+		return
+	}
+	current, ok, err := h.repoFor(ctx).GetByID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// O token vale só para o endereço a que foi enviado
+	if !ok || string(current.Email) != claims.Email {
+		respondTokenError(c, verification.ErrInvalidToken)
+		return
+	}
+
+	updated := current
+	if err := updated.VerifyEmail(time.Now().UTC()); err != nil {
+		if errors.Is(err, domain.ErrEmailAlreadyVerified) {
+			h.verifier.Consume(claims)
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		respondStatusError(c, err)
+		return
+	}
+
+	updated, err = h.repoFor(ctx).Update(ctx, updated)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			respondTokenError(c, verification.ErrInvalidToken)
+		case repository.ErrVersionConflict:
+			c.JSON(http.StatusConflict, gin.H{"error": "user was modified concurrently; retry"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	h.verifier.Consume(claims)
+
+	h.invalidateCache(current)
+	metrics.UsersUpdatedInc(updated.OrgID)
+	h.publish(ctx, &updated)
+
+	response := mappers.ToUserResponse(updated)
+	h.setCachedUser(updated, response)
+
+	c.Header("ETag", etagFor(response.Version))
+	c.JSON(http.StatusOK, response)
+}
+
+// ResendVerification envia um novo link (POST /users/verify/resend). A resposta
+// é 202 exista ou não o endereço; o limite de reenvios vale por endereço.
+func (h *UserHandler) ResendVerification(c *gin.Context) {
+	if h.verifier == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "email verification is not enabled"})
+		return
+	}
+
+	var req dtos.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validation.RespondValidationError(c, err)
+		return
+	}
+	email, err := vo.NewEmail(req.Email)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := h.ctx(c)
+	defer cancel()
+
+	orgID, _ := tenant.OrgFrom(ctx)
+	if wait, ok := h.verifier.AllowResend(orgID, string(email)); !ok {
+		respondThrottled(c, wait, "too many verification emails requested; retry later")
+		return
+	}
+
+	u, ok, err := h.repoFor(ctx).GetByEmail(ctx, email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if ok && needsVerification(u) {
+		recipient := verification.Recipient{UserID: string(u.ID), OrgID: u.OrgID, Email: string(u.Email)}
+		if err := h.verifier.Send(ctx, recipient); err != nil {
+			// Falhar aqui revelaria que o endereço existe; o cliente pode tentar de novo
+			log.Printf("verification resend to user %s failed: %v", u.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"status": "if the address needs verification, a new link was sent"})
+}
+
+// needsVerification indica se faz sentido reenviar o link ao usuário
+func needsVerification(u domain.User) bool {
+	if u.IsDeleted() || u.EmailVerifiedAt != nil {
+		return false
+	}
+	s := u.EffectiveStatus()
+	return s == domain.StatusPendingVerification || s == domain.StatusActive
+}
+
+// respondThrottled responde 429 com Retry-After em segundos inteiros
+func respondThrottled(c *gin.Context, wait time.Duration, msg string) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": msg})
+}
+
+// respondTokenError responde 410 para tokens expirados e 400 para os demais
+func respondTokenError(c *gin.Context, err error) {
+	if errors.Is(err, verification.ErrExpiredToken) {
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
@@ -1,19 +1,87 @@
 package mappers
 
-import "github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+import (
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+)
 
 type UserResponse struct {
-	Name     string          `json:"name"`
-	Email    string          `json:"email"`
-	Active   bool            `json:"active"`
-	UserType domain.UserType `json:"userType"`
+	ID              string          `json:"id"`
+	OrgID           string          `json:"orgId,omitempty"`
+	Name            string          `json:"name"`
+	Email           string          `json:"email"`
+	PreviousEmails  []string        `json:"previousEmails,omitempty"`
+	Active          bool            `json:"active"`
+	Status          domain.Status   `json:"status"`
+	StatusHistory   []StatusChange  `json:"statusHistory,omitempty"`
+	UserType        domain.UserType `json:"userType"`
+	Roles           []string        `json:"roles"`
+	Version         uint64          `json:"version"`
+	CreatedAt       string          `json:"createdAt"`
+	UpdatedAt       string          `json:"updatedAt"`
+	LastLoginAt     *string         `json:"lastLoginAt,omitempty"`
+	DeactivatedAt   *string         `json:"deactivatedAt,omitempty"`
+	EmailVerifiedAt *string         `json:"emailVerifiedAt,omitempty"`
+	DeletedAt       *string         `json:"deletedAt,omitempty"`
+}
+
+// StatusChange é uma transição de status na resposta
+type StatusChange struct {
+	From   domain.Status `json:"from"`
+	To     domain.Status `json:"to"`
+	Reason string        `json:"reason,omitempty"`
+	Actor  string        `json:"actor,omitempty"`
+	At     string        `json:"at"`
+}
+
+// formatTime formata datas em RFC 3339 (UTC)
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatTimePtr(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := formatTime(*t)
+	return &s
 }
 
 func ToUserResponse(u domain.User) UserResponse {
+	var previous []string
+	for _, h := range u.EmailHistory {
+		previous = append(previous, string(h.Previous))
+	}
+
+	var history []StatusChange
+	for _, sc := range u.StatusHistory {
+		history = append(history, StatusChange{
+			From:   sc.From,
+			To:     sc.To,
+			Reason: sc.Reason,
+			Actor:  sc.Actor,
+			At:     formatTime(sc.At),
+		})
+	}
+
 	return UserResponse{
-		Name:     u.Name,
-		Email:    string(u.Email),
-		Active:   u.Active,
-		UserType: u.UserType,
+		ID:              string(u.ID),
+		OrgID:           u.OrgID,
+		Name:            u.Name,
+		Email:           u.EmailForDisplay(),
+		PreviousEmails:  previous,
+		Active:          u.Active,
+		Status:          u.EffectiveStatus(),
+		StatusHistory:   history,
+		UserType:        u.UserType,
+		Roles:           u.EffectiveRoles(),
+		Version:         u.Version,
+		CreatedAt:       formatTime(u.CreatedAt),
+		UpdatedAt:       formatTime(u.UpdatedAt),
+		LastLoginAt:     formatTimePtr(u.LastLoginAt),
+		DeactivatedAt:   formatTimePtr(u.DeactivatedAt),
+		EmailVerifiedAt: formatTimePtr(u.EmailVerifiedAt),
+		DeletedAt:       formatTimePtr(u.DeletedAt),
 	}
 }
@@ -2,8 +2,10 @@ package mappers
 
 import (
 	"testing"
+	"time"
 
 	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
 )
 
 func TestToUserResponse_MapsAllFields(t *testing.T) {
@@ -17,9 +19,16 @@ func TestToUserResponse_MapsAllFields(t *testing.T) {
 	if err != nil {
 		t.Fatalf("domain.NewUser: %v", err)
 	}
+	u.OrgID = "acme"
 
 	resp := ToUserResponse(u)
 
+	if resp.ID != string(u.ID) || resp.ID == "" {
+		t.Fatalf("ID: got %q, want %q", resp.ID, string(u.ID))
+	}
+	if resp.OrgID != "acme" {
+		t.Fatalf("OrgID: got %q, want %q", resp.OrgID, "acme")
+	}
 	if resp.Name != "Ana" {
 		t.Fatalf("Name: got %q, want %q", resp.Name, "Ana")
 	}
@@ -29,9 +38,18 @@ func TestToUserResponse_MapsAllFields(t *testing.T) {
 	if resp.Active != true {
 		t.Fatalf("Active: got %v, want %v", resp.Active, true)
 	}
+	if resp.Status != domain.StatusActive || resp.StatusHistory != nil {
+		t.Fatalf("Status: got %q history=%v", resp.Status, resp.StatusHistory)
+	}
 	if resp.UserType != domain.UserTypeAdmin {
 		t.Fatalf("UserType: got %v, want %v", resp.UserType, domain.UserTypeAdmin)
 	}
+	if len(resp.Roles) != 2 || resp.Roles[0] != "admin" || resp.Roles[1] != "user" {
+		t.Fatalf("Roles: got %v, want [admin user]", resp.Roles)
+	}
+	if resp.Version != u.Version {
+		t.Fatalf("Version: got %d, want %d", resp.Version, u.Version)
+	}
 }
 
 func TestToUserResponse_MapsDifferentValues(t *testing.T) {
@@ -71,3 +89,68 @@ func TestToUserResponse_ZeroValueUser_DoesNotPanic(t *testing.T) {
 		t.Fatalf("unexpected response from zero value user: %+v", resp)
 	}
 }
+
+func TestToUserResponse_MapsPreviousEmails(t *testing.T) {
+	u, err := domain.NewUser("Ana", "ana@example.com", "secret123", true, domain.UserTypeUser)
+	if err != nil {
+		t.Fatalf("domain.NewUser: %v", err)
+	}
+
+	if resp := ToUserResponse(u); resp.PreviousEmails != nil {
+		t.Fatalf("PreviousEmails: got %v, want nil", resp.PreviousEmails)
+	}
+
+	u.ChangeEmail(vo.Address{Email: "ana.paula@example.com"}, time.Now())
+	resp := ToUserResponse(u)
+	if resp.Email != "ana.paula@example.com" {
+		t.Fatalf("Email: got %q", resp.Email)
+	}
+	if len(resp.PreviousEmails) != 1 || resp.PreviousEmails[0] != "ana@example.com" {
+		t.Fatalf("PreviousEmails: got %v", resp.PreviousEmails)
+	}
+}
+
+func TestToUserResponse_FormatsTimestampsAsRFC3339(t *testing.T) {
+	u, err := domain.NewUser("Ana", "ana@example.com", "secret123", false, domain.UserTypeUser)
+	if err != nil {
+		t.Fatalf("domain.NewUser: %v", err)
+	}
+
+	loc := time.FixedZone("BRT", -3*60*60)
+	u.CreatedAt = time.Date(2025, 3, 1, 9, 30, 0, 0, loc)
+	u.UpdatedAt = time.Date(2025, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	resp := ToUserResponse(u)
+	if resp.CreatedAt != "2025-03-01T12:30:00Z" {
+		t.Fatalf("CreatedAt: got %q", resp.CreatedAt)
+	}
+	if resp.UpdatedAt != "2025-03-02T10:00:00Z" {
+		t.Fatalf("UpdatedAt: got %q", resp.UpdatedAt)
+	}
+	if resp.LastLoginAt != nil || resp.DeactivatedAt != nil {
+		t.Fatalf("optional timestamps should be nil: last=%v deactivated=%v", resp.LastLoginAt, resp.DeactivatedAt)
+	}
+
+	deactivated := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	u.DeactivatedAt = &deactivated
+	resp = ToUserResponse(u)
+	if resp.DeactivatedAt == nil || *resp.DeactivatedAt != "2025-03-03T00:00:00Z" {
+		t.Fatalf("DeactivatedAt: got %v", resp.DeactivatedAt)
+	}
+	if resp.DeletedAt != nil {
+		t.Fatalf("DeletedAt: got %v, want nil", resp.DeletedAt)
+	}
+
+	u.DeletedAt = &deactivated
+	if resp = ToUserResponse(u); resp.DeletedAt == nil || *resp.DeletedAt != "2025-03-03T00:00:00Z" {
+		t.Fatalf("DeletedAt: got %v", resp.DeletedAt)
+	}
+}
+
+func TestToUserResponse_LegacyUserWithoutRoles(t *testing.T) {
+	// Registros sem papéis derivam os papéis do UserType
+	resp := ToUserResponse(domain.User{ID: "id", Name: "Ana", UserType: domain.UserTypeUser})
+	if len(resp.Roles) != 1 || resp.Roles[0] != "user" {
+		t.Fatalf("Roles: got %v, want [user]", resp.Roles)
+	}
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+)
+
+// SortField define o campo de data usado para ordenar a listagem
+type SortField string
+
+const (
+	SortByCreatedAt     SortField = "createdAt"
+	SortByUpdatedAt     SortField = "updatedAt"
+	SortByLastLoginAt   SortField = "lastLoginAt"
+	SortByDeactivatedAt SortField = "deactivatedAt"
+)
+
+// TimeRange é um intervalo [From, To); limites zerados não são aplicados
+type TimeRange struct {
+	From time.Time
+	To   time.Time
+}
+
+func (tr TimeRange) isSet() bool {
+	return !tr.From.IsZero() || !tr.To.IsZero()
+}
+
+func (tr TimeRange) contains(t time.Time) bool {
+	if !tr.From.IsZero() && t.Before(tr.From) {
+		return false
+	}
+	if !tr.To.IsZero() && !t.Before(tr.To) {
+		return false
+	}
+	return true
+}
+
+// containsPtr trata datas ausentes como fora de qualquer intervalo definido
+func (tr TimeRange) containsPtr(t *time.Time) bool {
+	if !tr.isSet() {
+		return true
+	}
+	return t != nil && tr.contains(*t)
+}
+
+// ListQuery reúne filtros e ordenação da listagem. O valor zero lista todos
+// os usuários não excluídos ordenados pela data de criação.
+type ListQuery struct {
+	// Email restringe a listagem a um endereço exato
+	Email          vo.Email
+	IncludeDeleted bool
+	// Statuses restringe a listagem aos status informados; vazio aceita todos
+	Statuses []domain.Status
+
+	Created     TimeRange
+	Updated     TimeRange
+	LastLogin   TimeRange
+	Deactivated TimeRange
+
+	SortBy   SortField
+	SortDesc bool
+}
+
+// Matches indica se o usuário passa por todos os filtros da consulta
+func (q ListQuery) Matches(u domain.User) bool {
+	if u.IsDeleted() && !q.IncludeDeleted {
+		return false
+	}
+	if q.Email != "" && u.Email != q.Email {
+		return false
+	}
+	if len(q.Statuses) > 0 && !slices.Contains(q.Statuses, u.EffectiveStatus()) {
+		return false
+	}
+	return q.Created.contains(u.CreatedAt) &&
+		q.Updated.contains(u.UpdatedAt) &&
+		q.LastLogin.containsPtr(u.LastLoginAt) &&
+		q.Deactivated.containsPtr(u.DeactivatedAt)
+}
+
+// Sort ordena os usuários conforme a consulta. Datas ausentes ficam sempre no
+// fim e o ID desempata, para que a ordem seja estável entre chamadas.
+func (q ListQuery) Sort(users []domain.User) {
+	key := func(u domain.User) *time.Time {
+		switch q.SortBy {
+		case SortByUpdatedAt:
+			return &u.UpdatedAt
+		case SortByLastLoginAt:
+			return u.LastLoginAt
+		case SortByDeactivatedAt:
+			return u.DeactivatedAt
+		default:
+			return &u.CreatedAt
+		}
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		a, b := key(users[i]), key(users[j])
+		switch {
+		case a == nil && b == nil:
+			return users[i].ID < users[j].ID
+		case a == nil:
+			return false
+		case b == nil:
+			return true
+		case a.Equal(*b):
+			return users[i].ID < users[j].ID
+		case q.SortDesc:
+			return a.After(*b)
+		default:
+			return a.Before(*b)
+		}
+	})
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+)
+
+// OutboxMessage é um evento de domínio aguardando publicação
+type OutboxMessage struct {
+	// ID é estável entre tentativas (usuário, versão e posição do evento na
+	// escrita) e serve para o consumidor descartar entregas repetidas
+	ID         string
+	OrgID      string // organização do repositório que gravou o evento
+	Event      domain.Event
+	EnqueuedAt time.Time
+	Attempts   int
+	LastError  string // erro da última tentativa, para quem examina as estacionadas
+}
+
+// Payload serializa o evento em JSON
+func (m OutboxMessage) Payload() ([]byte, error) {
+	return json.Marshal(m.Event)
+}
+
+// Outbox guarda os eventos gravados junto com as mutações do repositório até
+// que o relay os publique. Mensagens que esgotam as tentativas vão para a
+// fila de estacionadas (dead letter). Seu lock é sempre o último adquirido.
+type Outbox struct {
+	mu       sync.Mutex
+	messages []OutboxMessage
+	parked   []OutboxMessage
+}
+
+func NewOutbox() *Outbox {
+	return &Outbox{}
+}
+
+// WithOutbox faz o repositório gravar os eventos pendentes de cada escrita no
+// outbox, na mesma seção crítica da mutação: ou ambos acontecem, ou nenhum
+func WithOutbox(o *Outbox) Option {
+	return func(r *inMemoryUserRepo) {
+		r.outbox = o
+	}
+}
+
+// enqueue adiciona os eventos de uma escrita na versão informada do usuário
+func (o *Outbox) enqueue(orgID string, evts []domain.Event, version uint64, at time.Time) {
+	if len(evts) == 0 {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i, e := range evts {
+		o.messages = append(o.messages, OutboxMessage{
+			ID:         fmt.Sprintf("%s:%d:%d", e.AggregateID(), version, i),
+			OrgID:      orgID,
+			Event:      e,
+			EnqueuedAt: at,
+		})
+	}
+}
+
+// Pending devolve até limit mensagens na ordem de gravação, sem removê-las
+func (o *Outbox) Pending(limit int) []OutboxMessage {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if limit <= 0 || limit > len(o.messages) {
+		limit = len(o.messages)
+	}
+	out := make([]OutboxMessage, limit)
+	copy(out, o.messages[:limit])
+	return out
+}
+
+// Ack remove as mensagens já publicadas
+func (o *Outbox) Ack(ids ...string) {
+	if len(ids) == 0 {
+		return
+	}
+	done := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		done[id] = true
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	kept := o.messages[:0]
+	for _, m := range o.messages {
+		if !done[m.ID] {
+			kept = append(kept, m)
+		}
+	}
+	// Zerar o final para não reter eventos já publicados
+	clear(o.messages[len(kept):])
+	o.messages = kept
+}
+
+// markFailed registra mais uma tentativa malsucedida da mensagem e devolve
+// quantas já foram feitas
+func (o *Outbox) markFailed(id string, err error) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i := range o.messages {
+		if o.messages[i].ID == id {
+			o.messages[i].Attempts++
+			o.messages[i].LastError = err.Error()
+			return o.messages[i].Attempts
+		}
+	}
+	return 0
+}
+
+// park tira a mensagem da fila e a guarda entre as estacionadas
+func (o *Outbox) park(id string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i, m := range o.messages {
+		if m.ID == id {
+			o.parked = append(o.parked, m)
+			// slices.Delete zera a posição que sobra no final
+			o.messages = slices.Delete(o.messages, i, i+1)
+			return
+		}
+	}
+}
+
+// Parked devolve as mensagens estacionadas, na ordem em que desistiram delas
+func (o *Outbox) Parked() []OutboxMessage {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]OutboxMessage(nil), o.parked...)
+}
+
+// parkedCount devolve quantas mensagens estão estacionadas
+func (o *Outbox) parkedCount() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.parked)
+}
+
+// Requeue devolve mensagens estacionadas ao fim da fila, com as tentativas
+// zeradas, depois de corrigido o que as fazia falhar. Devolve quantas voltaram.
+func (o *Outbox) Requeue(ids ...string) int {
+	retry := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		retry[id] = true
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	kept := o.parked[:0]
+	n := 0
+	for _, m := range o.parked {
+		if !retry[m.ID] {
+			kept = append(kept, m)
+			continue
+		}
+		m.Attempts, m.LastError = 0, ""
+		o.messages = append(o.messages, m)
+		n++
+	}
+	clear(o.parked[len(kept):])
+	o.parked = kept
+	return n
+}
+
+// Lag devolve quantas mensagens aguardam publicação e a idade da mais antiga
+func (o *Outbox) Lag(now time.Time) (int, time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.messages) == 0 {
+		return 0, 0
+	}
+	return len(o.messages), now.Sub(o.messages[0].EnqueuedAt)
+}
+
+// persist grava o usuário sem eventos pendentes e, se houver outbox, enfileira
+// esses eventos. Deve ser chamado com o shard do usuário travado.
+func (r *inMemoryUserRepo) persist(s *shard, u domain.User) {
+	stored := u
+	evts := stored.PullEvents()
+	s.data[string(u.ID)] = stored
+	if r.outbox != nil {
+		r.outbox.enqueue(u.OrgID, evts, u.Version, r.now())
+	}
+}
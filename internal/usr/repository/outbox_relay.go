@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Publisher entrega uma mensagem do outbox ao destino (fila, barramento, assinantes em processo).
+// A entrega é pelo menos uma vez: o publisher deve tolerar o mesmo OutboxMessage.ID repetido.
+type Publisher interface {
+	Publish(ctx context.Context, msg OutboxMessage) error
+}
+
+// PublisherFunc adapta uma função a Publisher
+type PublisherFunc func(ctx context.Context, msg OutboxMessage) error
+
+func (f PublisherFunc) Publish(ctx context.Context, msg OutboxMessage) error {
+	return f(ctx, msg)
+}
+
+// DefaultMaxAttempts é quantas vezes o relay tenta uma mensagem antes de estacioná-la
+const DefaultMaxAttempts = 10
+
+// OutboxStats resume uma rodada do relay, para métricas
+type OutboxStats struct {
+	Published int
+	Failed    int
+	Parked    int // mensagens estacionadas nesta rodada
+	Pending   int
+	OldestAge time.Duration
+	// DeadLetters é o total de mensagens estacionadas aguardando Requeue
+	DeadLetters int
+}
+
+// OutboxRelay drena o outbox periodicamente para o publisher
+type OutboxRelay struct {
+	outbox      *Outbox
+	publisher   Publisher
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	now         func() time.Time
+	onRelay     func(OutboxStats)
+}
+
+// RelayOption configura o OutboxRelay
+type RelayOption func(*OutboxRelay)
+
+// WithMaxAttempts define quantas tentativas uma mensagem tem antes de ser
+// estacionada (DefaultMaxAttempts quando n <= 0)
+func WithMaxAttempts(n int) RelayOption {
+	return func(r *OutboxRelay) {
+		if n > 0 {
+			r.maxAttempts = n
+		}
+	}
+}
+
+// NewOutboxRelay cria o relay; onRelay (opcional) recebe o resultado de cada rodada
+func NewOutboxRelay(outbox *Outbox, publisher Publisher, interval time.Duration, batchSize int, onRelay func(OutboxStats), opts ...RelayOption) *OutboxRelay {
+	r := &OutboxRelay{
+		outbox:      outbox,
+		publisher:   publisher,
+		interval:    interval,
+		batchSize:   batchSize,
+		maxAttempts: DefaultMaxAttempts,
+		now:         time.Now,
+		onRelay:     onRelay,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// RelayOnce publica um lote em ordem e confirma o que foi entregue. Na primeira
+// falha o lote para, preservando a ordem; a mensagem é tentada de novo na próxima
+// rodada. Ao esgotar as tentativas ela é estacionada e o lote segue, para que uma
+// mensagem que nunca será entregue não trave a fila de todas as organizações.
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (OutboxStats, error) {
+	var stats OutboxStats
+	var firstErr error
+
+	var delivered []string
+	for _, msg := range r.outbox.Pending(r.batchSize) {
+		if err := ctx.Err(); err != nil {
+			firstErr = err
+			break
+		}
+		if err := r.publisher.Publish(ctx, msg); err != nil {
+			stats.Failed++
+			if r.outbox.markFailed(msg.ID, err) >= r.maxAttempts {
+				r.outbox.park(msg.ID)
+				stats.Parked++
+				log.Printf("outbox message %s (%s, org %q) parked after %d attempts: %v",
+					msg.ID, msg.Event.EventName(), msg.OrgID, r.maxAttempts, err)
+				continue
+			}
+			firstErr = err
+			break
+		}
+		delivered = append(delivered, msg.ID)
+	}
+	r.outbox.Ack(delivered...)
+	stats.Published = len(delivered)
+
+	stats.Pending, stats.OldestAge = r.outbox.Lag(r.now())
+	stats.DeadLetters = r.outbox.parkedCount()
+	if r.onRelay != nil {
+		r.onRelay(stats)
+	}
+	return stats, firstErr
+}
+
+// Run drena o outbox a cada intervalo até o contexto ser cancelado
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if stats, err := r.RelayOnce(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("outbox relay stopped after %d messages (%d pending): %v", stats.Published, stats.Pending, err)
+			}
+		}
+	}
+}
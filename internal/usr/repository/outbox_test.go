@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+)
+
+func TestOutbox_WrittenWithEachMutation(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	ob := NewOutbox()
+	repo := NewInMemoryUserRepository(WithClock(clock.Now), WithOutbox(ob))
+	ctx := context.Background()
+
+	u, err := repo.Create(ctx, mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := repo.ChangeEmail(ctx, u.ID, mustAddr(t, "ana.paula@example.com")); err != nil {
+		t.Fatalf("ChangeEmail: %v", err)
+	}
+	if _, err := repo.Delete(ctx, u.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	msgs := ob.Pending(0)
+	want := []struct{ name, id string }{
+		{domain.EventUserCreated, fmt.Sprintf("%s:1:0", u.ID)},
+		{domain.EventUserEmailChanged, fmt.Sprintf("%s:2:0", u.ID)},
+		{domain.EventUserDeleted, fmt.Sprintf("%s:3:0", u.ID)},
+	}
+	if len(msgs) != len(want) {
+		t.Fatalf("outbox: got %d messages, want %d", len(msgs), len(want))
+	}
+	for i, w := range want {
+		if msgs[i].Event.EventName() != w.name || msgs[i].ID != w.id {
+			t.Fatalf("message %d: got %s/%s, want %s/%s", i, msgs[i].Event.EventName(), msgs[i].ID, w.name, w.id)
+		}
+		if !msgs[i].EnqueuedAt.Equal(clock.Now()) {
+			t.Fatalf("message %d: EnqueuedAt %v", i, msgs[i].EnqueuedAt)
+		}
+	}
+
+	payload, err := msgs[0].Payload()
+	if err != nil || len(payload) == 0 {
+		t.Fatalf("Payload: %q err=%v", payload, err)
+	}
+}
+
+func TestOutbox_FailedMutationWritesNothing(t *testing.T) {
+	ob := NewOutbox()
+	repo := NewInMemoryUserRepository(WithOutbox(ob))
+	ctx := context.Background()
+
+	u, err := repo.Create(ctx, mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	ob.Ack(ob.Pending(0)[0].ID)
+
+	if _, err := repo.Create(ctx, mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)); err != ErrAlreadyExists {
+		t.Fatalf("duplicate Create: got %v", err)
+	}
+	stale := u
+	stale.Version = 99
+	_ = stale.Rename("Outra")
+	if _, err := repo.Update(ctx, stale); err != ErrVersionConflict {
+		t.Fatalf("stale Update: got %v", err)
+	}
+
+	if n, _ := ob.Lag(time.Now()); n != 0 {
+		t.Fatalf("failed writes left %d messages in the outbox", n)
+	}
+}
+
+func TestOutbox_ConcurrentWritesKeepOneMessagePerEvent(t *testing.T) {
+	ob := NewOutbox()
+	repo := NewInMemoryUserRepository(WithOutbox(ob))
+	ctx := context.Background()
+
+	const n = 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			u := mustUser(t, "User", fmt.Sprintf("user%d@example.com", i), true, domain.UserTypeUser)
+			if _, err := repo.Create(ctx, u); err != nil {
+				t.Errorf("Create: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	for _, m := range ob.Pending(0) {
+		if seen[m.ID] {
+			t.Fatalf("duplicate message id %q", m.ID)
+		}
+		seen[m.ID] = true
+	}
+	if len(seen) != n {
+		t.Fatalf("outbox: got %d messages, want %d", len(seen), n)
+	}
+}
+
+func TestOutboxRelay_DeliversInOrderAndRetriesFailures(t *testing.T) {
+	ob := NewOutbox()
+	repo := NewInMemoryUserRepository(WithOutbox(ob))
+	ctx := context.Background()
+
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		if _, err := repo.Create(ctx, mustUser(t, "User", email, true, domain.UserTypeUser)); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	var delivered []string
+	failOn := 1
+	pub := PublisherFunc(func(ctx context.Context, msg OutboxMessage) error {
+		if len(delivered) == failOn {
+			failOn = -1
+			return errors.New("broker unavailable")
+		}
+		delivered = append(delivered, msg.ID)
+		return nil
+	})
+
+	var rounds []OutboxStats
+	relay := NewOutboxRelay(ob, pub, time.Second, 10, func(s OutboxStats) { rounds = append(rounds, s) })
+
+	stats, err := relay.RelayOnce(ctx)
+	if err == nil {
+		t.Fatalf("expected publish error on first round")
+	}
+	if stats.Published != 1 || stats.Failed != 1 || stats.Pending != 2 {
+		t.Fatalf("first round: %+v", stats)
+	}
+	if pending := ob.Pending(0); pending[0].Attempts != 1 {
+		t.Fatalf("failed message attempts: got %d, want 1", pending[0].Attempts)
+	}
+
+	stats, err = relay.RelayOnce(ctx)
+	if err != nil || stats.Published != 2 || stats.Pending != 0 || stats.OldestAge != 0 {
+		t.Fatalf("second round: %+v err=%v", stats, err)
+	}
+	if len(rounds) != 2 {
+		t.Fatalf("onRelay: got %d calls, want 2", len(rounds))
+	}
+
+	all := map[string]bool{}
+	for _, id := range delivered {
+		all[id] = true
+	}
+	if len(delivered) != 3 || len(all) != 3 {
+		t.Fatalf("delivered: %v", delivered)
+	}
+}
+
+func TestOutboxRelay_BatchSizeAndLag(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	ob := NewOutbox()
+	repo := NewInMemoryUserRepository(WithClock(clock.Now), WithOutbox(ob))
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		u := mustUser(t, "User", fmt.Sprintf("user%d@example.com", i), true, domain.UserTypeUser)
+		if _, err := repo.Create(ctx, u); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		clock.Advance(time.Minute)
+	}
+
+	relay := NewOutboxRelay(ob, PublisherFunc(func(context.Context, OutboxMessage) error { return nil }), time.Second, 2, nil)
+	relay.now = clock.Now
+
+	stats, err := relay.RelayOnce(ctx)
+	if err != nil {
+		t.Fatalf("RelayOnce: %v", err)
+	}
+	// Restam as mensagens 3..5; a mais antiga foi gravada 3 minutos antes do relógio atual
+	if stats.Published != 2 || stats.Pending != 3 || stats.OldestAge != 3*time.Minute {
+		t.Fatalf("stats: %+v", stats)
+	}
+}
+
+func TestOutboxRelay_Run_StopsOnCancel(t *testing.T) {
+	ob := NewOutbox()
+	repo := NewInMemoryUserRepository(WithOutbox(ob))
+	if _, err := repo.Create(context.Background(), mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	published := make(chan string, 1)
+	relay := NewOutboxRelay(ob, PublisherFunc(func(ctx context.Context, msg OutboxMessage) error {
+		published <- msg.ID
+		return nil
+	}), 10*time.Millisecond, 10, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-published:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("relay did not publish")
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Run did not return after cancel")
+	}
+}
+
+func TestOutboxRelay_ParksMessagesThatKeepFailing(t *testing.T) {
+	ob := NewOutbox()
+	repo := NewInMemoryUserRepository(WithOutbox(ob))
+	ctx := context.Background()
+
+	for _, email := range []string{"bad@example.com", "a@example.com", "b@example.com"} {
+		if _, err := repo.Create(ctx, mustUser(t, "User", email, true, domain.UserTypeUser)); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	poison := ob.Pending(1)[0].ID
+
+	var delivered []string
+	broken := true
+	pub := PublisherFunc(func(ctx context.Context, msg OutboxMessage) error {
+		if msg.ID == poison && broken {
+			return errors.New("address is undeliverable")
+		}
+		delivered = append(delivered, msg.ID)
+		return nil
+	})
+	relay := NewOutboxRelay(ob, pub, time.Second, 10, nil, WithMaxAttempts(3))
+
+	// As duas primeiras falhas seguram a fila, preservando a ordem
+	for i := 1; i <= 2; i++ {
+		if stats, err := relay.RelayOnce(ctx); err == nil || stats.Published != 0 || stats.Pending != 3 {
+			t.Fatalf("round %d: %+v err=%v", i, stats, err)
+		}
+	}
+	// Na terceira a mensagem é estacionada e as seguintes passam
+	stats, err := relay.RelayOnce(ctx)
+	if err != nil || stats.Parked != 1 || stats.Published != 2 || stats.Pending != 0 || stats.DeadLetters != 1 {
+		t.Fatalf("third round: %+v err=%v", stats, err)
+	}
+	parked := ob.Parked()
+	if len(parked) != 1 || parked[0].ID != poison || parked[0].Attempts != 3 || parked[0].LastError != "address is undeliverable" {
+		t.Fatalf("parked: %+v", parked)
+	}
+
+	// Corrigida a causa, a mensagem volta para a fila e é entregue
+	broken = false
+	if n := ob.Requeue(poison, "unknown"); n != 1 {
+		t.Fatalf("Requeue: got %d, want 1", n)
+	}
+	stats, err = relay.RelayOnce(ctx)
+	if err != nil || stats.Published != 1 || stats.DeadLetters != 0 || len(ob.Parked()) != 0 {
+		t.Fatalf("after requeue: %+v err=%v", stats, err)
+	}
+	if len(delivered) != 3 || delivered[2] != poison {
+		t.Fatalf("delivered: %v", delivered)
+	}
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Purger remove periodicamente os usuários excluídos há mais tempo que a retenção,
+// em todas as organizações
+type Purger struct {
+	repos     *TenantRepositories
+	retention time.Duration
+	interval  time.Duration
+	now       func() time.Time
+	onPurge   func(orgID string, n int)
+}
+
+// NewPurger cria a rotina de purge; onPurge (opcional) recebe quantos registros
+// saíram de cada organização
+func NewPurger(repos *TenantRepositories, retention, interval time.Duration, onPurge func(orgID string, n int)) *Purger {
+	return &Purger{
+		repos:     repos,
+		retention: retention,
+		interval:  interval,
+		now:       time.Now,
+		onPurge:   onPurge,
+	}
+}
+
+// PurgeOnce executa uma rodada de purge e devolve o total removido. A falha
+// em uma organização não impede o purge das demais.
+func (p *Purger) PurgeOnce(ctx context.Context) (int, error) {
+	cutoff := p.now().Add(-p.retention)
+	total := 0
+	var errs []error
+	p.repos.Each(func(orgID string, repo UserRepository) {
+		n, err := repo.Purge(ctx, cutoff)
+		if n > 0 && p.onPurge != nil {
+			p.onPurge(orgID, n)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", orgID, err))
+		}
+		total += n
+	})
+	return total, errors.Join(errs...)
+}
+
+// Run executa o purge a cada intervalo até o contexto ser cancelado
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n, err := p.PurgeOnce(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("user purge failed after %d records: %v", n, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
+)
+
+// TenantRepositories mantém um repositório de usuários isolado por organização:
+// IDs, emails e listagens de uma organização não enxergam as demais, e o mesmo
+// email pode existir em organizações diferentes
+type TenantRepositories struct {
+	mu    sync.RWMutex
+	repos map[string]UserRepository
+	opts  []Option
+}
+
+// NewTenantRepositories cria o registro; as opções (relógio, outbox) valem para
+// o repositório de todas as organizações
+func NewTenantRepositories(opts ...Option) *TenantRepositories {
+	return &TenantRepositories{
+		repos: make(map[string]UserRepository),
+		opts:  opts,
+	}
+}
+
+// withOrg marca o repositório com a organização, que ele grava em cada usuário criado
+func withOrg(orgID string) Option {
+	return func(r *inMemoryUserRepo) {
+		r.orgID = orgID
+	}
+}
+
+// For devolve o repositório da organização, criando-o no primeiro uso
+func (t *TenantRepositories) For(orgID string) UserRepository {
+	t.mu.RLock()
+	repo, ok := t.repos[orgID]
+	t.mu.RUnlock()
+	if ok {
+		return repo
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if repo, ok := t.repos[orgID]; ok {
+		return repo
+	}
+	opts := append(append([]Option(nil), t.opts...), withOrg(orgID))
+	repo = NewInMemoryUserRepository(opts...)
+	t.repos[orgID] = repo
+	return repo
+}
+
+// Each percorre os repositórios já criados, em ordem de organização
+func (t *TenantRepositories) Each(fn func(orgID string, repo UserRepository)) {
+	t.mu.RLock()
+	ids := make([]string, 0, len(t.repos))
+	for id := range t.repos {
+		ids = append(ids, id)
+	}
+	t.mu.RUnlock()
+
+	sort.Strings(ids)
+	for _, id := range ids {
+		fn(id, t.For(id))
+	}
+}
+
+// FindUser busca um usuário não excluído da organização pelo ID ou pelo email.
+// Identificadores malformados são tratados como não encontrados.
+func (t *TenantRepositories) FindUser(ctx context.Context, orgID, ref string) (domain.User, bool, error) {
+	repo := t.For(orgID)
+	if strings.Contains(ref, "@") {
+		email, err := vo.NewEmail(ref)
+		if err != nil {
+			return domain.User{}, false, nil
+		}
+		return repo.GetByEmail(ctx, email)
+	}
+
+	id, err := vo.ParseUserID(ref)
+	if err != nil {
+		return domain.User{}, false, nil
+	}
+	return repo.GetByID(ctx, id)
+}
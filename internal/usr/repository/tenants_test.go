@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+)
+
+func TestTenantRepositories_IsolateOrganizations(t *testing.T) {
+	ob := NewOutbox()
+	tenants := NewTenantRepositories(WithOutbox(ob))
+	ctx := context.Background()
+
+	acme, globex := tenants.For("acme"), tenants.For("globex")
+	if tenants.For("acme") != acme {
+		t.Fatalf("For should return the same repository for the same organization")
+	}
+
+	a, err := acme.Create(ctx, mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser))
+	if err != nil {
+		t.Fatalf("Create acme: %v", err)
+	}
+	// O mesmo email é livre em outra organização
+	g, err := globex.Create(ctx, mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser))
+	if err != nil {
+		t.Fatalf("Create globex: %v", err)
+	}
+	if a.OrgID != "acme" || g.OrgID != "globex" {
+		t.Fatalf("OrgID: acme=%q globex=%q", a.OrgID, g.OrgID)
+	}
+	if _, err := acme.Create(ctx, mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)); err != ErrAlreadyExists {
+		t.Fatalf("duplicate in acme: got %v", err)
+	}
+
+	if _, ok, _ := globex.GetByID(ctx, a.ID); ok {
+		t.Fatalf("globex sees a user from acme")
+	}
+	if users, _ := acme.List(ctx, ListQuery{}); len(users) != 1 || users[0].ID != a.ID {
+		t.Fatalf("acme list: %+v", users)
+	}
+
+	// O OrgID gravado não muda por Update
+	a.PullEvents()
+	moved := a
+	moved.OrgID = "globex"
+	if updated, err := acme.Update(ctx, moved); err != nil || updated.OrgID != "acme" {
+		t.Fatalf("Update: org=%q err=%v", updated.OrgID, err)
+	}
+
+	orgs := map[string]int{}
+	for _, m := range ob.Pending(0) {
+		orgs[m.OrgID]++
+	}
+	if orgs["acme"] != 1 || orgs["globex"] != 1 {
+		t.Fatalf("outbox messages by org: %v", orgs)
+	}
+
+	var seen []string
+	tenants.Each(func(orgID string, _ UserRepository) { seen = append(seen, orgID) })
+	if len(seen) != 2 || seen[0] != "acme" || seen[1] != "globex" {
+		t.Fatalf("Each: %v", seen)
+	}
+}
+
+func TestTenantRepositories_FindUser(t *testing.T) {
+	tenants := NewTenantRepositories()
+	ctx := context.Background()
+
+	u, err := tenants.For("acme").Create(ctx, mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	for _, ref := range []string{string(u.ID), "ANA@example.com"} {
+		got, ok, err := tenants.FindUser(ctx, "acme", ref)
+		if err != nil || !ok || got.ID != u.ID {
+			t.Fatalf("FindUser(%q): ok=%v err=%v", ref, ok, err)
+		}
+	}
+	for _, ref := range []string{"not-an-id", "bad@", string(u.ID)} {
+		if _, ok, err := tenants.FindUser(ctx, "globex", ref); ok || err != nil {
+			t.Fatalf("FindUser(globex, %q): ok=%v err=%v", ref, ok, err)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"hash/fnv"
 	"sync"
+	"time"
 
 	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
 	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
@@ -13,14 +14,27 @@ import (
 var (
 	ErrAlreadyExists = errors.New("user already exists")
 	ErrNotFound      = errors.New("user not found")
+	// ErrVersionConflict indica que o usuário mudou desde a versão lida por quem chama
+	ErrVersionConflict = errors.New("user version conflict")
 )
 
+// UserRepository persiste usuários. Create e Update devolvem o usuário como
+// ficou armazenado, já com as datas de ciclo de vida e a versão preenchidas.
+// Update é um compare-and-swap: só grava se u.Version for a versão atual.
+// Delete é lógico: o usuário some de GetByID, GetByEmail e List (salvo
+// ListQuery.IncludeDeleted), mas o email continua reservado até o Purge.
+// RecordLogin grava só a data do login, sem conferir nem mudar a versão.
 type UserRepository interface {
-	Create(ctx context.Context, u domain.User) error
+	Create(ctx context.Context, u domain.User) (domain.User, error)
+	GetByID(ctx context.Context, id vo.UserID) (domain.User, bool, error)
 	GetByEmail(ctx context.Context, email vo.Email) (domain.User, bool, error)
-	List(ctx context.Context) ([]domain.User, error)
-	Update(ctx context.Context, u domain.User) error
-	Delete(ctx context.Context, email vo.Email) error
+	List(ctx context.Context, q ListQuery) ([]domain.User, error)
+	Update(ctx context.Context, u domain.User) (domain.User, error)
+	RecordLogin(ctx context.Context, id vo.UserID) (domain.User, error)
+	ChangeEmail(ctx context.Context, id vo.UserID, addr vo.Address) (domain.User, error)
+	Delete(ctx context.Context, id vo.UserID) (domain.User, error)
+	Restore(ctx context.Context, id vo.UserID) (domain.User, error)
+	Purge(ctx context.Context, deletedBefore time.Time) (int, error)
 }
 
 // shard representa um fragmento do repositório com seu próprio lock
@@ -29,77 +43,179 @@ type shard struct {
 	data map[string]domain.User
 }
 
-// inMemoryUserRepo implementa repositório em memória com sharding para melhor concorrência
+// indexShard guarda o índice secundário email -> ID com seu próprio lock
+type indexShard struct {
+	mu  sync.RWMutex
+	ids map[string]vo.UserID
+}
+
+// inMemoryUserRepo implementa repositório em memória com sharding para melhor concorrência.
+// Os usuários são indexados pelo ID; o email é um índice secundário único.
+// Ordem de locks: indexShard antes de shard (e o outbox por último), para evitar deadlocks.
 type inMemoryUserRepo struct {
-	shards    []*shard
-	shardMask uint32
+	shards      []*shard
+	emailShards []*indexShard
+	shardMask   uint32
+	now         func() time.Time
+	outbox      *Outbox
+	// orgID é a organização atendida por este repositório (ver TenantRepositories)
+	orgID string
+}
+
+// Option configura o repositório em memória
+type Option func(*inMemoryUserRepo)
+
+// WithClock substitui o relógio usado para as datas de ciclo de vida
+func WithClock(now func() time.Time) Option {
+	return func(r *inMemoryUserRepo) {
+		r.now = now
+	}
 }
 
 // NewInMemoryUserRepository cria um repositório em memória otimizado com sharding
-func NewInMemoryUserRepository() UserRepository {
+func NewInMemoryUserRepository(opts ...Option) UserRepository {
 	// Usar menos shards para debugging
 	numShards := 4
 	shards := make([]*shard, numShards)
+	emailShards := make([]*indexShard, numShards)
 
 	for i := 0; i < numShards; i++ {
 		shards[i] = &shard{
 			data: make(map[string]domain.User),
 		}
+		emailShards[i] = &indexShard{
+			ids: make(map[string]vo.UserID),
+		}
 	}
 
-	return &inMemoryUserRepo{
-		shards:    shards,
-		shardMask: uint32(numShards - 1), // Para numShards = 4, mask = 3
+	r := &inMemoryUserRepo{
+		shards:      shards,
+		emailShards: emailShards,
+		shardMask:   uint32(numShards - 1), // Para numShards = 4, mask = 3
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	return r
 }
 
-// getShard retorna o shard apropriado para uma chave
-func (r *inMemoryUserRepo) getShard(key string) *shard {
+// timestamp devolve o horário atual em UTC, truncado para o que o RFC 3339 representa
+func (r *inMemoryUserRepo) timestamp() time.Time {
+	return r.now().UTC().Truncate(time.Second)
+}
+
+func (r *inMemoryUserRepo) shardIndex(key string) uint32 {
 	h := fnv.New32a()
 	h.Write([]byte(key))
-	return r.shards[h.Sum32()&r.shardMask]
+	return h.Sum32() & r.shardMask
+}
+
+// getShard retorna o shard de dados apropriado para um ID
+func (r *inMemoryUserRepo) getShard(id vo.UserID) *shard {
+	return r.shards[r.shardIndex(string(id))]
 }
 
-func (r *inMemoryUserRepo) Create(ctx context.Context, u domain.User) error {
+// getEmailShard retorna o shard do índice apropriado para um email
+func (r *inMemoryUserRepo) getEmailShard(email vo.Email) *indexShard {
+	return r.emailShards[r.shardIndex(string(email))]
+}
+
+func (r *inMemoryUserRepo) Create(ctx context.Context, u domain.User) (domain.User, error) {
 	select {
 	case <-ctx.Done():
-		return ctx.Err()
+		return domain.User{}, ctx.Err()
 	default:
 	}
 
-	key := string(u.Email)
-	shard := r.getShard(key)
+	idx := r.getEmailShard(u.Email)
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
 
+	if _, ok := idx.ids[string(u.Email)]; ok {
+		return domain.User{}, ErrAlreadyExists
+	}
+
+	shard := r.getShard(u.ID)
 	shard.mu.Lock()
 	defer shard.mu.Unlock()
 
-	if _, ok := shard.data[key]; ok {
-		return ErrAlreadyExists
+	if _, ok := shard.data[string(u.ID)]; ok {
+		return domain.User{}, ErrAlreadyExists
+	}
+
+	now := r.timestamp()
+	if r.orgID != "" {
+		u.OrgID = r.orgID
+	}
+	u.Version = 1
+	u.CreatedAt = now
+	u.UpdatedAt = now
+	u.LastLoginAt = nil
+	u.DeactivatedAt = nil
+	// Contas pendentes de verificação nunca estiveram ativas, então não foram desativadas
+	if !u.Active && u.EffectiveStatus() != domain.StatusPendingVerification {
+		u.DeactivatedAt = &now
 	}
 
-	// Cópia defensiva
-	shard.data[key] = u
-	return nil
+	// Cópia defensiva; os eventos seguem com o usuário devolvido e para o outbox
+	r.persist(shard, u)
+	idx.ids[string(u.Email)] = u.ID
+	return u, nil
 }
 
-func (r *inMemoryUserRepo) GetByEmail(ctx context.Context, email vo.Email) (domain.User, bool, error) {
+func (r *inMemoryUserRepo) GetByID(ctx context.Context, id vo.UserID) (domain.User, bool, error) {
+	u, ok, err := r.getStored(ctx, id)
+	if err != nil || !ok || u.IsDeleted() {
+		return domain.User{}, false, err
+	}
+	return u, true, nil
+}
+
+// getStored busca o usuário pelo ID incluindo os excluídos logicamente
+func (r *inMemoryUserRepo) getStored(ctx context.Context, id vo.UserID) (domain.User, bool, error) {
 	select {
 	case <-ctx.Done():
 		return domain.User{}, false, ctx.Err()
 	default:
 	}
 
-	key := string(email)
-	shard := r.getShard(key)
+	shard := r.getShard(id)
 
 	shard.mu.RLock()
 	defer shard.mu.RUnlock()
 
-	u, ok := shard.data[key]
+	u, ok := shard.data[string(id)]
 	return u, ok, nil
 }
 
-func (r *inMemoryUserRepo) List(ctx context.Context) ([]domain.User, error) {
+func (r *inMemoryUserRepo) GetByEmail(ctx context.Context, email vo.Email) (domain.User, bool, error) {
+	select {
+	case <-ctx.Done():
+		return domain.User{}, false, ctx.Err()
+	default:
+	}
+
+	idx := r.getEmailShard(email)
+	idx.mu.RLock()
+	id, ok := idx.ids[string(email)]
+	idx.mu.RUnlock()
+	if !ok {
+		return domain.User{}, false, nil
+	}
+
+	u, ok, err := r.GetByID(ctx, id)
+	if err != nil || !ok {
+		return domain.User{}, false, err
+	}
+	// O índice pode ter mudado entre as duas leituras
+	if u.Email != email {
+		return domain.User{}, false, nil
+	}
+	return u, true, nil
+}
+
+func (r *inMemoryUserRepo) List(ctx context.Context, q ListQuery) ([]domain.User, error) {
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
@@ -121,52 +237,266 @@ func (r *inMemoryUserRepo) List(ctx context.Context) ([]domain.User, error) {
 	for _, shard := range r.shards {
 		shard.mu.RLock()
 		for _, u := range shard.data {
-			result = append(result, u)
+			if q.Matches(u) {
+				result = append(result, u)
+			}
 		}
 		shard.mu.RUnlock()
 	}
 
+	q.Sort(result)
 	return result, nil
 }
 
-func (r *inMemoryUserRepo) Update(ctx context.Context, u domain.User) error {
+func (r *inMemoryUserRepo) Update(ctx context.Context, u domain.User) (domain.User, error) {
+	select {
+	case <-ctx.Done():
+		return domain.User{}, ctx.Err()
+	default:
+	}
+
+	shard := r.getShard(u.ID)
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	current, ok := shard.data[string(u.ID)]
+	if !ok || current.IsDeleted() {
+		return domain.User{}, ErrNotFound
+	}
+	if u.Version != current.Version {
+		return domain.User{}, ErrVersionConflict
+	}
+	u.Version = current.Version + 1
+
+	// O email é chave do índice secundário e só muda via ChangeEmail
+	u.OrgID = current.OrgID
+	u.Email = current.Email
+	u.DisplayEmail = current.DisplayEmail
+	u.EmailHistory = current.EmailHistory
+
+	// Datas de ciclo de vida são do repositório, não de quem chama
+	now := r.timestamp()
+	u.CreatedAt = current.CreatedAt
+	u.UpdatedAt = now
+	u.LastLoginAt = current.LastLoginAt
+	u.DeletedAt = nil
+	switch {
+	case u.Active:
+		u.DeactivatedAt = nil
+	case current.Active:
+		u.DeactivatedAt = &now
+	default:
+		u.DeactivatedAt = current.DeactivatedAt
+	}
+
+	r.persist(shard, u)
+	return u, nil
+}
+
+// RecordLogin marca o login do usuário. A data do login não é uma alteração
+// do cadastro: não conflita com logins concorrentes nem invalida o ETag de
+// quem está editando o usuário. Os demais campos ficam como estão armazenados.
+func (r *inMemoryUserRepo) RecordLogin(ctx context.Context, id vo.UserID) (domain.User, error) {
+	select {
+	case <-ctx.Done():
+		return domain.User{}, ctx.Err()
+	default:
+	}
+
+	shard := r.getShard(id)
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	current, ok := shard.data[string(id)]
+	if !ok || current.IsDeleted() {
+		return domain.User{}, ErrNotFound
+	}
+
+	now := r.timestamp()
+	current.LastLoginAt = &now
+	r.persist(shard, current)
+	return current, nil
+}
+
+// ChangeEmail move o usuário para um novo email de forma atômica. Os shards do
+// índice do email antigo e do novo (que podem ser diferentes) são travados em
+// ordem crescente de posição, junto com o shard de dados, durante a troca.
+// Trocar só a forma de exibição (mesmo email canônico) também é permitido.
+func (r *inMemoryUserRepo) ChangeEmail(ctx context.Context, id vo.UserID, addr vo.Address) (domain.User, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return domain.User{}, ctx.Err()
+		default:
+		}
+
+		current, ok, err := r.GetByID(ctx, id)
+		if err != nil {
+			return domain.User{}, err
+		}
+		if !ok {
+			return domain.User{}, ErrNotFound
+		}
+		if current.Email == addr.Email && current.DisplayEmail == addr.Display {
+			return current, nil
+		}
+
+		u, retry, err := r.swapEmail(id, current.Email, addr)
+		if retry {
+			// O email mudou entre a leitura e o lock: tentar de novo
+			continue
+		}
+		return u, err
+	}
+}
+
+func (r *inMemoryUserRepo) swapEmail(id vo.UserID, oldEmail vo.Email, addr vo.Address) (domain.User, bool, error) {
+	newEmail := addr.Email
+	oldPos, newPos := r.shardIndex(string(oldEmail)), r.shardIndex(string(newEmail))
+	first, second := r.emailShards[oldPos], r.emailShards[newPos]
+	if newPos < oldPos {
+		first, second = second, first
+	}
+
+	first.mu.Lock()
+	defer first.mu.Unlock()
+	if second != first {
+		second.mu.Lock()
+		defer second.mu.Unlock()
+	}
+
+	shard := r.getShard(id)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	u, ok := shard.data[string(id)]
+	if !ok || u.IsDeleted() {
+		return domain.User{}, false, ErrNotFound
+	}
+	if u.Email != oldEmail {
+		return domain.User{}, true, nil
+	}
+
+	oldIdx, newIdx := r.getEmailShard(oldEmail), r.getEmailShard(newEmail)
+	if owner, taken := newIdx.ids[string(newEmail)]; taken && owner != id {
+		return domain.User{}, false, ErrAlreadyExists
+	}
+
+	now := r.timestamp()
+	u.ChangeEmail(addr, now)
+	u.UpdatedAt = now
+	u.Version++
+	r.persist(shard, u)
+	delete(oldIdx.ids, string(oldEmail))
+	newIdx.ids[string(newEmail)] = id
+	return u, false, nil
+}
+
+// Delete marca o usuário como excluído; o registro só some de fato no Purge
+func (r *inMemoryUserRepo) Delete(ctx context.Context, id vo.UserID) (domain.User, error) {
 	select {
 	case <-ctx.Done():
-		return ctx.Err()
+		return domain.User{}, ctx.Err()
 	default:
 	}
 
-	key := string(u.Email)
-	shard := r.getShard(key)
+	shard := r.getShard(id)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	u, ok := shard.data[string(id)]
+	if !ok || u.IsDeleted() {
+		return domain.User{}, ErrNotFound
+	}
+
+	now := r.timestamp()
+	u.MarkDeleted(now)
+	u.UpdatedAt = now
+	u.Version++
+	r.persist(shard, u)
+	return u, nil
+}
+
+// Restore desfaz a exclusão lógica. Restaurar um usuário ativo não tem efeito.
+func (r *inMemoryUserRepo) Restore(ctx context.Context, id vo.UserID) (domain.User, error) {
+	select {
+	case <-ctx.Done():
+		return domain.User{}, ctx.Err()
+	default:
+	}
 
+	shard := r.getShard(id)
 	shard.mu.Lock()
 	defer shard.mu.Unlock()
 
-	if _, ok := shard.data[key]; !ok {
-		return ErrNotFound
+	u, ok := shard.data[string(id)]
+	if !ok {
+		return domain.User{}, ErrNotFound
+	}
+	if !u.IsDeleted() {
+		return u, nil
 	}
 
-	shard.data[key] = u
-	return nil
+	now := r.timestamp()
+	u.Restore(now)
+	u.UpdatedAt = now
+	u.Version++
+	r.persist(shard, u)
+	return u, nil
 }
 
-func (r *inMemoryUserRepo) Delete(ctx context.Context, email vo.Email) error {
+// Purge remove definitivamente os usuários excluídos antes de deletedBefore,
+// liberando seus emails. Devolve quantos registros foram removidos.
+func (r *inMemoryUserRepo) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
 	select {
 	case <-ctx.Done():
-		return ctx.Err()
+		return 0, ctx.Err()
 	default:
 	}
 
-	key := string(email)
-	shard := r.getShard(key)
+	// Coletar candidatos com lock de leitura; a remoção confere de novo sob lock
+	var candidates []domain.User
+	for _, shard := range r.shards {
+		shard.mu.RLock()
+		for _, u := range shard.data {
+			if u.IsDeleted() && u.DeletedAt.Before(deletedBefore) {
+				candidates = append(candidates, u)
+			}
+		}
+		shard.mu.RUnlock()
+	}
+
+	purged := 0
+	for _, c := range candidates {
+		if err := ctx.Err(); err != nil {
+			return purged, err
+		}
+		if r.purgeOne(c.ID, c.Email, deletedBefore) {
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (r *inMemoryUserRepo) purgeOne(id vo.UserID, email vo.Email, deletedBefore time.Time) bool {
+	idx := r.getEmailShard(email)
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
 
+	shard := r.getShard(id)
 	shard.mu.Lock()
 	defer shard.mu.Unlock()
 
-	if _, ok := shard.data[key]; !ok {
-		return ErrNotFound
+	u, ok := shard.data[string(id)]
+	if !ok || u.Email != email || !u.IsDeleted() || !u.DeletedAt.Before(deletedBefore) {
+		return false
 	}
 
-	delete(shard.data, key)
-	return nil
+	delete(shard.data, string(id))
+	if idx.ids[string(email)] == id {
+		delete(idx.ids, string(email))
+	}
+	return true
 }
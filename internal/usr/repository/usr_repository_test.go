@@ -5,6 +5,7 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
 	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain/vo"
@@ -20,6 +21,15 @@ func mustEmail(t *testing.T, s string) vo.Email {
 	return e
 }
 
+func mustAddr(t *testing.T, s string) vo.Address {
+	t.Helper()
+	a, err := vo.ParseEmail(s)
+	if err != nil {
+		t.Fatalf("invalid email %q: %v", s, err)
+	}
+	return a
+}
+
 func mustUser(t *testing.T, name, email string, active bool, ut domain.UserType) domain.User {
 	t.Helper()
 	u, err := domain.NewUser(name, email, "secret123", active, ut)
@@ -35,7 +45,7 @@ func TestCreateAndGetByEmail(t *testing.T) {
 
 	u := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
 
-	if err := repo.Create(context.Background(), u); err != nil {
+	if _, err := repo.Create(context.Background(), u); err != nil {
 		t.Fatalf("Create: %v", err)
 	}
 
@@ -65,7 +75,7 @@ func TestCreate_DuplicateConcurrent(t *testing.T) {
 	for i := 0; i < N; i++ {
 		go func() {
 			defer wg.Done()
-			err := repo.Create(context.Background(), u)
+			_, err := repo.Create(context.Background(), u)
 			switch err {
 			case nil:
 				atomic.AddInt64(&successes, 1)
@@ -93,6 +103,241 @@ func TestCreate_DuplicateConcurrent(t *testing.T) {
 	}
 }
 
+func TestGetByID_ReturnsSameUserAsEmailLookup(t *testing.T) {
+	repo := NewInMemoryUserRepository()
+
+	u := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	if _, err := repo.Create(context.Background(), u); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	got, ok, err := repo.GetByID(context.Background(), u.ID)
+	if err != nil || !ok {
+		t.Fatalf("GetByID: ok=%v err=%v", ok, err)
+	}
+	if got.ID != u.ID || got.Email != u.Email {
+		t.Fatalf("GetByID: got %+v, want %+v", got, u)
+	}
+
+	if _, ok, _ := repo.GetByID(context.Background(), vo.UserID("0190f0c2-8b5a-7c3d-9e4f-0123456789ab")); ok {
+		t.Fatalf("GetByID: expected ok=false for unknown id")
+	}
+}
+
+func TestCreate_EmailIsUniqueAcrossIDs(t *testing.T) {
+	repo := NewInMemoryUserRepository()
+
+	u1 := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	u2 := mustUser(t, "Outra Ana", "ana@example.com", true, domain.UserTypeUser)
+	if u1.ID == u2.ID {
+		t.Fatalf("expected distinct ids")
+	}
+
+	if _, err := repo.Create(context.Background(), u1); err != nil {
+		t.Fatalf("Create u1: %v", err)
+	}
+	if _, err := repo.Create(context.Background(), u2); err != ErrAlreadyExists {
+		t.Fatalf("Create u2: got %v, want %v", err, ErrAlreadyExists)
+	}
+}
+
+func TestCreate_EmailUniquenessIgnoresCase(t *testing.T) {
+	repo := NewInMemoryUserRepository()
+
+	if _, err := repo.Create(context.Background(), mustUser(t, "Ana", "Ana@Example.com", true, domain.UserTypeUser)); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := repo.Create(context.Background(), mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)); err != ErrAlreadyExists {
+		t.Fatalf("Create lowercase duplicate: got %v, want %v", err, ErrAlreadyExists)
+	}
+
+	got, ok, err := repo.GetByEmail(context.Background(), mustEmail(t, "ANA@EXAMPLE.COM"))
+	if err != nil || !ok {
+		t.Fatalf("GetByEmail uppercase: ok=%v err=%v", ok, err)
+	}
+	if got.DisplayEmail != "Ana@Example.com" {
+		t.Fatalf("DisplayEmail: got %q, want %q", got.DisplayEmail, "Ana@Example.com")
+	}
+
+	// Mudar só a forma de exibição não colide com o próprio usuário
+	moved, err := repo.ChangeEmail(context.Background(), got.ID, mustAddr(t, "ana@example.com"))
+	if err != nil {
+		t.Fatalf("ChangeEmail display only: %v", err)
+	}
+	if moved.DisplayEmail != "ana@example.com" || len(moved.EmailHistory) != 0 {
+		t.Fatalf("display-only change: display=%q history=%v", moved.DisplayEmail, moved.EmailHistory)
+	}
+}
+
+func TestDelete_KeepsEmailReservedUntilPurge(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	repo := NewInMemoryUserRepository(WithClock(clock.Now))
+
+	u1 := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	if _, err := repo.Create(context.Background(), u1); err != nil {
+		t.Fatalf("Create u1: %v", err)
+	}
+	if _, err := repo.Delete(context.Background(), u1.ID); err != nil {
+		t.Fatalf("Delete u1: %v", err)
+	}
+
+	u2 := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	if _, err := repo.Create(context.Background(), u2); err != ErrAlreadyExists {
+		t.Fatalf("Create u2 before purge: got %v, want %v", err, ErrAlreadyExists)
+	}
+
+	// Ainda dentro da retenção: nada é removido
+	if n, err := repo.Purge(context.Background(), clock.Now()); err != nil || n != 0 {
+		t.Fatalf("Purge at deletion time: n=%d err=%v", n, err)
+	}
+
+	clock.Advance(time.Hour)
+	if n, err := repo.Purge(context.Background(), clock.Now()); err != nil || n != 1 {
+		t.Fatalf("Purge: n=%d err=%v, want 1", n, err)
+	}
+	if _, err := repo.Restore(context.Background(), u1.ID); err != ErrNotFound {
+		t.Fatalf("Restore after purge: got %v, want %v", err, ErrNotFound)
+	}
+
+	if _, err := repo.Create(context.Background(), u2); err != nil {
+		t.Fatalf("Create u2 after purge: %v", err)
+	}
+	got, ok, err := repo.GetByEmail(context.Background(), mustEmail(t, "ana@example.com"))
+	if err != nil || !ok || got.ID != u2.ID {
+		t.Fatalf("GetByEmail: got id=%q ok=%v err=%v, want id=%q", got.ID, ok, err, u2.ID)
+	}
+}
+
+func TestRepository_ReturnsEventsButDoesNotStoreThem(t *testing.T) {
+	repo := NewInMemoryUserRepository()
+	ctx := context.Background()
+
+	created, err := repo.Create(ctx, mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if evts := created.PullEvents(); len(evts) != 1 || evts[0].EventName() != domain.EventUserCreated {
+		t.Fatalf("Create events: %v", evts)
+	}
+
+	stored, _, _ := repo.GetByID(ctx, created.ID)
+	if evts := stored.PullEvents(); len(evts) != 0 {
+		t.Fatalf("stored user should not carry pending events, got %d", len(evts))
+	}
+
+	moved, err := repo.ChangeEmail(ctx, created.ID, mustAddr(t, "ana.paula@example.com"))
+	if err != nil {
+		t.Fatalf("ChangeEmail: %v", err)
+	}
+	evts := moved.PullEvents()
+	if len(evts) != 1 || evts[0].EventName() != domain.EventUserEmailChanged {
+		t.Fatalf("ChangeEmail events: %v", evts)
+	}
+
+	deleted, err := repo.Delete(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	evts = deleted.PullEvents()
+	if len(evts) != 1 || evts[0].EventName() != domain.EventUserDeleted || !evts[0].OccurredAt().Equal(*deleted.DeletedAt) {
+		t.Fatalf("Delete events: %v", evts)
+	}
+
+	restored, err := repo.Restore(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if evts := restored.PullEvents(); len(evts) != 1 || evts[0].EventName() != domain.EventUserRestored {
+		t.Fatalf("Restore events: %v", evts)
+	}
+}
+
+func TestSoftDelete_HiddenByDefault_AndRestore(t *testing.T) {
+	repo := NewInMemoryUserRepository()
+
+	ana := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	bob := mustUser(t, "Bob", "bob@example.com", true, domain.UserTypeUser)
+	for _, u := range []domain.User{ana, bob} {
+		if _, err := repo.Create(context.Background(), u); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	if _, err := repo.Delete(context.Background(), ana.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if _, ok, _ := repo.GetByID(context.Background(), ana.ID); ok {
+		t.Fatalf("GetByID should hide deleted users")
+	}
+	visible, _ := repo.List(context.Background(), ListQuery{})
+	if len(visible) != 1 || visible[0].ID != bob.ID {
+		t.Fatalf("List should hide deleted users, got %d", len(visible))
+	}
+	all, _ := repo.List(context.Background(), ListQuery{IncludeDeleted: true})
+	if len(all) != 2 {
+		t.Fatalf("List IncludeDeleted: got %d, want 2", len(all))
+	}
+	byEmail, _ := repo.List(context.Background(), ListQuery{IncludeDeleted: true, Email: ana.Email})
+	if len(byEmail) != 1 || byEmail[0].ID != ana.ID || byEmail[0].DeletedAt == nil {
+		t.Fatalf("List by email with deleted: %+v", byEmail)
+	}
+
+	if _, err := repo.Update(context.Background(), byEmail[0]); err != ErrNotFound {
+		t.Fatalf("Update deleted: got %v, want %v", err, ErrNotFound)
+	}
+	if _, err := repo.ChangeEmail(context.Background(), ana.ID, mustAddr(t, "x@example.com")); err != ErrNotFound {
+		t.Fatalf("ChangeEmail deleted: got %v, want %v", err, ErrNotFound)
+	}
+
+	restored, err := repo.Restore(context.Background(), ana.ID)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if restored.DeletedAt != nil {
+		t.Fatalf("Restore should clear DeletedAt")
+	}
+	if _, ok, _ := repo.GetByEmail(context.Background(), ana.Email); !ok {
+		t.Fatalf("restored user should be visible again")
+	}
+
+	// Restaurar de novo é idempotente
+	again, err := repo.Restore(context.Background(), ana.ID)
+	if err != nil || again.Version != restored.Version {
+		t.Fatalf("Restore twice: version %d -> %d err=%v", restored.Version, again.Version, err)
+	}
+}
+
+func TestPurger_UsesRetentionWindow(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	tenants := NewTenantRepositories(WithClock(clock.Now))
+	repo := tenants.For("acme")
+
+	u := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	if _, err := repo.Create(context.Background(), u); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := repo.Delete(context.Background(), u.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	reported := map[string]int{}
+	p := NewPurger(tenants, 30*24*time.Hour, time.Hour, func(org string, n int) { reported[org] += n })
+	p.now = clock.Now
+
+	clock.Advance(29 * 24 * time.Hour)
+	if n, err := p.PurgeOnce(context.Background()); err != nil || n != 0 {
+		t.Fatalf("inside retention: n=%d err=%v", n, err)
+	}
+
+	clock.Advance(2 * 24 * time.Hour)
+	if n, err := p.PurgeOnce(context.Background()); err != nil || n != 1 {
+		t.Fatalf("after retention: n=%d err=%v", n, err)
+	}
+	if reported["acme"] != 1 || len(reported) != 1 {
+		t.Fatalf("onPurge: got %v, want acme=1", reported)
+	}
+}
+
 func TestGetByEmail_NotFound(t *testing.T) {
 	repo := NewInMemoryUserRepository()
 
@@ -111,14 +356,14 @@ func TestList_ReturnsCopies(t *testing.T) {
 	u1 := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
 	u2 := mustUser(t, "Bob", "bob@example.com", false, domain.UserTypeAdmin)
 
-	if err := repo.Create(context.Background(), u1); err != nil {
+	if _, err := repo.Create(context.Background(), u1); err != nil {
 		t.Fatalf("Create u1: %v", err)
 	}
-	if err := repo.Create(context.Background(), u2); err != nil {
+	if _, err := repo.Create(context.Background(), u2); err != nil {
 		t.Fatalf("Create u2: %v", err)
 	}
 
-	list, err := repo.List(context.Background())
+	list, err := repo.List(context.Background(), ListQuery{})
 	if err != nil {
 		t.Fatalf("List: %v", err)
 	}
@@ -140,12 +385,14 @@ func TestUpdate_Success_And_NotFound(t *testing.T) {
 	repo := NewInMemoryUserRepository()
 
 	u := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
-	if err := repo.Create(context.Background(), u); err != nil {
+	if _, err := repo.Create(context.Background(), u); err != nil {
 		t.Fatalf("Create: %v", err)
 	}
 
 	updated := mustUser(t, "Ana Paula", "ana@example.com", false, domain.UserTypeAdmin)
-	if err := repo.Update(context.Background(), updated); err != nil {
+	updated.ID = u.ID
+	updated.Version = 1
+	if _, err := repo.Update(context.Background(), updated); err != nil {
 		t.Fatalf("Update existing: %v", err)
 	}
 
@@ -158,7 +405,7 @@ func TestUpdate_Success_And_NotFound(t *testing.T) {
 	}
 
 	missing := mustUser(t, "Carlos", "carlos@example.com", true, domain.UserTypeUser)
-	if err := repo.Update(context.Background(), missing); err != ErrNotFound {
+	if _, err := repo.Update(context.Background(), missing); err != ErrNotFound {
 		t.Fatalf("Update missing: got %v, want %v", err, ErrNotFound)
 	}
 }
@@ -167,11 +414,11 @@ func TestDelete_Success_And_NotFound(t *testing.T) {
 	repo := NewInMemoryUserRepository()
 
 	u := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
-	if err := repo.Create(context.Background(), u); err != nil {
+	if _, err := repo.Create(context.Background(), u); err != nil {
 		t.Fatalf("Create: %v", err)
 	}
 
-	if err := repo.Delete(context.Background(), mustEmail(t, "ana@example.com")); err != nil {
+	if _, err := repo.Delete(context.Background(), u.ID); err != nil {
 		t.Fatalf("Delete existing: %v", err)
 	}
 
@@ -183,18 +430,405 @@ func TestDelete_Success_And_NotFound(t *testing.T) {
 		t.Fatalf("GetByEmail after delete: expected ok=false")
 	}
 
-	if err := repo.Delete(context.Background(), mustEmail(t, "ana@example.com")); err != ErrNotFound {
+	if _, err := repo.Delete(context.Background(), u.ID); err != ErrNotFound {
 		t.Fatalf("Delete missing: got %v, want %v", err, ErrNotFound)
 	}
 }
 
+func TestChangeEmail_MovesIndexAndRecordsHistory(t *testing.T) {
+	repo := NewInMemoryUserRepository()
+
+	u := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	if _, err := repo.Create(context.Background(), u); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// Endereços suficientes para garantir trocas entre shards diferentes
+	emails := []string{"ana.paula@example.com", "ana1@example.com", "ana2@example.com", "ana3@example.com", "ana4@example.com"}
+	previous := "ana@example.com"
+	for i, e := range emails {
+		got, err := repo.ChangeEmail(context.Background(), u.ID, mustAddr(t, e))
+		if err != nil {
+			t.Fatalf("ChangeEmail(%q): %v", e, err)
+		}
+		if string(got.Email) != e {
+			t.Fatalf("Email: got %q, want %q", got.Email, e)
+		}
+		if len(got.EmailHistory) != i+1 || string(got.EmailHistory[i].Previous) != previous {
+			t.Fatalf("EmailHistory: got %+v, want last previous %q", got.EmailHistory, previous)
+		}
+
+		if _, ok, _ := repo.GetByEmail(context.Background(), mustEmail(t, previous)); ok {
+			t.Fatalf("old email %q should no longer resolve", previous)
+		}
+		byEmail, ok, err := repo.GetByEmail(context.Background(), mustEmail(t, e))
+		if err != nil || !ok || byEmail.ID != u.ID {
+			t.Fatalf("GetByEmail(%q): id=%q ok=%v err=%v", e, byEmail.ID, ok, err)
+		}
+		previous = e
+	}
+
+	// O endereço antigo fica livre para outro usuário
+	other := mustUser(t, "Outra Ana", "ana@example.com", true, domain.UserTypeUser)
+	if _, err := repo.Create(context.Background(), other); err != nil {
+		t.Fatalf("Create with released email: %v", err)
+	}
+}
+
+func TestChangeEmail_CollisionAndNotFound(t *testing.T) {
+	repo := NewInMemoryUserRepository()
+
+	ana := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	bob := mustUser(t, "Bob", "bob@example.com", true, domain.UserTypeUser)
+	for _, u := range []domain.User{ana, bob} {
+		if _, err := repo.Create(context.Background(), u); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	if _, err := repo.ChangeEmail(context.Background(), ana.ID, mustAddr(t, "bob@example.com")); err != ErrAlreadyExists {
+		t.Fatalf("collision: got %v, want %v", err, ErrAlreadyExists)
+	}
+	got, ok, err := repo.GetByEmail(context.Background(), mustEmail(t, "ana@example.com"))
+	if err != nil || !ok || got.ID != ana.ID || len(got.EmailHistory) != 0 {
+		t.Fatalf("user changed after rejected collision: %+v ok=%v err=%v", got, ok, err)
+	}
+
+	missing := mustUser(t, "Carlos", "carlos@example.com", true, domain.UserTypeUser)
+	if _, err := repo.ChangeEmail(context.Background(), missing.ID, mustAddr(t, "c@example.com")); err != ErrNotFound {
+		t.Fatalf("missing: got %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestChangeEmail_ConcurrentSwapsKeepIndexConsistent(t *testing.T) {
+	repo := NewInMemoryUserRepository()
+
+	ana := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	bob := mustUser(t, "Bob", "bob@example.com", true, domain.UserTypeUser)
+	for _, u := range []domain.User{ana, bob} {
+		if _, err := repo.Create(context.Background(), u); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	// Os dois disputam o mesmo endereço: exatamente um deve vencer
+	const target = "shared@example.com"
+	var wg sync.WaitGroup
+	var successes int64
+	for _, id := range []vo.UserID{ana.ID, bob.ID} {
+		wg.Add(1)
+		go func(id vo.UserID) {
+			defer wg.Done()
+			_, err := repo.ChangeEmail(context.Background(), id, mustAddr(t, target))
+			switch err {
+			case nil:
+				atomic.AddInt64(&successes, 1)
+			case ErrAlreadyExists:
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}(id)
+	}
+	wg.Wait()
+
+	if successes != 1 {
+		t.Fatalf("expected exactly 1 success, got %d", successes)
+	}
+	if _, ok, _ := repo.GetByEmail(context.Background(), mustEmail(t, target)); !ok {
+		t.Fatalf("target email should resolve to the winner")
+	}
+}
+
+func TestUpdate_CompareAndSwapOnVersion(t *testing.T) {
+	repo := NewInMemoryUserRepository()
+
+	created, err := repo.Create(context.Background(), mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if created.Version != 1 {
+		t.Fatalf("Create version: got %d, want 1", created.Version)
+	}
+
+	first := created
+	first.Name = "Ana Paula"
+	saved, err := repo.Update(context.Background(), first)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if saved.Version != 2 {
+		t.Fatalf("Update version: got %d, want 2", saved.Version)
+	}
+
+	// Escrita baseada numa leitura antiga deve ser rejeitada
+	stale := created
+	stale.Name = "Stale"
+	if _, err := repo.Update(context.Background(), stale); err != ErrVersionConflict {
+		t.Fatalf("stale update: got %v, want %v", err, ErrVersionConflict)
+	}
+	got, _, _ := repo.GetByID(context.Background(), created.ID)
+	if got.Name != "Ana Paula" || got.Version != 2 {
+		t.Fatalf("stale update leaked: %+v", got)
+	}
+
+	moved, err := repo.ChangeEmail(context.Background(), created.ID, mustAddr(t, "ana.paula@example.com"))
+	if err != nil {
+		t.Fatalf("ChangeEmail: %v", err)
+	}
+	if moved.Version != 3 {
+		t.Fatalf("ChangeEmail version: got %d, want 3", moved.Version)
+	}
+}
+
+func TestRecordLogin_SetsLastLoginAndKeepsOtherFields(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	repo := NewInMemoryUserRepository(WithClock(clock.Now))
+	ctx := context.Background()
+
+	created, err := repo.Create(ctx, mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	clock.Advance(time.Minute)
+	got, err := repo.RecordLogin(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("RecordLogin: %v", err)
+	}
+	if got.LastLoginAt == nil || !got.LastLoginAt.Equal(clock.Now()) {
+		t.Fatalf("LastLoginAt: got %v, want %v", got.LastLoginAt, clock.Now())
+	}
+	// O login não muda a versão: ETags e If-Match de quem edita continuam valendo
+	if got.Name != "Ana" || got.Version != created.Version || !got.UpdatedAt.Equal(created.UpdatedAt) {
+		t.Fatalf("RecordLogin changed other fields: %+v", got)
+	}
+	if _, err := repo.RecordLogin(ctx, created.ID); err != nil {
+		t.Fatalf("second RecordLogin: %v", err)
+	}
+	if _, err := repo.Update(ctx, created); err != nil {
+		t.Fatalf("Update after logins: %v", err)
+	}
+
+	if _, err := repo.Delete(ctx, created.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.RecordLogin(ctx, created.ID); err != ErrNotFound {
+		t.Fatalf("RecordLogin deleted: got %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestUpdate_ConcurrentWritersOnlyOneWins(t *testing.T) {
+	repo := NewInMemoryUserRepository()
+
+	created, err := repo.Create(context.Background(), mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	const N = 16
+	var wg sync.WaitGroup
+	var successes, conflicts int64
+	wg.Add(N)
+	for i := 0; i < N; i++ {
+		go func() {
+			defer wg.Done()
+			u := created
+			u.Name = "Writer"
+			switch _, err := repo.Update(context.Background(), u); err {
+			case nil:
+				atomic.AddInt64(&successes, 1)
+			case ErrVersionConflict:
+				atomic.AddInt64(&conflicts, 1)
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if successes != 1 || conflicts != N-1 {
+		t.Fatalf("expected 1 success and %d conflicts, got %d and %d", N-1, successes, conflicts)
+	}
+}
+
+// fakeClock é um relógio controlado pelos testes
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestLifecycleTimestamps_MaintainedByRepository(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	repo := NewInMemoryUserRepository(WithClock(clock.Now))
+
+	u := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
+	created, err := repo.Create(context.Background(), u)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !created.CreatedAt.Equal(clock.Now()) || !created.UpdatedAt.Equal(clock.Now()) {
+		t.Fatalf("Create timestamps: created=%v updated=%v", created.CreatedAt, created.UpdatedAt)
+	}
+	if created.DeactivatedAt != nil || created.LastLoginAt != nil {
+		t.Fatalf("Create optional timestamps should be nil: %+v", created)
+	}
+
+	clock.Advance(time.Hour)
+	deactivate := created
+	deactivate.Active = false
+	deactivate.CreatedAt = time.Time{} // quem chama não controla as datas
+	updated, err := repo.Update(context.Background(), deactivate)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if !updated.CreatedAt.Equal(created.CreatedAt) {
+		t.Fatalf("CreatedAt changed on update: %v", updated.CreatedAt)
+	}
+	if !updated.UpdatedAt.Equal(clock.Now()) {
+		t.Fatalf("UpdatedAt: got %v, want %v", updated.UpdatedAt, clock.Now())
+	}
+	if updated.DeactivatedAt == nil || !updated.DeactivatedAt.Equal(clock.Now()) {
+		t.Fatalf("DeactivatedAt: got %v, want %v", updated.DeactivatedAt, clock.Now())
+	}
+	deactivatedAt := *updated.DeactivatedAt
+
+	clock.Advance(time.Hour)
+	renamed := updated
+	renamed.Name = "Ana Paula"
+	updated, err = repo.Update(context.Background(), renamed)
+	if err != nil {
+		t.Fatalf("Update rename: %v", err)
+	}
+	if updated.DeactivatedAt == nil || !updated.DeactivatedAt.Equal(deactivatedAt) {
+		t.Fatalf("DeactivatedAt should be kept while inactive: %v", updated.DeactivatedAt)
+	}
+
+	clock.Advance(time.Hour)
+	reactivate := updated
+	reactivate.Active = true
+	updated, err = repo.Update(context.Background(), reactivate)
+	if err != nil {
+		t.Fatalf("Update reactivate: %v", err)
+	}
+	if updated.DeactivatedAt != nil {
+		t.Fatalf("DeactivatedAt should be cleared on reactivation: %v", updated.DeactivatedAt)
+	}
+
+	clock.Advance(time.Hour)
+	moved, err := repo.ChangeEmail(context.Background(), u.ID, mustAddr(t, "ana.paula@example.com"))
+	if err != nil {
+		t.Fatalf("ChangeEmail: %v", err)
+	}
+	if !moved.UpdatedAt.Equal(clock.Now()) || !moved.EmailHistory[0].ChangedAt.Equal(clock.Now()) {
+		t.Fatalf("ChangeEmail timestamps: updated=%v changed=%v", moved.UpdatedAt, moved.EmailHistory[0].ChangedAt)
+	}
+
+	pending, err := domain.NewPendingUser("Bia", "bia@example.com", "secret123", domain.UserTypeUser)
+	if err != nil {
+		t.Fatalf("NewPendingUser: %v", err)
+	}
+	if pending, err = repo.Create(context.Background(), pending); err != nil || pending.DeactivatedAt != nil {
+		t.Fatalf("pending user must not carry DeactivatedAt: %v err=%v", pending.DeactivatedAt, err)
+	}
+}
+
+func TestList_FiltersAndSortsByTimestamps(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: start}
+	repo := NewInMemoryUserRepository(WithClock(clock.Now))
+
+	var ids []vo.UserID
+	for _, e := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		u, err := repo.Create(context.Background(), mustUser(t, "User", e, true, domain.UserTypeUser))
+		if err != nil {
+			t.Fatalf("Create %s: %v", e, err)
+		}
+		ids = append(ids, u.ID)
+		clock.Advance(24 * time.Hour)
+	}
+
+	// a foi criado primeiro, mas é o último atualizado
+	first, _, _ := repo.GetByID(context.Background(), ids[0])
+	if _, err := repo.Update(context.Background(), first); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	order := func(users []domain.User) []vo.UserID {
+		out := make([]vo.UserID, 0, len(users))
+		for _, u := range users {
+			out = append(out, u.ID)
+		}
+		return out
+	}
+	assertOrder := func(name string, got []domain.User, want ...vo.UserID) {
+		t.Helper()
+		g := order(got)
+		if len(g) != len(want) {
+			t.Fatalf("%s: got %v, want %v", name, g, want)
+		}
+		for i := range want {
+			if g[i] != want[i] {
+				t.Fatalf("%s: got %v, want %v", name, g, want)
+			}
+		}
+	}
+
+	all, err := repo.List(context.Background(), ListQuery{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	assertOrder("default", all, ids[0], ids[1], ids[2])
+
+	desc, _ := repo.List(context.Background(), ListQuery{SortBy: SortByCreatedAt, SortDesc: true})
+	assertOrder("createdAt desc", desc, ids[2], ids[1], ids[0])
+
+	byUpdate, _ := repo.List(context.Background(), ListQuery{SortBy: SortByUpdatedAt})
+	assertOrder("updatedAt asc", byUpdate, ids[1], ids[2], ids[0])
+
+	window, _ := repo.List(context.Background(), ListQuery{
+		Created: TimeRange{From: start.Add(time.Hour), To: start.Add(48 * time.Hour)},
+	})
+	assertOrder("created window", window, ids[1])
+
+	neverLogged, _ := repo.List(context.Background(), ListQuery{
+		LastLogin: TimeRange{From: start},
+	})
+	assertOrder("lastLogin filter excludes missing dates", neverLogged)
+
+	second, _, _ := repo.GetByID(context.Background(), ids[1])
+	if err := second.ChangeStatus(domain.StatusSuspended, "", ""); err != nil {
+		t.Fatalf("ChangeStatus: %v", err)
+	}
+	suspended, err := repo.Update(context.Background(), second)
+	if err != nil {
+		t.Fatalf("Update suspended: %v", err)
+	}
+	if suspended.DeactivatedAt == nil {
+		t.Fatalf("suspended user must carry DeactivatedAt")
+	}
+	byStatus, _ := repo.List(context.Background(), ListQuery{Statuses: []domain.Status{domain.StatusSuspended, domain.StatusLocked}})
+	assertOrder("status filter", byStatus, ids[1])
+}
+
 func TestContextCanceled_OnOperations(t *testing.T) {
 	repo := NewInMemoryUserRepository()
 	u := mustUser(t, "Ana", "ana@example.com", true, domain.UserTypeUser)
 
 	ctxC, cancel := context.WithCancel(context.Background())
 	cancel()
-	if err := repo.Create(ctxC, u); err == nil {
+	if _, err := repo.Create(ctxC, u); err == nil {
 		t.Fatalf("Create with canceled context: expected error")
 	}
 
@@ -204,21 +838,45 @@ func TestContextCanceled_OnOperations(t *testing.T) {
 		t.Fatalf("GetByEmail with canceled context: expected error")
 	}
 
+	ctxI, cancelI := context.WithCancel(context.Background())
+	cancelI()
+	if _, _, err := repo.GetByID(ctxI, u.ID); err == nil {
+		t.Fatalf("GetByID with canceled context: expected error")
+	}
+
 	ctxL, cancelL := context.WithCancel(context.Background())
 	cancelL()
-	if _, err := repo.List(ctxL); err == nil {
+	if _, err := repo.List(ctxL, ListQuery{}); err == nil {
 		t.Fatalf("List with canceled context: expected error")
 	}
 
 	ctxU, cancelU := context.WithCancel(context.Background())
 	cancelU()
-	if err := repo.Update(ctxU, u); err == nil {
+	if _, err := repo.Update(ctxU, u); err == nil {
 		t.Fatalf("Update with canceled context: expected error")
 	}
 
+	ctxE, cancelE := context.WithCancel(context.Background())
+	cancelE()
+	if _, err := repo.ChangeEmail(ctxE, u.ID, mustAddr(t, "ana2@example.com")); err == nil {
+		t.Fatalf("ChangeEmail with canceled context: expected error")
+	}
+
+	ctxR, cancelR := context.WithCancel(context.Background())
+	cancelR()
+	if _, err := repo.Restore(ctxR, u.ID); err == nil {
+		t.Fatalf("Restore with canceled context: expected error")
+	}
+
+	ctxP, cancelP := context.WithCancel(context.Background())
+	cancelP()
+	if _, err := repo.Purge(ctxP, time.Now()); err == nil {
+		t.Fatalf("Purge with canceled context: expected error")
+	}
+
 	ctxD, cancelD := context.WithCancel(context.Background())
 	cancelD()
-	if err := repo.Delete(ctxD, mustEmail(t, "ana@example.com")); err == nil {
+	if _, err := repo.Delete(ctxD, u.ID); err == nil {
 		t.Fatalf("Delete with canceled context: expected error")
 	}
 }
@@ -2,16 +2,93 @@ package usr_router
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	rbac "github.com/williamkoller/cloud-architecture-golang/internal/rbac/domain"
 	"github.com/williamkoller/cloud-architecture-golang/internal/usr/handler"
 )
 
-func RegisterUserRoutes(group *gin.RouterGroup, h *handler.UserHandler) {
+// RouteOption configura o registro das rotas de usuário
+type RouteOption func(*routeConfig)
+
+type routeConfig struct {
+	resolver auth.PermissionResolver
+}
+
+// WithPermissionGuards exige a permissão de cada rota (users:read, users:write,
+// users:delete, roles:write). O principal deve ser anexado antes por um
+// autenticador (auth.Authenticate). O próprio usuário lê e altera o seu cadastro
+// sem a permissão; o cadastro (POST /users) é aberto, mas criar um Admin
+// exige roles:write (ver handler.WithPermissionResolver).
+func WithPermissionGuards(resolver auth.PermissionResolver) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.resolver = resolver
+	}
+}
+
+// guard devolve a cadeia de handlers da rota, precedida do guarda quando ativo
+func (cfg routeConfig) guard(perm rbac.Permission, h gin.HandlerFunc) []gin.HandlerFunc {
+	if cfg.resolver == nil {
+		return []gin.HandlerFunc{h}
+	}
+	return []gin.HandlerFunc{auth.RequirePermission(cfg.resolver, perm), h}
+}
+
+// guardSelf é como guard, mas libera o usuário do parâmetro :id
+func (cfg routeConfig) guardSelf(perm rbac.Permission, h gin.HandlerFunc) []gin.HandlerFunc {
+	if cfg.resolver == nil {
+		return []gin.HandlerFunc{h}
+	}
+	return []gin.HandlerFunc{auth.RequirePermissionOrSelf(cfg.resolver, perm, "id"), h}
+}
+
+// RegisterUserRoutes registra as rotas de usuário. O parâmetro :id aceita o ID
+// estável do usuário e, durante a migração, também o email das rotas antigas.
+func RegisterUserRoutes(group *gin.RouterGroup, h *handler.UserHandler, opts ...RouteOption) {
+	var cfg routeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	users := group.Group("/users")
 	{
 		users.POST("", h.CreateUser)
-		users.GET("", h.ListUsers)
-		users.GET("/:email", h.GetUser)
-		users.PATCH("/:email", h.UpdateUser)
-		users.DELETE("/:email", h.DeleteUser)
+		users.GET("", cfg.guard(rbac.PermUsersRead, h.ListUsers)...)
+		users.GET("/lookup", cfg.guard(rbac.PermUsersRead, h.LookupUser)...)
+		// Verificação de email é pública: quem a usa ainda não consegue se autenticar
+		users.POST("/verify", h.VerifyEmail)
+		users.POST("/verify/resend", h.ResendVerification)
+		users.GET("/:id", cfg.guardSelf(rbac.PermUsersRead, h.GetUser)...)
+		users.PATCH("/:id", cfg.guardSelf(rbac.PermUsersWrite, h.UpdateUser)...)
+		users.PUT("/:id/email", cfg.guardSelf(rbac.PermUsersWrite, h.ChangeEmail)...)
+		users.POST("/:id/activate", cfg.guard(rbac.PermUsersWrite, h.ActivateUser)...)
+		users.POST("/:id/suspend", cfg.guard(rbac.PermUsersWrite, h.SuspendUser)...)
+		users.POST("/:id/lock", cfg.guard(rbac.PermUsersWrite, h.LockUser)...)
+		users.POST("/:id/deactivate", cfg.guard(rbac.PermUsersWrite, h.DeactivateUser)...)
+		users.DELETE("/:id", cfg.guard(rbac.PermUsersDelete, h.DeleteUser)...)
+		users.POST("/:id/restore", cfg.guard(rbac.PermUsersDelete, h.RestoreUser)...)
+		users.PUT("/:id/roles/:role", cfg.guard(rbac.PermRolesWrite, h.AssignRole)...)
+		users.DELETE("/:id/roles/:role", cfg.guard(rbac.PermRolesWrite, h.RevokeRole)...)
+	}
+
+	// A própria conta, sem precisar do ID; o handler exige um usuário autenticado
+	me := group.Group("/me")
+	{
+		me.GET("", h.GetMe)
+		me.PATCH("", h.UpdateMe)
+		me.POST("/password", h.ChangeMyPassword)
+		me.DELETE("", h.DeleteMe)
 	}
+
+	// Recuperação de senha, pública como a verificação de email
+	password := group.Group("/password")
+	{
+		password.POST("/forgot", h.ForgotPassword)
+		password.POST("/reset", h.ResetPassword)
+	}
+}
+
+// RegisterOrgUserRoutes registra as mesmas rotas sob /orgs/:org/users. resolveOrg
+// (tenant.Middleware) roda antes dos guardas e fixa a organização da requisição.
+func RegisterOrgUserRoutes(group *gin.RouterGroup, h *handler.UserHandler, resolveOrg gin.HandlerFunc, opts ...RouteOption) {
+	RegisterUserRoutes(group.Group("/orgs/:org", resolveOrg), h, opts...)
 }
@@ -1,10 +1,14 @@
 package usr_router
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/williamkoller/cloud-architecture-golang/internal/auth"
+	"github.com/williamkoller/cloud-architecture-golang/internal/rbac/repository"
 	"github.com/williamkoller/cloud-architecture-golang/internal/usr/handler"
 )
 
@@ -28,9 +32,26 @@ func TestRegisterUserRoutes_RegistersAllExpectedRoutes(t *testing.T) {
 	expected := []exp{
 		{method: "POST", path: "/api/v1/users", wantFn: ".CreateUser"},
 		{method: "GET", path: "/api/v1/users", wantFn: ".ListUsers"},
-		{method: "GET", path: "/api/v1/users/:email", wantFn: ".GetUser"},
-		{method: "PATCH", path: "/api/v1/users/:email", wantFn: ".UpdateUser"},
-		{method: "DELETE", path: "/api/v1/users/:email", wantFn: ".DeleteUser"},
+		{method: "GET", path: "/api/v1/users/lookup", wantFn: ".LookupUser"},
+		{method: "POST", path: "/api/v1/users/verify", wantFn: ".VerifyEmail"},
+		{method: "POST", path: "/api/v1/users/verify/resend", wantFn: ".ResendVerification"},
+		{method: "GET", path: "/api/v1/users/:id", wantFn: ".GetUser"},
+		{method: "PATCH", path: "/api/v1/users/:id", wantFn: ".UpdateUser"},
+		{method: "PUT", path: "/api/v1/users/:id/email", wantFn: ".ChangeEmail"},
+		{method: "POST", path: "/api/v1/users/:id/activate", wantFn: ".ActivateUser"},
+		{method: "POST", path: "/api/v1/users/:id/suspend", wantFn: ".SuspendUser"},
+		{method: "POST", path: "/api/v1/users/:id/lock", wantFn: ".LockUser"},
+		{method: "POST", path: "/api/v1/users/:id/deactivate", wantFn: ".DeactivateUser"},
+		{method: "DELETE", path: "/api/v1/users/:id", wantFn: ".DeleteUser"},
+		{method: "POST", path: "/api/v1/users/:id/restore", wantFn: ".RestoreUser"},
+		{method: "PUT", path: "/api/v1/users/:id/roles/:role", wantFn: ".AssignRole"},
+		{method: "DELETE", path: "/api/v1/users/:id/roles/:role", wantFn: ".RevokeRole"},
+		{method: "GET", path: "/api/v1/me", wantFn: ".GetMe"},
+		{method: "PATCH", path: "/api/v1/me", wantFn: ".UpdateMe"},
+		{method: "POST", path: "/api/v1/me/password", wantFn: ".ChangeMyPassword"},
+		{method: "DELETE", path: "/api/v1/me", wantFn: ".DeleteMe"},
+		{method: "POST", path: "/api/v1/password/forgot", wantFn: ".ForgotPassword"},
+		{method: "POST", path: "/api/v1/password/reset", wantFn: ".ResetPassword"},
 	}
 
 	for _, e := range expected {
@@ -46,6 +67,81 @@ func TestRegisterUserRoutes_RegistersAllExpectedRoutes(t *testing.T) {
 	}
 }
 
+func TestRegisterUserRoutes_WithPermissionGuards(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	roles := repository.NewInMemoryRoleRepository()
+	r := gin.New()
+	api := r.Group("/api/v1")
+	api.Use(func(c *gin.Context) {
+		auth.SetPrincipal(c, auth.Principal{UserID: "u1", Roles: []string{c.GetHeader("X-Test-Role")}})
+		c.Next()
+	})
+	RegisterUserRoutes(api, &handler.UserHandler{}, WithPermissionGuards(roles))
+
+	// Rotas públicas chegam ao handler, que responde 404 sem verificação configurada
+	public := map[string]bool{
+		"/api/v1/users/verify":        true,
+		"/api/v1/users/verify/resend": true,
+		"/api/v1/password/forgot":     true,
+		"/api/v1/password/reset":      true,
+	}
+	// O cadastro é aberto: o corpo vazio chega ao handler e falha na validação
+	signup := "POST /api/v1/users"
+	// A própria conta não tem guarda: o handler exige o usuário (ver me_test.go)
+	self := map[string]bool{
+		"/api/v1/me":          true,
+		"/api/v1/me/password": true,
+	}
+
+	// O papel base não tem permissões: toda outra rota deve ser barrada antes do handler
+	for _, ri := range r.Routes() {
+		if self[ri.Path] {
+			continue
+		}
+		req := httptest.NewRequest(ri.Method, strings.ReplaceAll(strings.ReplaceAll(ri.Path, ":id", "x"), ":role", "y"), nil)
+		req.Header.Set("X-Test-Role", "user")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if ri.Method+" "+ri.Path == signup {
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("%s: got %d, want %d", signup, w.Code, http.StatusBadRequest)
+			}
+			continue
+		}
+		if public[ri.Path] {
+			if w.Code != http.StatusNotFound {
+				t.Fatalf("%s %s: public route got %d, want %d", ri.Method, ri.Path, w.Code, http.StatusNotFound)
+			}
+			continue
+		}
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("%s %s: got %d, want %d", ri.Method, ri.Path, w.Code, http.StatusForbidden)
+		}
+	}
+}
+
+func TestRegisterOrgUserRoutes_PrefixesOrganization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	var resolved bool
+	RegisterOrgUserRoutes(r.Group("/api/v1"), &handler.UserHandler{}, func(c *gin.Context) {
+		resolved = true
+		c.AbortWithStatus(http.StatusTeapot)
+	})
+
+	if _, ok := findRoute(r.Routes(), "GET", "/api/v1/orgs/:org/users/:id"); !ok {
+		t.Fatalf("route not found: GET /api/v1/orgs/:org/users/:id")
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/orgs/acme/users", nil))
+	if !resolved || w.Code != http.StatusTeapot {
+		t.Fatalf("organization middleware did not run first: status %d", w.Code)
+	}
+}
+
 func findRoute(routes []gin.RouteInfo, method, path string) (gin.RouteInfo, bool) {
 	for _, r := range routes {
 		if r.Method == method && r.Path == path {
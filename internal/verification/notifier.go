@@ -0,0 +1,72 @@
+package verification
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Message é o link de verificação a ser entregue ao usuário
+type Message struct {
+	To        string    `json:"to"`
+	UserID    string    `json:"userId"`
+	OrgID     string    `json:"orgId,omitempty"`
+	Link      string    `json:"link"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Notifier entrega o link de verificação (email, SMS, fila); implementações
+// reais entram aqui sem mudar o Service
+type Notifier interface {
+	SendVerification(ctx context.Context, m Message) error
+}
+
+// NotifierFunc adapta uma função para Notifier
+type NotifierFunc func(ctx context.Context, m Message) error
+
+func (f NotifierFunc) SendVerification(ctx context.Context, m Message) error {
+	return f(ctx, m)
+}
+
+// LogNotifier escreve o link no log, para desenvolvimento local
+type LogNotifier struct{}
+
+func (LogNotifier) SendVerification(ctx context.Context, m Message) error {
+	log.Printf("verification email to=%s user=%s org=%s link=%s expires=%s",
+		m.To, m.UserID, m.OrgID, m.Link, m.ExpiresAt.Format(time.RFC3339))
+	return nil
+}
+
+// FileNotifier acrescenta cada mensagem como uma linha JSON no arquivo, que
+// funciona como caixa de saída local para testes manuais
+type FileNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+func (n *FileNotifier) SendVerification(ctx context.Context, m Message) error {
+	line, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
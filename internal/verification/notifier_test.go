@@ -0,0 +1,40 @@
+package verification
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileNotifier_AppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.jsonl")
+	n := NewFileNotifier(path)
+
+	for _, to := range []string{"a@example.com", "b@example.com"} {
+		if err := n.SendVerification(context.Background(), Message{To: to, Link: "https://app/verify?token=x"}); err != nil {
+			t.Fatalf("SendVerification: %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer f.Close()
+
+	var got []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var m Message
+		if err := json.Unmarshal(sc.Bytes(), &m); err != nil {
+			t.Fatalf("line %q: %v", sc.Text(), err)
+		}
+		got = append(got, m.To)
+	}
+	if len(got) != 2 || got[0] != "a@example.com" || got[1] != "b@example.com" {
+		t.Fatalf("messages: %v", got)
+	}
+}
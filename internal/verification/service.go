@@ -0,0 +1,166 @@
+package verification
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Recipient identifica o usuário que deve confirmar o email
+type Recipient struct {
+	UserID string
+	OrgID  string
+	Email  string
+}
+
+// key identifica o usuário entre organizações
+func (r Recipient) key() string {
+	return r.OrgID + "/" + r.UserID
+}
+
+// Service emite, entrega e confere tokens de verificação de email. Cada
+// usuário tem no máximo um token válido: um reenvio substitui o anterior e
+// Consume o inutiliza, então o token é de uso único.
+type Service struct {
+	signer   *Signer
+	notifier Notifier
+	ttl      time.Duration
+	linkBase string
+	now      func() time.Time
+	throttle *Throttle
+
+	mu      sync.Mutex
+	current map[string]Claims // por Recipient.key
+}
+
+// Option configura o Service
+type Option func(*Service)
+
+// WithTTL define a validade dos tokens (padrão 24h)
+func WithTTL(d time.Duration) Option {
+	return func(s *Service) {
+		s.ttl = d
+	}
+}
+
+// WithLinkBase define a URL do link enviado; o token vai no parâmetro token
+func WithLinkBase(base string) Option {
+	return func(s *Service) {
+		s.linkBase = base
+	}
+}
+
+// WithClock substitui o relógio, para testes
+func WithClock(now func() time.Time) Option {
+	return func(s *Service) {
+		s.now = now
+	}
+}
+
+// WithResendLimit define o intervalo mínimo entre reenvios e o teto por hora
+// para um mesmo endereço (padrão 1 minuto e 5 por hora)
+func WithResendLimit(interval time.Duration, perHour int) Option {
+	return func(s *Service) {
+		s.throttle = NewThrottle(interval, perHour, time.Hour)
+	}
+}
+
+func NewService(signer *Signer, notifier Notifier, opts ...Option) *Service {
+	s := &Service{
+		signer:   signer,
+		notifier: notifier,
+		ttl:      24 * time.Hour,
+		now:      time.Now,
+		throttle: NewThrottle(time.Minute, 5, time.Hour),
+		current:  make(map[string]Claims),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Send emite um token novo, que substitui o anterior, e o entrega pelo notifier
+func (s *Service) Send(ctx context.Context, r Recipient) error {
+	id, err := newTokenID()
+	if err != nil {
+		return err
+	}
+	now := s.now().UTC()
+	c := Claims{
+		ID:        id,
+		UserID:    r.UserID,
+		OrgID:     r.OrgID,
+		Email:     r.Email,
+		ExpiresAt: now.Add(s.ttl).Truncate(time.Second),
+	}
+	token, err := s.signer.Sign(c)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	for k, old := range s.current {
+		if !now.Before(old.ExpiresAt) {
+			delete(s.current, k)
+		}
+	}
+	s.current[r.key()] = c
+	s.mu.Unlock()
+
+	return s.notifier.SendVerification(ctx, Message{
+		To:        r.Email,
+		UserID:    r.UserID,
+		OrgID:     r.OrgID,
+		Link:      s.link(token),
+		Token:     token,
+		ExpiresAt: c.ExpiresAt,
+	})
+}
+
+// Verify confere assinatura, validade e se o token ainda é o vigente do usuário
+func (s *Service) Verify(token string) (Claims, error) {
+	c, err := s.signer.Parse(strings.TrimSpace(token))
+	if err != nil {
+		return Claims{}, err
+	}
+	if !s.now().Before(c.ExpiresAt) {
+		return Claims{}, ErrExpiredToken
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cur, ok := s.current[Recipient{UserID: c.UserID, OrgID: c.OrgID}.key()]; !ok || cur.ID != c.ID {
+		return Claims{}, ErrUsedToken
+	}
+	return c, nil
+}
+
+// Consume inutiliza o token, se ele ainda for o vigente
+func (s *Service) Consume(c Claims) {
+	key := Recipient{UserID: c.UserID, OrgID: c.OrgID}.key()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cur, ok := s.current[key]; ok && cur.ID == c.ID {
+		delete(s.current, key)
+	}
+}
+
+// AllowResend aplica o limite de reenvios ao endereço, exista ele ou não, para
+// que a resposta não revele quais emails estão cadastrados
+func (s *Service) AllowResend(orgID, email string) (time.Duration, bool) {
+	return s.throttle.Allow(orgID+"/"+strings.ToLower(email), s.now())
+}
+
+func (s *Service) link(token string) string {
+	if s.linkBase == "" {
+		return token
+	}
+	sep := "?"
+	if strings.Contains(s.linkBase, "?") {
+		sep = "&"
+	}
+	return s.linkBase + sep + "token=" + url.QueryEscape(token)
+}
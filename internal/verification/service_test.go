@@ -0,0 +1,129 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/events"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/repository"
+)
+
+type inbox struct {
+	msgs []Message
+}
+
+func (b *inbox) SendVerification(ctx context.Context, m Message) error {
+	b.msgs = append(b.msgs, m)
+	return nil
+}
+
+func (b *inbox) last(t *testing.T) Message {
+	t.Helper()
+	if len(b.msgs) == 0 {
+		t.Fatalf("no message sent")
+	}
+	return b.msgs[len(b.msgs)-1]
+}
+
+func TestService_TokensAreSingleUseAndExpire(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	box := &inbox{}
+	s := NewService(mustSigner(t), box,
+		WithTTL(time.Hour),
+		WithLinkBase("https://app.example.com/verify"),
+		WithClock(func() time.Time { return now }),
+	)
+	r := Recipient{UserID: "u1", OrgID: "acme", Email: "ana@example.com"}
+
+	if err := s.Send(context.Background(), r); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	msg := box.last(t)
+	link, err := url.Parse(msg.Link)
+	if err != nil || link.Host != "app.example.com" || link.Query().Get("token") != msg.Token {
+		t.Fatalf("link: %q", msg.Link)
+	}
+	if msg.To != r.Email || !msg.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("message: %+v", msg)
+	}
+
+	c, err := s.Verify(msg.Token)
+	if err != nil || c.UserID != "u1" || c.OrgID != "acme" || c.Email != r.Email {
+		t.Fatalf("Verify: %+v err=%v", c, err)
+	}
+	s.Consume(c)
+	if _, err := s.Verify(msg.Token); err != ErrUsedToken {
+		t.Fatalf("reused token: got %v, want %v", err, ErrUsedToken)
+	}
+
+	// Um reenvio substitui o token anterior
+	_ = s.Send(context.Background(), r)
+	first := box.last(t).Token
+	_ = s.Send(context.Background(), r)
+	if _, err := s.Verify(first); err != ErrUsedToken {
+		t.Fatalf("replaced token: got %v, want %v", err, ErrUsedToken)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := s.Verify(box.last(t).Token); err != ErrExpiredToken {
+		t.Fatalf("expired token: got %v, want %v", err, ErrExpiredToken)
+	}
+}
+
+func TestService_AllowResendIsPerAddress(t *testing.T) {
+	s := NewService(mustSigner(t), &inbox{}, WithResendLimit(time.Minute, 5))
+
+	if _, ok := s.AllowResend("acme", "Ana@Example.com"); !ok {
+		t.Fatalf("first resend must be allowed")
+	}
+	if wait, ok := s.AllowResend("acme", "ana@example.com"); ok || wait <= 0 {
+		t.Fatalf("second resend: wait=%s ok=%v", wait, ok)
+	}
+	if _, ok := s.AllowResend("other", "ana@example.com"); !ok {
+		t.Fatalf("other organization must not share the limit")
+	}
+}
+
+func TestSendOnCreated_OnlyPendingUsers(t *testing.T) {
+	box := &inbox{}
+	s := NewService(mustSigner(t), box)
+	d := events.NewDispatcher()
+	d.Subscribe(domain.EventUserCreated, SendOnCreated(s))
+	pub := events.OutboxPublisher(d)
+
+	pending, err := domain.NewPendingUser("Ana", "ana@example.com", "secret123", domain.UserTypeUser)
+	if err != nil {
+		t.Fatalf("NewPendingUser: %v", err)
+	}
+	active, err := domain.NewUser("Bia", "bia@example.com", "secret123", true, domain.UserTypeUser)
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+
+	for _, u := range []domain.User{pending, active} {
+		for _, e := range u.PullEvents() {
+			if err := pub.Publish(context.Background(), repository.OutboxMessage{OrgID: "acme", Event: e}); err != nil {
+				t.Fatalf("Publish: %v", err)
+			}
+		}
+	}
+
+	if len(box.msgs) != 1 || box.msgs[0].To != "ana@example.com" || box.msgs[0].OrgID != "acme" {
+		t.Fatalf("messages: %+v", box.msgs)
+	}
+	if c, err := s.Verify(box.msgs[0].Token); err != nil || c.UserID != string(pending.ID) {
+		t.Fatalf("Verify: %+v err=%v", c, err)
+	}
+
+	failing := SendOnCreated(NewService(mustSigner(t), NotifierFunc(func(context.Context, Message) error {
+		return errors.New("smtp down")
+	})))
+	pending2, _ := domain.NewPendingUser("Caio", "caio@example.com", "secret123", domain.UserTypeUser)
+	if err := failing(context.Background(), pending2.PullEvents()[0]); err == nil {
+		t.Fatalf("notifier errors must be returned so the outbox retries")
+	}
+}
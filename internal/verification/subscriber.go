@@ -0,0 +1,26 @@
+package verification
+
+import (
+	"context"
+
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/domain"
+	"github.com/williamkoller/cloud-architecture-golang/internal/usr/events"
+)
+
+// SendOnCreated é o assinante de domain.EventUserCreated que envia o link aos
+// usuários criados em pending_verification. Uma reentrega do outbox gera um
+// token novo, que substitui o anterior.
+func SendOnCreated(s *Service) events.Subscriber {
+	return func(ctx context.Context, e domain.Event) error {
+		created, ok := e.(domain.UserCreated)
+		if !ok || created.Status != domain.StatusPendingVerification {
+			return nil
+		}
+		orgID, _ := events.OrgID(ctx)
+		return s.Send(ctx, Recipient{
+			UserID: string(created.UserID),
+			OrgID:  orgID,
+			Email:  string(created.Email),
+		})
+	}
+}
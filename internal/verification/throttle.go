@@ -0,0 +1,69 @@
+package verification
+
+import (
+	"sync"
+	"time"
+)
+
+// Throttle limita os reenvios por chave: um intervalo mínimo entre envios e
+// um teto de envios por janela. As chaves vêm de requisições anônimas, então
+// as que já não limitam nada são varridas a cada janela.
+type Throttle struct {
+	mu       sync.Mutex
+	interval time.Duration
+	max      int
+	window   time.Duration
+	sent     map[string][]time.Time
+	swept    time.Time
+}
+
+func NewThrottle(interval time.Duration, max int, window time.Duration) *Throttle {
+	return &Throttle{
+		interval: interval,
+		max:      max,
+		window:   window,
+		sent:     make(map[string][]time.Time),
+	}
+}
+
+// Allow registra um envio para a chave se os limites permitirem; caso
+// contrário devolve quanto tempo falta para o próximo envio
+func (t *Throttle) Allow(key string, now time.Time) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sweep(now)
+
+	// Descartar envios fora da janela; o slice é sempre crescente no tempo
+	recent := t.sent[key]
+	for len(recent) > 0 && !now.Before(recent[0].Add(t.window)) {
+		recent = recent[1:]
+	}
+
+	if n := len(recent); n > 0 {
+		if wait := recent[n-1].Add(t.interval).Sub(now); wait > 0 {
+			return wait, false
+		}
+	}
+	if t.max > 0 && len(recent) >= t.max {
+		return recent[0].Add(t.window).Sub(now), false
+	}
+
+	t.sent[key] = append(recent[:len(recent):len(recent)], now)
+	return 0, true
+}
+
+// sweep remove, no máximo uma vez por janela, as chaves cujo último envio já
+// não conta nem para o intervalo nem para a janela. Chamar com o lock.
+func (t *Throttle) sweep(now time.Time) {
+	if now.Sub(t.swept) < t.window {
+		return
+	}
+	t.swept = now
+
+	keep := max(t.window, t.interval)
+	for key, sent := range t.sent {
+		if len(sent) == 0 || !now.Before(sent[len(sent)-1].Add(keep)) {
+			delete(t.sent, key)
+		}
+	}
+}
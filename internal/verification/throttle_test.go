@@ -0,0 +1,58 @@
+package verification
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestThrottle_IntervalAndWindow(t *testing.T) {
+	th := NewThrottle(time.Minute, 3, time.Hour)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, ok := th.Allow("a", now); !ok {
+		t.Fatalf("first send must be allowed")
+	}
+	if wait, ok := th.Allow("a", now.Add(10*time.Second)); ok || wait != 50*time.Second {
+		t.Fatalf("within interval: wait=%s ok=%v", wait, ok)
+	}
+	if _, ok := th.Allow("b", now.Add(10*time.Second)); !ok {
+		t.Fatalf("keys must be throttled independently")
+	}
+
+	if _, ok := th.Allow("a", now.Add(time.Minute)); !ok {
+		t.Fatalf("second send after interval must be allowed")
+	}
+	if _, ok := th.Allow("a", now.Add(2*time.Minute)); !ok {
+		t.Fatalf("third send must be allowed")
+	}
+	// Teto da janela: o próximo envio só quando o primeiro sair da janela
+	if wait, ok := th.Allow("a", now.Add(10*time.Minute)); ok || wait != 50*time.Minute {
+		t.Fatalf("window cap: wait=%s ok=%v", wait, ok)
+	}
+	if _, ok := th.Allow("a", now.Add(time.Hour)); !ok {
+		t.Fatalf("send after the window must be allowed")
+	}
+}
+
+func TestThrottle_SweepsKeysThatNoLongerLimit(t *testing.T) {
+	th := NewThrottle(time.Minute, 3, time.Hour)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 100; i++ {
+		th.Allow(fmt.Sprintf("acme/random%d@example.com", i), now)
+	}
+	th.Allow("acme/ana@example.com", now.Add(30*time.Minute))
+	if len(th.sent) != 101 {
+		t.Fatalf("keys before the sweep: got %d", len(th.sent))
+	}
+
+	// Passada a janela, só fica a chave que ainda limita e a nova
+	th.Allow("acme/bia@example.com", now.Add(time.Hour+time.Minute))
+	if len(th.sent) != 2 {
+		t.Fatalf("keys after the sweep: got %d, want 2", len(th.sent))
+	}
+	if wait, ok := th.Allow("acme/bia@example.com", now.Add(time.Hour+time.Minute+10*time.Second)); ok || wait != 50*time.Second {
+		t.Fatalf("swept throttle must keep limiting: wait=%s ok=%v", wait, ok)
+	}
+}
@@ -0,0 +1,101 @@
+package verification
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// MinKeySize é o tamanho mínimo da chave HMAC dos tokens
+const MinKeySize = 32
+
+var (
+	ErrKeyTooShort  = errors.New("verification signing key must have at least 32 bytes")
+	ErrInvalidToken = errors.New("invalid verification token")
+	ErrExpiredToken = errors.New("verification token expired")
+	// ErrUsedToken cobre tokens já consumidos e os substituídos por um reenvio
+	ErrUsedToken = errors.New("verification token already used or replaced")
+)
+
+// Claims é o conteúdo assinado do token. O email amarra o token ao endereço
+// para o qual foi enviado: trocar o email invalida os links antigos.
+type Claims struct {
+	ID        string    `json:"jti"`
+	UserID    string    `json:"sub"`
+	OrgID     string    `json:"org,omitempty"`
+	Email     string    `json:"email"`
+	ExpiresAt time.Time `json:"exp"`
+}
+
+// Signer assina e confere tokens no formato base64url(claims).base64url(hmac)
+type Signer struct {
+	key []byte
+}
+
+func NewSigner(key []byte) (*Signer, error) {
+	if len(key) < MinKeySize {
+		return nil, ErrKeyTooShort
+	}
+	return &Signer{key: append([]byte(nil), key...)}, nil
+}
+
+// RandomKey gera uma chave nova; tokens assinados com ela não sobrevivem a um
+// reinício, então serve apenas para desenvolvimento local
+func RandomKey() ([]byte, error) {
+	key := make([]byte, MinKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (s *Signer) Sign(c Claims) (string, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	return body + "." + base64.RawURLEncoding.EncodeToString(s.mac(body)), nil
+}
+
+// Parse confere a assinatura e devolve as claims; a validade é checada pelo Service
+func (s *Signer) Parse(token string) (Claims, error) {
+	body, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return Claims{}, ErrInvalidToken
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, s.mac(body)) {
+		return Claims{}, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	var c Claims
+	if err := json.Unmarshal(payload, &c); err != nil || c.ID == "" || c.UserID == "" {
+		return Claims{}, ErrInvalidToken
+	}
+	return c, nil
+}
+
+func (s *Signer) mac(body string) []byte {
+	m := hmac.New(sha256.New, s.key)
+	m.Write([]byte(body))
+	return m.Sum(nil)
+}
+
+// newTokenID gera o identificador único (jti) de um token
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,62 @@
+package verification
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func mustSigner(t *testing.T) *Signer {
+	t.Helper()
+	s, err := NewSigner(testKey)
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+	return s
+}
+
+func TestNewSigner_RejectsShortKey(t *testing.T) {
+	if _, err := NewSigner([]byte("short")); err != ErrKeyTooShort {
+		t.Fatalf("got %v, want %v", err, ErrKeyTooShort)
+	}
+	key, err := RandomKey()
+	if err != nil || len(key) != MinKeySize {
+		t.Fatalf("RandomKey: len=%d err=%v", len(key), err)
+	}
+}
+
+func TestSigner_SignAndParse(t *testing.T) {
+	s := mustSigner(t)
+	want := Claims{ID: "jti-1", UserID: "u1", OrgID: "acme", Email: "ana@example.com", ExpiresAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)}
+
+	token, err := s.Sign(want)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	got, err := s.Parse(token)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got != want {
+		t.Fatalf("claims: got %+v, want %+v", got, want)
+	}
+
+	body, sig, _ := strings.Cut(token, ".")
+	other, _ := NewSigner([]byte("fedcba9876543210fedcba9876543210"))
+	tampered := []string{
+		"",
+		"no-dot",
+		body + ".AAAA",
+		body + "x." + sig,
+	}
+	for _, tok := range tampered {
+		if _, err := s.Parse(tok); err != ErrInvalidToken {
+			t.Fatalf("Parse(%q): got %v, want %v", tok, err, ErrInvalidToken)
+		}
+	}
+	if _, err := other.Parse(token); err != ErrInvalidToken {
+		t.Fatalf("other key: got %v, want %v", err, ErrInvalidToken)
+	}
+}
@@ -18,6 +18,9 @@ module "apigw" {
   env                  = var.env
   lambda_invoke_arn    = module.lambda.lambda_invoke_arn
   lambda_function_name = module.lambda.lambda_function_name
+
+  authorizer_invoke_arn    = module.lambda.authorizer_invoke_arn
+  authorizer_function_name = module.lambda.authorizer_function_name
   depends_on = [module.lambda]
 }
 
@@ -49,6 +52,13 @@ module "lambda" {
   iam_policy_attachment_name = module.iam.lambda_policy_attachment_name
   provisioned_concurrency    = var.provisioned_concurrency
 
+  environment = {
+    JWT_SECRET               = var.jwt_secret
+    NOTIFY_WEBHOOK_URL       = var.notify_webhook_url
+    NOTIFY_WEBHOOK_TOKEN     = var.notify_webhook_token
+    VERIFICATION_SIGNING_KEY = var.verification_signing_key
+  }
+
   depends_on = [module.iam, module.ecr]
 }
 
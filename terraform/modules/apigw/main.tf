@@ -27,6 +27,19 @@ resource "aws_apigatewayv2_integration" "lambda_integration" {
   integration_method     = "POST"
 }
 
+# Autorizador Lambda (REQUEST, respostas simples). Sem cache e sem
+# identity_sources: requisições sem credencial também chegam a ele e seguem
+# anônimas para as rotas públicas; só credencial inválida é negada.
+resource "aws_apigatewayv2_authorizer" "lambda_authorizer" {
+  api_id                            = aws_apigatewayv2_api.http_api.id
+  name                              = "${var.env}-token-authorizer"
+  authorizer_type                   = "REQUEST"
+  authorizer_uri                    = var.authorizer_invoke_arn
+  authorizer_payload_format_version = "2.0"
+  enable_simple_responses           = true
+  authorizer_result_ttl_in_seconds  = 0
+}
+
 resource "aws_apigatewayv2_route" "health_route" {
   api_id    = aws_apigatewayv2_api.http_api.id
   route_key = "GET /health"
@@ -34,15 +47,19 @@ resource "aws_apigatewayv2_route" "health_route" {
 }
 
 resource "aws_apigatewayv2_route" "any_root" {
-  api_id    = aws_apigatewayv2_api.http_api.id
-  route_key = "ANY /"
-  target    = "integrations/${aws_apigatewayv2_integration.lambda_integration.id}"
+  api_id             = aws_apigatewayv2_api.http_api.id
+  route_key          = "ANY /"
+  target             = "integrations/${aws_apigatewayv2_integration.lambda_integration.id}"
+  authorization_type = "CUSTOM"
+  authorizer_id      = aws_apigatewayv2_authorizer.lambda_authorizer.id
 }
 
 resource "aws_apigatewayv2_route" "any_proxy" {
-  api_id    = aws_apigatewayv2_api.http_api.id
-  route_key = "ANY /{proxy+}"
-  target    = "integrations/${aws_apigatewayv2_integration.lambda_integration.id}"
+  api_id             = aws_apigatewayv2_api.http_api.id
+  route_key          = "ANY /{proxy+}"
+  target             = "integrations/${aws_apigatewayv2_integration.lambda_integration.id}"
+  authorization_type = "CUSTOM"
+  authorizer_id      = aws_apigatewayv2_authorizer.lambda_authorizer.id
 }
 
 resource "aws_apigatewayv2_stage" "default_stage" {
@@ -87,4 +104,12 @@ resource "aws_lambda_permission" "api_invoke" {
   function_name = var.lambda_function_name
   principal     = "apigateway.amazonaws.com"
   source_arn    = "${aws_apigatewayv2_api.http_api.execution_arn}/*/*"
-}
\ No newline at end of file
+}
+
+resource "aws_lambda_permission" "authorizer_invoke" {
+  statement_id  = "AllowAPIGatewayInvokeAuthorizer"
+  action        = "lambda:InvokeFunction"
+  function_name = var.authorizer_function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "${aws_apigatewayv2_api.http_api.execution_arn}/authorizers/${aws_apigatewayv2_authorizer.lambda_authorizer.id}"
+}
@@ -13,6 +13,16 @@ variable "lambda_function_name" {
   description = "Lambda function name"
 }
 
+variable "authorizer_invoke_arn" {
+  type        = string
+  description = "Invoke ARN do Lambda autorizador"
+}
+
+variable "authorizer_function_name" {
+  type        = string
+  description = "Nome do Lambda autorizador"
+}
+
 variable "custom_domain_name" {
   type        = string
   description = "Nome do domínio customizado (opcional)"
@@ -1,3 +1,7 @@
+locals {
+  environment = { for k, v in var.environment : k => v if v != "" }
+}
+
 resource "aws_lambda_function" "golang_lambda" {
   function_name = "${var.env}-golang-api"
   role          = var.lambda_execution_role_arn
@@ -10,6 +14,11 @@ resource "aws_lambda_function" "golang_lambda" {
   publish       = true
   architectures = ["x86_64"]
 
+  # Configuração e segredos da aplicação; variáveis vazias ficam de fora
+  environment {
+    variables = local.environment
+  }
+
   tags = {
     Name        = "${var.env}-golang-api"
     Environment = var.env
@@ -25,6 +34,30 @@ resource "aws_lambda_function" "golang_lambda" {
   }
 }
 
+# Mesma imagem no papel de autorizador da HTTP API. Recebe o mesmo ambiente
+# da API: confere os tokens com as mesmas chaves de assinatura.
+resource "aws_lambda_function" "authorizer" {
+  function_name = "${var.env}-golang-api-authorizer"
+  role          = var.lambda_execution_role_arn
+
+  package_type = "Image"
+  image_uri    = "${var.ecr_repository_url}:${var.image_tag}"
+
+  timeout       = 5
+  memory_size   = 128
+  architectures = ["x86_64"]
+
+  environment {
+    variables = merge(local.environment, { LAMBDA_MODE = "authorizer" })
+  }
+
+  tags = {
+    Name        = "${var.env}-golang-api-authorizer"
+    Environment = var.env
+    Project     = "cloud-architecture-golang"
+  }
+}
+
 # Alias para staging (opcional, pode ser removido se não necessário)
 resource "aws_lambda_alias" "staging" {
   name             = "staging"
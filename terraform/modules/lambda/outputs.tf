@@ -12,4 +12,12 @@ output "lambda_invoke_arn" {
 
 output "lambda_alias_arn" {
   value = aws_lambda_alias.staging.arn
-}
\ No newline at end of file
+}
+
+output "authorizer_invoke_arn" {
+  value = aws_lambda_function.authorizer.invoke_arn
+}
+
+output "authorizer_function_name" {
+  value = aws_lambda_function.authorizer.function_name
+}
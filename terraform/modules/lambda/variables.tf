@@ -26,4 +26,11 @@ variable "provisioned_concurrency" {
   type        = number
   description = "Número de execuções concorrentes provisionadas"
   default     = 0
-}
\ No newline at end of file
+}
+
+variable "environment" {
+  type        = map(string)
+  description = "Variáveis de ambiente da função (valores vazios são ignorados)"
+  default     = {}
+  sensitive   = true
+}
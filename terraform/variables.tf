@@ -62,4 +62,30 @@ variable "alias" {
   description = "Alias para o workspace Prometheus"
   type        = string
   default     = "prometheus"
-}
\ No newline at end of file
+}
+
+variable "notify_webhook_url" {
+  type        = string
+  description = "URL do serviço que entrega os emails de verificação e de redefinição de senha; vazio desliga os dois fluxos"
+  default     = ""
+}
+
+variable "notify_webhook_token" {
+  type        = string
+  description = "Token enviado ao serviço de notificações no header Authorization"
+  default     = ""
+  sensitive   = true
+}
+
+variable "verification_signing_key" {
+  type        = string
+  description = "Chave (32+ bytes) que assina os links de verificação de email; obrigatória com notify_webhook_url"
+  default     = ""
+  sensitive   = true
+}
+
+variable "jwt_secret" {
+  type        = string
+  description = "Chave HS256 (32+ bytes) que assina os tokens; a mesma em todas as instâncias do Lambda"
+  sensitive   = true
+}